@@ -191,6 +191,10 @@ func CmpResponse(t testing.TB,
 // empty. If you want to ignore the body response, use td.Ignore()
 // explicitly.
 //
+// In case of failure, paths are rendered as JSON pointers using json
+// struct tag names, unless a path style has been explicitly
+// configured in "t" (if it is a *td.T) or in td.DefaultContextConfig.
+//
 // See TestAPI type and its methods for more flexible tests.
 func CmpJSONResponse(t testing.TB,
 	req *http.Request,
@@ -199,7 +203,13 @@ func CmpJSONResponse(t testing.TB,
 	args ...interface{},
 ) bool {
 	t.Helper()
-	return CmpMarshaledResponse(t,
+
+	tt := td.NewT(t)
+	if tt.Config.PathStyle == td.PathStyleDefault {
+		tt = tt.PathStyle(td.PathStyleJSONPointer)
+	}
+
+	return CmpMarshaledResponse(tt,
 		req,
 		handler,
 		json.Unmarshal,
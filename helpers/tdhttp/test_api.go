@@ -34,6 +34,7 @@ type TestAPI struct {
 	t       *td.T
 	handler http.Handler
 	name    string
+	hooks   []*td.Hooks
 
 	sentAt       time.Time
 	response     *httptest.ResponseRecorder
@@ -44,6 +45,9 @@ type TestAPI struct {
 	// autoDumpResponse dumps the received response when a test fails.
 	autoDumpResponse bool
 	responseDumped   bool
+
+	// jsonDiff renders CmpJSONBody failures as JSON diffs.
+	jsonDiff bool
 }
 
 // NewTestAPI creates a TestAPI that can be used to test routes of the
@@ -77,7 +81,7 @@ func NewTestAPI(tb testing.TB, handler http.Handler) *TestAPI {
 // With creates a new *TestAPI instance copied from "t", but resetting
 // the testing.TB instance the tests are based on to "tb". The
 // returned instance is independent from "t", sharing only the same
-// handler.
+// handler and sets of hooks (see WithHooks).
 //
 // It is typically used when the *TestAPI instance is "reused" in
 // sub-tests, as in:
@@ -102,12 +106,34 @@ func NewTestAPI(tb testing.TB, handler http.Handler) *TestAPI {
 // See Run method for another way to handle subtests.
 func (t *TestAPI) With(tb testing.TB) *TestAPI {
 	return &TestAPI{
-		t:                td.NewT(tb),
+		t:                t.newT(tb),
 		handler:          t.handler,
+		hooks:            t.hooks,
 		autoDumpResponse: t.autoDumpResponse,
+		jsonDiff:         t.jsonDiff,
 	}
 }
 
+// WithHooks uses the hooks of "sets" in addition to those already
+// used by t, when comparing status, headers and bodies. These sets
+// are kept by instances returned by With and passed by Run. It
+// returns t. See td.Hooks type for details.
+//
+//   ta := tdhttp.NewTestAPI(t, mux).WithHooks(testutil.Hooks)
+func (t *TestAPI) WithHooks(sets ...*td.Hooks) *TestAPI {
+	t.hooks = append(append([]*td.Hooks{}, t.hooks...), sets...)
+	t.t = t.t.WithHooks(sets...)
+	return t
+}
+
+func (t *TestAPI) newT(tb testing.TB) *td.T {
+	nt := td.NewT(tb)
+	if len(t.hooks) > 0 {
+		nt = nt.WithHooks(t.hooks...)
+	}
+	return nt
+}
+
 // T returns the internal instance of *td.T.
 func (t *TestAPI) T() *td.T {
 	return t.t
@@ -116,7 +142,12 @@ func (t *TestAPI) T() *td.T {
 // Run runs "f" as a subtest of t called "name".
 func (t *TestAPI) Run(name string, f func(t *TestAPI)) bool {
 	return t.t.Run(name, func(tdt *td.T) {
-		f(NewTestAPI(tdt, t.handler))
+		f(&TestAPI{
+			t:        tdt,
+			handler:  t.handler,
+			hooks:    t.hooks,
+			jsonDiff: t.jsonDiff,
+		})
 	})
 }
 
@@ -127,6 +158,21 @@ func (t *TestAPI) AutoDumpResponse() *TestAPI {
 	return t
 }
 
+// JSONDiff allows to render the failures of the following
+// CmpJSONBody calls as the received JSON body, indented, in which
+// mismatching members are highlighted and annotated with the reason
+// of the mismatch and the expected value. It is kept by instances
+// returned by With and passed by Run. See td.ContextConfig.JSONDiff
+// for details. It returns t.
+//
+//   ta := tdhttp.NewTestAPI(t, mux).JSONDiff()
+//
+// Note that ta.JSONDiff() acts as ta.JSONDiff(true).
+func (t *TestAPI) JSONDiff(enable ...bool) *TestAPI {
+	t.jsonDiff = len(enable) == 0 || enable[0]
+	return t
+}
+
 // Name allows to name the series of tests that follow. This name is
 // used as a prefix for all following tests, in case of failure to
 // qualify each test. If len(args) > 1 and the first item of "args" is
@@ -677,12 +723,54 @@ func (t *TestAPI) CmpBody(expectedBody interface{}) *TestAPI {
 //     "age":  26
 //   }`))
 //
+// In case of failure, paths are rendered as JSON pointers using json
+// struct tag names, as in "Response.Body/items/3/created_at", unless
+// a path style has been explicitly configured in the *td.T instance
+// or in td.DefaultContextConfig.
+//
+// If JSON diff is enabled, using JSONDiff method or
+// td.ContextConfig.JSONDiff, a failure is rendered as the received
+// body, indented, in which mismatching members are highlighted and
+// annotated:
+//
+//   Response.Body: 1 JSON mismatch
+//     {
+//       "age": 26,  // ✗ values differ
+//                   //   expected: 27
+//       "id": 42,
+//       "name": "Bob"
+//     }
+//
 // It fails if no request has been sent yet.
 func (t *TestAPI) CmpJSONBody(expectedBody interface{}) *TestAPI {
 	t.t.Helper()
+	defer t.jsonPathStyle()()
+	if t.jsonDiff {
+		defer t.withJSONDiff()()
+	}
 	return t.CmpMarshaledBody(json.Unmarshal, expectedBody)
 }
 
+// withJSONDiff makes t.t render failures as JSON diffs. It returns a
+// function restoring the original t.t.
+func (t *TestAPI) withJSONDiff() func() {
+	orig := t.t
+	t.t = t.t.JSONDiff()
+	return func() { t.t = orig }
+}
+
+// jsonPathStyle makes t.t render paths as JSON pointers, if no path
+// style has been explicitly configured. It returns a function
+// restoring the original t.t.
+func (t *TestAPI) jsonPathStyle() func() {
+	if t.t.Config.PathStyle != td.PathStyleDefault {
+		return func() {}
+	}
+	orig := t.t
+	t.t = t.t.PathStyle(td.PathStyleJSONPointer)
+	return func() { t.t = orig }
+}
+
 // CmpXMLBody tests that the last request response body can be
 // encoding/xml.Unmarshall'ed and that it matches
 // expectedBody. expectedBody can be any type encoding/xml can
@@ -884,6 +972,9 @@ func (t *TestAPI) dumpResponse() {
 // https://pkg.go.dev/github.com/maxatome/go-testdeep/td#T.Anchor
 //
 // See A method for a shorter synonym of Anchor.
+//
+// Since go 1.18, the generic tdhttp.Anchor and tdhttp.A functions
+// avoid the type assertion, as in tdhttp.A[uint64](ta, td.NotZero()).
 func (t *TestAPI) Anchor(operator td.TestDeep, model ...interface{}) interface{} {
 	return t.t.Anchor(operator, model...)
 }
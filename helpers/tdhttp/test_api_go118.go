@@ -0,0 +1,52 @@
+// Copyright (c) 2021, Maxime Soulé
+// All rights reserved.
+//
+// This source code is licensed under the BSD-style license found in the
+// LICENSE file in the root directory of this source tree.
+
+//go:build go1.18
+// +build go1.18
+
+package tdhttp
+
+import (
+	"github.com/maxatome/go-testdeep/td"
+)
+
+// Anchor is the generic counterpart of (*TestAPI).Anchor method. It
+// returns a value of type X allowing to anchor the TestDeep operator
+// "operator" in a go classic litteral like a struct, slice, array or
+// map value, without any type assertion:
+//
+//   ta := tdhttp.NewTestAPI(tt, mux)
+//
+//   ta.Get("/person/42").
+//     CmpStatus(http.StatusOK).
+//     CmpJSONBody(Person{
+//       ID:   tdhttp.Anchor[uint64](ta, td.NotZero()),
+//       Name: "Bob",
+//       Age:  26,
+//     })
+//
+// See td.Anchor function documentation for details
+// https://pkg.go.dev/github.com/maxatome/go-testdeep/td#Anchor
+//
+// See A function for a shorter synonym of Anchor.
+func Anchor[X any](ta *TestAPI, operator td.TestDeep) X {
+	return td.Anchor[X](ta.t, operator)
+}
+
+// A is a synonym for Anchor function.
+//
+//   ta := tdhttp.NewTestAPI(tt, mux)
+//
+//   ta.Get("/person/42").
+//     CmpStatus(http.StatusOK).
+//     CmpJSONBody(Person{
+//       ID:   tdhttp.A[uint64](ta, td.NotZero()),
+//       Name: "Bob",
+//       Age:  26,
+//     })
+func A[X any](ta *TestAPI, operator td.TestDeep) X {
+	return td.Anchor[X](ta.t, operator)
+}
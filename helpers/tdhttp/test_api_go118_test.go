@@ -0,0 +1,54 @@
+// Copyright (c) 2021, Maxime Soulé
+// All rights reserved.
+//
+// This source code is licensed under the BSD-style license found in the
+// LICENSE file in the root directory of this source tree.
+
+//go:build go1.18
+// +build go1.18
+
+package tdhttp_test
+
+import (
+	"testing"
+
+	"github.com/maxatome/go-testdeep/helpers/tdhttp"
+	"github.com/maxatome/go-testdeep/helpers/tdutil"
+	"github.com/maxatome/go-testdeep/td"
+)
+
+func TestAnchorGeneric(t *testing.T) {
+	mux := server()
+
+	type ReqBody struct {
+		Hey int `json:"hey"`
+	}
+	type Resp struct {
+		Method  string  `json:"method"`
+		ReqBody ReqBody `json:"body"`
+	}
+
+	ta := tdhttp.NewTestAPI(tdutil.NewT("test"), mux)
+	td.CmpFalse(t,
+		ta.PostJSON("/any/json", ReqBody{Hey: 123}).
+			CmpStatus(200).
+			CmpJSONBody(Resp{
+				Method: tdhttp.Anchor[string](ta, td.Re(`^(?i)post\z`)),
+				ReqBody: ReqBody{
+					Hey: tdhttp.A[int](ta, td.Between(120, 130)),
+				},
+			}).
+			Failed())
+
+	ta = tdhttp.NewTestAPI(tdutil.NewT("test"), mux)
+	td.CmpTrue(t,
+		ta.PostJSON("/any/json", ReqBody{Hey: 123}).
+			CmpStatus(200).
+			CmpJSONBody(Resp{
+				Method: "POST",
+				ReqBody: ReqBody{
+					Hey: tdhttp.A[int](ta, td.Gt(123)),
+				},
+			}).
+			Failed())
+}
@@ -553,6 +553,50 @@ func TestNewTestAPI(t *testing.T) {
 		td.CmpContains(t, mockT.LogBuf(), "Body cannot be empty when using CmpJSONBody")
 		td.Cmp(t, mockT.LogBuf(), td.Contains("Received response:\n"))
 
+		// JSON body mismatch, paths rendered as JSON pointers by default
+		type JResp struct {
+			Method string `json:"method"`
+		}
+		mockT = tdutil.NewT("test")
+		td.CmpTrue(t,
+			tdhttp.NewTestAPI(mockT, mux).
+				Get("/any/json").
+				CmpJSONBody(JResp{Method: "POST"}).
+				Failed())
+		td.CmpContains(t, mockT.LogBuf(), "Response.Body/method: values differ\n")
+
+		// JSON body mismatch, explicit path style
+		mockT = tdutil.NewT("test")
+		td.CmpTrue(t,
+			tdhttp.NewTestAPI(td.NewT(mockT).PathStyle(td.PathStyleGo), mux).
+				Get("/any/json").
+				CmpJSONBody(JResp{Method: "POST"}).
+				Failed())
+		td.CmpContains(t, mockT.LogBuf(), "Response.Body.Method: values differ\n")
+
+		// JSON body mismatch, rendered as JSON diff
+		mockT = tdutil.NewT("test")
+		td.CmpTrue(t,
+			tdhttp.NewTestAPI(mockT, mux).
+				JSONDiff().
+				Get("/any/json").
+				CmpJSONBody(JResp{Method: "POST"}).
+				Failed())
+		td.CmpContains(t, mockT.LogBuf(), `Response.Body: 1 JSON mismatch
+	{
+	  "method": "GET"  // ✗ values differ
+	                   //   expected: "POST"
+	}`)
+
+		// JSON diff enabled in the *td.T instance
+		mockT = tdutil.NewT("test")
+		td.CmpTrue(t,
+			tdhttp.NewTestAPI(td.NewT(mockT).JSONDiff(), mux).
+				Get("/any/json").
+				CmpJSONBody(td.JSON(`{"method": "POST"}`)).
+				Failed())
+		td.CmpContains(t, mockT.LogBuf(), "Response.Body: 1 JSON mismatch\n")
+
 		// No XML body
 		mockT = tdutil.NewT("test")
 		td.CmpTrue(t,
@@ -598,6 +642,28 @@ func TestWith(t *testing.T) {
 	td.CmpContains(t, nt.LogBuf(), "X-Testdeep-Method: HEAD") // Header dumped
 }
 
+func TestWithHooks(t *testing.T) {
+	mux := server()
+
+	hooks := td.NewHooks("fold").AddCmpHooks(strings.EqualFold)
+
+	ta := tdhttp.NewTestAPI(tdutil.NewT("test1"), mux)
+	td.CmpTrue(t, ta.Get("/any").CmpBody("get!").Failed())
+
+	ta = tdhttp.NewTestAPI(tdutil.NewT("test2"), mux).WithHooks(hooks)
+	td.CmpFalse(t, ta.Get("/any").CmpBody("get!").Failed())
+
+	// Hooks are kept by With
+	nta := ta.With(tdutil.NewT("test3"))
+	td.CmpFalse(t, nta.Get("/any").CmpBody("get!").Failed())
+
+	// and Run
+	ok := ta.Run("Test", func(ta *tdhttp.TestAPI) {
+		td.CmpFalse(t, ta.Get("/any").CmpBody("get!").Failed())
+	})
+	td.CmpTrue(t, ok)
+}
+
 func TestOr(t *testing.T) {
 	mux := server()
 
@@ -138,6 +138,14 @@ func shouldContinue(t *td.T, testName string, ret []reflect.Value) bool {
 //     })
 //   }
 //
+// Sets of hooks shared between packages can be used the same way:
+//
+//   func TestSuite(t *testing.T) {
+//     tdsuite.Run(t, &Suite{}, td.ContextConfig{
+//       Hooks: []*td.Hooks{testutil.Hooks},
+//     })
+//   }
+//
 // Run returns true if all the tests succeeded, false otherwise.
 //
 // Note that if "suite" is not empty struct, it should probably be a
@@ -11,6 +11,7 @@ import (
 	"math"
 	"reflect"
 	"sync"
+	"unsafe"
 
 	"github.com/maxatome/go-testdeep/internal/color"
 )
@@ -20,18 +21,19 @@ type anchor struct {
 	Operator reflect.Value // Operator is a td.TestDeep behind
 }
 
-// Info gathers all anchors information.
+// Info gathers all anchors information. It is safe for concurrent use.
 type Info struct {
 	sync.Mutex
 	index   int
 	persist bool
-	anchors map[interface{}]anchor
+	inUse   int // number of comparisons in progress, see BeginUse
+	anchors map[interface{}]*anchor
 }
 
 // NewInfo returns a new instance of *Info.
 func NewInfo() *Info {
 	return &Info{
-		anchors: map[interface{}]anchor{},
+		anchors: map[interface{}]*anchor{},
 	}
 }
 
@@ -41,13 +43,14 @@ func (i *Info) AddAnchor(typ reflect.Type, op reflect.Value) reflect.Value {
 	i.Lock()
 	defer i.Unlock()
 
-	anc, key := i.build(typ)
+	anc := i.build(typ)
+	key, _ := keyOf(anc)
 
 	if i.anchors == nil {
-		i.anchors = map[interface{}]anchor{}
+		i.anchors = map[interface{}]*anchor{}
 	}
 
-	i.anchors[key] = anchor{
+	i.anchors[key] = &anchor{
 		Anchor:   anc,
 		Operator: op,
 	}
@@ -83,6 +86,34 @@ func (i *Info) ResetAnchors(force bool) {
 	}
 }
 
+// BeginUse records that a new comparison possibly using anchors
+// starts. EndUse has to be called at the end of this comparison.
+func (i *Info) BeginUse() {
+	i.Lock()
+	defer i.Unlock()
+	i.inUse++
+}
+
+// EndUse records that a comparison started by BeginUse ends. If no
+// other comparison is in progress and persistence is disabled, all
+// anchors are removed, as ResetAnchors(false) does.
+func (i *Info) EndUse() {
+	i.Lock()
+	defer i.Unlock()
+
+	if i.inUse > 0 {
+		i.inUse--
+	}
+	if i.inUse > 0 || i.persist {
+		return
+	}
+
+	for k := range i.anchors {
+		delete(i.anchors, k)
+	}
+	i.index = 0
+}
+
 func (i *Info) nextIndex() (n int) {
 	n = i.index
 	i.index++
@@ -104,8 +135,34 @@ func (i *Info) ResolveAnchor(v reflect.Value) (reflect.Value, bool) {
 		return v, false
 	}
 
-	var key interface{}
-sw:
+	key, ok := keyOf(v)
+	if !ok {
+		return v, false
+	}
+
+	i.Lock()
+	defer i.Unlock()
+	if anchor, ok := i.anchors[key]; ok {
+		return anchor.Operator, true
+	}
+	return v, false
+}
+
+// funcKey is the key of an anchored function, it is the address of
+// its closure.
+type funcKey uintptr
+
+// compositeKey is the key of an anchored struct or array, whose type
+// is not anchorable by itself. "key" is the key of the field or item
+// holding the unique value of this struct or array.
+type compositeKey struct {
+	typ reflect.Type
+	key interface{}
+}
+
+// keyOf returns the key of "v" usable in an anchors map, or false if
+// "v" cannot be an anchor.
+func keyOf(v reflect.Value) (interface{}, bool) {
 	switch v.Kind() {
 	case reflect.Int,
 		reflect.Int8,
@@ -123,72 +180,244 @@ sw:
 		reflect.Complex64,
 		reflect.Complex128,
 		reflect.String:
-		key = v.Interface()
+		return v.Interface(), true
 
 	case reflect.Chan,
 		reflect.Map,
 		reflect.Slice,
 		reflect.Ptr:
-		key = v.Pointer()
+		if v.IsNil() {
+			return nil, false
+		}
+		return v.Pointer(), true
+
+	case reflect.Func:
+		if v.IsNil() {
+			return nil, false
+		}
+		// v.Pointer() returns the code pointer, the same for all
+		// functions created by reflect.MakeFunc, so use the closure one
+		fn := reflect.New(v.Type())
+		fn.Elem().Set(v)
+		return funcKey(*(*uintptr)(unsafe.Pointer(fn.Pointer()))), true
+
+	case reflect.Interface:
+		if v.IsNil() {
+			return nil, false
+		}
+		return keyOf(v.Elem())
 
 	case reflect.Struct:
 		typ := v.Type()
-		if typ.Comparable() {
-			// Check for anchorable types. No need of 2 passes here.
-			for _, at := range AnchorableTypes {
-				if typ == at.typ || at.typ.ConvertibleTo(typ) {
-					key = v.Interface()
-					break sw
+		if isAnchorableType(typ) {
+			if !typ.Comparable() {
+				return nil, false
+			}
+			return v.Interface(), true
+		}
+		if idx := uniqueIndex(typ); idx >= 0 {
+			if key, ok := keyOf(v.Field(idx)); ok {
+				return compositeKey{typ: typ, key: key}, true
+			}
+		}
+
+	case reflect.Array:
+		if uniqueIndex(v.Type()) == 0 {
+			if key, ok := keyOf(v.Index(0)); ok {
+				return compositeKey{typ: v.Type(), key: key}, true
+			}
+		}
+	}
+	return nil, false
+}
+
+func isAnchorableType(typ reflect.Type) bool {
+	for _, at := range AnchorableTypes {
+		if typ == at.typ || at.typ.ConvertibleTo(typ) {
+			return true
+		}
+	}
+	return false
+}
+
+var (
+	uniqueIndexesMu sync.Mutex
+	uniqueIndexes   = map[reflect.Type]int{}
+)
+
+func resetUniqueIndexes() {
+	uniqueIndexesMu.Lock()
+	uniqueIndexes = map[reflect.Type]int{}
+	uniqueIndexesMu.Unlock()
+}
+
+// uniqueIndex returns the index of the field (for a struct) or the
+// item (for an array, always 0) able to hold a unique value, so
+// values of type "typ" can be anchors. It returns -1 if no such field
+// or item exists.
+//
+// For structs, only exported fields are concerned. Fields whose kind
+// allows to generate unique values by themselves (numbers and
+// strings) are preferred to the others.
+func uniqueIndex(typ reflect.Type) int {
+	uniqueIndexesMu.Lock()
+	idx, ok := uniqueIndexes[typ]
+	uniqueIndexesMu.Unlock()
+	if ok {
+		return idx
+	}
+
+	idx = -1
+	switch typ.Kind() {
+	case reflect.Struct:
+	passes:
+		for _, byValue := range [...]bool{true, false} {
+			for n := 0; n < typ.NumField(); n++ {
+				f := typ.Field(n)
+				if f.PkgPath == "" && isByValue(f.Type) == byValue && canBeUnique(f.Type) {
+					idx = n
+					break passes
 				}
 			}
 		}
-		fallthrough
 
-	default:
-		return v, false
+	case reflect.Array:
+		if typ.Len() > 0 && canBeUnique(typ.Elem()) {
+			idx = 0
+		}
 	}
 
-	i.Lock()
-	defer i.Unlock()
-	if anchor, ok := i.anchors[key]; ok {
-		return anchor.Operator, true
+	uniqueIndexesMu.Lock()
+	uniqueIndexes[typ] = idx
+	uniqueIndexesMu.Unlock()
+	return idx
+}
+
+// isByValue returns true if values of type "typ" are unique by
+// themselves, not because of their address.
+func isByValue(typ reflect.Type) bool {
+	switch typ.Kind() {
+	case reflect.Int,
+		reflect.Int8,
+		reflect.Int16,
+		reflect.Int32,
+		reflect.Int64,
+		reflect.Uint,
+		reflect.Uint8,
+		reflect.Uint16,
+		reflect.Uint32,
+		reflect.Uint64,
+		reflect.Uintptr,
+		reflect.Float32,
+		reflect.Float64,
+		reflect.Complex64,
+		reflect.Complex128,
+		reflect.String:
+		return true
 	}
-	return v, false
+	return false
+}
+
+// canBeUnique returns true if unique values of type "typ" can be
+// built by build method.
+func canBeUnique(typ reflect.Type) bool {
+	switch typ.Kind() {
+	case reflect.Bool, reflect.UnsafePointer, reflect.Invalid:
+		return false
+	case reflect.Slice, reflect.Ptr:
+		// All zero-sized values share the same address
+		return typ.Elem().Size() > 0
+	case reflect.Struct:
+		return isAnchorableType(typ) || uniqueIndex(typ) >= 0
+	case reflect.Array:
+		return uniqueIndex(typ) >= 0
+	case reflect.Interface:
+		return interfaceWrapper(typ) != nil
+	}
+	return true
+}
+
+var (
+	interfaceWrappersMu sync.Mutex
+	interfaceWrappers   = map[reflect.Type]reflect.Type{}
+)
+
+// interfaceWrapper returns a struct type implementing the interface
+// type "typ", whose last field is an int used to hold a unique value.
+// It returns nil if such a type cannot be created, typically because
+// "typ" has unexported methods.
+func interfaceWrapper(typ reflect.Type) (wrapper reflect.Type) {
+	interfaceWrappersMu.Lock()
+	defer interfaceWrappersMu.Unlock()
+
+	wrapper, ok := interfaceWrappers[typ]
+	if ok {
+		return
+	}
+
+	defer func() {
+		if recover() != nil {
+			wrapper = nil
+		}
+		if wrapper != nil && !wrapper.Implements(typ) {
+			wrapper = nil
+		}
+		interfaceWrappers[typ] = wrapper
+	}()
+
+	var fields []reflect.StructField
+	if typ.NumMethod() > 0 {
+		fields = append(fields, reflect.StructField{
+			Name:      "Interface",
+			Type:      typ,
+			Anonymous: true,
+		})
+	}
+	fields = append(fields, reflect.StructField{
+		Name: "TestDeepAnchor",
+		Type: reflect.TypeOf(0),
+	})
+	return reflect.StructOf(fields)
 }
 
-func (i *Info) setInt(typ reflect.Type, min int64) (reflect.Value, interface{}) {
+func (i *Info) setInt(typ reflect.Type, min int64) reflect.Value {
 	nvm := reflect.New(typ).Elem()
 	nvm.SetInt(min + int64(i.nextIndex()))
-	return nvm, nvm.Interface()
+	return nvm
 }
 
-func (i *Info) setUint(typ reflect.Type, max uint64) (reflect.Value, interface{}) {
+func (i *Info) setUint(typ reflect.Type, max uint64) reflect.Value {
 	nvm := reflect.New(typ).Elem()
 	nvm.SetUint(max - uint64(i.nextIndex()))
-	return nvm, nvm.Interface()
+	return nvm
 }
 
-func (i *Info) setFloat(typ reflect.Type, min float64) (reflect.Value, interface{}) {
+func (i *Info) setFloat(typ reflect.Type, min float64) reflect.Value {
 	nvm := reflect.New(typ).Elem()
 	nvm.SetFloat(min + float64(i.nextIndex()))
-	return nvm, nvm.Interface()
+	return nvm
 }
 
-func (i *Info) setComplex(typ reflect.Type, min float64) (reflect.Value, interface{}) {
+func (i *Info) setComplex(typ reflect.Type, min float64) reflect.Value {
 	nvm := reflect.New(typ).Elem()
 	min += float64(i.nextIndex())
 	nvm.SetComplex(complex(min, min))
-	return nvm, nvm.Interface()
+	return nvm
 }
 
-// build builds a new value of type "typ" and returns it under two
-// forms:
-//   - the new value itself as a reflect.Value;
-//   - an interface{} usable as a key in an AnchorsSet map.
+// build builds a new unique value of type "typ" and returns it.
 //
-// It panics if "typ" kind is not recognized or if it is a
-// non-anchorable struct.
-func (i *Info) build(typ reflect.Type) (reflect.Value, interface{}) {
+// Values of reference kinds (chan, func, map, pointer and slice) are
+// unique thanks to their address. A struct value is unique thanks to
+// its registered builder (see AddAnchorableStructType) or, if its type
+// is not registered, thanks to one of its exported fields. An array
+// value is unique thanks to its first item. An interface value is
+// unique thanks to the struct type it contains, built using
+// reflect.StructOf.
+//
+// It panics if "typ" kind is not supported, as bool kind, or if
+// no unique value can be built for "typ".
+func (i *Info) build(typ reflect.Type) reflect.Value {
 	// For each numeric type, anchor the operator on a number close to
 	// the limit of this type, but not at the extreme limit to avoid
 	// edge cases where these limits are used in real world and so avoid
@@ -231,42 +460,74 @@ func (i *Info) build(typ reflect.Type) (reflect.Value, interface{}) {
 	case reflect.String:
 		nvm := reflect.New(typ).Elem()
 		nvm.SetString(fmt.Sprintf("<testdeep@anchor#%d>", i.nextIndex()))
-		return nvm, nvm.Interface()
+		return nvm
 
 	case reflect.Chan:
-		nvm := reflect.MakeChan(typ, 0)
-		return nvm, nvm.Pointer()
+		return reflect.MakeChan(typ, 0)
 
 	case reflect.Map:
-		nvm := reflect.MakeMap(typ)
-		return nvm, nvm.Pointer()
+		return reflect.MakeMap(typ)
 
 	case reflect.Slice:
-		nvm := reflect.MakeSlice(typ, 0, 0)
-		return nvm, nvm.Pointer()
+		if !canBeUnique(typ) {
+			panic(color.Bad(typ.String() + " slice type is not supported as an anchor, as its items are zero-sized"))
+		}
+		// Capacity must not be 0 to get a unique address
+		return reflect.MakeSlice(typ, 0, 1)
 
 	case reflect.Ptr:
-		nvm := reflect.New(typ.Elem())
-		return nvm, nvm.Pointer()
+		return reflect.New(typ.Elem())
+
+	case reflect.Func:
+		return reflect.MakeFunc(typ, func([]reflect.Value) []reflect.Value {
+			panic(color.Bad("an anchored " + typ.String() + " function cannot be called"))
+		})
+
+	case reflect.Interface:
+		wrapper := interfaceWrapper(typ)
+		if wrapper == nil {
+			panic(color.Bad(typ.String() + " interface type is not supported as an anchor"))
+		}
+		w := reflect.New(wrapper).Elem()
+		last := w.NumField() - 1
+		w.Field(last).Set(i.build(wrapper.Field(last).Type))
+		nvm := reflect.New(typ).Elem()
+		nvm.Set(w)
+		return nvm
 
 	case reflect.Struct:
 		// First pass for the exact type
 		for _, at := range AnchorableTypes {
 			if typ == at.typ {
-				nvm := at.builder.Call([]reflect.Value{reflect.ValueOf(i.nextIndex())})[0]
-				return nvm, nvm.Interface()
+				return at.builder.Call([]reflect.Value{reflect.ValueOf(i.nextIndex())})[0]
 			}
 		}
 		// Second pass for convertible type
 		for _, at := range AnchorableTypes {
 			if at.typ.ConvertibleTo(typ) {
-				nvm := at.builder.Call([]reflect.Value{reflect.ValueOf(i.nextIndex())})[0].
+				return at.builder.Call([]reflect.Value{reflect.ValueOf(i.nextIndex())})[0].
 					Convert(typ)
-				return nvm, nvm.Interface()
 			}
 		}
+		// Then use an exported field able to hold a unique value
+		if idx := uniqueIndex(typ); idx >= 0 {
+			nvm := reflect.New(typ).Elem()
+			nvm.Field(idx).Set(i.build(typ.Field(idx).Type))
+			return nvm
+		}
 		panic(color.Bad(typ.String() + " struct type is not supported as an anchor. Try AddAnchorableStructType"))
 
+	case reflect.Array:
+		if uniqueIndex(typ) < 0 {
+			panic(color.Bad(typ.String() + " array type is not supported as an anchor"))
+		}
+		nvm := reflect.New(typ).Elem()
+		nvm.Index(0).Set(i.build(typ.Elem()))
+		return nvm
+
+	case reflect.Bool:
+		panic(color.Bad("bool kind is not supported as an anchor, as only two values exist. Anchor the enclosing struct instead"))
+
 	default:
 		panic(typ.Kind().String() + " kind is not supported as an anchor")
 	}
@@ -7,9 +7,12 @@
 package anchors_test
 
 import (
+	"fmt"
+	"io"
 	"reflect"
 	"testing"
 	"time"
+	"unsafe"
 
 	"github.com/maxatome/go-testdeep/internal/anchors"
 	"github.com/maxatome/go-testdeep/internal/test"
@@ -27,6 +30,8 @@ func TestInfo(t *testing.T) {
 	test.IsFalse(t, i.DoAnchorsPersist())
 }
 
+type privIface interface{ priv() }
+
 func TestBuildResolveAnchor(t *testing.T) {
 	var i anchors.Info
 
@@ -64,6 +69,104 @@ func TestBuildResolveAnchor(t *testing.T) {
 		checkResolveAnchor(t, (map[string]bool)(nil), "map")
 		checkResolveAnchor(t, ([]int)(nil), "slice")
 		checkResolveAnchor(t, (*time.Time)(nil), "pointer")
+		checkResolveAnchor(t, (func(int) bool)(nil), "func")
+		checkResolveAnchor(t, [2]int{}, "array")
+	})
+
+	t.Run("AddAnchor reference kinds are unique", func(t *testing.T) {
+		for _, typ := range []reflect.Type{
+			reflect.TypeOf([]int{}),
+			reflect.TypeOf(func() {}),
+			reflect.TypeOf(map[int]bool{}),
+		} {
+			v1 := i.AddAnchor(typ, reflect.ValueOf("op1"))
+			v2 := i.AddAnchor(typ, reflect.ValueOf("op2"))
+
+			op, found := i.ResolveAnchor(v1)
+			test.IsTrue(t, found)
+			test.EqualStr(t, op.String(), "op1")
+
+			op, found = i.ResolveAnchor(v2)
+			test.IsTrue(t, found)
+			test.EqualStr(t, op.String(), "op2")
+		}
+
+		fn := i.AddAnchor(reflect.TypeOf(func() {}), reflect.ValueOf("fn"))
+		test.CheckPanic(t, func() { fn.Interface().(func())() },
+			"an anchored func() function cannot be called")
+	})
+
+	t.Run("AddAnchor interfaces", func(t *testing.T) {
+		for _, typ := range []reflect.Type{
+			reflect.TypeOf((*interface{})(nil)).Elem(),
+			reflect.TypeOf((*fmt.Stringer)(nil)).Elem(),
+			reflect.TypeOf((*io.ReadCloser)(nil)).Elem(),
+		} {
+			v := i.AddAnchor(typ, reflect.ValueOf("iface"))
+			test.IsTrue(t, v.Type() == typ)
+			test.IsTrue(t, v.Elem().Type().Implements(typ))
+
+			op, found := i.ResolveAnchor(v)
+			test.IsTrue(t, found)
+			test.EqualStr(t, op.String(), "iface")
+
+			// Also found when stored in an interface{}
+			var x interface{} = v.Interface()
+			op, found = i.ResolveAnchor(reflect.ValueOf(&x).Elem())
+			test.IsTrue(t, found)
+			test.EqualStr(t, op.String(), "iface")
+		}
+
+		// nil interface is never an anchor
+		var s fmt.Stringer
+		_, found := i.ResolveAnchor(reflect.ValueOf(&s).Elem())
+		test.IsFalse(t, found)
+	})
+
+	t.Run("AddAnchor structs with an exported field", func(t *testing.T) {
+		type withBool struct {
+			Ok   bool
+			priv int
+			Tags []string
+			ID   int
+		}
+		v := i.AddAnchor(reflect.TypeOf(withBool{}), reflect.ValueOf("withBool"))
+		test.IsTrue(t, v.Interface().(withBool).ID != 0)
+		test.IsTrue(t, v.Interface().(withBool).Tags == nil)
+
+		op, found := i.ResolveAnchor(v)
+		test.IsTrue(t, found)
+		test.EqualStr(t, op.String(), "withBool")
+
+		_, found = i.ResolveAnchor(reflect.ValueOf(withBool{ID: 12}))
+		test.IsFalse(t, found)
+
+		// Not comparable struct, using a slice field
+		type withSlice struct {
+			Ok   bool
+			Tags []string
+		}
+		v = i.AddAnchor(reflect.TypeOf(withSlice{}), reflect.ValueOf("withSlice"))
+		op, found = i.ResolveAnchor(v)
+		test.IsTrue(t, found)
+		test.EqualStr(t, op.String(), "withSlice")
+
+		_, found = i.ResolveAnchor(reflect.ValueOf(withSlice{Tags: []string{"a"}}))
+		test.IsFalse(t, found)
+
+		// Nested struct
+		type nested struct {
+			Ok  bool
+			Sub withSlice
+		}
+		v = i.AddAnchor(reflect.TypeOf(nested{}), reflect.ValueOf("nested"))
+		op, found = i.ResolveAnchor(v)
+		test.IsTrue(t, found)
+		test.EqualStr(t, op.String(), "nested")
+
+		// Sub-struct is not the anchor
+		_, found = i.ResolveAnchor(v.Field(1))
+		test.IsFalse(t, found)
 	})
 
 	t.Run("AddAnchor", func(t *testing.T) {
@@ -101,15 +204,41 @@ func TestBuildResolveAnchor(t *testing.T) {
 		// AddAnchor for builtin time.Time type
 		checkResolveAnchor(t, time.Time{}, "time.Time{}")
 
+		// AddAnchor for bool type
+		test.CheckPanic(t, func() {
+			i.AddAnchor(reflect.TypeOf(true), reflect.ValueOf(123))
+		}, "bool kind is not supported as an anchor, as only two values exist. Anchor the enclosing struct instead")
+
 		// AddAnchor for unknown type
 		test.CheckPanic(t, func() {
-			i.AddAnchor(reflect.TypeOf(func() {}), reflect.ValueOf(123))
-		}, "func kind is not supported as an anchor")
+			i.AddAnchor(reflect.TypeOf(unsafe.Pointer(nil)), reflect.ValueOf(123))
+		}, "unsafe.Pointer kind is not supported as an anchor")
 
-		// AddAnchor for unknown struct type
+		// AddAnchor for struct type without any field able to be unique
 		test.CheckPanic(t, func() {
 			i.AddAnchor(reflect.TypeOf(struct{}{}), reflect.ValueOf(123))
 		}, "struct {} struct type is not supported as an anchor. Try AddAnchorableStructType")
+		test.CheckPanic(t, func() {
+			i.AddAnchor(reflect.TypeOf(struct {
+				Ok   bool
+				priv int
+			}{}), reflect.ValueOf(123))
+		}, "struct { Ok bool; priv int } struct type is not supported as an anchor. Try AddAnchorableStructType")
+
+		// AddAnchor for array type without any item able to be unique
+		test.CheckPanic(t, func() {
+			i.AddAnchor(reflect.TypeOf([0]int{}), reflect.ValueOf(123))
+		}, "[0]int array type is not supported as an anchor")
+
+		// AddAnchor for slice of zero-sized items
+		test.CheckPanic(t, func() {
+			i.AddAnchor(reflect.TypeOf([]struct{}{}), reflect.ValueOf(123))
+		}, "[]struct {} slice type is not supported as an anchor, as its items are zero-sized")
+
+		// AddAnchor for interface with unexported methods
+		test.CheckPanic(t, func() {
+			i.AddAnchor(reflect.TypeOf((*privIface)(nil)).Elem(), reflect.ValueOf(123))
+		}, "anchors_test.privIface interface type is not supported as an anchor")
 
 		// Struct not comparable
 		type notComparable struct{ s []int }
@@ -175,6 +304,55 @@ func TestBuildResolveAnchor(t *testing.T) {
 		test.IsFalse(t, found)
 	})
 
+	t.Run("BeginUse/EndUse", func(t *testing.T) {
+		i := anchors.NewInfo()
+
+		v1 := i.AddAnchor(reflect.TypeOf(12), reflect.ValueOf("op1"))
+		v2 := i.AddAnchor(reflect.TypeOf(12), reflect.ValueOf("op2"))
+
+		// 2 comparisons in progress
+		i.BeginUse()
+		i.BeginUse()
+
+		_, found := i.ResolveAnchor(v1)
+		test.IsTrue(t, found)
+
+		i.EndUse()
+
+		// v1 still there, as a comparison is in progress
+		_, found = i.ResolveAnchor(v1)
+		test.IsTrue(t, found)
+
+		i.EndUse()
+
+		// No more comparisons in progress, all anchors are freed,
+		// even v2 that was never resolved
+		_, found = i.ResolveAnchor(v1)
+		test.IsFalse(t, found)
+		_, found = i.ResolveAnchor(v2)
+		test.IsFalse(t, found)
+
+		// Indexes are reset
+		v3 := i.AddAnchor(reflect.TypeOf(12), reflect.ValueOf("op3"))
+		test.EqualInt(t, int(v3.Int()), int(v1.Int()))
+
+		// With persistence, nothing is freed
+		i.SetAnchorsPersist(true)
+		i.BeginUse()
+		i.EndUse()
+		_, found = i.ResolveAnchor(v3)
+		test.IsTrue(t, found)
+
+		i.SetAnchorsPersist(false)
+		i.BeginUse()
+		i.EndUse()
+		_, found = i.ResolveAnchor(v3)
+		test.IsFalse(t, found)
+
+		// Unbalanced EndUse does not break anything
+		i.EndUse()
+	})
+
 	t.Run("skip", func(t *testing.T) {
 		var i *anchors.Info
 
@@ -82,6 +82,8 @@ func AddAnchorableStructType(fn interface{}) error {
 				typ:     typ,
 				builder: vfn,
 			})
+			// Structs containing this new type can now be anchored
+			resetUniqueIndexes()
 			return nil
 		}
 	}
@@ -7,9 +7,13 @@
 package ctxerr
 
 import (
+	"reflect"
+	"strings"
+
 	"github.com/maxatome/go-testdeep/internal/anchors"
 	"github.com/maxatome/go-testdeep/internal/hooks"
 	"github.com/maxatome/go-testdeep/internal/location"
+	"github.com/maxatome/go-testdeep/internal/util"
 	"github.com/maxatome/go-testdeep/internal/visited"
 )
 
@@ -39,6 +43,39 @@ type Context struct {
 	UseEqual bool
 	// See ContextConfig.BeLax for details.
 	BeLax bool
+	// If true, got json.Number values come from a JSON document and
+	// are converted to float64, int64 or uint64 (see json.NumberValue)
+	// before being passed to a TestDeep operator.
+	JSONNumbers bool
+	// See ContextConfig.PathStyle for details.
+	PathStyle PathStyle
+	// See ContextConfig.GotAsGo for details.
+	GotAsGo bool
+	// See ContextConfig.JSONDiff for details.
+	JSONDiff bool
+	// See ContextConfig.PreferStringer for details.
+	PreferStringer bool
+	// See ContextConfig.PathRules for details.
+	PathRules []PathRule
+	// See ContextConfig.FloatAbsTolerance, FloatRelTolerance and
+	// FloatULPTolerance for details.
+	FloatTolerance FloatTolerance
+	// See ContextConfig.NaNEqual for details.
+	NaNEqual bool
+	// See ContextConfig.UnorderedSlices for details. nil means no
+	// slices are concerned.
+	Unordered *Unordered
+	// See ContextConfig.NilEqualsEmpty for details.
+	NilEqualsEmpty bool
+	// If true, unexported struct fields are not compared. See
+	// ContextConfig.UnexportedFields for details.
+	IgnoreUnexported bool
+	// If not empty and IgnoreUnexported is true, only unexported
+	// fields declared in other packages than LocalPackage are ignored.
+	LocalPackage string
+	// Path length at which a path rule has been applied, so it is not
+	// applied again at the same level.
+	pathRulesLevel int
 }
 
 // InitErrors initializes Context *Errors slice, if MaxErrors < 0 or
@@ -137,6 +174,16 @@ func (c Context) AddField(field string) (new Context) {
 	return
 }
 
+// AddStructField creates a new Context from current one plus "." +
+// field.Name. Contrary to AddField, the json tag of "field" is
+// recorded and used by PathString depending on PathStyle.
+func (c Context) AddStructField(field reflect.StructField) (new Context) {
+	new = c
+	new.Path = new.Path.AddStructField(field)
+	new.Depth++
+	return
+}
+
 // AddArrayIndex creates a new Context from current one plus an array
 // dereference for index-th item.
 func (c Context) AddArrayIndex(index int) (new Context) {
@@ -172,10 +219,61 @@ func (c Context) AddFunctionCall(fn string) (new Context) {
 	return
 }
 
+// PathString returns the string representation of the current path,
+// rendered using PathStyle.
+func (c Context) PathString() string {
+	return c.Path.StringStyle(c.PathStyle)
+}
+
 // ResetPath creates a new Context from current one but reinitializing Path.
 func (c Context) ResetPath(newRoot string) (new Context) {
 	new = c
 	new.Path = NewPath(newRoot)
 	new.Depth++
+	new.pathRulesLevel = 0
 	return
 }
+
+// PathRule returns the expected value of the last path rule matching
+// the current path, if any. In this case, the returned Context has to
+// be used to compare this expected value, so path rules are not
+// applied again at the same level.
+func (c Context) PathRule() (Context, reflect.Value, bool) {
+	if len(c.PathRules) == 0 || c.pathRulesLevel == len(c.Path) {
+		return c, reflect.Value{}, false
+	}
+	for i := len(c.PathRules) - 1; i >= 0; i-- {
+		if c.PathRules[i].Pattern.Match(c.Path) {
+			c.pathRulesLevel = len(c.Path)
+			return c, c.PathRules[i].Expected, true
+		}
+	}
+	return c, reflect.Value{}, false
+}
+
+// UsesPath returns true if the comparison behavior depends on the
+// current path, that is if some path rules or unordered slices path
+// patterns are defined. If false, the path only matters to report
+// errors.
+func (c Context) UsesPath() bool {
+	return len(c.PathRules) > 0 ||
+		(c.Unordered != nil && len(c.Unordered.Patterns) > 0)
+}
+
+// IgnoreField returns true if struct field "sf" has not to be
+// compared, due to IgnoreUnexported and LocalPackage fields. A
+// "_test" suffix of the package declaring "sf" is ignored.
+func (c Context) IgnoreField(sf reflect.StructField) bool {
+	return c.IgnoreUnexported && sf.PkgPath != "" &&
+		(c.LocalPackage == "" ||
+			strings.TrimSuffix(sf.PkgPath, "_test") != c.LocalPackage)
+}
+
+// ToString stringifies "val" as util.ToString does, but taking into
+// account formatters and PreferStringer option of the context.
+func (c Context) ToString(val interface{}) string {
+	return util.ToStringOpts(val, util.FormatOpts{
+		Formatters:     c.Hooks,
+		PreferStringer: c.PreferStringer,
+	})
+}
@@ -7,9 +7,14 @@
 package ctxerr_test
 
 import (
+	"errors"
+	"reflect"
+	"strconv"
+	"strings"
 	"testing"
 
 	"github.com/maxatome/go-testdeep/internal/ctxerr"
+	"github.com/maxatome/go-testdeep/internal/hooks"
 	"github.com/maxatome/go-testdeep/internal/location"
 	"github.com/maxatome/go-testdeep/internal/test"
 )
@@ -262,4 +267,74 @@ func TestContextPath(t *testing.T) {
 	ctx = ctx.ResetPath("NEW")
 	test.EqualStr(t, ctx.Path.String(), "NEW")
 	test.EqualInt(t, ctx.Depth, 2)
+
+	ctx = ctxerr.Context{Path: ctxerr.NewPath("DATA")}
+	ctx = ctx.AddStructField(reflect.StructField{
+		Name: "Field",
+		Tag:  `json:"field"`,
+	})
+	test.EqualStr(t, ctx.Path.String(), "DATA.Field")
+	test.EqualStr(t, ctx.PathString(), "DATA.Field")
+	test.EqualInt(t, ctx.Depth, 1)
+
+	ctx.PathStyle = ctxerr.PathStyleJSONTags
+	test.EqualStr(t, ctx.PathString(), "DATA.field")
+
+	ctx.PathStyle = ctxerr.PathStyleJSONPointer
+	test.EqualStr(t, ctx.PathString(), "DATA/field")
+}
+
+func TestContextToString(t *testing.T) {
+	ctx := ctxerr.Context{}
+	test.IsTrue(t, strings.HasSuffix(ctx.ToString(errors.New("boom")), ")(boom)"))
+
+	ctx.PreferStringer = true
+	test.EqualStr(t, ctx.ToString(errors.New("boom")), "boom")
+
+	ctx.Hooks = hooks.NewInfo()
+	err := ctx.Hooks.AddFormatHooks([]interface{}{
+		func(i int) string { return "#" + strconv.Itoa(i) },
+	})
+	test.NoError(t, err)
+	test.EqualStr(t, ctx.ToString(12), "#12")
+}
+
+func TestContextIgnoreField(t *testing.T) {
+	exported := reflect.StructField{Name: "Field"}
+	local := reflect.StructField{Name: "field", PkgPath: "foo/bar"}
+	localTest := reflect.StructField{Name: "field", PkgPath: "foo/bar_test"}
+	foreign := reflect.StructField{Name: "field", PkgPath: "foo/zip"}
+
+	ctx := ctxerr.Context{}
+	test.IsFalse(t, ctx.IgnoreField(local))
+
+	ctx.IgnoreUnexported = true
+	test.IsFalse(t, ctx.IgnoreField(exported))
+	test.IsTrue(t, ctx.IgnoreField(local))
+	test.IsTrue(t, ctx.IgnoreField(foreign))
+
+	ctx.LocalPackage = "foo/bar"
+	test.IsFalse(t, ctx.IgnoreField(exported))
+	test.IsFalse(t, ctx.IgnoreField(local))
+	test.IsFalse(t, ctx.IgnoreField(localTest))
+	test.IsTrue(t, ctx.IgnoreField(foreign))
+}
+
+func TestContextUsesPath(t *testing.T) {
+	pattern, err := ctxerr.ParsePathPattern("DATA[*].Tags")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := ctxerr.Context{}
+	test.IsFalse(t, ctx.UsesPath())
+
+	ctx.Unordered = &ctxerr.Unordered{All: true}
+	test.IsFalse(t, ctx.UsesPath())
+
+	ctx.Unordered.Patterns = []ctxerr.PathPattern{pattern}
+	test.IsTrue(t, ctx.UsesPath())
+
+	ctx = ctxerr.Context{PathRules: []ctxerr.PathRule{{Pattern: pattern}}}
+	test.IsTrue(t, ctx.UsesPath())
 }
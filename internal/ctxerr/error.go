@@ -136,10 +136,10 @@ func (e *Error) Append(buf *bytes.Buffer, prefix string) {
 	buf.WriteString(color.TitleOn)
 	if pos := strings.Index(e.Message, "%%"); pos >= 0 {
 		buf.WriteString(e.Message[:pos])
-		buf.WriteString(e.Context.Path.String())
+		buf.WriteString(e.Context.PathString())
 		buf.WriteString(e.Message[pos+2:])
 	} else {
-		buf.WriteString(e.Context.Path.String())
+		buf.WriteString(e.Context.PathString())
 		buf.WriteString(": ")
 		buf.WriteString(e.Message)
 	}
@@ -193,7 +193,7 @@ func (e *Error) GotString() string {
 	if e.Summary != nil {
 		return ""
 	}
-	return util.ToString(e.Got)
+	return e.Context.ToString(e.Got)
 }
 
 // ExpectedString returns the string corresponding to the Expected
@@ -203,7 +203,7 @@ func (e *Error) ExpectedString() string {
 	if e.Summary != nil {
 		return ""
 	}
-	return util.ToString(e.Expected)
+	return e.Context.ToString(e.Expected)
 }
 
 // SummaryString returns the string corresponding to the Summary
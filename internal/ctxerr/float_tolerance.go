@@ -0,0 +1,41 @@
+// Copyright (c) 2021, Maxime Soulé
+// All rights reserved.
+//
+// This source code is licensed under the BSD-style license found in the
+// LICENSE file in the root directory of this source tree.
+
+package ctxerr
+
+import (
+	"strconv"
+	"strings"
+)
+
+// FloatTolerance gathers the tolerances used to compare floats. See
+// ContextConfig.FloatAbsTolerance, FloatRelTolerance and
+// FloatULPTolerance for details.
+type FloatTolerance struct {
+	Abs float64
+	Rel float64
+	ULP uint
+}
+
+// IsZero returns true if no tolerance is set.
+func (f FloatTolerance) IsZero() bool {
+	return f.Abs <= 0 && f.Rel <= 0 && f.ULP == 0
+}
+
+// String returns the set tolerances, as in "abs=1e-09, ulp=4".
+func (f FloatTolerance) String() string {
+	var parts []string
+	if f.Abs > 0 {
+		parts = append(parts, "abs="+strconv.FormatFloat(f.Abs, 'g', -1, 64))
+	}
+	if f.Rel > 0 {
+		parts = append(parts, "rel="+strconv.FormatFloat(f.Rel, 'g', -1, 64))
+	}
+	if f.ULP > 0 {
+		parts = append(parts, "ulp="+strconv.FormatUint(uint64(f.ULP), 10))
+	}
+	return strings.Join(parts, ", ")
+}
@@ -7,6 +7,7 @@
 package ctxerr
 
 import (
+	"reflect"
 	"strconv"
 	"strings"
 
@@ -15,29 +16,53 @@ import (
 
 // Path defines a structure depth path, typically used to mark a
 // position during a deep traversal in case of error.
-type Path []pathLevel
-
-type pathLevelKind uint8
-
-type pathLevel struct {
-	Content  string
+type Path []PathLevel
+
+// PathLevelKind is the kind of a PathLevel.
+type PathLevelKind uint8
+
+// PathLevel is one level of a Path.
+type PathLevel struct {
+	Content string
+	// JSONName is the JSON name of a struct field or the raw key of a
+	// map. For a struct field, empty means an embedded struct whose
+	// fields are promoted in JSON.
+	JSONName string
 	Pointers int
-	Kind     pathLevelKind
+	Kind     PathLevelKind
 }
 
+// PathLevel kinds.
 const (
-	levelStruct pathLevelKind = iota
-	levelArray
-	levelMap
-	levelFunc
-	levelCustom
+	LevelStruct PathLevelKind = iota
+	LevelArray
+	LevelMap
+	LevelFunc
+	LevelCustom
+)
+
+// PathStyle defines how a Path is rendered.
+type PathStyle uint8
+
+// Path styles.
+const (
+	// PathStyleGo renders paths using Go syntax and Go field names, as
+	// in DATA.Items[3].CreatedAt.
+	PathStyleGo PathStyle = iota
+	// PathStyleJSONTags renders paths using Go syntax but json struct
+	// tag names instead of Go field names, as in
+	// DATA.items[3].created_at.
+	PathStyleJSONTags
+	// PathStyleJSONPointer renders paths as RFC 6901 JSON pointers
+	// following the root name, as in DATA/items/3/created_at.
+	PathStyleJSONPointer
 )
 
 // NewPath returns a new Path initialized with "root" root node.
 func NewPath(root string) Path {
 	return Path{
 		{
-			Kind:    levelCustom,
+			Kind:    LevelCustom,
 			Content: root,
 		},
 	}
@@ -61,7 +86,7 @@ func (p Path) Equal(o Path) bool {
 	return true
 }
 
-func (p Path) addLevel(level pathLevel) Path {
+func (p Path) addLevel(level PathLevel) Path {
 	new := make(Path, len(p), len(p)+1)
 	copy(new, p)
 	return append(new, level)
@@ -84,9 +109,26 @@ func (p Path) AddField(field string) Path {
 		return nil
 	}
 
-	new := p.addLevel(pathLevel{
-		Kind:    levelStruct,
-		Content: field,
+	return p.addStructLevel(field, field)
+}
+
+// AddStructField adds a level corresponding to struct field
+// "field". Contrary to AddField, the json tag of "field" is used to
+// render the path with PathStyleJSONTags and PathStyleJSONPointer
+// styles.
+func (p Path) AddStructField(field reflect.StructField) Path {
+	if p == nil {
+		return nil
+	}
+
+	return p.addStructLevel(field.Name, jsonFieldName(field))
+}
+
+func (p Path) addStructLevel(field, jsonName string) Path {
+	new := p.addLevel(PathLevel{
+		Kind:     LevelStruct,
+		Content:  field,
+		JSONName: jsonName,
 	})
 
 	if len(new) > 1 && new[len(new)-2].Pointers > 0 {
@@ -102,8 +144,8 @@ func (p Path) AddArrayIndex(index int) Path {
 		return nil
 	}
 
-	return p.addLevel(pathLevel{
-		Kind:    levelArray,
+	return p.addLevel(PathLevel{
+		Kind:    LevelArray,
 		Content: strconv.Itoa(index),
 	})
 }
@@ -114,10 +156,24 @@ func (p Path) AddMapKey(key interface{}) Path {
 		return nil
 	}
 
-	return p.addLevel(pathLevel{
-		Kind:    levelMap,
+	level := PathLevel{
+		Kind:    LevelMap,
 		Content: util.ToString(key),
-	})
+	}
+
+	switch tkey := key.(type) {
+	case string:
+		level.JSONName = tkey
+	case reflect.Value:
+		if tkey.Kind() == reflect.String {
+			level.JSONName = tkey.String()
+		}
+	}
+	if level.JSONName == "" {
+		level.JSONName = level.Content
+	}
+
+	return p.addLevel(level)
 }
 
 // AddPtr adds "num" pointers levels.
@@ -138,8 +194,8 @@ func (p Path) AddFunctionCall(fn string) Path {
 		return nil
 	}
 
-	return p.addLevel(pathLevel{
-		Kind:    levelFunc,
+	return p.addLevel(PathLevel{
+		Kind:    LevelFunc,
 		Content: fn,
 	})
 }
@@ -150,8 +206,8 @@ func (p Path) AddCustomLevel(custom string) Path {
 		return nil
 	}
 
-	return p.addLevel(pathLevel{
-		Kind:    levelCustom,
+	return p.addLevel(PathLevel{
+		Kind:    LevelCustom,
 		Content: custom,
 	})
 }
@@ -169,7 +225,7 @@ func (p Path) String() string {
 			ptrs = strings.Repeat("*", level.Pointers)
 		}
 
-		if level.Kind == levelFunc {
+		if level.Kind == LevelFunc {
 			str = ptrs + level.Content + "(" + str + ")"
 		} else {
 			if i > 0 && p[i-1].Pointers > 0 {
@@ -180,9 +236,9 @@ func (p Path) String() string {
 			}
 
 			switch level.Kind {
-			case levelStruct:
+			case LevelStruct:
 				str += "." + level.Content
-			case levelArray, levelMap:
+			case LevelArray, LevelMap:
 				str += "[" + level.Content + "]"
 			default:
 				str += level.Content
@@ -193,6 +249,119 @@ func (p Path) String() string {
 	return str
 }
 
+// StringStyle returns the string representation of "p" using
+// "style". PathStyleGo is the same as String.
+func (p Path) StringStyle(style PathStyle) string {
+	switch style {
+	case PathStyleJSONTags:
+		return p.jsonString(false)
+	case PathStyleJSONPointer:
+		return p.jsonString(true)
+	default:
+		return p.String()
+	}
+}
+
+var jsonPointerEscaper = strings.NewReplacer("~", "~0", "/", "~1")
+
+// jsonString renders "p" using JSON names. Pointers are not
+// rendered, as they do not exist in JSON.
+func (p Path) jsonString(pointer bool) string {
+	var str string
+
+	for _, level := range p {
+		switch level.Kind {
+		case LevelFunc:
+			str = level.Content + "(" + str + ")"
+
+		case LevelStruct:
+			if level.JSONName == "" { // promoted fields of embedded struct
+				continue
+			}
+			if pointer {
+				str += "/" + jsonPointerEscaper.Replace(level.JSONName)
+			} else {
+				str += "." + level.JSONName
+			}
+
+		case LevelArray:
+			if pointer {
+				str += "/" + level.Content
+			} else {
+				str += "[" + level.Content + "]"
+			}
+
+		case LevelMap:
+			if pointer {
+				str += "/" + jsonPointerEscaper.Replace(level.JSONName)
+			} else {
+				str += "[" + level.Content + "]"
+			}
+
+		default:
+			str += level.Content
+		}
+	}
+
+	return str
+}
+
+// JSONPointer returns the RFC 6901 JSON pointer corresponding to
+// the levels of "p" starting at index "from", so "" if there is no
+// such level. Pointers are ignored, as they do not exist in JSON, and
+// the JSON pointer stops at the first level that cannot be
+// represented in JSON, as a function call or a custom level.
+func (p Path) JSONPointer(from int) string {
+	var str string
+
+	for i := from; i < len(p); i++ {
+		level := p[i]
+		switch level.Kind {
+		case LevelStruct:
+			if level.JSONName != "" { // else promoted fields of embedded struct
+				str += "/" + jsonPointerEscaper.Replace(level.JSONName)
+			}
+
+		case LevelArray:
+			str += "/" + level.Content
+
+		case LevelMap:
+			str += "/" + jsonPointerEscaper.Replace(level.JSONName)
+
+		default:
+			return str
+		}
+	}
+
+	return str
+}
+
+// jsonFieldName returns the JSON name of struct field "field", as
+// encoding/json would use it. It returns "" for an embedded struct
+// without json name, as its fields are promoted.
+func jsonFieldName(field reflect.StructField) string {
+	tag := field.Tag.Get("json")
+	if tag != "-" {
+		if pos := strings.IndexByte(tag, ','); pos >= 0 {
+			tag = tag[:pos]
+		}
+		if tag != "" {
+			return tag
+		}
+	}
+
+	if field.Anonymous {
+		ft := field.Type
+		if ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+		if ft.Kind() == reflect.Struct {
+			return ""
+		}
+	}
+	return field.Name
+}
+
 /*
 func setPtrs(buf []byte, num int) {
 	for i := 0; i < num; i++ {
@@ -209,14 +378,14 @@ func (p Path) String() string {
 	for i, level := range p {
 		size += level.Pointers + len(level.Content)
 
-		if level.Kind == levelFunc || (i > 0 && p[i-1].Pointers > 0) {
+		if level.Kind == LevelFunc || (i > 0 && p[i-1].Pointers > 0) {
 			size += 2 // () ⇒ content(x) || (x)content
 		}
 
 		switch level.Kind {
-		case levelStruct:
+		case LevelStruct:
 			size++ // "."
-		case levelArray, levelMap:
+		case LevelArray, LevelMap:
 			size += 2 // []
 		}
 	}
@@ -225,7 +394,7 @@ func (p Path) String() string {
 	curLen := 0
 
 	for i, level := range p {
-		if level.Kind == levelFunc {
+		if level.Kind == LevelFunc {
 			// **content(prev)
 			levelLen := level.Pointers + len(level.Content) + 1
 			copy(buf[levelLen:], buf[:curLen])
@@ -253,12 +422,12 @@ func (p Path) String() string {
 				}
 			}
 			switch level.Kind {
-			case levelStruct:
+			case LevelStruct:
 				buf[curLen] = '.'
 				curLen++
 				copy(buf[curLen:], []byte(level.Content))
 				curLen += len(level.Content)
-			case levelArray, levelMap:
+			case LevelArray, LevelMap:
 				buf[curLen] = '['
 				curLen++
 				copy(buf[curLen:], []byte(level.Content))
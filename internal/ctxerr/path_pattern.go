@@ -0,0 +1,224 @@
+// Copyright (c) 2021, Maxime Soulé
+// All rights reserved.
+//
+// This source code is licensed under the BSD-style license found in the
+// LICENSE file in the root directory of this source tree.
+
+package ctxerr
+
+import (
+	"errors"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+type patternLevelKind uint8
+
+const (
+	patternRoot      patternLevelKind = iota
+	patternField                      // .Name
+	patternAnyField                   // .*
+	patternIndex                      // [3], [key] or ["key"]
+	patternAnyIndex                   // [*]
+	patternAnyLevels                  // .**
+)
+
+type patternLevel struct {
+	kind    patternLevelKind
+	content string
+}
+
+// PathPattern is a compiled path pattern, matching Path instances. See
+// ParsePathPattern for its syntax.
+type PathPattern []patternLevel
+
+// ParsePathPattern compiles "pattern" into a PathPattern. A pattern
+// uses the Go path syntax, as in "DATA.Items[3].ID", plus:
+//   - ".*" matching any struct field;
+//   - "[*]" matching any array/slice index or map key;
+//   - ".**" matching zero or more levels, whatever they are.
+//
+// A struct field matches its Go name as well as its json tag name. A
+// map key matches its rendering in paths, as in ["key"], as well as
+// its raw string value, as in [key]. Pointer dereferences are
+// ignored.
+func ParsePathPattern(pattern string) (PathPattern, error) {
+	end := strings.IndexAny(pattern, ".[")
+	if end < 0 {
+		end = len(pattern)
+	}
+	if end == 0 {
+		return nil, errors.New("root name is missing")
+	}
+
+	pp := PathPattern{{kind: patternRoot, content: pattern[:end]}}
+
+	for s := pattern[end:]; s != ""; {
+		switch s[0] {
+		case '.':
+			s = s[1:]
+			end = strings.IndexAny(s, ".[")
+			if end < 0 {
+				end = len(s)
+			}
+			switch name := s[:end]; name {
+			case "":
+				return nil, errors.New("empty field name at offset " +
+					strconv.Itoa(len(pattern)-len(s)))
+			case "*":
+				pp = append(pp, patternLevel{kind: patternAnyField})
+			case "**":
+				pp = append(pp, patternLevel{kind: patternAnyLevels})
+			default:
+				pp = append(pp, patternLevel{kind: patternField, content: name})
+			}
+			s = s[end:]
+
+		case '[':
+			var (
+				content string
+				err     error
+			)
+			content, s, err = parsePatternIndex(s[1:])
+			if err != nil {
+				return nil, errors.New(err.Error() + " at offset " +
+					strconv.Itoa(len(pattern)-len(s)))
+			}
+			if content == "*" {
+				pp = append(pp, patternLevel{kind: patternAnyIndex})
+			} else {
+				pp = append(pp, patternLevel{kind: patternIndex, content: content})
+			}
+
+		default:
+			return nil, errors.New("unexpected " + strconv.QuoteRune(rune(s[0])) +
+				" at offset " + strconv.Itoa(len(pattern)-len(s)))
+		}
+	}
+
+	return pp, nil
+}
+
+// parsePatternIndex parses the content of a [...] level, "s"
+// starting just after the '['. It returns the content, the remaining
+// string after the ']' and an error if any.
+func parsePatternIndex(s string) (string, string, error) {
+	if s != "" && s[0] == '"' {
+		// Quoted key: find the closing quote, skipping escaped chars
+		for i := 1; i < len(s); i++ {
+			switch s[i] {
+			case '\\':
+				i++
+			case '"':
+				if i+1 >= len(s) || s[i+1] != ']' {
+					return "", s, errors.New("']' expected after quoted key")
+				}
+				if _, err := strconv.Unquote(s[:i+1]); err != nil {
+					return "", s, errors.New("invalid quoted key")
+				}
+				return s[:i+1], s[i+2:], nil
+			}
+		}
+		return "", s, errors.New("unterminated quoted key")
+	}
+
+	end := strings.IndexByte(s, ']')
+	if end < 0 {
+		return "", s, errors.New("unterminated '['")
+	}
+	if end == 0 {
+		return "", s, errors.New("empty index")
+	}
+	return s[:end], s[end+1:], nil
+}
+
+// Match returns true if "p" matches "pp" pattern.
+func (pp PathPattern) Match(p Path) bool {
+	if len(pp) == 0 || len(p) == 0 {
+		return false
+	}
+	return pp.match(p)
+}
+
+func (pp PathPattern) match(p Path) bool {
+	for len(pp) > 0 {
+		if pp[0].kind == patternAnyLevels {
+			rest := pp[1:]
+			for i := 0; i <= len(p); i++ {
+				if rest.match(p[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+
+		if len(p) == 0 || !pp[0].matchLevel(p[0]) {
+			return false
+		}
+		pp, p = pp[1:], p[1:]
+	}
+	return len(p) == 0
+}
+
+func (l patternLevel) matchLevel(level PathLevel) bool {
+	switch l.kind {
+	case patternRoot:
+		return level.Kind == LevelCustom && level.Content == l.content
+	case patternField:
+		return level.Kind == LevelStruct &&
+			(level.Content == l.content || level.JSONName == l.content)
+	case patternAnyField:
+		return level.Kind == LevelStruct
+	case patternIndex:
+		return (level.Kind == LevelArray || level.Kind == LevelMap) &&
+			(level.Content == l.content ||
+				(level.Kind == LevelMap && level.JSONName == l.content))
+	case patternAnyIndex:
+		return level.Kind == LevelArray || level.Kind == LevelMap
+	}
+	return false
+}
+
+// PathRule associates a PathPattern to an expected value, replacing
+// the original one each time the pattern matches the current path.
+type PathRule struct {
+	Pattern  PathPattern
+	Expected reflect.Value
+}
+
+// Unordered defines which slices and arrays have to be compared
+// regardless of the order of their items.
+type Unordered struct {
+	// All means all slices and arrays are concerned.
+	All bool
+	// Patterns contains the path patterns of concerned slices and
+	// arrays.
+	Patterns []PathPattern
+	// ElemTypes contains the item types of concerned slices and arrays.
+	ElemTypes []reflect.Type
+}
+
+// Match returns true if the slice or array of type "t" at path "p"
+// has to be compared regardless of the order of its items. "u" can
+// be nil, so Match always returns false.
+func (u *Unordered) Match(p Path, t reflect.Type) bool {
+	if u == nil {
+		return false
+	}
+	if u.All {
+		return true
+	}
+	elem := t.Elem()
+	for _, et := range u.ElemTypes {
+		if et == elem {
+			return true
+		}
+	}
+	for _, pp := range u.Patterns {
+		if pp.Match(p) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,88 @@
+// Copyright (c) 2021, Maxime Soulé
+// All rights reserved.
+//
+// This source code is licensed under the BSD-style license found in the
+// LICENSE file in the root directory of this source tree.
+
+package ctxerr_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/maxatome/go-testdeep/internal/ctxerr"
+	"github.com/maxatome/go-testdeep/internal/test"
+)
+
+func TestPathPattern(t *testing.T) {
+	root := ctxerr.NewPath("DATA")
+	items := root.AddPtr(1).
+		AddStructField(reflect.StructField{Name: "Items", Tag: `json:"items"`})
+	id := items.AddArrayIndex(3).
+		AddStructField(reflect.StructField{Name: "ID", Tag: `json:"id"`})
+	updated := items.AddArrayIndex(1).
+		AddField("Sub").
+		AddMapKey("key").
+		AddField("UpdatedAt")
+
+	for _, tc := range []struct {
+		pattern string
+		path    ctxerr.Path
+		match   bool
+	}{
+		{pattern: "DATA", path: root, match: true},
+		{pattern: "DATA", path: items, match: false},
+		{pattern: "ROOT", path: root, match: false},
+		{pattern: "DATA.Items", path: items, match: true},
+		{pattern: "DATA.items", path: items, match: true},
+		{pattern: "DATA.*", path: items, match: true},
+		{pattern: "DATA[*]", path: items, match: false},
+		{pattern: "DATA.Items[3].ID", path: id, match: true},
+		{pattern: "DATA.Items[2].ID", path: id, match: false},
+		{pattern: "DATA.Items[*].ID", path: id, match: true},
+		{pattern: "DATA.items[*].id", path: id, match: true},
+		{pattern: "DATA.Items[*]", path: id, match: false},
+		{pattern: "DATA.**.ID", path: id, match: true},
+		{pattern: "DATA.**", path: id, match: true},
+		{pattern: "DATA.**", path: root, match: true},
+		{pattern: "DATA.**.Items[*].**.ID", path: id, match: true},
+		{pattern: "DATA.**.UpdatedAt", path: id, match: false},
+		{pattern: "DATA.**.UpdatedAt", path: updated, match: true},
+		{pattern: `DATA.Items[1].Sub["key"].UpdatedAt`, path: updated, match: true},
+		{pattern: `DATA.Items[1].Sub[key].UpdatedAt`, path: updated, match: true},
+		{pattern: `DATA.Items[1].Sub[*].*`, path: updated, match: true},
+		{pattern: `DATA.Items[1].Sub["other"].UpdatedAt`, path: updated, match: false},
+		{pattern: `DATA.Items[1].Sub[1].UpdatedAt`, path: updated, match: false},
+	} {
+		pp, err := ctxerr.ParsePathPattern(tc.pattern)
+		if test.NoError(t, err, tc.pattern) {
+			test.EqualBool(t, pp.Match(tc.path), tc.match,
+				"pattern %s vs path %s", tc.pattern, tc.path)
+		}
+	}
+
+	var pp ctxerr.PathPattern
+	test.IsFalse(t, pp.Match(root))
+
+	// Errors
+	for pattern, expected := range map[string]string{
+		"":                   "root name is missing",
+		".Field":             "root name is missing",
+		"DATA..Field":        "empty field name at offset 5",
+		"DATA.Field.":        "empty field name at offset 11",
+		"DATA[":              "unterminated '[' at offset 5",
+		"DATA[]":             "empty index at offset 5",
+		`DATA["key`:          "unterminated quoted key at offset 5",
+		`DATA["key"x]`:       "']' expected after quoted key at offset 5",
+		`DATA["\z"]`:         "invalid quoted key at offset 5",
+		"DATA[1]x":           `unexpected 'x' at offset 7`,
+		`DATA["a\"]b"].Name`: "",
+	} {
+		_, err := ctxerr.ParsePathPattern(pattern)
+		if expected == "" {
+			test.NoError(t, err, pattern)
+		} else if test.Error(t, err, pattern) {
+			test.EqualStr(t, err.Error(), expected, pattern)
+		}
+	}
+}
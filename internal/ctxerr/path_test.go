@@ -7,6 +7,7 @@
 package ctxerr_test
 
 import (
+	"reflect"
 	"testing"
 
 	"github.com/maxatome/go-testdeep/internal/ctxerr"
@@ -197,6 +198,163 @@ func TestEqual(t *testing.T) {
 	test.IsFalse(t, path.Equal(ctxerr.NewPath("DATA").AddPtr(2).AddField("field2")))
 }
 
+func TestPathStringStyle(t *testing.T) {
+	type Embedded struct {
+		Promoted int
+	}
+	type Item struct {
+		Embedded
+		CreatedAt int `json:"created_at,omitempty"`
+		Name      string
+		Secret    string `json:"-"`
+		Opt       int    `json:",omitempty"`
+		Named     Embedded
+		NamedEmb  Embedded `json:"emb"`
+	}
+	st := reflect.TypeOf(Item{})
+
+	items := ctxerr.NewPath("DATA").
+		AddPtr(1).
+		AddField("Items").
+		AddArrayIndex(3).
+		AddPtr(1)
+
+	for i, testCase := range []struct {
+		Path        ctxerr.Path
+		Go          string
+		JSONTags    string
+		JSONPointer string
+	}{
+		{
+			Path:        ctxerr.NewPath("DATA"),
+			Go:          "DATA",
+			JSONTags:    "DATA",
+			JSONPointer: "DATA",
+		},
+		{
+			Path:        items.AddStructField(st.Field(1)),
+			Go:          "DATA.Items[3].CreatedAt",
+			JSONTags:    "DATA.Items[3].created_at",
+			JSONPointer: "DATA/Items/3/created_at",
+		},
+		{
+			Path:        items.AddStructField(st.Field(2)),
+			Go:          "DATA.Items[3].Name",
+			JSONTags:    "DATA.Items[3].Name",
+			JSONPointer: "DATA/Items/3/Name",
+		},
+		{
+			Path:        items.AddStructField(st.Field(3)),
+			Go:          "DATA.Items[3].Secret",
+			JSONTags:    "DATA.Items[3].Secret",
+			JSONPointer: "DATA/Items/3/Secret",
+		},
+		{
+			Path:        items.AddStructField(st.Field(4)),
+			Go:          "DATA.Items[3].Opt",
+			JSONTags:    "DATA.Items[3].Opt",
+			JSONPointer: "DATA/Items/3/Opt",
+		},
+		{
+			Path: items.AddStructField(st.Field(0)).
+				AddStructField(st.Field(0).Type.Field(0)),
+			Go:          "DATA.Items[3].Embedded.Promoted",
+			JSONTags:    "DATA.Items[3].Promoted",
+			JSONPointer: "DATA/Items/3/Promoted",
+		},
+		{
+			Path: items.AddStructField(st.Field(5)).
+				AddStructField(st.Field(5).Type.Field(0)),
+			Go:          "DATA.Items[3].Named.Promoted",
+			JSONTags:    "DATA.Items[3].Named.Promoted",
+			JSONPointer: "DATA/Items/3/Named/Promoted",
+		},
+		{
+			Path:        items.AddStructField(st.Field(6)),
+			Go:          "DATA.Items[3].NamedEmb",
+			JSONTags:    "DATA.Items[3].emb",
+			JSONPointer: "DATA/Items/3/emb",
+		},
+		{
+			Path:        ctxerr.NewPath("DATA").AddMapKey("a/b~c"),
+			Go:          `DATA["a/b~c"]`,
+			JSONTags:    `DATA["a/b~c"]`,
+			JSONPointer: "DATA/a~1b~0c",
+		},
+		{
+			Path:        ctxerr.NewPath("DATA").AddMapKey(reflect.ValueOf("key")),
+			Go:          `DATA["key"]`,
+			JSONTags:    `DATA["key"]`,
+			JSONPointer: "DATA/key",
+		},
+		{
+			Path:        ctxerr.NewPath("DATA").AddMapKey(12),
+			Go:          "DATA[12]",
+			JSONTags:    "DATA[12]",
+			JSONPointer: "DATA/12",
+		},
+		{
+			Path: ctxerr.NewPath("DATA").
+				AddField("Items").
+				AddFunctionCall("len"),
+			Go:          "len(DATA.Items)",
+			JSONTags:    "len(DATA.Items)",
+			JSONPointer: "len(DATA/Items)",
+		},
+		{
+			Path: ctxerr.NewPath("DATA").
+				AddField("Items").
+				AddCustomLevel("<smuggled>"),
+			Go:          "DATA.Items<smuggled>",
+			JSONTags:    "DATA.Items<smuggled>",
+			JSONPointer: "DATA/Items<smuggled>",
+		},
+	} {
+		test.EqualStr(t, testCase.Path.StringStyle(ctxerr.PathStyleGo),
+			testCase.Go, "#%d: Go style", i)
+		test.EqualStr(t, testCase.Path.StringStyle(ctxerr.PathStyleJSONTags),
+			testCase.JSONTags, "#%d: JSON tags style", i)
+		test.EqualStr(t, testCase.Path.StringStyle(ctxerr.PathStyleJSONPointer),
+			testCase.JSONPointer, "#%d: JSON pointer style", i)
+	}
+
+	var nilPath ctxerr.Path
+	if nilPath.AddStructField(st.Field(1)) != nil {
+		t.Error("AddStructField on nil path should return nil")
+	}
+}
+
+func TestPathJSONPointer(t *testing.T) {
+	type Embedded struct {
+		Promoted int
+	}
+	type Item struct {
+		Embedded
+		CreatedAt int `json:"created_at"`
+	}
+	st := reflect.TypeOf(Item{})
+
+	base := ctxerr.NewPath("DATA").AddPtr(1).AddField("Items")
+
+	test.EqualStr(t, base.JSONPointer(len(base)), "")
+	test.EqualStr(t, base.JSONPointer(1), "/Items")
+
+	path := base.AddArrayIndex(3).
+		AddPtr(1).
+		AddStructField(st.Field(0)).
+		AddStructField(st.Field(0).Type.Field(0))
+	test.EqualStr(t, path.JSONPointer(len(base)), "/3/Promoted")
+
+	path = base.AddMapKey("a/b~c").AddStructField(st.Field(1))
+	test.EqualStr(t, path.JSONPointer(len(base)), "/a~1b~0c/created_at")
+
+	path = base.AddArrayIndex(1).AddFunctionCall("len").AddArrayIndex(2)
+	test.EqualStr(t, path.JSONPointer(len(base)), "/1")
+
+	path = base.AddMapKey("x").AddCustomLevel("<smuggled>").AddMapKey("y")
+	test.EqualStr(t, path.JSONPointer(len(base)), "/x")
+}
+
 /*
 func BenchmarkStringString(b *testing.B) {
 	path := ctxerr.NewPath("DATA").
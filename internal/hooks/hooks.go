@@ -15,15 +15,164 @@ import (
 	"github.com/maxatome/go-testdeep/internal/types"
 )
 
-type hooks map[reflect.Type]reflect.Value
+// hook is an interface or a predicate hook.
+type hook struct {
+	// fn is the hook function, invalid if the hook is removed
+	fn reflect.Value
+	// in is the type of the first parameter of fn
+	in reflect.Type
+	// match is the predicate of a predicate hook, nil for an interface
+	// hook
+	match func(reflect.Type) bool
+}
+
+// matches returns true if the hook applies to type "t".
+func (h *hook) matches(t reflect.Type) bool {
+	if h.match == nil {
+		return t.Implements(h.in)
+	}
+	return t.AssignableTo(h.in) && h.match(t)
+}
+
+// hookResult is the result of a hook lookup. fn is invalid if the
+// hook is removed or if no hook is found.
+type hookResult struct {
+	fn    reflect.Value
+	found bool
+}
+
+// hooks gathers hooks of a same family. Exact type hooks are stored
+// in a map so they are the fastest to retrieve. Interface and
+// predicate hooks are stored in registration order, and the result
+// of their lookup is cached per type.
+//
+// A removed hook is recorded with an invalid function, so it hides
+// hooks of the same type inherited from parents.
+type hooks struct {
+	exact   map[reflect.Type]reflect.Value
+	generic []hook
+	cache   map[reflect.Type]hookResult
+}
+
+// add records "fn" as an exact type hook or, if "in" is an
+// interface, as an interface hook.
+func (h *hooks) add(in reflect.Type, fn reflect.Value) {
+	if in.Kind() != reflect.Interface {
+		if h.exact == nil {
+			h.exact = map[reflect.Type]reflect.Value{}
+		}
+		h.exact[in] = fn
+		return
+	}
+
+	// A new hook for a same interface replaces the old one
+	for n, old := range h.generic {
+		if old.match == nil && old.in == in {
+			h.generic = append(h.generic[:n:n], h.generic[n+1:]...)
+			break
+		}
+	}
+	h.addGeneric(hook{fn: fn, in: in})
+}
+
+// addIf records "fn" as a predicate hook.
+func (h *hooks) addIf(match func(reflect.Type) bool, fn reflect.Value) {
+	h.addGeneric(hook{fn: fn, in: fn.Type().In(0), match: match})
+}
+
+func (h *hooks) addGeneric(nh hook) {
+	h.generic = append(h.generic, nh)
+	h.cache = nil
+}
+
+// get returns the hook matching type "t". The exact type hook comes
+// first, then the last registered interface hook implemented by "t",
+// then the last registered predicate hook matching "t". Interface and
+// predicate hooks never apply to interface types.
+//
+// The returned bool is true if a hook is found, even if it is a
+// removed one, in this case the returned function is invalid.
+func (h *hooks) get(t reflect.Type) (reflect.Value, bool) {
+	if fn, ok := h.exact[t]; ok {
+		return fn, true
+	}
+	if len(h.generic) == 0 || t.Kind() == reflect.Interface {
+		return reflect.Value{}, false
+	}
+
+	if res, ok := h.cache[t]; ok {
+		return res.fn, res.found
+	}
+
+	var res hookResult
+	for _, predicates := range [...]bool{false, true} {
+		for n := len(h.generic) - 1; n >= 0; n-- {
+			if cur := &h.generic[n]; (cur.match != nil) == predicates && cur.matches(t) {
+				res = hookResult{fn: cur.fn, found: true}
+				break
+			}
+		}
+		if res.found {
+			break
+		}
+	}
+
+	if h.cache == nil {
+		h.cache = map[reflect.Type]hookResult{}
+	}
+	h.cache[t] = res // even if not found, to remember a miss
+	return res.fn, res.found
+}
+
+// isEmpty returns true if no hook is recorded in "h", removed ones
+// being ignored.
+func (h *hooks) isEmpty() bool {
+	for _, fn := range h.exact {
+		if fn.IsValid() {
+			return false
+		}
+	}
+	for _, g := range h.generic {
+		if g.fn.IsValid() {
+			return false
+		}
+	}
+	return true
+}
+
+func (h *hooks) copy() hooks {
+	var to hooks
+	if len(h.exact) > 0 {
+		to.exact = make(map[reflect.Type]reflect.Value, len(h.exact))
+		for t, v := range h.exact {
+			to.exact[t] = v
+		}
+	}
+	if len(h.generic) > 0 {
+		to.generic = append([]hook(nil), h.generic...)
+	}
+	return to
+}
 
 // Info gathers all hooks information.
 type Info struct {
 	sync.Mutex
 	cmp     hooks
 	smuggle hooks
+	format  hooks
+	// parents are consulted, from the last to the first, when no hook
+	// is found in this instance
+	parents []*Info
 }
 
+type family func(*Info) *hooks
+
+var (
+	cmpFamily     family = func(i *Info) *hooks { return &i.cmp }
+	smuggleFamily family = func(i *Info) *hooks { return &i.smuggle }
+	formatFamily  family = func(i *Info) *hooks { return &i.format }
+)
+
 // NewInfo returns a new instance of *Info.
 func NewInfo() *Info {
 	return &Info{}
@@ -31,18 +180,6 @@ func NewInfo() *Info {
 
 var ErrBoolean = errors.New("CmpHook(got, expected) failed")
 
-func copyHooks(from hooks) hooks {
-	if len(from) == 0 {
-		return nil
-	}
-
-	to := make(hooks, len(from))
-	for t, v := range from {
-		to[t] = v
-	}
-	return to
-}
-
 // Copy returns a new instance of *Info with the same hooks as i. As a
 // special case, if i is nil, returned instance is non-nil.
 func (i *Info) Copy() *Info {
@@ -55,12 +192,131 @@ func (i *Info) Copy() *Info {
 	i.Lock()
 	defer i.Unlock()
 
-	ni.cmp = copyHooks(i.cmp)
-	ni.smuggle = copyHooks(i.smuggle)
+	ni.cmp = i.cmp.copy()
+	ni.smuggle = i.smuggle.copy()
+	ni.format = i.format.copy()
+	if len(i.parents) > 0 {
+		ni.parents = append([]*Info(nil), i.parents...)
+	}
 
 	return ni
 }
 
+// Inherit records "parents" as parents of i. When no hook is found in
+// i, parents are consulted, from the last to the first one. So hooks
+// of i take precedence over parents ones, and hooks of a parent take
+// precedence over previous parents ones. nil parents are ignored.
+func (i *Info) Inherit(parents ...*Info) {
+	i.Lock()
+	defer i.Unlock()
+
+	for _, p := range parents {
+		if p != nil && p != i {
+			i.parents = append(i.parents, p)
+		}
+	}
+}
+
+var testDeepStringer = reflect.TypeOf((*types.TestDeepStringer)(nil)).Elem()
+
+func isCmpHook(ft reflect.Type) bool {
+	return !ft.IsVariadic() &&
+		ft.NumIn() == 2 &&
+		ft.NumOut() == 1 &&
+		ft.In(0) == ft.In(1) &&
+		(ft.Out(0) == types.Bool || ft.Out(0) == types.Error)
+}
+
+func isSmuggleHook(ft reflect.Type) bool {
+	return !ft.IsVariadic() &&
+		ft.NumIn() == 1 &&
+		(ft.NumOut() == 1 || (ft.NumOut() == 2 && ft.Out(1) == types.Error)) &&
+		ft.Out(0).Kind() != reflect.Interface
+}
+
+func isFormatHook(ft reflect.Type) bool {
+	return !ft.IsVariadic() &&
+		ft.NumIn() == 1 &&
+		ft.NumOut() == 1 &&
+		ft.Out(0) == types.String
+}
+
+// add records functions of "fns" in "h", checking their signatures
+// using "check".
+func (i *Info) add(h *hooks, fns []interface{}, check func(reflect.Type) bool, signature string) error {
+	for n, fn := range fns {
+		vfn := reflect.ValueOf(fn)
+
+		if vfn.Kind() != reflect.Func {
+			return fmt.Errorf("expects a function, not a %s (@%d)", vfn.Kind(), n)
+		}
+
+		ft := vfn.Type()
+		if !check(ft) {
+			return fmt.Errorf("expects: %s not %s (@%d)", signature, ft, n)
+		}
+
+		i.Lock()
+		h.add(ft.In(0), vfn)
+		i.Unlock()
+	}
+	return nil
+}
+
+// remove records removed hooks for types of "types" in "h", so hooks
+// for these types recorded in parents are hidden.
+func (i *Info) remove(h *hooks, types []reflect.Type) {
+	i.Lock()
+	defer i.Unlock()
+
+	for _, t := range types {
+		h.add(t, reflect.Value{})
+	}
+}
+
+// addIf records "fn" in "h" as a predicate hook, checking its
+// signature using "check".
+func (i *Info) addIf(h *hooks, match func(reflect.Type) bool, fn interface{}, check func(reflect.Type) bool, signature string) error {
+	if match == nil {
+		return errors.New("expects a non-nil predicate")
+	}
+
+	vfn := reflect.ValueOf(fn)
+	if vfn.Kind() != reflect.Func {
+		return fmt.Errorf("expects a function, not a %s", vfn.Kind())
+	}
+
+	ft := vfn.Type()
+	if !check(ft) {
+		return fmt.Errorf("expects: %s not %s", signature, ft)
+	}
+
+	i.Lock()
+	h.addIf(match, vfn)
+	i.Unlock()
+	return nil
+}
+
+// get returns the hook of family "f" matching type "t", looking in i
+// then in its parents. The returned bool is false if no hook is found
+// or if the found one is a removed one.
+func (i *Info) get(f family, t reflect.Type) (reflect.Value, bool) {
+	fn, _ := i.lookup(f, t)
+	return fn, fn.IsValid()
+}
+
+func (i *Info) lookup(f family, t reflect.Type) (reflect.Value, bool) {
+	i.Lock()
+	fn, found := f(i).get(t)
+	parents := i.parents
+	i.Unlock()
+
+	for n := len(parents) - 1; !found && n >= 0; n-- {
+		fn, found = parents[n].lookup(f, t)
+	}
+	return fn, found
+}
+
 // AddCmpHooks records new Cmp hooks using functions contained in "fns".
 //
 // Each function in "fns" has to be a function with the following
@@ -69,43 +325,46 @@ func (i *Info) Copy() *Info {
 //   func (A, A) error
 // First arg is always "got", and second is always "expected".
 //
-// A cannot be an interface. This retriction can be removed in the
-// future, if really needed.
+// If A is an interface, the hook applies to all types implementing
+// it. See Cmp for precedence rules.
 //
 // It returns an error if an item of "fns" is not a function or if its
 // signature does not match the expected ones.
 func (i *Info) AddCmpHooks(fns []interface{}) error {
-	for n, fn := range fns {
-		vfn := reflect.ValueOf(fn)
-
-		if vfn.Kind() != reflect.Func {
-			return fmt.Errorf("expects a function, not a %s (@%d)", vfn.Kind(), n)
-		}
+	return i.add(&i.cmp, fns, isCmpHook, "func (T, T) bool|error")
+}
 
-		ft := vfn.Type()
-		if !ft.IsVariadic() &&
-			ft.NumIn() == 2 &&
-			ft.NumOut() == 1 &&
-			ft.In(0) == ft.In(1) &&
-			ft.In(0).Kind() != reflect.Interface &&
-			(ft.Out(0) == types.Bool || ft.Out(0) == types.Error) {
-			i.Lock()
-			if i.cmp == nil {
-				i.cmp = hooks{}
-			}
-			i.cmp[ft.In(0)] = vfn
-			i.Unlock()
-			continue
-		}
+// AddCmpHookIf records a new Cmp hook "fn" applying to all types for
+// which "match" returns true. "fn" has the same possible signatures
+// as AddCmpHooks ones, but the hook only applies to types assignable
+// to A, so A is typically an interface.
+//
+// It returns an error if "match" is nil, if "fn" is not a function or
+// if its signature does not match the expected ones.
+func (i *Info) AddCmpHookIf(match func(reflect.Type) bool, fn interface{}) error {
+	return i.addIf(&i.cmp, match, fn, isCmpHook, "func (T, T) bool|error")
+}
 
-		return fmt.Errorf("expects: func (T, T) bool|error not %s (@%d)", ft, n)
-	}
-	return nil
+// RemoveCmpHooks removes Cmp hooks for types of "types", including
+// those recorded in parents. If a type is an interface, the
+// corresponding interface hook is removed.
+func (i *Info) RemoveCmpHooks(types []reflect.Type) {
+	i.remove(&i.cmp, types)
 }
 
 // Cmp checks if a Cmp hook exists matching "got" and "expected" types.
 //
-// If no, it returns (false, nil)
+// Hooks are searched in this order, the first found wins:
+//   - the hook recorded for the exact "got" type;
+//   - the last recorded hook whose parameter is an interface
+//     implemented by "got" type;
+//   - the last recorded predicate hook matching "got" type.
+// Interface and predicate hooks never apply to interface types.
+//
+// In all cases, "expected" type has to be assignable to the hook
+// parameter type, and cannot be a TestDeep operator.
+//
+// If no hook is found, it returns (false, nil)
 //
 // If yes, it calls it and returns (true, nil) if it succeeds,
 // (true, <an error>) if it fails. If the hook returns a false bool, the
@@ -115,16 +374,13 @@ func (i *Info) Cmp(got, expected reflect.Value) (bool, error) {
 		return false, nil
 	}
 
-	tg := got.Type()
-
-	i.Lock()
-	vfn, ok := i.cmp[tg]
-	i.Unlock()
+	vfn, ok := i.get(cmpFamily, got.Type())
 	if !ok {
 		return false, nil
 	}
 
-	if !expected.Type().AssignableTo(vfn.Type().In(1)) {
+	te := expected.Type()
+	if !te.AssignableTo(vfn.Type().In(1)) || te.Implements(testDeepStringer) {
 		return false, nil
 	}
 
@@ -147,42 +403,37 @@ func (i *Info) Cmp(got, expected reflect.Value) (bool, error) {
 //   func (A) B
 //   func (A) (B, error)
 //
-// A cannot be an interface. This retriction can be removed in the
-// future, if really needed.
+// If A is an interface, the hook applies to all types implementing
+// it. See Cmp for precedence rules.
 //
-// B can be an interface.
+// B cannot be an interface.
 //
 // It returns an error if an item of "fns" is not a function or if its
 // signature does not match the expected ones.
 func (i *Info) AddSmuggleHooks(fns []interface{}) error {
-	for n, fn := range fns {
-		vfn := reflect.ValueOf(fn)
-
-		if vfn.Kind() != reflect.Func {
-			return fmt.Errorf("expects a function, not a %s (@%d)", vfn.Kind(), n)
-		}
+	return i.add(&i.smuggle, fns, isSmuggleHook, "func (A) (B[, error])")
+}
 
-		ft := vfn.Type()
-		if !ft.IsVariadic() &&
-			ft.NumIn() == 1 &&
-			ft.In(0).Kind() != reflect.Interface &&
-			(ft.NumOut() == 1 || (ft.NumOut() == 2 && ft.Out(1) == types.Error)) &&
-			ft.Out(0).Kind() != reflect.Interface {
-			i.Lock()
-			if i.smuggle == nil {
-				i.smuggle = hooks{}
-			}
-			i.smuggle[ft.In(0)] = vfn
-			i.Unlock()
-			continue
-		}
+// AddSmuggleHookIf records a new Smuggle hook "fn" applying to all
+// types for which "match" returns true. "fn" has the same possible
+// signatures as AddSmuggleHooks ones, but the hook only applies to
+// types assignable to A, so A is typically an interface.
+//
+// It returns an error if "match" is nil, if "fn" is not a function or
+// if its signature does not match the expected ones.
+func (i *Info) AddSmuggleHookIf(match func(reflect.Type) bool, fn interface{}) error {
+	return i.addIf(&i.smuggle, match, fn, isSmuggleHook, "func (A) (B[, error])")
+}
 
-		return fmt.Errorf("expects: func (A) (B[, error]) not %s (@%d)", ft, n)
-	}
-	return nil
+// RemoveSmuggleHooks removes Smuggle hooks for types of "types",
+// including those recorded in parents. If a type is an interface, the
+// corresponding interface hook is removed.
+func (i *Info) RemoveSmuggleHooks(types []reflect.Type) {
+	i.remove(&i.smuggle, types)
 }
 
-// Smuggle checks if a Smuggle hook exists matching "*got" type.
+// Smuggle checks if a Smuggle hook exists matching "*got" type. See
+// Cmp for precedence rules.
 //
 // If no, it returns (false, nil)
 //
@@ -193,11 +444,7 @@ func (i *Info) Smuggle(got *reflect.Value) (bool, error) {
 		return false, nil
 	}
 
-	tg := got.Type()
-
-	i.Lock()
-	vfn, ok := i.smuggle[tg]
-	i.Unlock()
+	vfn, ok := i.get(smuggleFamily, got.Type())
 	if !ok {
 		return false, nil
 	}
@@ -212,3 +459,73 @@ func (i *Info) Smuggle(got *reflect.Value) (bool, error) {
 	*got = res[0]
 	return true, nil
 }
+
+// AddFormatHooks records new Format hooks using functions contained
+// in "fns".
+//
+// Each function in "fns" has to be a function with the following
+// signature:
+//   func (A) string
+//
+// If A is an interface, the hook applies to all types implementing
+// it. See Cmp for precedence rules.
+//
+// It returns an error if an item of "fns" is not a function or if its
+// signature does not match the expected one.
+func (i *Info) AddFormatHooks(fns []interface{}) error {
+	return i.add(&i.format, fns, isFormatHook, "func (A) string")
+}
+
+// RemoveFormatHooks removes Format hooks for types of "types",
+// including those recorded in parents. If a type is an interface, the
+// corresponding interface hook is removed.
+func (i *Info) RemoveFormatHooks(types []reflect.Type) {
+	i.remove(&i.format, types)
+}
+
+// HasFormat returns true if a Format hook exists for type "t".
+func (i *Info) HasFormat(t reflect.Type) bool {
+	if i == nil {
+		return false
+	}
+
+	_, ok := i.get(formatFamily, t)
+	return ok
+}
+
+// HasFormatters returns true if at least one Format hook is
+// recorded in i or in its parents.
+func (i *Info) HasFormatters() bool {
+	if i == nil {
+		return false
+	}
+
+	i.Lock()
+	empty := i.format.isEmpty()
+	parents := i.parents
+	i.Unlock()
+
+	for n := len(parents) - 1; empty && n >= 0; n-- {
+		empty = !parents[n].HasFormatters()
+	}
+	return !empty
+}
+
+// Format checks if a Format hook exists matching "got" type. See Cmp
+// for precedence rules.
+//
+// If no, it returns ("", false)
+//
+// If yes, it calls it and returns (<its result>, true).
+func (i *Info) Format(got reflect.Value) (string, bool) {
+	if i == nil {
+		return "", false
+	}
+
+	vfn, ok := i.get(formatFamily, got.Type())
+	if !ok {
+		return "", false
+	}
+
+	return vfn.Call([]reflect.Value{got})[0].String(), true
+}
@@ -8,6 +8,7 @@ package hooks_test
 
 import (
 	"errors"
+	"fmt"
 	"reflect"
 	"strconv"
 	"strings"
@@ -15,6 +16,7 @@ import (
 
 	"github.com/maxatome/go-testdeep/internal/hooks"
 	"github.com/maxatome/go-testdeep/internal/test"
+	"github.com/maxatome/go-testdeep/internal/types"
 )
 
 func TestAddCmpHooks(t *testing.T) {
@@ -48,11 +50,6 @@ func TestAddCmpHooks(t *testing.T) {
 			cmp:  func(a int, b bool) bool { return true },
 			err:  "expects: func (T, T) bool|error not func(int, bool) bool (@1)",
 		},
-		{
-			name: "interface",
-			cmp:  func(a, b interface{}) bool { return true },
-			err:  "expects: func (T, T) bool|error not func(interface {}, interface {}) bool (@1)",
-		},
 		{
 			name: "bad return",
 			cmp:  func(a, b int) int { return 0 },
@@ -201,11 +198,6 @@ func TestAddSmuggleHooks(t *testing.T) {
 			smuggle: func(a, b int) bool { return true },
 			err:     "expects: func (A) (B[, error]) not func(int, int) bool (@1)",
 		},
-		{
-			name:    "interface",
-			smuggle: func(a interface{}) bool { return true },
-			err:     "expects: func (A) (B[, error]) not func(interface {}) bool (@1)",
-		},
 		{
 			name:    "out",
 			smuggle: func(a int) {},
@@ -302,3 +294,320 @@ func TestCopy(t *testing.T) {
 	test.IsTrue(t, handled)
 	test.IsTrue(t, handled)
 }
+
+func TestFormat(t *testing.T) {
+	var i *hooks.Info
+
+	s, handled := i.Format(reflect.ValueOf(12))
+	test.IsFalse(t, handled)
+	test.EqualStr(t, s, "")
+	test.IsFalse(t, i.HasFormat(reflect.TypeOf(12)))
+	test.IsFalse(t, i.HasFormatters())
+
+	i = hooks.NewInfo()
+	test.IsFalse(t, i.HasFormatters())
+	test.NoError(t, i.AddFormatHooks([]interface{}{
+		func(n int) string { return "int:" + strconv.Itoa(n) },
+		strconv.Quote,
+	}))
+
+	test.IsTrue(t, i.HasFormat(reflect.TypeOf(12)))
+	test.IsTrue(t, i.HasFormat(reflect.TypeOf("")))
+	test.IsFalse(t, i.HasFormat(reflect.TypeOf(true)))
+	test.IsTrue(t, i.HasFormatters())
+
+	// Formatters of parents are taken into account, removed ones are not
+	child := hooks.NewInfo()
+	child.Inherit(i)
+	test.IsTrue(t, child.HasFormatters())
+	removed := hooks.NewInfo()
+	removed.RemoveFormatHooks([]reflect.Type{reflect.TypeOf(12)})
+	test.IsFalse(t, removed.HasFormatters())
+
+	s, handled = i.Format(reflect.ValueOf(12))
+	test.IsTrue(t, handled)
+	test.EqualStr(t, s, "int:12")
+
+	s, handled = i.Format(reflect.ValueOf("foo"))
+	test.IsTrue(t, handled)
+	test.EqualStr(t, s, `"foo"`)
+
+	_, handled = i.Format(reflect.ValueOf(true))
+	test.IsFalse(t, handled)
+
+	// Copy
+	s, handled = i.Copy().Format(reflect.ValueOf(12))
+	test.IsTrue(t, handled)
+	test.EqualStr(t, s, "int:12")
+}
+
+func TestAddFormatHooks(t *testing.T) {
+	for _, tst := range []struct {
+		name   string
+		format interface{}
+		err    string
+	}{
+		{
+			name:   "not a function",
+			format: "zip",
+			err:    "expects a function, not a string (@1)",
+		},
+		{
+			name:   "no variadic",
+			format: func(a ...byte) string { return "" },
+			err:    "expects: func (A) string not func(...uint8) string (@1)",
+		},
+		{
+			name:   "in",
+			format: func(a, b int) string { return "" },
+			err:    "expects: func (A) string not func(int, int) string (@1)",
+		},
+		{
+			name:   "out",
+			format: func(a int) (string, error) { return "", nil },
+			err:    "expects: func (A) string not func(int) (string, error) (@1)",
+		},
+		{
+			name:   "bad return",
+			format: func(a int) int { return 0 },
+			err:    "expects: func (A) string not func(int) int (@1)",
+		},
+	} {
+		i := hooks.NewInfo()
+
+		err := i.AddFormatHooks([]interface{}{
+			func(a int) string { return "" },
+			tst.format,
+		})
+		if test.Error(t, err, tst.name) {
+			if !strings.Contains(err.Error(), tst.err) {
+				t.Errorf("<%s> does not contain <%s> for %s", err, tst.err, tst.name)
+			}
+		}
+	}
+}
+
+type stringerA struct{}
+
+func (stringerA) String() string { return "A" }
+
+type stringerB struct{}
+
+func (stringerB) String() string { return "B" }
+
+func TestInterfaceHooks(t *testing.T) {
+	i := hooks.NewInfo()
+
+	var called string
+	test.NoError(t, i.AddCmpHooks([]interface{}{
+		func(a, b fmt.Stringer) bool { called = "stringer"; return a.String() == b.String() },
+		func(a, b error) bool { called = "error"; return true },
+	}))
+
+	handled, err := i.Cmp(reflect.ValueOf(stringerA{}), reflect.ValueOf(stringerA{}))
+	test.NoError(t, err)
+	test.IsTrue(t, handled)
+	test.EqualStr(t, called, "stringer")
+
+	handled, err = i.Cmp(reflect.ValueOf(stringerA{}), reflect.ValueOf(stringerB{}))
+	test.IsTrue(t, handled)
+	test.IsTrue(t, err == hooks.ErrBoolean)
+
+	// expected not assignable
+	handled, _ = i.Cmp(reflect.ValueOf(stringerA{}), reflect.ValueOf(12))
+	test.IsFalse(t, handled)
+
+	// expected is an operator
+	handled, _ = i.Cmp(reflect.ValueOf(stringerA{}), reflect.ValueOf(types.RawString("op")))
+	test.IsFalse(t, handled)
+
+	// Interface types are never concerned
+	var s fmt.Stringer = stringerA{}
+	handled, _ = i.Cmp(reflect.ValueOf(&s).Elem(), reflect.ValueOf(&s).Elem())
+	test.IsFalse(t, handled)
+
+	// Type implementing both interfaces: the last recorded wins
+	handled, _ = i.Cmp(reflect.ValueOf(strErr{}), reflect.ValueOf(strErr{}))
+	test.IsTrue(t, handled)
+	test.EqualStr(t, called, "error")
+
+	// Re-recording a hook for an interface makes it the last one
+	test.NoError(t, i.AddCmpHooks([]interface{}{
+		func(a, b fmt.Stringer) bool { called = "stringer2"; return true },
+	}))
+	handled, _ = i.Cmp(reflect.ValueOf(strErr{}), reflect.ValueOf(strErr{}))
+	test.IsTrue(t, handled)
+	test.EqualStr(t, called, "stringer2")
+
+	// Exact type hook takes precedence
+	test.NoError(t, i.AddCmpHooks([]interface{}{
+		func(a, b stringerA) bool { called = "exact"; return true },
+	}))
+	handled, _ = i.Cmp(reflect.ValueOf(stringerA{}), reflect.ValueOf(stringerA{}))
+	test.IsTrue(t, handled)
+	test.EqualStr(t, called, "exact")
+
+	// Copy keeps generic hooks
+	handled, _ = i.Copy().Cmp(reflect.ValueOf(stringerB{}), reflect.ValueOf(stringerB{}))
+	test.IsTrue(t, handled)
+	test.EqualStr(t, called, "stringer2")
+}
+
+type strErr struct{}
+
+func (strErr) String() string { return "S" }
+func (strErr) Error() string  { return "E" }
+
+func TestPredicateHooks(t *testing.T) {
+	i := hooks.NewInfo()
+
+	isPtrToStruct := func(t reflect.Type) bool {
+		return t.Kind() == reflect.Ptr && t.Elem().Kind() == reflect.Struct
+	}
+
+	var called string
+	test.NoError(t, i.AddCmpHookIf(isPtrToStruct,
+		func(a, b interface{}) bool { called = "ptr"; return true }))
+
+	handled, err := i.Cmp(reflect.ValueOf(&stringerA{}), reflect.ValueOf(&stringerB{}))
+	test.NoError(t, err)
+	test.IsTrue(t, handled)
+	test.EqualStr(t, called, "ptr")
+
+	handled, _ = i.Cmp(reflect.ValueOf(stringerA{}), reflect.ValueOf(stringerA{}))
+	test.IsFalse(t, handled)
+
+	// Interface hooks take precedence over predicate ones
+	test.NoError(t, i.AddCmpHooks([]interface{}{
+		func(a, b fmt.Stringer) bool { called = "stringer"; return true },
+	}))
+	handled, _ = i.Cmp(reflect.ValueOf(&stringerA{}), reflect.ValueOf(&stringerA{}))
+	test.IsTrue(t, handled)
+	test.EqualStr(t, called, "stringer")
+
+	// Smuggle
+	test.NoError(t, i.AddSmuggleHookIf(
+		func(t reflect.Type) bool { return t.Kind() == reflect.Int8 },
+		func(a interface{}) int { return int(a.(int8)) }))
+	got := reflect.ValueOf(int8(42))
+	handled, err = i.Smuggle(&got)
+	test.NoError(t, err)
+	test.IsTrue(t, handled)
+	test.EqualInt(t, int(got.Int()), 42)
+	test.IsTrue(t, got.Type() == reflect.TypeOf(0))
+
+	got = reflect.ValueOf(int16(42))
+	handled, _ = i.Smuggle(&got)
+	test.IsFalse(t, handled)
+
+	// Errors
+	err = i.AddCmpHookIf(nil, func(a, b int) bool { return true })
+	if test.Error(t, err) {
+		test.EqualStr(t, err.Error(), "expects a non-nil predicate")
+	}
+	err = i.AddCmpHookIf(isPtrToStruct, 12)
+	if test.Error(t, err) {
+		test.EqualStr(t, err.Error(), "expects a function, not a int")
+	}
+	err = i.AddSmuggleHookIf(isPtrToStruct, func(a, b int) bool { return true })
+	if test.Error(t, err) {
+		test.EqualStr(t, err.Error(),
+			"expects: func (A) (B[, error]) not func(int, int) bool")
+	}
+}
+
+func TestInherit(t *testing.T) {
+	var called string
+
+	parent1 := hooks.NewInfo()
+	test.NoError(t, parent1.AddCmpHooks([]interface{}{
+		func(a, b int) bool { called = "parent1 int"; return true },
+		func(a, b string) bool { called = "parent1 string"; return true },
+	}))
+	test.NoError(t, parent1.AddFormatHooks([]interface{}{
+		func(a int) string { return "int!" },
+	}))
+
+	parent2 := hooks.NewInfo()
+	test.NoError(t, parent2.AddCmpHooks([]interface{}{
+		func(a, b string) bool { called = "parent2 string"; return true },
+		func(a, b fmt.Stringer) bool { called = "parent2 stringer"; return true },
+	}))
+
+	i := hooks.NewInfo()
+	i.Inherit(parent1, nil, parent2, i)
+	test.NoError(t, i.AddCmpHooks([]interface{}{
+		func(a, b bool) bool { called = "local bool"; return true },
+	}))
+
+	check := func(i *hooks.Info, v interface{}, expected string) {
+		t.Helper()
+		called = ""
+		handled, err := i.Cmp(reflect.ValueOf(v), reflect.ValueOf(v))
+		test.NoError(t, err)
+		test.EqualBool(t, handled, expected != "")
+		test.EqualStr(t, called, expected)
+	}
+
+	check(i, 12, "parent1 int")
+	check(i, "str", "parent2 string") // last parent wins
+	check(i, true, "local bool")
+	check(i, stringerA{}, "parent2 stringer")
+	check(i, 1.2, "")
+
+	test.IsTrue(t, i.HasFormat(reflect.TypeOf(0)))
+	s, ok := i.Format(reflect.ValueOf(12))
+	test.IsTrue(t, ok)
+	test.EqualStr(t, s, "int!")
+
+	// Local hook overrides parent ones
+	test.NoError(t, i.AddCmpHooks([]interface{}{
+		func(a, b int) bool { called = "local int"; return true },
+	}))
+	check(i, 12, "local int")
+
+	// Copy keeps parents
+	c := i.Copy()
+	check(c, "str", "parent2 string")
+
+	// Removed hooks hide parent ones
+	c.RemoveCmpHooks([]reflect.Type{
+		reflect.TypeOf(0),
+		reflect.TypeOf(""),
+		reflect.TypeOf((*fmt.Stringer)(nil)).Elem(),
+	})
+	check(c, 12, "")
+	check(c, "str", "")
+	check(c, stringerA{}, "")
+	check(c, true, "local bool")
+
+	// but only in the copy
+	check(i, 12, "local int")
+	check(i, "str", "parent2 string")
+	check(i, stringerA{}, "parent2 stringer")
+
+	// A hook added after a removal is used again
+	test.NoError(t, c.AddCmpHooks([]interface{}{
+		func(a, b string) bool { called = "copy string"; return true },
+	}))
+	check(c, "str", "copy string")
+
+	c.RemoveFormatHooks([]reflect.Type{reflect.TypeOf(0)})
+	test.IsFalse(t, c.HasFormat(reflect.TypeOf(0)))
+	test.IsTrue(t, i.HasFormat(reflect.TypeOf(0)))
+
+	// Smuggle
+	test.NoError(t, parent1.AddSmuggleHooks([]interface{}{
+		func(a int8) int { return int(a) },
+	}))
+	got := reflect.ValueOf(int8(42))
+	handled, err := c.Smuggle(&got)
+	test.NoError(t, err)
+	test.IsTrue(t, handled)
+	test.IsTrue(t, got.Type() == reflect.TypeOf(0))
+
+	c.RemoveSmuggleHooks([]reflect.Type{reflect.TypeOf(int8(0))})
+	got = reflect.ValueOf(int8(42))
+	handled, _ = c.Smuggle(&got)
+	test.IsFalse(t, handled)
+}
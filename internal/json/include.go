@@ -0,0 +1,115 @@
+// Copyright (c) 2021, Maxime Soulé
+// All rights reserved.
+//
+// This source code is licensed under the BSD-style license found in the
+// LICENSE file in the root directory of this source tree.
+
+package json
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// include handles Include(FILENAME) and Include(FILENAME, PARAMS)
+// directives. FILENAME, if relative, is relative to the directory of
+// the current file. PARAMS is an object whose members are accessible
+// in the included document using Param().
+func (j *json) include(params []interface{}, opPos Position) (interface{}, error) {
+	if len(params) == 0 || len(params) > 2 {
+		return nil, errors.New("Include() requires a file name and an optional parameters object")
+	}
+	file, ok := params[0].(string)
+	if !ok || file == "" {
+		return nil, errors.New("Include() bad #1 parameter, file name string required")
+	}
+	var incParams map[string]interface{}
+	if len(params) == 2 {
+		incParams, ok = params[1].(map[string]interface{})
+		if !ok {
+			return nil, errors.New("Include() bad #2 parameter, parameters object required")
+		}
+	}
+
+	if !filepath.IsAbs(file) && j.opts.File != "" {
+		file = filepath.Join(filepath.Dir(j.opts.File), file)
+	}
+
+	abs, err := filepath.Abs(file)
+	if err != nil {
+		return nil, fmt.Errorf("Include() %s", err)
+	}
+	for i, inc := range j.opts.includes {
+		if inc == abs {
+			chain := append(append([]string{}, j.opts.includes[i:]...), abs)
+			return nil, fmt.Errorf("Include() cycle detected: %s",
+				strings.Join(chain, " → "))
+		}
+	}
+
+	buf, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("Include() %s", err)
+	}
+
+	inc := json{
+		buf:  buf,
+		pos:  Position{Line: 1, File: file},
+		opts: j.opts,
+	}
+	inc.opts.StartPos = Position{}
+	inc.opts.File = file
+	inc.opts.includes = append(j.opts.includes[:len(j.opts.includes):len(j.opts.includes)], abs)
+	inc.opts.params = incParams
+	inc.opts.usedParams = map[string]bool{}
+	yyParse(&inc)
+
+	// Report each error at its own position, and continue parsing
+	for _, err := range inc.errs {
+		err.fatal = false
+		j.errs = append(j.errs, err)
+	}
+
+	// Parameters not used by the included document are probably typos
+	unused := make([]string, 0, len(incParams))
+	for name := range incParams {
+		if !inc.opts.usedParams[name] {
+			unused = append(unused, name)
+		}
+	}
+	if len(unused) > 0 {
+		sort.Strings(unused)
+		j.error(fmt.Sprintf("Include() %s does not use parameter(s) %s",
+			file, strings.Join(unused, ", ")), opPos)
+	}
+
+	return inc.value, nil
+}
+
+// param handles Param(NAME) and Param(NAME, DEFAULT) directives,
+// returning the value of the parameter NAME passed to Include(), or
+// DEFAULT if it is not passed.
+func (j *json) param(params []interface{}, opPos Position) (interface{}, error) {
+	if len(params) == 0 || len(params) > 2 {
+		return nil, errors.New("Param() requires a name and an optional default value")
+	}
+	name, ok := params[0].(string)
+	if !ok || name == "" {
+		return nil, errors.New("Param() bad #1 parameter, name string required")
+	}
+
+	if value, ok := j.opts.params[name]; ok {
+		j.opts.usedParams[name] = true
+		return value, nil
+	}
+	if len(params) == 2 {
+		return params[1], nil
+	}
+
+	j.error(fmt.Sprintf("Param() missing parameter %q without default value", name), opPos)
+	return nil, nil // continue parsing
+}
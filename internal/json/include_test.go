@@ -0,0 +1,223 @@
+// Copyright (c) 2021, Maxime Soulé
+// All rights reserved.
+//
+// This source code is licensed under the BSD-style license found in the
+// LICENSE file in the root directory of this source tree.
+
+package json_test
+
+import (
+	ejson "encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/maxatome/go-testdeep/internal/json"
+	"github.com/maxatome/go-testdeep/internal/test"
+)
+
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		name = filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(name), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(name, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestInclude(t *testing.T) {
+	dir, err := ioutil.TempDir("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir) // clean up
+
+	writeFiles(t, dir, map[string]string{
+		"main.json": `{"items": [], "pagination": Include("common/pagination.json")}`,
+		"common/pagination.json": `{
+  "page":     Param("page", 1),
+  "per_page": Param("per_page", 20),
+  "links":    Include("links.json", {"next": Param("next", null)})
+}`,
+		"common/links.json": `{"next": Param("next")}`,
+		"error.json": `{
+  "error": {
+    "code":    Param("code"),
+    "message": Param("message", "unknown error")
+  }
+}`,
+		"bad/syntax.json":  "{\n  \"a\": 1,\n  \"b\": ]\n}",
+		"bad/param.json":   "[\n  Param(\"missing\")\n]",
+		"bad/op.json":      "{\n  \"a\": Param(\"x\"),\n  \"b\": AnyOp()\n}",
+		"bad/include.json": `Include("../bad/syntax.json")`,
+		"cycle/a.json":     `{"b": Include("b.json")}`,
+		"cycle/b.json":     `{"a": Include("a.json")}`,
+		"cycle/self.json":  `[Include("self.json")]`,
+	})
+
+	t.Run("OK", func(t *testing.T) {
+		got, err := json.Parse([]byte(`Include("main.json")`),
+			json.ParseOpts{File: filepath.Join(dir, "main")})
+		if test.NoError(t, err) {
+			test.IsTrue(t, reflect.DeepEqual(got, map[string]interface{}{
+				"items": []interface{}{},
+				"pagination": map[string]interface{}{
+					"page":     ejson.Number("1"),
+					"per_page": ejson.Number("20"),
+					"links":    map[string]interface{}{"next": nil},
+				},
+			}), "%#v", got)
+		}
+
+		// Relative to the current working directory if no file
+		cwd, _ := os.Getwd()
+		defer os.Chdir(cwd) //nolint: errcheck
+		if err = os.Chdir(dir); err != nil {
+			t.Fatal(err)
+		}
+
+		got, err = json.Parse([]byte(`[
+  Include("common/pagination.json", {"page": 3, "next": "/items?page=4"}),
+  Include("error.json", {"code": $1}),
+  Include("error.json", {"code": 404, "message": "not found"}),
+]`),
+			json.ParseOpts{Placeholders: []interface{}{500}})
+		if test.NoError(t, err) {
+			test.IsTrue(t, reflect.DeepEqual(got, []interface{}{
+				map[string]interface{}{
+					"page":     ejson.Number("3"),
+					"per_page": ejson.Number("20"),
+					"links":    map[string]interface{}{"next": "/items?page=4"},
+				},
+				map[string]interface{}{
+					"error": map[string]interface{}{
+						"code":    500,
+						"message": "unknown error",
+					},
+				},
+				map[string]interface{}{
+					"error": map[string]interface{}{
+						"code":    ejson.Number("404"),
+						"message": "not found",
+					},
+				},
+			}), "%#v", got)
+		}
+
+		// Operators in included files see their position
+		var opPos json.Position
+		_, err = json.Parse([]byte(`Include("bad/op.json", {"x": 1})`),
+			json.ParseOpts{
+				OpFn: func(op json.Operator, pos json.Position) (interface{}, error) {
+					opPos = pos
+					return "OK", nil
+				},
+			})
+		if test.NoError(t, err) {
+			test.EqualStr(t, opPos.String(), "at line 3:7 (pos 28) in bad/op.json")
+		}
+
+		// Param outside any included file
+		got, err = json.Parse([]byte(`Param("p", true)`))
+		if test.NoError(t, err) {
+			test.EqualBool(t, got.(bool), true)
+		}
+	})
+
+	t.Run("Errors", func(t *testing.T) {
+		cwd, _ := os.Getwd()
+		defer os.Chdir(cwd) //nolint: errcheck
+		if err = os.Chdir(dir); err != nil {
+			t.Fatal(err)
+		}
+
+		for i, tst := range []struct{ js, err string }{
+			{
+				js:  `Include("bad/syntax.json")`,
+				err: "syntax error: unexpected ']' at line 3:7 (pos 19) in bad/syntax.json",
+			},
+			{
+				js:  `Include("bad/include.json")`,
+				err: "syntax error: unexpected ']' at line 3:7 (pos 19) in bad/syntax.json",
+			},
+			{
+				js: `[Include("bad/param.json"), Include("bad/param.json", {"missing": 1, "zip": 2, "foo": 3})]`,
+				err: `Param() missing parameter "missing" without default value at line 2:2 (pos 4) in bad/param.json
+Include() bad/param.json does not use parameter(s) foo, zip at line 1:28 (pos 28)`,
+			},
+			{
+				js:  `Include("bad/op.json", {"x": 1})`,
+				err: `unknown operator "AnyOp" at line 3:7 (pos 28) in bad/op.json`,
+			},
+			{
+				js: `[1, Include("cycle/a.json")]`,
+				err: fmt.Sprintf("Include() cycle detected: %[1]s → %[2]s → %[1]s at line 1:6 (pos 6) in cycle/b.json",
+					filepath.Join(dir, "cycle", "a.json"), filepath.Join(dir, "cycle", "b.json")),
+			},
+			{
+				js: `Include("cycle/self.json")`,
+				err: fmt.Sprintf("Include() cycle detected: %[1]s → %[1]s at line 1:1 (pos 1) in cycle/self.json",
+					filepath.Join(dir, "cycle", "self.json")),
+			},
+			{
+				js:  `Include("unknown.json")`,
+				err: "Include() open unknown.json: ",
+			},
+			{
+				js:  `Include()`,
+				err: "Include() requires a file name and an optional parameters object at line 1:0 (pos 0)",
+			},
+			{
+				js:  `Include("a.json", {}, 3)`,
+				err: "Include() requires a file name and an optional parameters object at line 1:0 (pos 0)",
+			},
+			{
+				js:  `Include(1)`,
+				err: "Include() bad #1 parameter, file name string required at line 1:0 (pos 0)",
+			},
+			{
+				js:  `Include("error.json", [])`,
+				err: "Include() bad #2 parameter, parameters object required at line 1:0 (pos 0)",
+			},
+			{
+				js:  `Param()`,
+				err: "Param() requires a name and an optional default value at line 1:0 (pos 0)",
+			},
+			{
+				js:  `Param("")`,
+				err: "Param() bad #1 parameter, name string required at line 1:0 (pos 0)",
+			},
+			{
+				js:  `Param("x")`,
+				err: `Param() missing parameter "x" without default value at line 1:0 (pos 0)`,
+			},
+		} {
+			_, err := json.Parse([]byte(tst.js))
+			if test.Error(t, err, "#%d", i) {
+				test.IsTrue(t, strings.HasPrefix(err.Error(), tst.err),
+					"#%d\n     got: %s\nexpected: %s", i, err, tst.err)
+			}
+		}
+
+		// Errors in the main file are located in it too
+		self := filepath.Join(dir, "cycle", "self.json")
+		_, err = json.Parse([]byte(`[Include("self.json")]`), json.ParseOpts{File: self})
+		if test.Error(t, err) {
+			test.EqualStr(t, err.Error(),
+				fmt.Sprintf("Include() cycle detected: %[1]s → %[1]s at line 1:1 (pos 1) in %[1]s", self))
+		}
+		_, err = json.Parse([]byte("[\n  Param(\"x\")\n]"), json.ParseOpts{File: "main.json"})
+		if test.Error(t, err) {
+			test.EqualStr(t, err.Error(),
+				`Param() missing parameter "x" without default value at line 2:2 (pos 4) in main.json`)
+		}
+	})
+}
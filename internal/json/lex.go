@@ -8,8 +8,10 @@ package json
 
 import (
 	"bytes"
+	ejson "encoding/json"
 	"errors"
 	"fmt"
+	"path/filepath"
 	"strconv"
 	"strings"
 	"unicode"
@@ -23,6 +25,9 @@ type Position struct {
 	Pos  int
 	Line int
 	Col  int
+	// File is the name of the file the position belongs to, empty
+	// if the document does not come from a file
+	File string
 }
 
 func (p Position) incHoriz(bytes int, runes ...int) Position {
@@ -38,6 +43,9 @@ func (p Position) incHoriz(bytes int, runes ...int) Position {
 }
 
 func (p Position) String() string {
+	if p.File != "" {
+		return fmt.Sprintf("at line %d:%d (pos %d) in %s", p.Line, p.Col, p.Pos, p.File)
+	}
 	return fmt.Sprintf("at line %d:%d (pos %d)", p.Line, p.Col, p.Pos)
 }
 
@@ -56,8 +64,29 @@ type json struct {
 type ParseOpts struct {
 	Placeholders       []interface{}
 	PlaceholdersByName map[string]interface{}
-	OpShortcutFn       func(string, Position) (interface{}, bool)
+	OpShortcutFn       func(string, Position) (interface{}, error)
 	OpFn               func(Operator, Position) (interface{}, error)
+	// StartPos, if its Line is not 0, is the position of the first
+	// byte of buf, useful when buf is extracted from a bigger document
+	StartPos Position
+	// File, if not empty, is the name of the file buf comes from. The
+	// files included using Include() are relative to its directory
+	File string
+
+	// includes is the chain of the absolute names of the files
+	// currently included, to detect cycles
+	includes []string
+	// params contains the parameters of the currently included file,
+	// accessible using Param()
+	params map[string]interface{}
+	// usedParams records the params accessed using Param()
+	usedParams map[string]bool
+}
+
+// NewPosition returns a Position corresponding to rune offset "pos",
+// at "line" (starting at 1) and column "col" (starting at 0).
+func NewPosition(pos, line, col int) Position {
+	return Position{Pos: pos, Line: line, Col: col}
 }
 
 func Parse(buf []byte, opts ...ParseOpts) (interface{}, error) {
@@ -69,6 +98,16 @@ func Parse(buf []byte, opts ...ParseOpts) (interface{}, error) {
 	}
 	if len(opts) > 0 {
 		j.opts = opts[0]
+		if j.opts.StartPos.Line > 0 {
+			j.pos = j.opts.StartPos
+			j.pos.bpos = 0
+		}
+	}
+	if j.opts.File != "" {
+		j.pos.File = j.opts.File
+		if abs, err := filepath.Abs(j.opts.File); err == nil {
+			j.opts.includes = []string{abs}
+		}
 	}
 	yyParse(&j)
 
@@ -129,14 +168,21 @@ func (j *json) moveHoriz(bytes int, runes ...int) {
 	j.curSize = 0
 }
 
-func (j *json) getOperatorShortcut(operator string, opPos Position) (interface{}, bool) {
+func (j *json) getOperatorShortcut(operator string, opPos Position) (interface{}, error) {
 	if j.opts.OpShortcutFn == nil {
-		return nil, false
+		return nil, fmt.Errorf(`bad operator shortcut "$^%s"`, operator)
 	}
 	return j.opts.OpShortcutFn(operator, opPos)
 }
 
 func (j *json) getOperator(operator Operator, opPos Position) (interface{}, error) {
+	switch operator.Name {
+	case "Include":
+		return j.include(operator.Params, opPos)
+	case "Param":
+		return j.param(operator.Params, opPos)
+	}
+
 	if j.opts.OpFn == nil {
 		return nil, fmt.Errorf("unknown operator %q", operator.Name)
 	}
@@ -208,7 +254,7 @@ func (j *json) nextToken(lval *yySymType) int {
 
 	case '$':
 		var dollarToken string
-		end := bytes.IndexAny(j.buf[j.pos.bpos+1:], " \t\r\n,}])")
+		end := bytes.IndexAny(j.buf[j.pos.bpos+1:], " \t\r\n,}])(")
 		if end >= 0 {
 			dollarToken = string(j.buf[j.pos.bpos+1 : j.pos.bpos+1+end])
 		} else {
@@ -219,6 +265,15 @@ func (j *json) nextToken(lval *yySymType) int {
 			return '$'
 		}
 
+		// User defined operator with parameters: $^MyOp(…)
+		if end >= 0 && dollarToken[0] == '^' &&
+			j.buf[j.pos.bpos+1+end] == '(' {
+			j.moveHoriz(1+len(dollarToken), 1+utf8.RuneCountInString(dollarToken))
+			j.pushPos(j.lastTokenPos)
+			lval.string = dollarToken
+			return OPERATOR
+		}
+
 		token, value := j.parseDollarToken(dollarToken, j.pos)
 		if token != 0 {
 			lval.value = value
@@ -337,7 +392,7 @@ str:
 	return "", false
 }
 
-func (j *json) parseNumber() (float64, bool) {
+func (j *json) parseNumber() (ejson.Number, bool) {
 	// j.buf[j.pos.bpos] == '[0-9]' → caller responsibility
 
 	i := j.pos.bpos + 1
@@ -351,15 +406,18 @@ num:
 		}
 	}
 
-	f, err := strconv.ParseFloat(string(j.buf[j.pos.bpos:i]), 64)
-	if err != nil {
+	// The number is kept as is, so no precision is lost, but its
+	// syntax is checked. Out of range numbers are accepted.
+	n := string(j.buf[j.pos.bpos:i])
+	if _, err := strconv.ParseFloat(n, 64); err != nil &&
+		err.(*strconv.NumError).Err != strconv.ErrRange {
 		j.fatal("invalid number")
-		return 0, false
+		return "", false
 	}
 
 	j.curSize = 0
 	j.pos = j.pos.incHoriz(i - j.pos.bpos)
-	return f, true
+	return ejson.Number(n), true
 }
 
 // parseDollarToken parses a $123 or $tag or $^Shortcut token.
@@ -404,11 +462,9 @@ func (j *json) parseDollarToken(dollarToken string, dollarPos Position) (int, in
 
 	// Test for operator shortcut
 	if firstRune == '^' {
-		op, ok := j.getOperatorShortcut(dollarToken[1:], dollarPos)
-		if !ok {
-			j.error(
-				fmt.Sprintf(`bad operator shortcut "$%s"`, dollarToken),
-				dollarPos)
+		op, err := j.getOperatorShortcut(dollarToken[1:], dollarPos)
+		if err != nil {
+			j.error(err.Error(), dollarPos)
 			// continue parsing
 		}
 		return OPERATOR_SHORTCUT, op
@@ -13,18 +13,21 @@ import (
 	"math"
 	"sort"
 	"strconv"
+	"strings"
 	"unicode/utf8"
 )
 
 type marshaler struct {
 	buf    *bytes.Buffer
+	opts   *MarshalOpts
+	ptr    string
 	indent int
 	tmp    []byte
 }
 
 // Marshal returns the JSON encoding of "v". It differs from
 // encoding/json.Marshal() as it only handles map[string]interface{},
-// []interface{}, bool, float64, string, nil and
+// []interface{}, bool, float64, encoding/json.Number, string, nil and
 // encoding/json.Marshaler values. It also accepts "invalid" JSON data
 // returned by MarshalJSON method.
 func Marshal(v interface{}, indent int) ([]byte, error) {
@@ -49,6 +52,121 @@ func AppendMarshal(buf *bytes.Buffer, v interface{}, indent int) error {
 	return m.marshal(v)
 }
 
+// MarshalOpts allows to annotate the JSON encoding produced by
+// AppendMarshalOpts.
+type MarshalOpts struct {
+	// Indent is the indentation of the first line.
+	Indent int
+	// Comments contains the comments to append after values, indexed
+	// by their RFC 6901 JSON pointer, "" being the root value one. A
+	// comment can contain several lines.
+	Comments map[string]string
+	// HighlightOn and HighlightOff surround each commented value.
+	HighlightOn, HighlightOff string
+	// CommentOn and CommentOff surround each comment.
+	CommentOn, CommentOff string
+}
+
+// AppendMarshalOpts does the same as AppendMarshal but appends
+// "opts" comments after the corresponding values, as in:
+//
+//   {
+//     "age": 42,  // a comment
+//     "name": "Bob"
+//   }
+func AppendMarshalOpts(buf *bytes.Buffer, v interface{}, opts MarshalOpts) error {
+	m := marshaler{
+		indent: opts.Indent,
+		buf:    buf,
+		opts:   &opts,
+	}
+
+	comment, ok := m.comment("")
+	if ok {
+		buf.WriteString(opts.HighlightOn)
+	}
+	if err := m.marshal(v); err != nil {
+		return err
+	}
+	if ok {
+		buf.WriteString(opts.HighlightOff)
+		m.writeComment(comment)
+	}
+	return nil
+}
+
+var pointerEscaper = strings.NewReplacer("~", "~0", "/", "~1")
+
+// comment returns the comment of the value designated by "ptr"
+// JSON pointer, if any.
+func (m *marshaler) comment(ptr string) (string, bool) {
+	if m.opts == nil {
+		return "", false
+	}
+	comment, ok := m.opts.Comments[ptr]
+	return comment, ok
+}
+
+// writeComment appends "comment" at the end of the current line. If
+// "comment" contains several lines, the following ones are aligned on
+// the first one.
+func (m *marshaler) writeComment(comment string) {
+	b := m.buf.Bytes()
+	line := b[bytes.LastIndexByte(b, '\n')+1:]
+	col := utf8.RuneCount(line) + 2
+	for _, s := range []string{m.opts.HighlightOn, m.opts.HighlightOff} {
+		if s != "" {
+			col -= bytes.Count(line, []byte(s)) * utf8.RuneCountInString(s)
+		}
+	}
+
+	m.buf.WriteString("  ")
+	m.buf.WriteString(m.opts.CommentOn)
+	for i, l := range strings.Split(comment, "\n") {
+		if i > 0 {
+			fmt.Fprintf(m.buf, "\n%*s", col, "")
+		}
+		m.buf.WriteString("// ")
+		m.buf.WriteString(l)
+	}
+	m.buf.WriteString(m.opts.CommentOff)
+}
+
+// marshalItem marshals "v", an item of an object or an array,
+// designated by "ptr" JSON pointer. "prefix", if any, is the key of
+// the item. "last" is true if "v" is the last item of the container.
+func (m *marshaler) marshalItem(ptr, prefix string, v interface{}, last bool) error {
+	saveIndent, savePtr := m.indent, m.ptr
+	fmt.Fprintf(m.buf, "%*s", m.indent, "")
+
+	comment, ok := m.comment(ptr)
+	if ok {
+		m.buf.WriteString(m.opts.HighlightOn)
+	}
+
+	if prefix != "" {
+		m.buf.WriteString(prefix)
+		m.indent += utf8.RuneCountInString(prefix)
+	}
+	m.ptr = ptr
+	if err := m.marshal(v); err != nil {
+		return err
+	}
+	m.indent, m.ptr = saveIndent, savePtr
+
+	if ok {
+		m.buf.WriteString(m.opts.HighlightOff)
+	}
+	if !last {
+		m.buf.WriteByte(',')
+	}
+	if ok {
+		m.writeComment(comment)
+	}
+	m.buf.WriteByte('\n')
+	return nil
+}
+
 func (m *marshaler) marshal(v interface{}) error {
 	if v == nil {
 		m.buf.WriteString("null")
@@ -71,21 +189,16 @@ func (m *marshaler) marshal(v interface{}) error {
 			keys = append(keys, k)
 		}
 		sort.Strings(keys)
-		beg := "{\n"
-		for _, k := range keys {
-			m.buf.WriteString(beg)
-			beg = ",\n"
-
-			saveIndent, lenBefore := m.indent, m.buf.Len()
-			fmt.Fprintf(m.buf, `%*s%q: `, m.indent, "", k)
-			m.indent = utf8.RuneCount(m.buf.Bytes()[lenBefore:])
-			if err := m.marshal(vt[k]); err != nil {
+		m.buf.WriteString("{\n")
+		for i, k := range keys {
+			err := m.marshalItem(m.ptr+"/"+pointerEscaper.Replace(k),
+				strconv.Quote(k)+": ", vt[k], i == len(keys)-1)
+			if err != nil {
 				return err
 			}
-			m.indent = saveIndent
 		}
 		m.indent -= 2
-		fmt.Fprintf(m.buf, "\n%*s}", m.indent, "")
+		fmt.Fprintf(m.buf, "%*s}", m.indent, "")
 
 	case []interface{}:
 		if len(vt) == 0 {
@@ -97,21 +210,22 @@ func (m *marshaler) marshal(v interface{}) error {
 			break
 		}
 		m.indent += 2
-		beg := "[\n"
-		for _, v := range vt {
-			m.buf.WriteString(beg)
-			beg = ",\n"
-			fmt.Fprintf(m.buf, "%*s", m.indent, "")
-			if err := m.marshal(v); err != nil {
+		m.buf.WriteString("[\n")
+		for i, v := range vt {
+			err := m.marshalItem(m.ptr+"/"+strconv.Itoa(i), "", v, i == len(vt)-1)
+			if err != nil {
 				return err
 			}
 		}
 		m.indent -= 2
-		fmt.Fprintf(m.buf, "\n%*s]", m.indent, "")
+		fmt.Fprintf(m.buf, "%*s]", m.indent, "")
 
 	case string:
 		fmt.Fprintf(m.buf, `%q`, vt)
 
+	case ejson.Number:
+		m.buf.WriteString(string(vt))
+
 	case float64:
 		m.marshalFloat64(vt)
 
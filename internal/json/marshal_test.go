@@ -155,3 +155,59 @@ func TestAppendMarshal(t *testing.T) {
 
 	test.EqualStr(t, buf.String(), `<<"foo">>`)
 }
+
+func TestAppendMarshalOpts(t *testing.T) {
+	doc := map[string]interface{}{
+		"age":  float64(42),
+		"name": "Bob",
+		"kids": []interface{}{"Alice", "Brian"},
+		"a/b":  map[string]interface{}{"~": true},
+	}
+
+	var buf bytes.Buffer
+	err := json.AppendMarshalOpts(&buf, doc, json.MarshalOpts{
+		Comments: map[string]string{
+			"/age":     "too old",
+			"/kids/1":  "first line\nsecond line",
+			"/a~1b/~0": "escaped",
+		},
+		HighlightOn:  "<",
+		HighlightOff: ">",
+		CommentOn:    "[",
+		CommentOff:   "]",
+	})
+	test.NoError(t, err)
+	test.EqualStr(t, buf.String(), `{
+  "a/b": {
+           <"~": true>  [// escaped]
+         },
+  <"age": 42>,  [// too old]
+  "kids": [
+            "Alice",
+            <"Brian">  [// first line
+                     // second line]
+          ],
+  "name": "Bob"
+}`)
+
+	// Root comment
+	buf.Reset()
+	err = json.AppendMarshalOpts(&buf, []interface{}{}, json.MarshalOpts{
+		Comments: map[string]string{"": "empty"},
+	})
+	test.NoError(t, err)
+	test.EqualStr(t, buf.String(), `[]  // empty`)
+
+	// Without comments, same as AppendMarshal
+	buf.Reset()
+	err = json.AppendMarshalOpts(&buf, doc, json.MarshalOpts{Indent: 2})
+	test.NoError(t, err)
+	var expected bytes.Buffer
+	json.AppendMarshal(&expected, doc, 2) //nolint: errcheck
+	test.EqualStr(t, buf.String(), expected.String())
+
+	// Error
+	buf.Reset()
+	err = json.AppendMarshalOpts(&buf, 123, json.MarshalOpts{})
+	test.Error(t, err)
+}
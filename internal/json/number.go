@@ -0,0 +1,169 @@
+// Copyright (c) 2021, Maxime Soulé
+// All rights reserved.
+//
+// This source code is licensed under the BSD-style license found in the
+// LICENSE file in the root directory of this source tree.
+
+package json
+
+import (
+	ejson "encoding/json"
+	"fmt"
+	"math"
+	"math/big"
+	"reflect"
+	"strconv"
+)
+
+// maxExactFloat is the greatest integer such as all integers in
+// [-maxExactFloat, maxExactFloat] are exactly representable as
+// float64.
+const maxExactFloat = 1 << 53
+
+func numberRat(n ejson.Number) (*big.Rat, bool) {
+	return new(big.Rat).SetString(string(n))
+}
+
+// NumberEqual returns true if "a" and "b" represent exactly the same
+// number, whatever their literal representations are, so 1, 1.0 and
+// 1e0 are equal.
+func NumberEqual(a, b ejson.Number) bool {
+	ra, okA := numberRat(a)
+	rb, okB := numberRat(b)
+	if !okA || !okB {
+		return a == b
+	}
+	return ra.Cmp(rb) == 0
+}
+
+// NumberIsInteger returns true if "n" is an integral number, as 1,
+// 1.0 or 1e3.
+func NumberIsInteger(n ejson.Number) bool {
+	r, ok := numberRat(n)
+	return ok && r.IsInt()
+}
+
+// NumberOf returns the json.Number representing the numeric value
+// "v". false is returned if "v" is not numeric or if it is a NaN or
+// an infinite float.
+func NumberOf(v reflect.Value) (ejson.Number, bool) {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return ejson.Number(strconv.FormatInt(v.Int(), 10)), true
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Uintptr:
+		return ejson.Number(strconv.FormatUint(v.Uint(), 10)), true
+
+	case reflect.Float32, reflect.Float64:
+		f := v.Float()
+		if math.IsNaN(f) || math.IsInf(f, 0) {
+			return "", false
+		}
+		return ejson.Number(strconv.FormatFloat(f, 'g', -1, v.Type().Bits())), true
+	}
+	return "", false
+}
+
+// IsNumberKind returns true if kind "k" is an integer or a float one,
+// so a json.Number can be converted to a type of this kind using
+// NumberConvert.
+func IsNumberKind(k reflect.Kind) bool {
+	switch k {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
+
+// NumberConvert converts "n" to a value of type "typ", typ kind
+// being an integer or a float one. An error is returned if "n" does
+// not fit in "typ": a non-integral number or an overflow for an
+// integer type, an overflow for a float type. Note that a float
+// conversion can lose precision, as 0.1 is not exactly
+// representable by a float64.
+func NumberConvert(n ejson.Number, typ reflect.Type) (reflect.Value, error) {
+	v := reflect.New(typ).Elem()
+
+	switch typ.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(string(n), 10, 64)
+		if err != nil {
+			// Perhaps an integral number like 1.0 or 1e3
+			r, ok := numberRat(n)
+			if !ok || !r.IsInt() || !r.Num().IsInt64() {
+				break
+			}
+			i = r.Num().Int64()
+		}
+		if v.OverflowInt(i) {
+			break
+		}
+		v.SetInt(i)
+		return v, nil
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Uintptr:
+		u, err := strconv.ParseUint(string(n), 10, 64)
+		if err != nil {
+			r, ok := numberRat(n)
+			if !ok || !r.IsInt() || !r.Num().IsUint64() {
+				break
+			}
+			u = r.Num().Uint64()
+		}
+		if v.OverflowUint(u) {
+			break
+		}
+		v.SetUint(u)
+		return v, nil
+
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(string(n), typ.Bits())
+		if err != nil {
+			break
+		}
+		v.SetFloat(f)
+		return v, nil
+
+	default:
+		return reflect.Value{}, fmt.Errorf("%s cannot be converted to %s", n, typ)
+	}
+
+	return reflect.Value{}, fmt.Errorf("%s does not fit in %s", n, typ)
+}
+
+// NumberValue returns the Go value best representing "n" when no
+// type is imposed: a float64 if "n" is not integral or if it is
+// exactly representable by a float64, else an int64 or an uint64 if
+// it fits, else "n" itself.
+func NumberValue(n ejson.Number) interface{} {
+	r, ok := numberRat(n)
+	if !ok {
+		return n
+	}
+
+	if r.IsInt() {
+		num := r.Num()
+		if num.IsInt64() {
+			i := num.Int64()
+			if i >= -maxExactFloat && i <= maxExactFloat {
+				return float64(i)
+			}
+			return i
+		}
+		if num.IsUint64() {
+			return num.Uint64()
+		}
+		return n
+	}
+
+	f, err := strconv.ParseFloat(string(n), 64)
+	if err != nil {
+		return n
+	}
+	return f
+}
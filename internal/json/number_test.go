@@ -0,0 +1,143 @@
+// Copyright (c) 2021, Maxime Soulé
+// All rights reserved.
+//
+// This source code is licensed under the BSD-style license found in the
+// LICENSE file in the root directory of this source tree.
+
+package json_test
+
+import (
+	ejson "encoding/json"
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/maxatome/go-testdeep/internal/json"
+	"github.com/maxatome/go-testdeep/internal/test"
+)
+
+func TestNumberEqual(t *testing.T) {
+	for _, tst := range []struct {
+		a, b  ejson.Number
+		equal bool
+	}{
+		{a: "1", b: "1", equal: true},
+		{a: "1", b: "1.0", equal: true},
+		{a: "1", b: "1e0", equal: true},
+		{a: "-0", b: "0", equal: true},
+		{a: "0.1", b: "1e-1", equal: true},
+		{a: "9007199254740993", b: "9007199254740993", equal: true},
+		{a: "9007199254740993", b: "9007199254740992"},
+		{a: "0.10000000000000000001", b: "0.1"},
+		{a: "1", b: "2"},
+		{a: "bad", b: "bad", equal: true},
+		{a: "bad", b: "1"},
+	} {
+		test.EqualBool(t, json.NumberEqual(tst.a, tst.b), tst.equal,
+			"%s == %s", tst.a, tst.b)
+	}
+}
+
+func TestNumberIsInteger(t *testing.T) {
+	test.IsTrue(t, json.NumberIsInteger("1"))
+	test.IsTrue(t, json.NumberIsInteger("1.0"))
+	test.IsTrue(t, json.NumberIsInteger("1e3"))
+	test.IsTrue(t, json.NumberIsInteger("-9007199254740993"))
+	test.IsFalse(t, json.NumberIsInteger("0.1"))
+	test.IsFalse(t, json.NumberIsInteger("1e-3"))
+	test.IsFalse(t, json.NumberIsInteger("bad"))
+}
+
+func TestNumberOf(t *testing.T) {
+	for _, tst := range []struct {
+		v        interface{}
+		expected ejson.Number
+	}{
+		{v: 42, expected: "42"},
+		{v: int64(-9007199254740993), expected: "-9007199254740993"},
+		{v: uint64(math.MaxUint64), expected: "18446744073709551615"},
+		{v: 0.1, expected: "0.1"},
+		{v: float32(0.1), expected: "0.1"},
+		{v: 1e21, expected: "1e+21"},
+	} {
+		n, ok := json.NumberOf(reflect.ValueOf(tst.v))
+		if test.IsTrue(t, ok, "%v", tst.v) {
+			test.EqualStr(t, string(n), string(tst.expected))
+		}
+	}
+
+	for _, v := range []interface{}{"42", math.NaN(), math.Inf(1), true} {
+		_, ok := json.NumberOf(reflect.ValueOf(v))
+		test.IsFalse(t, ok, "%v", v)
+	}
+}
+
+func TestNumberConvert(t *testing.T) {
+	for _, tst := range []struct {
+		n        ejson.Number
+		expected interface{}
+	}{
+		{n: "42", expected: 42},
+		{n: "42.0", expected: int8(42)},
+		{n: "1e3", expected: int16(1000)},
+		{n: "9007199254740993", expected: int64(9007199254740993)},
+		{n: "18446744073709551615", expected: uint64(math.MaxUint64)},
+		{n: "2e1", expected: uint(20)},
+		{n: "0.5", expected: 0.5},
+		{n: "0.5", expected: float32(0.5)},
+	} {
+		v, err := json.NumberConvert(tst.n, reflect.TypeOf(tst.expected))
+		if test.NoError(t, err, "%s", tst.n) {
+			test.IsTrue(t, v.Interface() == tst.expected,
+				"%s: got %v, expected %v", tst.n, v.Interface(), tst.expected)
+		}
+	}
+
+	for _, tst := range []struct {
+		n        ejson.Number
+		typ      reflect.Type
+		expected string
+	}{
+		{n: "1.5", typ: reflect.TypeOf(0), expected: "1.5 does not fit in int"},
+		{n: "128", typ: reflect.TypeOf(int8(0)), expected: "128 does not fit in int8"},
+		{
+			n:        "9223372036854775808",
+			typ:      reflect.TypeOf(int64(0)),
+			expected: "9223372036854775808 does not fit in int64",
+		},
+		{n: "-1", typ: reflect.TypeOf(uint(0)), expected: "-1 does not fit in uint"},
+		{n: "1e400", typ: reflect.TypeOf(0.0), expected: "1e400 does not fit in float64"},
+		{n: "1e39", typ: reflect.TypeOf(float32(0)), expected: "1e39 does not fit in float32"},
+		{n: "42", typ: reflect.TypeOf(""), expected: "42 cannot be converted to string"},
+	} {
+		_, err := json.NumberConvert(tst.n, tst.typ)
+		if test.Error(t, err, "%s → %s", tst.n, tst.typ) {
+			test.EqualStr(t, err.Error(), tst.expected)
+		}
+	}
+
+	test.IsTrue(t, json.IsNumberKind(reflect.Int))
+	test.IsTrue(t, json.IsNumberKind(reflect.Float32))
+	test.IsFalse(t, json.IsNumberKind(reflect.String))
+	test.IsFalse(t, json.IsNumberKind(reflect.Complex128))
+}
+
+func TestNumberValue(t *testing.T) {
+	for _, tst := range []struct {
+		n        ejson.Number
+		expected interface{}
+	}{
+		{n: "42", expected: 42.0},
+		{n: "-9007199254740992", expected: -9007199254740992.0},
+		{n: "9007199254740993", expected: int64(9007199254740993)},
+		{n: "18446744073709551615", expected: uint64(math.MaxUint64)},
+		{n: "18446744073709551616", expected: ejson.Number("18446744073709551616")},
+		{n: "0.25", expected: 0.25},
+		{n: "1e400", expected: ejson.Number("1e400")},
+		{n: "bad", expected: ejson.Number("bad")},
+	} {
+		got := json.NumberValue(tst.n)
+		test.IsTrue(t, got == tst.expected,
+			"%s: got %T(%v), expected %T(%v)", tst.n, got, got, tst.expected, tst.expected)
+	}
+}
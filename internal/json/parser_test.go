@@ -7,6 +7,7 @@
 package json_test
 
 import (
+	"bytes"
 	ejson "encoding/json"
 	"fmt"
 	"reflect"
@@ -19,6 +20,14 @@ import (
 	"github.com/maxatome/go-testdeep/internal/test"
 )
 
+// unmarshal unmarshals "js" as json.Parse does, so keeping numbers
+// as json.Number.
+func unmarshal(js []byte, v *interface{}) error {
+	dec := ejson.NewDecoder(bytes.NewReader(js))
+	dec.UseNumber()
+	return dec.Decode(v)
+}
+
 func TestJSON(t *testing.T) {
 	t.Run("Basics", func(t *testing.T) {
 		for i, js := range []string{
@@ -33,6 +42,9 @@ func TestJSON(t *testing.T) {
 			`  123.456E-4   `,
 			`  -123e-4   `,
 			`0`,
+			`9007199254740993`,
+			`0.100000000000000000000000001`,
+			`1e400`,
 			`""`,
 			`"123.456$"`,
 			` "foo bar \" \\ \/ \b \f \n\r \t \u20ac \u10e6 \u10E6 héhô" `,
@@ -62,7 +74,7 @@ func TestJSON(t *testing.T) {
 			js := []byte(js)
 
 			var expected interface{}
-			err := ejson.Unmarshal(js, &expected)
+			err := unmarshal(js, &expected)
 			if err != nil {
 				t.Fatalf("#%d, bad JSON: %s", i, err)
 			}
@@ -86,7 +98,7 @@ func TestJSON(t *testing.T) {
 		check := func(gotJSON, expectedJSON string) {
 			t.Helper()
 			var expected interface{}
-			err := ejson.Unmarshal([]byte(expectedJSON), &expected)
+			err := unmarshal([]byte(expectedJSON), &expected)
 			if err != nil {
 				t.Fatalf("bad JSON: %s", err)
 			}
@@ -389,12 +401,12 @@ syntax error: unexpected EOF at line 1:6 (pos 6)`,
 		} {
 			_, err := json.Parse([]byte(js),
 				json.ParseOpts{
-					OpShortcutFn: func(name string, pos json.Position) (interface{}, bool) {
+					OpShortcutFn: func(name string, pos json.Position) (interface{}, error) {
 						if name == "KnownOp" {
-							return "OK", true
+							return "OK", nil
 						}
 						anyOpPos = pos
-						return nil, false
+						return nil, fmt.Errorf(`bad operator shortcut "$^%s"`, name)
 					},
 				})
 			if test.Error(t, err, "json.Parse fails", js) {
@@ -406,5 +418,54 @@ syntax error: unexpected EOF at line 1:6 (pos 6)`,
 					js)
 			}
 		}
+
+		// User defined operators with parameters
+		got, err := json.Parse([]byte(`[ $^KnownOp(1, "x"), $^NoParams() ]`),
+			json.ParseOpts{
+				OpFn: func(op json.Operator, pos json.Position) (interface{}, error) {
+					return op, nil
+				},
+			})
+		if test.NoError(t, err, "json.Parse OK") {
+			test.IsTrue(t, reflect.DeepEqual(got, []interface{}{
+				json.Operator{Name: "^KnownOp", Params: []interface{}{ejson.Number("1"), "x"}},
+				json.Operator{Name: "^NoParams", Params: []interface{}{}},
+			}))
+		}
+
+		_, err = json.Parse([]byte(`  [ $^KnownOp(1),  $^AnyOp(2) ]`),
+			json.ParseOpts{
+				OpFn: func(op json.Operator, pos json.Position) (interface{}, error) {
+					if op.Name == "^KnownOp" {
+						return "OK", nil
+					}
+					anyOpPos = pos
+					return nil, fmt.Errorf("hmm weird operator %q", op.Name)
+				},
+			})
+		if test.Error(t, err, "json.Parse fails") {
+			test.EqualInt(t, anyOpPos.Pos, 19)
+			test.EqualInt(t, anyOpPos.Line, 1)
+			test.EqualInt(t, anyOpPos.Col, 19)
+			test.EqualStr(t, err.Error(),
+				`hmm weird operator "^AnyOp" at line 1:19 (pos 19)`)
+		}
+
+		// Start position
+		_, err = json.Parse([]byte("[1,\n $^AnyOp(2) ]"),
+			json.ParseOpts{
+				OpFn: func(op json.Operator, pos json.Position) (interface{}, error) {
+					anyOpPos = pos
+					return nil, fmt.Errorf("hmm weird operator %q", op.Name)
+				},
+				StartPos: json.NewPosition(100, 10, 4),
+			})
+		if test.Error(t, err, "json.Parse fails") {
+			test.EqualInt(t, anyOpPos.Pos, 105)
+			test.EqualInt(t, anyOpPos.Line, 11)
+			test.EqualInt(t, anyOpPos.Col, 1)
+			test.EqualStr(t, err.Error(),
+				`hmm weird operator "^AnyOp" at line 11:1 (pos 105)`)
+		}
 	})
 }
@@ -0,0 +1,991 @@
+// Copyright (c) 2021, Maxime Soulé
+// All rights reserved.
+//
+// This source code is licensed under the BSD-style license found in the
+// LICENSE file in the root directory of this source tree.
+
+// Package jsonschema implements a JSON Schema validator supporting
+// the core, applicator and validation vocabularies of draft
+// 2020-12. Only local references (starting with #) are supported,
+// and they are always resolved against the root document. Annotation
+// keywords (format, title, default, …) are ignored, unevaluatedItems
+// and unevaluatedProperties are not supported.
+//
+// Validated values are the ones produced by encoding/json when
+// unmarshaling in an interface{} with numbers kept as json.Number:
+// nil, bool, json.Number, string, []interface{} and
+// map[string]interface{}. Numbers are so compared exactly.
+package jsonschema
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/url"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/maxatome/go-testdeep/internal/util"
+)
+
+// Draft is the only supported $schema value.
+const Draft = "https://json-schema.org/draft/2020-12/schema"
+
+// maxDepth is the maximum number of nested $ref followed during a
+// validation, to detect infinite recursions.
+const maxDepth = 256
+
+// Violation describes a value not conforming to a schema.
+type Violation struct {
+	// Path is the location of the faulty value in the validated
+	// value: string items are object keys, int items are array
+	// indexes.
+	Path []interface{}
+	// Keyword is the location of the failing keyword in the schema,
+	// as in "#/properties/age/minimum".
+	Keyword string
+	// Value is the faulty value.
+	Value interface{}
+	// Message describes the violation.
+	Message string
+}
+
+// Schema is a compiled JSON Schema.
+type Schema struct {
+	root *schema
+}
+
+type patternSchema struct {
+	re     *regexp.Regexp
+	schema *schema
+}
+
+type schema struct {
+	loc     string // keyword location of this schema
+	boolean *bool  // non-nil for boolean schemas
+
+	ref *schema // $ref and $dynamicRef
+
+	types []string
+	enum  []interface{}
+	cnst  *interface{}
+
+	multipleOf       *number
+	maximum          *number
+	exclusiveMaximum *number
+	minimum          *number
+	exclusiveMinimum *number
+
+	maxLength *int
+	minLength *int
+	pattern   *regexp.Regexp
+
+	maxItems    *int
+	minItems    *int
+	uniqueItems bool
+	maxContains *int
+	minContains *int
+
+	maxProperties     *int
+	minProperties     *int
+	required          []string
+	dependentRequired map[string][]string
+
+	allOf []*schema
+	anyOf []*schema
+	oneOf []*schema
+	not   *schema
+	ifS   *schema
+	thenS *schema
+	elseS *schema
+
+	dependentSchemas map[string]*schema
+
+	prefixItems []*schema
+	items       *schema
+	contains    *schema
+
+	properties           map[string]*schema
+	patternProperties    []patternSchema
+	additionalProperties *schema
+	propertyNames        *schema
+}
+
+// Error is a schema compilation error.
+type Error struct {
+	// Keyword is the location of the bad keyword, as in
+	// "#/properties/age/minimum".
+	Keyword string
+	mesg    string
+}
+
+func (e *Error) Error() string {
+	return e.Keyword + ": " + e.mesg
+}
+
+type compileError struct {
+	error
+}
+
+type pendingRef struct {
+	schema *schema
+	ref    string
+	ptr    string // pointer of the $ref keyword
+}
+
+type compiler struct {
+	root    interface{}
+	schemas map[string]*schema // by JSON pointer, "" being the root
+	anchors map[string]*schema
+	refs    []pendingRef
+}
+
+var validTypes = map[string]bool{
+	"null":    true,
+	"boolean": true,
+	"object":  true,
+	"array":   true,
+	"number":  true,
+	"string":  true,
+	"integer": true,
+}
+
+// Compile compiles "doc", a JSON Schema unmarshaled by encoding/json
+// in an interface{}.
+func Compile(doc interface{}) (s *Schema, err error) {
+	c := compiler{
+		root:    doc,
+		schemas: map[string]*schema{},
+		anchors: map[string]*schema{},
+	}
+
+	defer func() {
+		if e := recover(); e != nil {
+			ce, ok := e.(compileError)
+			if !ok {
+				panic(e)
+			}
+			s, err = nil, ce.error
+		}
+	}()
+
+	if m, ok := doc.(map[string]interface{}); ok {
+		if draft, exists := m["$schema"]; exists {
+			if str, ok := draft.(string); !ok || strings.TrimSuffix(str, "#") != Draft {
+				c.fatal("/$schema", "only "+Draft+" is supported")
+			}
+		}
+	}
+
+	root := c.compile(doc, "")
+
+	// Resolving references can compile new sub-schemas, themselves
+	// containing references
+	for len(c.refs) > 0 {
+		ref := c.refs[0]
+		c.refs = c.refs[1:]
+		ref.schema.ref = c.resolve(ref)
+	}
+
+	return &Schema{root: root}, nil
+}
+
+func (c *compiler) fatal(ptr, mesg string, args ...interface{}) {
+	if len(args) > 0 {
+		mesg = fmt.Sprintf(mesg, args...)
+	}
+	panic(compileError{&Error{Keyword: "#" + ptr, mesg: mesg}})
+}
+
+func (c *compiler) resolve(ref pendingRef) *schema {
+	if !strings.HasPrefix(ref.ref, "#") {
+		c.fatal(ref.ptr, "only local references are supported, not %q", ref.ref)
+	}
+
+	fragment, err := url.PathUnescape(ref.ref[1:])
+	if err != nil {
+		c.fatal(ref.ptr, "bad reference %q: %s", ref.ref, err)
+	}
+
+	// Anchor
+	if fragment != "" && fragment[0] != '/' {
+		s := c.anchors[fragment]
+		if s == nil {
+			c.fatal(ref.ptr, "unknown anchor %q", fragment)
+		}
+		return s
+	}
+
+	if s := c.schemas[fragment]; s != nil {
+		return s
+	}
+
+	raw, err := util.JSONPointer(c.root, fragment)
+	if err != nil {
+		c.fatal(ref.ptr, "cannot resolve reference %q: %s", ref.ref, err)
+	}
+	return c.compile(raw, fragment)
+}
+
+func (c *compiler) compile(raw interface{}, ptr string) *schema {
+	if s := c.schemas[ptr]; s != nil {
+		return s
+	}
+
+	s := &schema{loc: "#" + ptr}
+	c.schemas[ptr] = s
+
+	switch raw := raw.(type) {
+	case bool:
+		s.boolean = &raw
+		return s
+	case map[string]interface{}:
+		keys := make([]string, 0, len(raw))
+		for kw := range raw {
+			keys = append(keys, kw)
+		}
+		sort.Strings(keys)
+		for _, kw := range keys {
+			c.keyword(s, kw, raw[kw], ptr+"/"+escapePointer(kw))
+		}
+	default:
+		c.fatal(ptr, "a schema must be an object or a boolean")
+	}
+
+	return s
+}
+
+func (c *compiler) keyword(s *schema, kw string, v interface{}, ptr string) {
+	switch kw {
+	case "$schema":
+		if ptr != "/$schema" {
+			c.fatal(ptr, "$schema is only allowed at root")
+		}
+
+	case "$anchor", "$dynamicAnchor":
+		name := c.string(ptr, v)
+		if c.anchors[name] != nil {
+			c.fatal(ptr, "anchor %q already defined", name)
+		}
+		c.anchors[name] = s
+
+	case "$ref", "$dynamicRef":
+		if s.ref != nil {
+			c.fatal(ptr, "$ref and $dynamicRef cannot be used together")
+		}
+		// temporary, replaced when resolved
+		s.ref = s
+		c.refs = append(c.refs, pendingRef{schema: s, ref: c.string(ptr, v), ptr: ptr})
+
+	case "$defs":
+		c.schemaMap(ptr, v)
+
+	case "type":
+		switch v := v.(type) {
+		case string:
+			s.types = []string{v}
+		case []interface{}:
+			s.types = c.strings(ptr, v, true)
+		default:
+			c.fatal(ptr, "must be a string or an array of strings")
+		}
+		for _, typ := range s.types {
+			if !validTypes[typ] {
+				c.fatal(ptr, "unknown type %q", typ)
+			}
+		}
+
+	case "enum":
+		arr, ok := v.([]interface{})
+		if !ok {
+			c.fatal(ptr, "must be an array")
+		}
+		s.enum = arr
+
+	case "const":
+		s.cnst = &v
+
+	case "multipleOf":
+		s.multipleOf = c.number(ptr, v)
+		if s.multipleOf.rat.Sign() <= 0 {
+			c.fatal(ptr, "must be strictly greater than 0")
+		}
+	case "maximum":
+		s.maximum = c.number(ptr, v)
+	case "exclusiveMaximum":
+		s.exclusiveMaximum = c.number(ptr, v)
+	case "minimum":
+		s.minimum = c.number(ptr, v)
+	case "exclusiveMinimum":
+		s.exclusiveMinimum = c.number(ptr, v)
+
+	case "maxLength":
+		s.maxLength = c.nonNegInt(ptr, v)
+	case "minLength":
+		s.minLength = c.nonNegInt(ptr, v)
+	case "pattern":
+		s.pattern = c.regexp(ptr, c.string(ptr, v))
+
+	case "maxItems":
+		s.maxItems = c.nonNegInt(ptr, v)
+	case "minItems":
+		s.minItems = c.nonNegInt(ptr, v)
+	case "uniqueItems":
+		b, ok := v.(bool)
+		if !ok {
+			c.fatal(ptr, "must be a boolean")
+		}
+		s.uniqueItems = b
+	case "maxContains":
+		s.maxContains = c.nonNegInt(ptr, v)
+	case "minContains":
+		s.minContains = c.nonNegInt(ptr, v)
+
+	case "maxProperties":
+		s.maxProperties = c.nonNegInt(ptr, v)
+	case "minProperties":
+		s.minProperties = c.nonNegInt(ptr, v)
+	case "required":
+		arr, ok := v.([]interface{})
+		if !ok {
+			c.fatal(ptr, "must be an array of strings")
+		}
+		s.required = c.strings(ptr, arr, false)
+	case "dependentRequired":
+		m, ok := v.(map[string]interface{})
+		if !ok {
+			c.fatal(ptr, "must be an object")
+		}
+		s.dependentRequired = make(map[string][]string, len(m))
+		for name, req := range m {
+			rptr := ptr + "/" + escapePointer(name)
+			arr, ok := req.([]interface{})
+			if !ok {
+				c.fatal(rptr, "must be an array of strings")
+			}
+			s.dependentRequired[name] = c.strings(rptr, arr, false)
+		}
+
+	case "allOf":
+		s.allOf = c.schemaArray(ptr, v)
+	case "anyOf":
+		s.anyOf = c.schemaArray(ptr, v)
+	case "oneOf":
+		s.oneOf = c.schemaArray(ptr, v)
+	case "not":
+		s.not = c.compile(v, ptr)
+	case "if":
+		s.ifS = c.compile(v, ptr)
+	case "then":
+		s.thenS = c.compile(v, ptr)
+	case "else":
+		s.elseS = c.compile(v, ptr)
+	case "dependentSchemas":
+		s.dependentSchemas = c.schemaMap(ptr, v)
+
+	case "prefixItems":
+		s.prefixItems = c.schemaArray(ptr, v)
+	case "items":
+		s.items = c.compile(v, ptr)
+	case "contains":
+		s.contains = c.compile(v, ptr)
+
+	case "properties":
+		s.properties = c.schemaMap(ptr, v)
+	case "patternProperties":
+		m := c.schemaMap(ptr, v)
+		names := make([]string, 0, len(m))
+		for name := range m {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			s.patternProperties = append(s.patternProperties, patternSchema{
+				re:     c.regexp(ptr+"/"+escapePointer(name), name),
+				schema: m[name],
+			})
+		}
+	case "additionalProperties":
+		s.additionalProperties = c.compile(v, ptr)
+	case "propertyNames":
+		s.propertyNames = c.compile(v, ptr)
+
+	case "unevaluatedItems", "unevaluatedProperties":
+		c.fatal(ptr, "%s keyword is not supported", kw)
+
+	default:
+		// Annotations and unknown keywords are ignored
+	}
+}
+
+func (c *compiler) string(ptr string, v interface{}) string {
+	s, ok := v.(string)
+	if !ok {
+		c.fatal(ptr, "must be a string")
+	}
+	return s
+}
+
+func (c *compiler) strings(ptr string, arr []interface{}, nonEmpty bool) []string {
+	if nonEmpty && len(arr) == 0 {
+		c.fatal(ptr, "must be a non-empty array")
+	}
+	strs := make([]string, len(arr))
+	seen := make(map[string]bool, len(arr))
+	for i, item := range arr {
+		s, ok := item.(string)
+		if !ok {
+			c.fatal(ptr+"/"+strconv.Itoa(i), "must be a string")
+		}
+		if seen[s] {
+			c.fatal(ptr+"/"+strconv.Itoa(i), "duplicate %q string", s)
+		}
+		seen[s] = true
+		strs[i] = s
+	}
+	return strs
+}
+
+func (c *compiler) number(ptr string, v interface{}) *number {
+	n, ok := newNumber(v)
+	if !ok {
+		c.fatal(ptr, "must be a number")
+	}
+	return n
+}
+
+func (c *compiler) nonNegInt(ptr string, v interface{}) *int {
+	n, ok := newNumber(v)
+	if !ok || n.rat.Sign() < 0 || !n.rat.IsInt() || !n.rat.Num().IsInt64() {
+		c.fatal(ptr, "must be a non-negative integer")
+	}
+	i := int(n.rat.Num().Int64())
+	return &i
+}
+
+func (c *compiler) regexp(ptr, re string) *regexp.Regexp {
+	r, err := regexp.Compile(re)
+	if err != nil {
+		c.fatal(ptr, "invalid regexp %q: %s", re, err)
+	}
+	return r
+}
+
+func (c *compiler) schemaArray(ptr string, v interface{}) []*schema {
+	arr, ok := v.([]interface{})
+	if !ok || len(arr) == 0 {
+		c.fatal(ptr, "must be a non-empty array of schemas")
+	}
+	schemas := make([]*schema, len(arr))
+	for i, item := range arr {
+		schemas[i] = c.compile(item, ptr+"/"+strconv.Itoa(i))
+	}
+	return schemas
+}
+
+func (c *compiler) schemaMap(ptr string, v interface{}) map[string]*schema {
+	m, ok := v.(map[string]interface{})
+	if !ok {
+		c.fatal(ptr, "must be an object of schemas")
+	}
+	schemas := make(map[string]*schema, len(m))
+	for name, item := range m {
+		schemas[name] = c.compile(item, ptr+"/"+escapePointer(name))
+	}
+	return schemas
+}
+
+var pointerEsc = strings.NewReplacer("~", "~0", "/", "~1")
+
+func escapePointer(s string) string {
+	return pointerEsc.Replace(s)
+}
+
+//
+// Validation
+//
+
+type state struct {
+	report  func(Violation) bool
+	stopped bool
+	depth   int
+}
+
+// fail records the violation "vi" and returns true if the
+// validation has to stop.
+func (st *state) fail(vi Violation) bool {
+	if st.report == nil || !st.report(vi) {
+		st.stopped = true
+	}
+	return st.stopped
+}
+
+// Validate validates "v" against s. "v" has to be unmarshaled by
+// encoding/json in an interface{}. Each violation is passed to
+// "report". If "report" returns false, the validation stops. If
+// "report" is nil, the validation stops at the first violation. It
+// returns true if "v" conforms to s.
+func (s *Schema) Validate(v interface{}, report func(Violation) bool) bool {
+	return s.root.validate(v, nil, &state{report: report})
+}
+
+// matches returns true if "v" conforms to s, without reporting any
+// violation.
+func (s *schema) matches(v interface{}, st *state) bool {
+	return s.validate(v, nil, &state{depth: st.depth})
+}
+
+func appendPath(path []interface{}, item interface{}) []interface{} {
+	return append(path[:len(path):len(path)], item)
+}
+
+func typeOf(v interface{}) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case json.Number:
+		return "number"
+	case string:
+		return "string"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	}
+	return fmt.Sprintf("%T", v)
+}
+
+func hasType(v interface{}, typ string) bool {
+	vtyp := typeOf(v)
+	if typ == "integer" {
+		n, ok := newNumber(v)
+		return ok && n.rat.IsInt()
+	}
+	return vtyp == typ
+}
+
+// number is a JSON number, kept as written to be displayed and as a
+// big.Rat to be compared exactly.
+type number struct {
+	lit json.Number
+	rat *big.Rat
+}
+
+// newNumber returns the number "v", if "v" is a valid json.Number.
+func newNumber(v interface{}) (*number, bool) {
+	lit, ok := v.(json.Number)
+	if !ok {
+		return nil, false
+	}
+	r, ok := new(big.Rat).SetString(string(lit))
+	if !ok {
+		return nil, false
+	}
+	return &number{lit: lit, rat: r}, true
+}
+
+func (n *number) String() string {
+	return string(n.lit)
+}
+
+func plural(n int, one, many string) string {
+	if n == 1 {
+		return "1 " + one
+	}
+	return strconv.Itoa(n) + " " + many
+}
+
+func (s *schema) validate(v interface{}, path []interface{}, st *state) bool {
+	valid := true
+	fail := func(kw, mesg string, args ...interface{}) bool {
+		valid = false
+		return st.fail(Violation{
+			Path:    path,
+			Keyword: s.loc + "/" + kw,
+			Value:   v,
+			Message: fmt.Sprintf(mesg, args...),
+		})
+	}
+	// sub validates "v" against "sub" at "path"
+	sub := func(sub *schema, v interface{}, path []interface{}) bool {
+		if !sub.validate(v, path, st) {
+			valid = false
+		}
+		return st.stopped
+	}
+
+	if s.boolean != nil {
+		if !*s.boolean {
+			valid = false
+			st.fail(Violation{
+				Path:    path,
+				Keyword: s.loc,
+				Value:   v,
+				Message: "not allowed by false schema",
+			})
+		}
+		return valid
+	}
+
+	if s.ref != nil {
+		if st.depth >= maxDepth {
+			fail("$ref", "too many nested references, infinite recursion?")
+			return false
+		}
+		st.depth++
+		stop := sub(s.ref, v, path)
+		st.depth--
+		if stop {
+			return false
+		}
+	}
+
+	//
+	// Any instance type
+	if s.types != nil {
+		ok := false
+		for _, typ := range s.types {
+			if hasType(v, typ) {
+				ok = true
+				break
+			}
+		}
+		if !ok {
+			exp := s.types[0]
+			if len(s.types) > 1 {
+				exp = "one of " + strings.Join(s.types, ", ")
+			}
+			if fail("type", "must be %s, not %s", exp, typeOf(v)) {
+				return false
+			}
+		}
+	}
+
+	if s.enum != nil {
+		ok := false
+		for _, e := range s.enum {
+			if equal(v, e) {
+				ok = true
+				break
+			}
+		}
+		if !ok && fail("enum", "must be one of the enum values") {
+			return false
+		}
+	}
+
+	if s.cnst != nil && !equal(v, *s.cnst) {
+		if fail("const", "must be equal to the const value") {
+			return false
+		}
+	}
+
+	//
+	// Type specific keywords
+	switch v := v.(type) {
+	case json.Number:
+		n, ok := newNumber(v)
+		if !ok {
+			break
+		}
+		if s.multipleOf != nil {
+			if !new(big.Rat).Quo(n.rat, s.multipleOf.rat).IsInt() {
+				if fail("multipleOf", "must be a multiple of %s", s.multipleOf) {
+					return false
+				}
+			}
+		}
+		if s.maximum != nil && n.rat.Cmp(s.maximum.rat) > 0 {
+			if fail("maximum", "must be ≤ %s", s.maximum) {
+				return false
+			}
+		}
+		if s.exclusiveMaximum != nil && n.rat.Cmp(s.exclusiveMaximum.rat) >= 0 {
+			if fail("exclusiveMaximum", "must be < %s", s.exclusiveMaximum) {
+				return false
+			}
+		}
+		if s.minimum != nil && n.rat.Cmp(s.minimum.rat) < 0 {
+			if fail("minimum", "must be ≥ %s", s.minimum) {
+				return false
+			}
+		}
+		if s.exclusiveMinimum != nil && n.rat.Cmp(s.exclusiveMinimum.rat) <= 0 {
+			if fail("exclusiveMinimum", "must be > %s", s.exclusiveMinimum) {
+				return false
+			}
+		}
+
+	case string:
+		if s.maxLength != nil || s.minLength != nil {
+			l := utf8.RuneCountInString(v)
+			if s.maxLength != nil && l > *s.maxLength {
+				if fail("maxLength", "must be at most %s long, not %d",
+					plural(*s.maxLength, "character", "characters"), l) {
+					return false
+				}
+			}
+			if s.minLength != nil && l < *s.minLength {
+				if fail("minLength", "must be at least %s long, not %d",
+					plural(*s.minLength, "character", "characters"), l) {
+					return false
+				}
+			}
+		}
+		if s.pattern != nil && !s.pattern.MatchString(v) {
+			if fail("pattern", "must match %q", s.pattern) {
+				return false
+			}
+		}
+
+	case []interface{}:
+		if s.maxItems != nil && len(v) > *s.maxItems {
+			if fail("maxItems", "must have at most %s, not %d",
+				plural(*s.maxItems, "item", "items"), len(v)) {
+				return false
+			}
+		}
+		if s.minItems != nil && len(v) < *s.minItems {
+			if fail("minItems", "must have at least %s, not %d",
+				plural(*s.minItems, "item", "items"), len(v)) {
+				return false
+			}
+		}
+		if s.uniqueItems {
+		unique:
+			for i := 1; i < len(v); i++ {
+				for j := 0; j < i; j++ {
+					if equal(v[i], v[j]) {
+						if fail("uniqueItems", "items #%d and #%d are equal", j, i) {
+							return false
+						}
+						break unique
+					}
+				}
+			}
+		}
+
+		for i, item := range v {
+			var itemSchema *schema
+			if i < len(s.prefixItems) {
+				itemSchema = s.prefixItems[i]
+			} else {
+				itemSchema = s.items
+			}
+			if itemSchema != nil && sub(itemSchema, item, appendPath(path, i)) {
+				return false
+			}
+		}
+
+		if s.contains != nil {
+			num := 0
+			for _, item := range v {
+				if s.contains.matches(item, st) {
+					num++
+				}
+			}
+			minContains := 1
+			if s.minContains != nil {
+				minContains = *s.minContains
+			}
+			if num < minContains {
+				if fail("contains", "must contain at least %s matching %s, not %d",
+					plural(minContains, "item", "items"), s.contains.loc, num) {
+					return false
+				}
+			}
+			if s.maxContains != nil && num > *s.maxContains {
+				if fail("maxContains", "must contain at most %s matching %s, not %d",
+					plural(*s.maxContains, "item", "items"), s.contains.loc, num) {
+					return false
+				}
+			}
+		}
+
+	case map[string]interface{}:
+		if s.maxProperties != nil && len(v) > *s.maxProperties {
+			if fail("maxProperties", "must have at most %s, not %d",
+				plural(*s.maxProperties, "property", "properties"), len(v)) {
+				return false
+			}
+		}
+		if s.minProperties != nil && len(v) < *s.minProperties {
+			if fail("minProperties", "must have at least %s, not %d",
+				plural(*s.minProperties, "property", "properties"), len(v)) {
+				return false
+			}
+		}
+		for _, name := range s.required {
+			if _, ok := v[name]; !ok {
+				if fail("required", "missing property %q", name) {
+					return false
+				}
+			}
+		}
+
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			for _, name := range s.dependentRequired[key] {
+				if _, ok := v[name]; !ok {
+					if fail("dependentRequired",
+						"missing property %q, required when %q is present", name, key) {
+						return false
+					}
+				}
+			}
+		}
+
+		for _, key := range keys {
+			if depSchema := s.dependentSchemas[key]; depSchema != nil {
+				if sub(depSchema, v, path) {
+					return false
+				}
+			}
+		}
+
+		if s.propertyNames != nil {
+			for _, key := range keys {
+				if sub(s.propertyNames, key, path) {
+					return false
+				}
+			}
+		}
+
+		for _, key := range keys {
+			keyPath := appendPath(path, key)
+			additional := true
+
+			if propSchema := s.properties[key]; propSchema != nil {
+				additional = false
+				if sub(propSchema, v[key], keyPath) {
+					return false
+				}
+			}
+			for _, ps := range s.patternProperties {
+				if ps.re.MatchString(key) {
+					additional = false
+					if sub(ps.schema, v[key], keyPath) {
+						return false
+					}
+				}
+			}
+			if additional && s.additionalProperties != nil {
+				if sub(s.additionalProperties, v[key], keyPath) {
+					return false
+				}
+			}
+		}
+	}
+
+	//
+	// Applicators on the whole instance
+	for _, allSchema := range s.allOf {
+		if sub(allSchema, v, path) {
+			return false
+		}
+	}
+
+	if s.anyOf != nil {
+		ok := false
+		for _, anySchema := range s.anyOf {
+			if anySchema.matches(v, st) {
+				ok = true
+				break
+			}
+		}
+		if !ok && fail("anyOf", "must match at least one schema") {
+			return false
+		}
+	}
+
+	if s.oneOf != nil {
+		var matching []string
+		for i, oneSchema := range s.oneOf {
+			if oneSchema.matches(v, st) {
+				matching = append(matching, "#"+strconv.Itoa(i))
+			}
+		}
+		switch len(matching) {
+		case 1:
+		case 0:
+			if fail("oneOf", "must match exactly one schema, none matched") {
+				return false
+			}
+		default:
+			if fail("oneOf", "must match exactly one schema, %s matched",
+				strings.Join(matching, ", ")) {
+				return false
+			}
+		}
+	}
+
+	if s.not != nil && s.not.matches(v, st) {
+		if fail("not", "must not match schema") {
+			return false
+		}
+	}
+
+	if s.ifS != nil {
+		if s.ifS.matches(v, st) {
+			if s.thenS != nil && sub(s.thenS, v, path) {
+				return false
+			}
+		} else if s.elseS != nil && sub(s.elseS, v, path) {
+			return false
+		}
+	}
+
+	return valid
+}
+
+// equal returns true if "a" and "b" are equal JSON values. Numbers
+// are equal if they have the same value, whatever their
+// representations are, so 1 and 1.0 are equal.
+func equal(a, b interface{}) bool {
+	switch a := a.(type) {
+	case json.Number:
+		na, okA := newNumber(a)
+		nb, okB := newNumber(b)
+		if !okA || !okB {
+			return reflect.DeepEqual(a, b)
+		}
+		return na.rat.Cmp(nb.rat) == 0
+
+	case []interface{}:
+		b, ok := b.([]interface{})
+		if !ok || len(a) != len(b) {
+			return false
+		}
+		for i := range a {
+			if !equal(a[i], b[i]) {
+				return false
+			}
+		}
+		return true
+
+	case map[string]interface{}:
+		b, ok := b.(map[string]interface{})
+		if !ok || len(a) != len(b) {
+			return false
+		}
+		for k, av := range a {
+			bv, ok := b[k]
+			if !ok || !equal(av, bv) {
+				return false
+			}
+		}
+		return true
+	}
+	return reflect.DeepEqual(a, b)
+}
@@ -0,0 +1,248 @@
+// Copyright (c) 2021, Maxime Soulé
+// All rights reserved.
+//
+// This source code is licensed under the BSD-style license found in the
+// LICENSE file in the root directory of this source tree.
+
+package jsonschema_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/maxatome/go-testdeep/internal/jsonschema"
+	"github.com/maxatome/go-testdeep/internal/test"
+)
+
+func unmarshal(t *testing.T, s string) interface{} {
+	t.Helper()
+	var v interface{}
+	dec := json.NewDecoder(strings.NewReader(s))
+	dec.UseNumber()
+	if err := dec.Decode(&v); err != nil {
+		t.Fatalf("cannot unmarshal %s: %s", s, err)
+	}
+	return v
+}
+
+func compile(t *testing.T, schema string) *jsonschema.Schema {
+	t.Helper()
+	s, err := jsonschema.Compile(unmarshal(t, schema))
+	if err != nil {
+		t.Fatalf("cannot compile %s: %s", schema, err)
+	}
+	return s
+}
+
+// violations returns all violations of "instance" against "s", one
+// per line as "PATH KEYWORD: MESSAGE".
+func violations(t *testing.T, s *jsonschema.Schema, instance string) string {
+	t.Helper()
+	var lines []string
+	s.Validate(unmarshal(t, instance), func(vi jsonschema.Violation) bool {
+		var path bytes.Buffer
+		for _, p := range vi.Path {
+			fmt.Fprintf(&path, "/%v", p)
+		}
+		lines = append(lines, fmt.Sprintf("%s %s: %s", path.String(), vi.Keyword, vi.Message))
+		return true
+	})
+	return strings.Join(lines, "\n")
+}
+
+func TestValidate(t *testing.T) {
+	for i, tst := range []struct {
+		schema   string
+		instance string
+		expected string
+	}{
+		// Boolean schemas
+		{schema: `true`, instance: `42`},
+		{schema: `false`, instance: `42`, expected: ` #: not allowed by false schema`},
+		// type
+		{schema: `{"type": "integer"}`, instance: `42`},
+		{schema: `{"type": "integer"}`, instance: `42.5`, expected: ` #/type: must be integer, not number`},
+		{schema: `{"type": ["string", "null"]}`, instance: `null`},
+		{schema: `{"type": ["string", "null"]}`, instance: `true`,
+			expected: ` #/type: must be one of string, null, not boolean`},
+		// enum & const
+		{schema: `{"enum": [1, "a", {"b": [null]}]}`, instance: `{"b": [null]}`},
+		{schema: `{"enum": [1, "a"]}`, instance: `2`, expected: ` #/enum: must be one of the enum values`},
+		{schema: `{"const": [1, 2]}`, instance: `[1, 2]`},
+		{schema: `{"const": [1, 2]}`, instance: `[2, 1]`, expected: ` #/const: must be equal to the const value`},
+		{schema: `{"const": {"a": [1.0]}}`, instance: `{"a": [1]}`},
+		// numbers
+		{schema: `{"multipleOf": 0.5}`, instance: `4.5`},
+		{schema: `{"multipleOf": 2}`, instance: `7`, expected: ` #/multipleOf: must be a multiple of 2`},
+		{schema: `{"minimum": 1, "maximum": 3}`, instance: `3`},
+		{
+			schema:   `{"exclusiveMinimum": 1, "exclusiveMaximum": 1}`,
+			instance: `1`,
+			expected: " #/exclusiveMaximum: must be < 1\n #/exclusiveMinimum: must be > 1",
+		},
+		{schema: `{"minimum": 1}`, instance: `"not a number"`},
+		{schema: `{"multipleOf": 0.1}`, instance: `0.3`},
+		{
+			schema:   `{"maximum": 9007199254740992}`,
+			instance: `9007199254740993`,
+			expected: ` #/maximum: must be ≤ 9007199254740992`,
+		},
+		{schema: `{"type": "integer"}`, instance: `1e2`},
+		// strings
+		{schema: `{"minLength": 2, "maxLength": 3}`, instance: `"éé"`},
+		{schema: `{"maxLength": 1}`, instance: `"éé"`,
+			expected: ` #/maxLength: must be at most 1 character long, not 2`},
+		{schema: `{"minLength": 3}`, instance: `"éé"`,
+			expected: ` #/minLength: must be at least 3 characters long, not 2`},
+		{schema: `{"pattern": "^a+$"}`, instance: `"ab"`, expected: ` #/pattern: must match "^a+$"`},
+		// arrays
+		{schema: `{"minItems": 3}`, instance: `[1]`, expected: ` #/minItems: must have at least 3 items, not 1`},
+		{schema: `{"maxItems": 1}`, instance: `[1, 2]`, expected: ` #/maxItems: must have at most 1 item, not 2`},
+		{schema: `{"uniqueItems": true}`, instance: `[1, {"a": 1}, 2, {"a": 1}]`,
+			expected: ` #/uniqueItems: items #1 and #3 are equal`},
+		{
+			schema:   `{"prefixItems": [{"type": "string"}], "items": {"type": "integer"}}`,
+			instance: `[1, 2, "3"]`,
+			expected: "/0 #/prefixItems/0/type: must be string, not number\n/2 #/items/type: must be integer, not string",
+		},
+		{schema: `{"prefixItems": [true], "items": false}`, instance: `[1, 2]`,
+			expected: `/1 #/items: not allowed by false schema`},
+		{schema: `{"contains": {"type": "string"}}`, instance: `[1, "a"]`},
+		{schema: `{"contains": {"type": "string"}}`, instance: `[1, 2]`,
+			expected: ` #/contains: must contain at least 1 item matching #/contains, not 0`},
+		{schema: `{"contains": {"type": "string"}, "minContains": 0}`, instance: `[1]`},
+		{schema: `{"contains": {"type": "string"}, "maxContains": 1}`, instance: `["a", "b"]`,
+			expected: ` #/maxContains: must contain at most 1 item matching #/contains, not 2`},
+		// objects
+		{
+			schema:   `{"required": ["a", "b"], "minProperties": 2, "maxProperties": 0}`,
+			instance: `{"a": 1}`,
+			expected: " #/maxProperties: must have at most 0 properties, not 1\n" +
+				" #/minProperties: must have at least 2 properties, not 1\n" +
+				` #/required: missing property "b"`,
+		},
+		{schema: `{"dependentRequired": {"a": ["b"]}}`, instance: `{"a": 1}`,
+			expected: ` #/dependentRequired: missing property "b", required when "a" is present`},
+		{schema: `{"dependentSchemas": {"a": {"required": ["b"]}}}`, instance: `{"a": 1}`,
+			expected: ` #/dependentSchemas/a/required: missing property "b"`},
+		{schema: `{"propertyNames": {"maxLength": 2}}`, instance: `{"abc": 1}`,
+			expected: ` #/propertyNames/maxLength: must be at most 2 characters long, not 3`},
+		{
+			schema: `{
+  "properties": {"a": {"type": "string"}, "a/b": {"type": "string"}},
+  "patternProperties": {"^x": {"type": "integer"}},
+  "additionalProperties": false
+}`,
+			instance: `{"a": 1, "a/b": "ok", "x1": 1.5, "y": 1}`,
+			expected: "/a #/properties/a/type: must be string, not number\n" +
+				"/x1 #/patternProperties/^x/type: must be integer, not number\n" +
+				"/y #/additionalProperties: not allowed by false schema",
+		},
+		// applicators
+		{
+			schema:   `{"allOf": [{"type": "integer"}, {"minimum": 10}]}`,
+			instance: `1.5`,
+			expected: " #/allOf/0/type: must be integer, not number\n #/allOf/1/minimum: must be ≥ 10",
+		},
+		{schema: `{"anyOf": [{"type": "integer"}, {"type": "string"}]}`, instance: `"a"`},
+		{schema: `{"anyOf": [{"type": "integer"}, {"type": "string"}]}`, instance: `null`,
+			expected: ` #/anyOf: must match at least one schema`},
+		{schema: `{"oneOf": [{"type": "integer"}, {"minimum": 2}]}`, instance: `1`},
+		{schema: `{"oneOf": [{"type": "integer"}, {"minimum": 2}]}`, instance: `3`,
+			expected: ` #/oneOf: must match exactly one schema, #0, #1 matched`},
+		{schema: `{"oneOf": [{"type": "integer"}, {"minimum": 2}]}`, instance: `1.5`,
+			expected: ` #/oneOf: must match exactly one schema, none matched`},
+		{schema: `{"not": {"type": "null"}}`, instance: `null`, expected: ` #/not: must not match schema`},
+		{
+			schema:   `{"if": {"type": "integer"}, "then": {"minimum": 10}, "else": {"type": "string"}}`,
+			instance: `[1, 2]`,
+			expected: ` #/else/type: must be string, not array`,
+		},
+		{schema: `{"if": {"type": "integer"}, "then": {"minimum": 10}}`, instance: `2`,
+			expected: ` #/then/minimum: must be ≥ 10`},
+		// references
+		{
+			schema: `{
+  "$schema": "https://json-schema.org/draft/2020-12/schema",
+  "$defs": {
+    "node": {
+      "$anchor": "node",
+      "type": "object",
+      "properties": {
+        "value": {"$ref": "#/$defs/positive"},
+        "children": {"type": "array", "items": {"$ref": "#node"}}
+      }
+    },
+    "positive": {"type": "number", "exclusiveMinimum": 0}
+  },
+  "$ref": "#/$defs/node"
+}`,
+			instance: `{"value": 1, "children": [{"value": 2}, {"children": [{"value": 0}]}]}`,
+			expected: `/children/1/children/0/value #/$defs/positive/exclusiveMinimum: must be > 0`,
+		},
+		{
+			schema:   `{"properties": {"a": {"type": "string"}, "b": {"$ref": "#/properties/a"}}}`,
+			instance: `{"b": 1}`,
+			expected: `/b #/properties/a/type: must be string, not number`,
+		},
+		{schema: `{"$defs": {"a b": {"type": "null"}}, "$ref": "#/$defs/a%20b"}`, instance: `null`},
+		{schema: `{"$dynamicAnchor": "meta", "items": {"$dynamicRef": "#meta"}, "type": "array"}`,
+			instance: `[[], [1]]`,
+			expected: `/1/0 #/type: must be array, not number`},
+		{schema: `{"$ref": "#"}`, instance: `1`,
+			expected: ` #/$ref: too many nested references, infinite recursion?`},
+	} {
+		s := compile(t, tst.schema)
+		test.EqualStr(t, violations(t, s, tst.instance), tst.expected,
+			"#%d: %s with %s", i, tst.schema, tst.instance)
+	}
+}
+
+func TestValidateStop(t *testing.T) {
+	s := compile(t, `{"items": {"type": "string"}}`)
+
+	var num int
+	ok := s.Validate(unmarshal(t, `[1, 2, 3]`), func(vi jsonschema.Violation) bool {
+		num++
+		return num < 2
+	})
+	test.IsFalse(t, ok)
+	test.EqualInt(t, num, 2)
+
+	test.IsFalse(t, s.Validate(unmarshal(t, `[1, 2, 3]`), nil))
+	test.IsTrue(t, s.Validate(unmarshal(t, `["a"]`), nil))
+}
+
+func TestCompileErrors(t *testing.T) {
+	for i, tst := range []struct{ schema, err string }{
+		{schema: `42`, err: "#: a schema must be an object or a boolean"},
+		{schema: `{"$schema": "http://json-schema.org/draft-07/schema#"}`,
+			err: "#/$schema: only https://json-schema.org/draft/2020-12/schema is supported"},
+		{schema: `{"items": {"$schema": "https://json-schema.org/draft/2020-12/schema"}}`,
+			err: "#/items/$schema: $schema is only allowed at root"},
+		{schema: `{"type": "int"}`, err: `#/type: unknown type "int"`},
+		{schema: `{"type": []}`, err: `#/type: must be a non-empty array`},
+		{schema: `{"type": ["null", "null"]}`, err: `#/type/1: duplicate "null" string`},
+		{schema: `{"minimum": "1"}`, err: `#/minimum: must be a number`},
+		{schema: `{"multipleOf": 0}`, err: `#/multipleOf: must be strictly greater than 0`},
+		{schema: `{"minLength": 1.5}`, err: `#/minLength: must be a non-negative integer`},
+		{schema: `{"pattern": "("}`, err: "#/pattern: invalid regexp \"(\": error parsing regexp: missing closing ): `(`"},
+		{schema: `{"required": [1]}`, err: `#/required/0: must be a string`},
+		{schema: `{"dependentRequired": {"a": "b"}}`, err: `#/dependentRequired/a: must be an array of strings`},
+		{schema: `{"allOf": []}`, err: `#/allOf: must be a non-empty array of schemas`},
+		{schema: `{"properties": {"a/b": 1}}`, err: `#/properties/a~1b: a schema must be an object or a boolean`},
+		{schema: `{"unevaluatedProperties": false}`, err: `#/unevaluatedProperties: unevaluatedProperties keyword is not supported`},
+		{schema: `{"$ref": "other.json#/a"}`, err: `#/$ref: only local references are supported, not "other.json#/a"`},
+		{schema: `{"$ref": "#/$defs/none"}`, err: `#/$ref: cannot resolve reference "#/$defs/none": key not found @/$defs`},
+		{schema: `{"$ref": "#none"}`, err: `#/$ref: unknown anchor "none"`},
+		{schema: `{"$anchor": "a", "items": {"$anchor": "a"}}`, err: `#/items/$anchor: anchor "a" already defined`},
+	} {
+		_, err := jsonschema.Compile(unmarshal(t, tst.schema))
+		if test.Error(t, err, "#%d: %s", i, tst.schema) {
+			test.EqualStr(t, err.Error(), tst.err, "#%d: %s", i, tst.schema)
+		}
+	}
+}
@@ -81,7 +81,19 @@ func (t *TestingTB) Cleanup(fn func()) {
 		}
 		fn()
 	}
-	runtime.SetFinalizer(t, func(t *TestingTB) { t.cleanup() })
+	if old == nil {
+		runtime.SetFinalizer(t, func(t *TestingTB) { t.RunCleanups() })
+	}
+}
+
+// RunCleanups runs, then forgets, the functions registered by
+// Cleanup, as testing.T does at the end of a test.
+func (t *TestingTB) RunCleanups() {
+	if t.cleanup != nil {
+		cleanup := t.cleanup
+		t.cleanup = nil
+		cleanup()
+	}
 }
 
 // Fatal mocks testing.T Error method.
@@ -20,8 +20,10 @@ var (
 	FmtStringer     = reflect.TypeOf((*fmt.Stringer)(nil)).Elem()
 	Error           = reflect.TypeOf((*error)(nil)).Elem()
 	JsonUnmarshaler = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem() //nolint: golint
+	JsonNumber      = reflect.TypeOf(json.Number(""))                 //nolint: golint
 	Time            = reflect.TypeOf(time.Time{})
 	Int             = reflect.TypeOf(int(0))
+	Float64         = reflect.TypeOf(float64(0))
 	Uint8           = reflect.TypeOf(uint8(0))
 	Rune            = reflect.TypeOf(rune(0))
 	String          = reflect.TypeOf("")
@@ -0,0 +1,268 @@
+// Copyright (c) 2021, Maxime Soulé
+// All rights reserved.
+//
+// This source code is licensed under the BSD-style license found in the
+// LICENSE file in the root directory of this source tree.
+
+package util
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+
+	"github.com/maxatome/go-testdeep/helpers/tdutil"
+	"github.com/maxatome/go-testdeep/internal/dark"
+	"github.com/maxatome/go-testdeep/internal/hooks"
+	"github.com/maxatome/go-testdeep/internal/types"
+)
+
+// FormatOpts allows to customize how ToStringOpts renders values.
+type FormatOpts struct {
+	// Formatters contains per type formatters, checked before global
+	// ones. Can be nil.
+	Formatters *hooks.Info
+	// PreferStringer allows to use Error() or String() method, if
+	// any, instead of go-spew to render a value.
+	PreferStringer bool
+}
+
+// GlobalFormatOpts returns the global formatting options, always
+// taken into account by ToString and ToStringOpts. It is typically
+// overridden by td package.
+var GlobalFormatOpts = func() FormatOpts { return FormatOpts{} }
+
+// ToStringOpts works as ToString but uses "opts" and
+// GlobalFormatOpts() to render "val" and its contents.
+func ToStringOpts(val interface{}, opts FormatOpts) string {
+	global := GlobalFormatOpts()
+	f := formatter{
+		local:          opts.Formatters,
+		global:         global.Formatters,
+		preferStringer: opts.PreferStringer || global.PreferStringer,
+	}
+	return f.toString(val)
+}
+
+type formatter struct {
+	local, global  *hooks.Info
+	preferStringer bool
+	types          map[reflect.Type]bool
+	visited        map[uintptr]bool
+}
+
+func (f *formatter) toString(val interface{}) string {
+	if val == nil {
+		return "nil"
+	}
+
+	switch tval := val.(type) {
+	case reflect.Value:
+		newVal, ok := dark.GetInterface(tval, true)
+		if ok {
+			return f.toString(newVal)
+		}
+
+	case []reflect.Value:
+		var buf bytes.Buffer
+		sliceToBuffer(&buf, tval, f.toString)
+		return buf.String()
+
+	case types.TestDeepStringer:
+		return tval.String()
+	}
+
+	if f.preferStringer || f.local.HasFormatters() || f.global.HasFormatters() {
+		if v := reflect.ValueOf(val); f.concerned(v.Type()) {
+			return f.render(v)
+		}
+	}
+
+	switch tval := val.(type) {
+	// no "(string) " prefix for printable strings
+	case string:
+		return tdutil.FormatString(tval)
+
+		// no "(int) " prefix for ints
+	case int:
+		return strconv.Itoa(tval)
+
+		// no "(bool)" prefix for booleans
+	case bool:
+		return TernStr(tval, "true", "false")
+
+		// JSON numbers are displayed as is
+	case json.Number:
+		return string(tval)
+	}
+
+	return tdutil.SpewString(val)
+}
+
+// isLeaf returns true if values of type "t" are directly rendered
+// by a formatter or by their String() or Error() method.
+func (f *formatter) isLeaf(t reflect.Type) bool {
+	return f.local.HasFormat(t) || f.global.HasFormat(t) ||
+		(f.preferStringer &&
+			(t.Implements(types.Error) || t.Implements(types.FmtStringer)))
+}
+
+// concerned returns true if "t" or any type it contains is a leaf
+// type. Interfaces are always concerned as their contents is only
+// known at runtime.
+func (f *formatter) concerned(t reflect.Type) bool {
+	if c, ok := f.types[t]; ok {
+		return c
+	}
+	if f.types == nil {
+		f.types = map[reflect.Type]bool{}
+	}
+	f.types[t] = false // protect against recursive types
+
+	c := f.isLeaf(t)
+	if !c {
+		switch t.Kind() {
+		case reflect.Interface:
+			c = true
+		case reflect.Ptr, reflect.Slice, reflect.Array:
+			c = f.concerned(t.Elem())
+		case reflect.Map:
+			c = f.concerned(t.Key()) || f.concerned(t.Elem())
+		case reflect.Struct:
+			for i := t.NumField() - 1; i >= 0 && !c; i-- {
+				c = f.concerned(t.Field(i).Type)
+			}
+		}
+	}
+
+	f.types[t] = c
+	return c
+}
+
+func (f *formatter) leaf(v reflect.Value) (string, bool) {
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface, reflect.Map, reflect.Slice,
+		reflect.Func, reflect.Chan:
+		if v.IsNil() {
+			return "", false
+		}
+	}
+
+	if !f.isLeaf(v.Type()) {
+		return "", false
+	}
+
+	iface, ok := dark.GetInterface(v, true)
+	if !ok {
+		return "", false
+	}
+
+	vi := reflect.ValueOf(iface)
+	if s, ok := f.local.Format(vi); ok {
+		return s, true
+	}
+	if s, ok := f.global.Format(vi); ok {
+		return s, true
+	}
+
+	switch tiface := iface.(type) {
+	case error:
+		return tiface.Error(), true
+	case fmt.Stringer:
+		return tiface.String(), true
+	}
+	return "", false
+}
+
+func (f *formatter) spew(v reflect.Value) string {
+	iface, ok := dark.GetInterface(v, true)
+	if !ok {
+		return v.String()
+	}
+	return tdutil.SpewString(iface)
+}
+
+// render renders "v" the same way go-spew does, except for leaf
+// values.
+func (f *formatter) render(v reflect.Value) string {
+	if s, ok := f.leaf(v); ok {
+		return s
+	}
+	if !f.concerned(v.Type()) {
+		return f.spew(v)
+	}
+
+	var buf bytes.Buffer
+	switch v.Kind() {
+	case reflect.Interface:
+		if v.IsNil() {
+			return f.spew(v)
+		}
+		return f.render(v.Elem())
+
+	case reflect.Ptr:
+		if v.IsNil() {
+			return f.spew(v)
+		}
+		if f.visited[v.Pointer()] {
+			return "(" + v.Type().String() + ") <already shown>"
+		}
+		if f.visited == nil {
+			f.visited = map[uintptr]bool{}
+		}
+		f.visited[v.Pointer()] = true
+		defer delete(f.visited, v.Pointer())
+		return "&" + f.render(v.Elem())
+
+	case reflect.Struct:
+		fmt.Fprintf(&buf, "(%s) {", v.Type())
+		for i, num := 0, v.NumField(); i < num; i++ {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			buf.WriteString("\n ")
+			buf.WriteString(v.Type().Field(i).Name)
+			buf.WriteString(": ")
+			buf.WriteString(IndentString(f.render(v.Field(i)), " "))
+		}
+
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.IsNil() {
+			return f.spew(v)
+		}
+		fmt.Fprintf(&buf, "(%s) (len=%d) {", v.Type(), v.Len())
+		for i, num := 0, v.Len(); i < num; i++ {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			buf.WriteString("\n ")
+			buf.WriteString(IndentString(f.render(v.Index(i)), " "))
+		}
+
+	case reflect.Map:
+		if v.IsNil() {
+			return f.spew(v)
+		}
+		fmt.Fprintf(&buf, "(%s) (len=%d) {", v.Type(), v.Len())
+		for i, key := range tdutil.MapSortedKeys(v) {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			buf.WriteString("\n ")
+			buf.WriteString(IndentString(f.render(key), " "))
+			buf.WriteString(": ")
+			buf.WriteString(IndentString(f.render(v.MapIndex(key)), " "))
+		}
+
+	default:
+		return f.spew(v)
+	}
+
+	if buf.Bytes()[buf.Len()-1] != '{' {
+		buf.WriteByte('\n')
+	}
+	buf.WriteByte('}')
+	return buf.String()
+}
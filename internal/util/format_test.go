@@ -0,0 +1,124 @@
+// Copyright (c) 2021, Maxime Soulé
+// All rights reserved.
+//
+// This source code is licensed under the BSD-style license found in the
+// LICENSE file in the root directory of this source tree.
+
+package util_test
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"testing"
+
+	"github.com/maxatome/go-testdeep/helpers/tdutil"
+	"github.com/maxatome/go-testdeep/internal/hooks"
+	"github.com/maxatome/go-testdeep/internal/test"
+	"github.com/maxatome/go-testdeep/internal/util"
+)
+
+type fmtID [4]byte
+
+type fmtStringer int
+
+func (s fmtStringer) String() string { return "stringer#" + strconv.Itoa(int(s)) }
+
+type fmtItem struct {
+	ID    fmtID
+	Names []string
+	Any   interface{}
+	next  *fmtItem
+}
+
+func TestToStringOpts(t *testing.T) {
+	formatters := hooks.NewInfo()
+	test.NoError(t, formatters.AddFormatHooks([]interface{}{
+		func(id fmtID) string { return fmt.Sprintf("ID<%x>", id[:]) },
+	}))
+	opts := util.FormatOpts{Formatters: formatters}
+
+	test.EqualStr(t, util.ToStringOpts(fmtID{1, 2, 3, 4}, opts), "ID<01020304>")
+	test.EqualStr(t, util.ToStringOpts(fmtID{1, 2, 3, 4}, util.FormatOpts{}),
+		"(util_test.fmtID) (len=4 cap=4) {\n 00000000  01 02 03 04                                       |....|\n}")
+
+	// Not concerned types keep the default rendering
+	test.EqualStr(t, util.ToStringOpts("foo", opts), `"foo"`)
+	test.EqualStr(t, util.ToStringOpts(12, opts), "12")
+	test.EqualStr(t, util.ToStringOpts([]int{1}, opts), util.ToString([]int{1}))
+
+	item := &fmtItem{
+		ID:    fmtID{0xca, 0xfe},
+		Names: []string{"bob"},
+		Any:   map[string]fmtID{"x": {1}},
+	}
+	item.next = item
+	test.EqualStr(t, util.ToStringOpts(item, opts), `&(util_test.fmtItem) {
+ ID: ID<cafe0000>,
+ Names: ([]string) (len=1 cap=1) {
+  (string) (len=3) "bob"
+ },
+ Any: (map[string]util_test.fmtID) (len=1) {
+  (string) (len=1) "x": ID<01000000>
+ },
+ next: (*util_test.fmtItem) <already shown>
+}`)
+
+	test.EqualStr(t,
+		util.ToStringOpts([]fmtID{{1}, {2}}, opts),
+		"([]util_test.fmtID) (len=2) {\n ID<01000000>,\n ID<02000000>\n}")
+	test.EqualStr(t, util.ToStringOpts([]fmtID{}, opts), "([]util_test.fmtID) (len=0) {}")
+	test.EqualStr(t, util.ToStringOpts([]fmtID(nil), opts), "([]util_test.fmtID) <nil>")
+
+	// PreferStringer
+	opts = util.FormatOpts{PreferStringer: true}
+	test.EqualStr(t, util.ToStringOpts(fmtStringer(12), opts), "stringer#12")
+	test.EqualStr(t, util.ToStringOpts(errors.New("boom"), opts), "boom")
+	test.EqualStr(t,
+		util.ToStringOpts([]interface{}{fmtStringer(1), nil}, opts),
+		"([]interface {}) (len=2) {\n stringer#1,\n (interface {}) <nil>\n}")
+	test.EqualStr(t,
+		util.ToStringOpts(fmtStringer(12), util.FormatOpts{}),
+		"(util_test.fmtStringer) stringer#12")
+}
+
+func TestGlobalFormatOpts(t *testing.T) {
+	formatters := hooks.NewInfo()
+	test.NoError(t, formatters.AddFormatHooks([]interface{}{
+		func(id fmtID) string { return "global" },
+	}))
+
+	orig := util.GlobalFormatOpts
+	defer func() { util.GlobalFormatOpts = orig }()
+	util.GlobalFormatOpts = func() util.FormatOpts {
+		return util.FormatOpts{Formatters: formatters}
+	}
+
+	test.EqualStr(t, util.ToString(fmtID{}), "global")
+
+	// Local formatters take precedence
+	local := hooks.NewInfo()
+	test.NoError(t, local.AddFormatHooks([]interface{}{
+		func(id fmtID) string { return "local" },
+	}))
+	test.EqualStr(t, util.ToStringOpts(fmtID{}, util.FormatOpts{Formatters: local}), "local")
+}
+
+func TestNoFormatters(t *testing.T) {
+	// As td package does, global formatters are always non-nil
+	orig := util.GlobalFormatOpts
+	defer func() { util.GlobalFormatOpts = orig }()
+	util.GlobalFormatOpts = func() util.FormatOpts {
+		return util.FormatOpts{Formatters: hooks.NewInfo()}
+	}
+
+	// Without any formatter registered, the default rendering is kept
+	for _, v := range []interface{}{
+		[]interface{}{1, "foo", fmtID{1}},
+		&fmtItem{Names: []string{"bob"}, Any: []int{1, 2}},
+		map[string]interface{}{"x": []string{"a"}},
+	} {
+		test.EqualStr(t, util.ToStringOpts(v, util.FormatOpts{Formatters: hooks.NewInfo()}),
+			tdutil.SpewString(v))
+	}
+}
@@ -0,0 +1,393 @@
+// Copyright (c) 2021, Maxime Soulé
+// All rights reserved.
+//
+// This source code is licensed under the BSD-style license found in the
+// LICENSE file in the root directory of this source tree.
+
+package util
+
+import (
+	"bytes"
+	"go/format"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/maxatome/go-testdeep/helpers/tdutil"
+	"github.com/maxatome/go-testdeep/internal/dark"
+)
+
+var (
+	timeType  = reflect.TypeOf(time.Time{})
+	bytesType = reflect.TypeOf([]byte(nil))
+)
+
+// GoLiteral returns "v" rendered as a gofmt'ed Go expression that
+// can be pasted in Go source code:
+//   - named types are qualified by their package name;
+//   - pointers to composite values use the & operator, pointers to
+//     other values use an inline helper function;
+//   - time.Time values use time.Date;
+//   - unexported struct fields are rendered as comments, as they
+//     cannot be set from another package;
+//   - zero struct fields are omitted;
+//   - cycles, non-nil functions, channels and unsafe pointers are
+//     rendered as commented nil.
+func GoLiteral(v reflect.Value) string {
+	g := goLiteral{
+		visited:   map[uintptr]bool{},
+		multiline: true,
+	}
+	g.value(v, true)
+
+	const prefix = "_ = "
+	src, err := format.Source(append([]byte(prefix), g.buf.Bytes()...))
+	if err != nil {
+		return g.buf.String()
+	}
+	return strings.TrimPrefix(string(src), prefix)
+}
+
+type goLiteral struct {
+	buf       bytes.Buffer
+	visited   map[uintptr]bool
+	multiline bool
+}
+
+// value renders "v". If "typed" is true, the rendering has to carry
+// the type of "v", as its context does not imply it.
+func (g *goLiteral) value(v reflect.Value, typed bool) {
+	if !v.IsValid() {
+		g.buf.WriteString("nil")
+		return
+	}
+
+	if v.Type() == timeType {
+		g.time(v)
+		return
+	}
+
+	switch v.Kind() {
+	case reflect.Bool:
+		g.scalar(v, strconv.FormatBool(v.Bool()), typed, reflect.Bool)
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		g.scalar(v, strconv.FormatInt(v.Int(), 10), typed, reflect.Int)
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Uintptr:
+		g.scalar(v, strconv.FormatUint(v.Uint(), 10), typed, reflect.Int)
+
+	case reflect.Float32, reflect.Float64:
+		f := v.Float()
+		switch {
+		case math.IsNaN(f):
+			g.scalar(v, "math.NaN()", true, reflect.Float64)
+		case math.IsInf(f, 0):
+			g.scalar(v, "math.Inf("+TernStr(f > 0, "1", "-1")+")", true, reflect.Float64)
+		default:
+			lit := formatFloat(f)
+			if strings.ContainsAny(lit, ".e") {
+				g.scalar(v, lit, typed, reflect.Float64)
+			} else {
+				g.scalar(v, lit, typed, reflect.Int)
+			}
+		}
+
+	case reflect.Complex64, reflect.Complex128:
+		c := v.Complex()
+		g.scalar(v,
+			"complex("+formatFloat(real(c))+", "+formatFloat(imag(c))+")",
+			typed, reflect.Complex128)
+
+	case reflect.String:
+		g.scalar(v, strconv.Quote(v.String()), typed, reflect.String)
+
+	case reflect.Ptr:
+		if v.IsNil() {
+			g.nil(v, typed)
+			return
+		}
+		if g.enter(v.Pointer()) {
+			g.buf.WriteString("nil /* cycle */")
+			return
+		}
+		defer g.leave(v.Pointer())
+
+		elem := v.Elem()
+		switch elem.Kind() {
+		case reflect.Struct, reflect.Array, reflect.Slice, reflect.Map:
+			if elem.Type() != timeType {
+				g.buf.WriteByte('&')
+				g.value(elem, true)
+				return
+			}
+		}
+		// func(v T) *T { return &v }(value)
+		g.buf.WriteString("func(v ")
+		g.buf.WriteString(TypeGoName(elem.Type()))
+		g.buf.WriteString(") ")
+		g.buf.WriteString(TypeGoName(v.Type()))
+		g.buf.WriteString(" { return &v }(")
+		g.value(elem, false)
+		g.buf.WriteByte(')')
+
+	case reflect.Interface:
+		if v.IsNil() {
+			g.buf.WriteString("nil")
+			return
+		}
+		g.value(v.Elem(), true)
+
+	case reflect.Slice:
+		if v.IsNil() {
+			g.nil(v, typed)
+			return
+		}
+		if v.Type() == bytesType {
+			g.buf.WriteString("[]byte(")
+			g.buf.WriteString(strconv.Quote(string(v.Bytes())))
+			g.buf.WriteByte(')')
+			return
+		}
+		if g.enter(v.Pointer()) {
+			g.buf.WriteString("nil /* cycle */")
+			return
+		}
+		defer g.leave(v.Pointer())
+		g.list(v)
+
+	case reflect.Array:
+		g.list(v)
+
+	case reflect.Map:
+		if v.IsNil() {
+			g.nil(v, typed)
+			return
+		}
+		if g.enter(v.Pointer()) {
+			g.buf.WriteString("nil /* cycle */")
+			return
+		}
+		defer g.leave(v.Pointer())
+
+		elemTyped := v.Type().Elem().Kind() == reflect.Interface
+		keyTyped := v.Type().Key().Kind() == reflect.Interface
+		g.open(v.Type(), v.Len() == 0)
+		for i, key := range tdutil.MapSortedKeys(v) {
+			g.sep(i)
+			g.value(key, keyTyped)
+			g.buf.WriteString(": ")
+			g.value(v.MapIndex(key), elemTyped)
+		}
+		g.close(v.Len() == 0)
+
+	case reflect.Struct:
+		g.structure(v)
+
+	default: // Func, Chan & UnsafePointer
+		if v.IsNil() {
+			g.nil(v, typed)
+			return
+		}
+		g.buf.WriteString("nil /* ")
+		g.buf.WriteString(v.Kind().String())
+		g.buf.WriteString(" */")
+	}
+}
+
+func (g *goLiteral) enter(ptr uintptr) bool {
+	if g.visited[ptr] {
+		return true
+	}
+	g.visited[ptr] = true
+	return false
+}
+
+func (g *goLiteral) leave(ptr uintptr) {
+	delete(g.visited, ptr)
+}
+
+// scalar renders "lit" literal of "v". "def" is the kind of the
+// default type of "lit".
+func (g *goLiteral) scalar(v reflect.Value, lit string, typed bool, def reflect.Kind) {
+	t := v.Type()
+	if typed && (t.Name() != def.String() || t.PkgPath() != "") {
+		g.buf.WriteString(TypeGoName(t))
+		g.buf.WriteByte('(')
+		g.buf.WriteString(lit)
+		g.buf.WriteByte(')')
+		return
+	}
+	g.buf.WriteString(lit)
+}
+
+func (g *goLiteral) nil(v reflect.Value, typed bool) {
+	if typed {
+		g.buf.WriteByte('(')
+		g.buf.WriteString(TypeGoName(v.Type()))
+		g.buf.WriteString(")(nil)")
+		return
+	}
+	g.buf.WriteString("nil")
+}
+
+func (g *goLiteral) time(v reflect.Value) {
+	var tm time.Time
+	if iface, ok := dark.GetInterface(v, true); ok {
+		tm = iface.(time.Time)
+	}
+
+	g.buf.WriteString("time.Date(")
+	g.buf.WriteString(strconv.Itoa(tm.Year()))
+	g.buf.WriteString(", time.")
+	g.buf.WriteString(tm.Month().String())
+	for _, n := range [...]int{tm.Day(), tm.Hour(), tm.Minute(), tm.Second(), tm.Nanosecond()} {
+		g.buf.WriteString(", ")
+		g.buf.WriteString(strconv.Itoa(n))
+	}
+	g.buf.WriteString(", ")
+
+	switch loc := tm.Location(); loc {
+	case time.UTC:
+		g.buf.WriteString("time.UTC")
+	case time.Local:
+		g.buf.WriteString("time.Local")
+	default:
+		name, offset := tm.Zone()
+		g.buf.WriteString("time.FixedZone(")
+		g.buf.WriteString(strconv.Quote(name))
+		g.buf.WriteString(", ")
+		g.buf.WriteString(strconv.Itoa(offset))
+		g.buf.WriteByte(')')
+	}
+	g.buf.WriteByte(')')
+}
+
+func (g *goLiteral) list(v reflect.Value) {
+	elemTyped := v.Type().Elem().Kind() == reflect.Interface
+	num := v.Len()
+	g.open(v.Type(), num == 0)
+	for i := 0; i < num; i++ {
+		g.sep(i)
+		g.value(v.Index(i), elemTyped)
+	}
+	g.close(num == 0)
+}
+
+func (g *goLiteral) structure(v reflect.Value) {
+	t := v.Type()
+
+	g.buf.WriteString(TypeGoName(t))
+	g.buf.WriteByte('{')
+
+	first := true
+	for i, num := 0, t.NumField(); i < num; i++ {
+		field := v.Field(i)
+		if isZero(field) {
+			continue
+		}
+
+		sf := t.Field(i)
+		exported := sf.PkgPath == ""
+		if !exported && !g.multiline {
+			continue
+		}
+
+		if first {
+			if g.multiline {
+				g.buf.WriteByte('\n')
+			}
+			first = false
+		} else if !g.multiline {
+			g.buf.WriteString(", ")
+		}
+
+		if !exported {
+			// A line comment cannot span several lines, so unexported
+			// fields are rendered on only one line
+			sub := goLiteral{visited: g.visited, multiline: false}
+			sub.value(field, sf.Type.Kind() == reflect.Interface)
+			g.buf.WriteString("// ")
+			g.buf.WriteString(sf.Name)
+			g.buf.WriteString(": ")
+			g.buf.Write(sub.buf.Bytes())
+			g.buf.WriteByte('\n')
+			continue
+		}
+
+		g.buf.WriteString(sf.Name)
+		g.buf.WriteString(": ")
+		g.value(field, sf.Type.Kind() == reflect.Interface)
+		if g.multiline {
+			g.buf.WriteString(",\n")
+		}
+	}
+	g.buf.WriteByte('}')
+}
+
+func (g *goLiteral) open(t reflect.Type, empty bool) {
+	g.buf.WriteString(TypeGoName(t))
+	g.buf.WriteByte('{')
+	if g.multiline && !empty {
+		g.buf.WriteByte('\n')
+	}
+}
+
+func (g *goLiteral) sep(i int) {
+	if i > 0 {
+		if g.multiline {
+			g.buf.WriteString(",\n")
+		} else {
+			g.buf.WriteString(", ")
+		}
+	}
+}
+
+func (g *goLiteral) close(empty bool) {
+	if g.multiline && !empty {
+		g.buf.WriteString(",\n")
+	}
+	g.buf.WriteByte('}')
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
+
+// isZero returns true if "v" is the zero value of its type.
+func isZero(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Bool:
+		return !v.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int() == 0
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Uintptr:
+		return v.Uint() == 0
+	case reflect.Float32, reflect.Float64:
+		return v.Float() == 0 && !math.Signbit(v.Float())
+	case reflect.Complex64, reflect.Complex128:
+		return v.Complex() == 0
+	case reflect.String:
+		return v.Len() == 0
+	case reflect.Array:
+		for i := v.Len() - 1; i >= 0; i-- {
+			if !isZero(v.Index(i)) {
+				return false
+			}
+		}
+		return true
+	case reflect.Struct:
+		for i := v.NumField() - 1; i >= 0; i-- {
+			if !isZero(v.Field(i)) {
+				return false
+			}
+		}
+		return true
+	default: // Chan, Func, Interface, Map, Ptr, Slice & UnsafePointer
+		return v.IsNil()
+	}
+}
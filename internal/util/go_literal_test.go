@@ -0,0 +1,141 @@
+// Copyright (c) 2021, Maxime Soulé
+// All rights reserved.
+//
+// This source code is licensed under the BSD-style license found in the
+// LICENSE file in the root directory of this source tree.
+
+package util_test
+
+import (
+	"math"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/maxatome/go-testdeep/internal/test"
+	"github.com/maxatome/go-testdeep/internal/util"
+)
+
+type goLitSub struct {
+	A int
+	b string
+}
+
+type goLitItem struct {
+	Name    string
+	Num     *int
+	Sub     *goLitSub
+	Subs    []goLitSub
+	Map     map[string]interface{}
+	Date    time.Time
+	Any     interface{}
+	private int
+	Bytes   []byte
+	Float   float32
+	Fn      func()
+	Next    *goLitItem
+}
+
+type myInt int
+
+func TestGoLiteral(t *testing.T) {
+	num := 3
+	item := &goLitItem{
+		Name: "Bob",
+		Num:  &num,
+		Sub:  &goLitSub{A: 1, b: "hidden"},
+		Subs: []goLitSub{{A: 2}, {}},
+		Map: map[string]interface{}{
+			"a": 1,
+			"b": []int{1, 2},
+			"c": nil,
+			"d": 1.5,
+		},
+		Date:    time.Date(2021, time.April, 1, 12, 0, 0, 5, time.UTC),
+		Any:     uint8(4),
+		private: 12,
+		Bytes:   []byte("hey"),
+		Float:   1,
+		Fn:      func() {},
+	}
+	item.Next = item
+
+	test.EqualStr(t, util.GoLiteral(reflect.ValueOf(item)), `&util_test.goLitItem{
+	Name: "Bob",
+	Num:  func(v int) *int { return &v }(3),
+	Sub: &util_test.goLitSub{
+		A: 1,
+		// b: "hidden"
+	},
+	Subs: []util_test.goLitSub{
+		util_test.goLitSub{
+			A: 2,
+		},
+		util_test.goLitSub{},
+	},
+	Map: map[string]interface{}{
+		"a": 1,
+		"b": []int{
+			1,
+			2,
+		},
+		"c": nil,
+		"d": 1.5,
+	},
+	Date: time.Date(2021, time.April, 1, 12, 0, 0, 5, time.UTC),
+	Any:  uint8(4),
+	// private: 12
+	Bytes: []byte("hey"),
+	Float: 1,
+	Fn:    nil, /* func */
+	Next:  nil, /* cycle */
+}`)
+
+	for i, tc := range []struct {
+		got      interface{}
+		expected string
+	}{
+		{got: nil, expected: "nil"},
+		{got: 12, expected: "12"},
+		{got: int64(-12), expected: "int64(-12)"},
+		{got: uint(12), expected: "uint(12)"},
+		{got: myInt(12), expected: "util_test.myInt(12)"},
+		{got: true, expected: "true"},
+		{got: "foo\n", expected: `"foo\n"`},
+		{got: 1.5, expected: "1.5"},
+		{got: 2.0, expected: "float64(2)"},
+		{got: float32(1.5), expected: "float32(1.5)"},
+		{got: math.NaN(), expected: "math.NaN()"},
+		{got: float32(math.Inf(-1)), expected: "float32(math.Inf(-1))"},
+		{got: complex(1, 2), expected: "complex(1, 2)"},
+		{got: &num, expected: "func(v int) *int { return &v }(3)"},
+		{got: []int(nil), expected: "([]int)(nil)"},
+		{got: []int{}, expected: "[]int{}"},
+		{got: [2]bool{true}, expected: "[2]bool{\n\ttrue,\n\tfalse,\n}"},
+		{got: map[int]bool{}, expected: "map[int]bool{}"},
+		{got: (*int)(nil), expected: "(*int)(nil)"},
+		{got: []interface{}{1, "a", nil}, expected: "[]interface{}{\n\t1,\n\t\"a\",\n\tnil,\n}"},
+		{
+			got:      time.Date(2021, time.May, 2, 0, 0, 0, 0, time.FixedZone("X", 3600)),
+			expected: `time.Date(2021, time.May, 2, 0, 0, 0, 0, time.FixedZone("X", 3600))`,
+		},
+		{
+			got:      struct{ X, y int }{X: 1, y: 2},
+			expected: "struct {\n\tX int\n\ty int\n}{\n\tX: 1,\n\t// y: 2\n}",
+		},
+		{
+			got:      []goLitSub{{b: "x"}},
+			expected: "[]util_test.goLitSub{\n\tutil_test.goLitSub{\n\t\t// b: \"x\"\n\t},\n}",
+		},
+		{
+			got: struct {
+				X int
+				y []goLitSub
+			}{X: 1, y: []goLitSub{{A: 2, b: "z"}, {}}},
+			expected: "struct {\n\tX int\n\ty []util_test.goLitSub\n}{\n\tX: 1,\n\t// y: []util_test.goLitSub{util_test.goLitSub{A: 2}, util_test.goLitSub{}}\n}",
+		},
+	} {
+		test.EqualStr(t, util.GoLiteral(reflect.ValueOf(tc.got)), tc.expected,
+			"#%d", i)
+	}
+}
@@ -0,0 +1,774 @@
+// Copyright (c) 2021, Maxime Soulé
+// All rights reserved.
+//
+// This source code is licensed under the BSD-style license found in the
+// LICENSE file in the root directory of this source tree.
+
+package util
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// JSONPath is a compiled JSONPath expression. See ParseJSONPath.
+type JSONPath struct {
+	segments []jsonPathSegment
+}
+
+// JSONPathMatch is a value matched by a JSONPath expression.
+type JSONPathMatch struct {
+	Path  string // normalized path of Value, as $.foo[0]['a b']
+	Value interface{}
+}
+
+type jsonPathSegment struct {
+	descendant bool
+	selectors  []jsonPathSelector
+}
+
+type jsonPathSelector interface {
+	apply(root interface{}, m JSONPathMatch, add func(JSONPathMatch))
+}
+
+type (
+	jsonPathName     string
+	jsonPathWildcard struct{}
+	jsonPathIndex    int
+	jsonPathSlice    struct {
+		start, end *int
+		step       int
+	}
+	jsonPathFilter struct {
+		expr jsonPathExpr
+	}
+)
+
+// jsonPathKey returns the path level corresponding to key "name".
+func jsonPathKey(name string) string {
+	if name != "" {
+		ident := true
+		for i, r := range name {
+			if r != '_' && !('a' <= r && r <= 'z') && !('A' <= r && r <= 'Z') &&
+				(i == 0 || !('0' <= r && r <= '9')) {
+				ident = false
+				break
+			}
+		}
+		if ident {
+			return "." + name
+		}
+	}
+	return "['" + strings.Replace(strings.Replace(name, `\`, `\\`, -1), "'", `\'`, -1) + "']" //nolint: gocritic
+}
+
+// jsonPathChildren calls "fn" for each child of "m", in order for
+// arrays and in sorted keys order for objects.
+func jsonPathChildren(m JSONPathMatch, fn func(JSONPathMatch)) {
+	switch v := m.Value.(type) {
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			fn(JSONPathMatch{Path: m.Path + jsonPathKey(k), Value: v[k]})
+		}
+
+	case []interface{}:
+		for i, item := range v {
+			fn(JSONPathMatch{Path: m.Path + "[" + strconv.Itoa(i) + "]", Value: item})
+		}
+	}
+}
+
+// jsonPathDescendants calls "fn" for "m" and all its descendants.
+func jsonPathDescendants(m JSONPathMatch, fn func(JSONPathMatch)) {
+	fn(m)
+	jsonPathChildren(m, func(child JSONPathMatch) {
+		jsonPathDescendants(child, fn)
+	})
+}
+
+func (s jsonPathName) apply(root interface{}, m JSONPathMatch, add func(JSONPathMatch)) {
+	if obj, ok := m.Value.(map[string]interface{}); ok {
+		if v, ok := obj[string(s)]; ok {
+			add(JSONPathMatch{Path: m.Path + jsonPathKey(string(s)), Value: v})
+		}
+	}
+}
+
+func (s jsonPathWildcard) apply(root interface{}, m JSONPathMatch, add func(JSONPathMatch)) {
+	jsonPathChildren(m, add)
+}
+
+func (s jsonPathIndex) apply(root interface{}, m JSONPathMatch, add func(JSONPathMatch)) {
+	if arr, ok := m.Value.([]interface{}); ok {
+		i := int(s)
+		if i < 0 {
+			i += len(arr)
+		}
+		if i >= 0 && i < len(arr) {
+			add(JSONPathMatch{Path: m.Path + "[" + strconv.Itoa(i) + "]", Value: arr[i]})
+		}
+	}
+}
+
+func (s jsonPathSlice) apply(root interface{}, m JSONPathMatch, add func(JSONPathMatch)) {
+	arr, ok := m.Value.([]interface{})
+	if !ok || s.step == 0 {
+		return
+	}
+
+	n := len(arr)
+	bound := func(i *int, def, min, max int) int {
+		if i == nil {
+			return def
+		}
+		v := *i
+		if v < 0 {
+			v += n
+		}
+		if v < min {
+			return min
+		}
+		if v > max {
+			return max
+		}
+		return v
+	}
+
+	addIndex := func(i int) {
+		add(JSONPathMatch{Path: m.Path + "[" + strconv.Itoa(i) + "]", Value: arr[i]})
+	}
+
+	if s.step > 0 {
+		for i, end := bound(s.start, 0, 0, n), bound(s.end, n, 0, n); i < end; i += s.step {
+			addIndex(i)
+		}
+		return
+	}
+	for i, end := bound(s.start, n-1, -1, n-1), bound(s.end, -1, -1, n-1); i > end; i += s.step {
+		addIndex(i)
+	}
+}
+
+func (s jsonPathFilter) apply(root interface{}, m JSONPathMatch, add func(JSONPathMatch)) {
+	jsonPathChildren(m, func(child JSONPathMatch) {
+		if s.expr.test(root, child.Value) {
+			add(child)
+		}
+	})
+}
+
+// Find returns all the values of "v" matched by "p", in document
+// order. To be searched, "v" has to contain map[string]interface{} or
+// []interface{} values, as encoding/json produces when unmarshaling
+// into an interface{}.
+func (p *JSONPath) Find(v interface{}) []JSONPathMatch {
+	return p.find(v, v)
+}
+
+func (p *JSONPath) find(root, v interface{}) []JSONPathMatch {
+	cur := []JSONPathMatch{{Path: "$", Value: v}}
+	for _, seg := range p.segments {
+		var next []JSONPathMatch
+		add := func(m JSONPathMatch) { next = append(next, m) }
+		for _, m := range cur {
+			if seg.descendant {
+				jsonPathDescendants(m, func(d JSONPathMatch) {
+					for _, sel := range seg.selectors {
+						sel.apply(root, d, add)
+					}
+				})
+				continue
+			}
+			for _, sel := range seg.selectors {
+				sel.apply(root, m, add)
+			}
+		}
+		cur = next
+	}
+	return cur
+}
+
+//
+// Filter expressions
+//
+
+type jsonPathExpr interface {
+	// test returns the boolean value of the expression
+	test(root, cur interface{}) bool
+	// value returns the value of the expression and whether it exists
+	value(root, cur interface{}) (interface{}, bool)
+}
+
+type (
+	jsonPathOr      struct{ left, right jsonPathExpr }
+	jsonPathAnd     struct{ left, right jsonPathExpr }
+	jsonPathNot     struct{ expr jsonPathExpr }
+	jsonPathCompare struct {
+		op          string
+		left, right jsonPathExpr
+	}
+	jsonPathQuery struct {
+		fromRoot bool
+		path     JSONPath
+	}
+	jsonPathLiteral struct{ v interface{} }
+)
+
+func (e jsonPathOr) test(root, cur interface{}) bool {
+	return e.left.test(root, cur) || e.right.test(root, cur)
+}
+
+func (e jsonPathOr) value(root, cur interface{}) (interface{}, bool) {
+	return e.test(root, cur), true
+}
+
+func (e jsonPathAnd) test(root, cur interface{}) bool {
+	return e.left.test(root, cur) && e.right.test(root, cur)
+}
+
+func (e jsonPathAnd) value(root, cur interface{}) (interface{}, bool) {
+	return e.test(root, cur), true
+}
+
+func (e jsonPathNot) test(root, cur interface{}) bool {
+	return !e.expr.test(root, cur)
+}
+
+func (e jsonPathNot) value(root, cur interface{}) (interface{}, bool) {
+	return e.test(root, cur), true
+}
+
+func (e jsonPathCompare) test(root, cur interface{}) bool {
+	l, lok := e.left.value(root, cur)
+	r, rok := e.right.value(root, cur)
+
+	switch e.op {
+	case "==":
+		return jsonPathEqual(l, lok, r, rok)
+	case "!=":
+		return !jsonPathEqual(l, lok, r, rok)
+	}
+
+	if !lok || !rok {
+		return false
+	}
+
+	cmp, isNum := jsonPathNumberCmp(l, r)
+	if !isNum {
+		ls, lok := l.(string)
+		rs, rok := r.(string)
+		if !lok || !rok {
+			return false
+		}
+		cmp = strings.Compare(ls, rs)
+	}
+
+	switch e.op {
+	case "<":
+		return cmp < 0
+	case "<=":
+		return cmp <= 0
+	case ">":
+		return cmp > 0
+	default: // ">="
+		return cmp >= 0
+	}
+}
+
+func (e jsonPathCompare) value(root, cur interface{}) (interface{}, bool) {
+	return e.test(root, cur), true
+}
+
+func jsonPathEqual(l interface{}, lok bool, r interface{}, rok bool) bool {
+	if !lok || !rok {
+		return lok == rok
+	}
+	if cmp, isNum := jsonPathNumberCmp(l, r); isNum {
+		return cmp == 0
+	}
+	return reflect.DeepEqual(l, r)
+}
+
+// jsonPathNumberCmp compares "l" and "r" if both are numbers, either
+// float64 or json.Number as documents can be unmarshaled with or
+// without json.Decoder.UseNumber. Two json.Number are compared
+// exactly, otherwise as float64. It returns false if "l" or "r" is
+// not a number.
+func jsonPathNumberCmp(l, r interface{}) (int, bool) {
+	ln, lIsNum := l.(json.Number)
+	rn, rIsNum := r.(json.Number)
+	if lIsNum && rIsNum {
+		lr, lok := new(big.Rat).SetString(string(ln))
+		rr, rok := new(big.Rat).SetString(string(rn))
+		if lok && rok {
+			return lr.Cmp(rr), true
+		}
+	}
+
+	lf, lok := jsonPathFloat(l)
+	rf, rok := jsonPathFloat(r)
+	if !lok || !rok {
+		return 0, false
+	}
+	switch {
+	case lf < rf:
+		return -1, true
+	case lf > rf:
+		return 1, true
+	}
+	return 0, true
+}
+
+func jsonPathFloat(v interface{}) (float64, bool) {
+	switch v := v.(type) {
+	case float64:
+		return v, true
+	case json.Number:
+		f, err := v.Float64()
+		return f, err == nil
+	}
+	return 0, false
+}
+
+// test returns true if the query matches at least one value.
+func (e jsonPathQuery) test(root, cur interface{}) bool {
+	if e.fromRoot {
+		cur = root
+	}
+	return len(e.path.find(root, cur)) > 0
+}
+
+// value returns the value matched by the query, only if it matches
+// exactly one value.
+func (e jsonPathQuery) value(root, cur interface{}) (interface{}, bool) {
+	if e.fromRoot {
+		cur = root
+	}
+	if m := e.path.find(root, cur); len(m) == 1 {
+		return m[0].Value, true
+	}
+	return nil, false
+}
+
+func (e jsonPathLiteral) test(root, cur interface{}) bool {
+	return e.v == true
+}
+
+func (e jsonPathLiteral) value(root, cur interface{}) (interface{}, bool) {
+	return e.v, true
+}
+
+//
+// Parser
+//
+
+type jsonPathParser struct {
+	expr string
+	pos  int
+}
+
+// JSONPathError is returned by ParseJSONPath when the expression
+// cannot be parsed.
+type JSONPathError struct {
+	Message string
+	Pos     int // byte offset in the expression
+}
+
+func (e *JSONPathError) Error() string {
+	return fmt.Sprintf("%s at offset %d", e.Message, e.Pos)
+}
+
+// ParseJSONPath parses the JSONPath expression "expr". The following
+// subset of JSONPath (as RFC 9535 specifies it) is supported:
+//
+//	$                  root value
+//	.name  ['name']    object member (double quotes allowed)
+//	.*  [*]            all members/items
+//	..                 recursive descent, as in $..name or $..[0]
+//	[1]  [-1]          array item, negative indexes from the end
+//	[1:3]  [::2]       array slice [start:end:step]
+//	['a','b']  [0,2]   union of selectors
+//	[?(filter)]        items for which filter is true
+//
+// Filters can use @ (the current item) and $ (the root value) paths,
+// literals (numbers, strings, true, false and null), comparison
+// operators ==, !=, <, <=, > and >=, logical operators &&, || and !,
+// and parentheses. A path alone as in [?(@.isbn)] tests its
+// existence.
+func ParseJSONPath(expr string) (*JSONPath, error) {
+	p := jsonPathParser{expr: expr}
+
+	p.skipSpaces()
+	if !p.eat("$") {
+		return nil, p.error("JSONPath must start with $")
+	}
+
+	segments, err := p.parseSegments()
+	if err != nil {
+		return nil, err
+	}
+
+	p.skipSpaces()
+	if p.pos < len(p.expr) {
+		return nil, p.unexpected()
+	}
+	return &JSONPath{segments: segments}, nil
+}
+
+func (p *jsonPathParser) error(msg string) error {
+	return &JSONPathError{Message: msg, Pos: p.pos}
+}
+
+func (p *jsonPathParser) unexpected() error {
+	if p.pos >= len(p.expr) {
+		return p.error("unexpected end of JSONPath")
+	}
+	r, _ := utf8.DecodeRuneInString(p.expr[p.pos:])
+	return p.error(fmt.Sprintf("unexpected %q", r))
+}
+
+func (p *jsonPathParser) peek() byte {
+	if p.pos < len(p.expr) {
+		return p.expr[p.pos]
+	}
+	return 0
+}
+
+func (p *jsonPathParser) eat(s string) bool {
+	if strings.HasPrefix(p.expr[p.pos:], s) {
+		p.pos += len(s)
+		return true
+	}
+	return false
+}
+
+func (p *jsonPathParser) skipSpaces() {
+	for p.pos < len(p.expr) && strings.IndexByte(" \t\r\n", p.expr[p.pos]) >= 0 {
+		p.pos++
+	}
+}
+
+func (p *jsonPathParser) parseSegments() ([]jsonPathSegment, error) {
+	var segments []jsonPathSegment
+	for {
+		var seg jsonPathSegment
+		switch {
+		case p.eat(".."):
+			seg.descendant = true
+			if p.peek() == '[' {
+				break
+			}
+			fallthrough
+		case p.eat("."):
+			if p.eat("*") {
+				seg.selectors = []jsonPathSelector{jsonPathWildcard{}}
+			} else {
+				name := p.parseName()
+				if name == "" {
+					return nil, p.error("member name expected")
+				}
+				seg.selectors = []jsonPathSelector{jsonPathName(name)}
+			}
+			segments = append(segments, seg)
+			continue
+
+		case p.peek() != '[':
+			return segments, nil
+		}
+
+		// Bracketed selectors
+		p.pos++
+		for {
+			p.skipSpaces()
+			sel, err := p.parseSelector()
+			if err != nil {
+				return nil, err
+			}
+			seg.selectors = append(seg.selectors, sel)
+
+			p.skipSpaces()
+			if p.eat("]") {
+				break
+			}
+			if !p.eat(",") {
+				return nil, p.unexpected()
+			}
+		}
+		segments = append(segments, seg)
+	}
+}
+
+func (p *jsonPathParser) parseName() string {
+	start := p.pos
+	for p.pos < len(p.expr) {
+		c := p.expr[p.pos]
+		if c < utf8.RuneSelf && c != '_' && c != '-' &&
+			!('a' <= c && c <= 'z') && !('A' <= c && c <= 'Z') && !('0' <= c && c <= '9') {
+			break
+		}
+		p.pos++
+	}
+	return p.expr[start:p.pos]
+}
+
+func (p *jsonPathParser) parseSelector() (jsonPathSelector, error) {
+	switch c := p.peek(); {
+	case c == '\'' || c == '"':
+		s, err := p.parseString()
+		if err != nil {
+			return nil, err
+		}
+		return jsonPathName(s), nil
+
+	case c == '*':
+		p.pos++
+		return jsonPathWildcard{}, nil
+
+	case c == '?':
+		p.pos++
+		p.skipSpaces()
+		expr, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		return jsonPathFilter{expr: expr}, nil
+
+	case c == '-' || c == ':' || ('0' <= c && c <= '9'):
+		var nums [3]*int
+		i := 0
+		for {
+			p.skipSpaces()
+			if c := p.peek(); c == '-' || ('0' <= c && c <= '9') {
+				n, err := p.parseInt()
+				if err != nil {
+					return nil, err
+				}
+				nums[i] = &n
+			}
+			p.skipSpaces()
+			if i == 2 || !p.eat(":") {
+				break
+			}
+			i++
+		}
+		if i == 0 {
+			return jsonPathIndex(*nums[0]), nil
+		}
+		slice := jsonPathSlice{start: nums[0], end: nums[1], step: 1}
+		if nums[2] != nil {
+			slice.step = *nums[2]
+		}
+		return slice, nil
+	}
+	return nil, p.unexpected()
+}
+
+func (p *jsonPathParser) parseInt() (int, error) {
+	start := p.pos
+	p.eat("-")
+	for c := p.peek(); '0' <= c && c <= '9'; c = p.peek() {
+		p.pos++
+	}
+	n, err := strconv.Atoi(p.expr[start:p.pos])
+	if err != nil {
+		p.pos = start
+		return 0, p.error("invalid integer")
+	}
+	return n, nil
+}
+
+func (p *jsonPathParser) parseString() (string, error) {
+	start := p.pos
+	quote := p.expr[p.pos]
+	p.pos++
+
+	var buf []byte
+	for p.pos < len(p.expr) {
+		c := p.expr[p.pos]
+		switch c {
+		case quote:
+			p.pos++
+			return string(buf), nil
+
+		case '\\':
+			p.pos++
+			if p.pos >= len(p.expr) {
+				break
+			}
+			esc := p.expr[p.pos]
+			switch esc {
+			case '\\', '/', '\'', '"':
+				buf = append(buf, esc)
+			case 'b':
+				buf = append(buf, '\b')
+			case 'f':
+				buf = append(buf, '\f')
+			case 'n':
+				buf = append(buf, '\n')
+			case 'r':
+				buf = append(buf, '\r')
+			case 't':
+				buf = append(buf, '\t')
+			case 'u':
+				if p.pos+5 > len(p.expr) {
+					return "", p.error("invalid \\u escape")
+				}
+				r, err := strconv.ParseUint(p.expr[p.pos+1:p.pos+5], 16, 16)
+				if err != nil {
+					return "", p.error("invalid \\u escape")
+				}
+				var rb [utf8.UTFMax]byte
+				buf = append(buf, rb[:utf8.EncodeRune(rb[:], rune(r))]...)
+				p.pos += 4
+			default:
+				return "", p.error(fmt.Sprintf("invalid escape \\%c", esc))
+			}
+			p.pos++
+
+		default:
+			buf = append(buf, c)
+			p.pos++
+		}
+	}
+	p.pos = start
+	return "", p.error("unterminated string")
+}
+
+func (p *jsonPathParser) parseOr() (jsonPathExpr, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		p.skipSpaces()
+		if !p.eat("||") {
+			return left, nil
+		}
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = jsonPathOr{left: left, right: right}
+	}
+}
+
+func (p *jsonPathParser) parseAnd() (jsonPathExpr, error) {
+	left, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		p.skipSpaces()
+		if !p.eat("&&") {
+			return left, nil
+		}
+		right, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		left = jsonPathAnd{left: left, right: right}
+	}
+}
+
+func (p *jsonPathParser) parseNot() (jsonPathExpr, error) {
+	p.skipSpaces()
+	if p.peek() == '!' && !strings.HasPrefix(p.expr[p.pos:], "!=") {
+		p.pos++
+		expr, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return jsonPathNot{expr: expr}, nil
+	}
+	return p.parseCompare()
+}
+
+var jsonPathCompareOps = []string{"==", "!=", "<=", ">=", "<", ">"}
+
+func (p *jsonPathParser) parseCompare() (jsonPathExpr, error) {
+	left, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+	p.skipSpaces()
+	for _, op := range jsonPathCompareOps {
+		if p.eat(op) {
+			right, err := p.parseOperand()
+			if err != nil {
+				return nil, err
+			}
+			return jsonPathCompare{op: op, left: left, right: right}, nil
+		}
+	}
+	return left, nil
+}
+
+func (p *jsonPathParser) parseOperand() (jsonPathExpr, error) {
+	p.skipSpaces()
+	switch c := p.peek(); {
+	case c == '(':
+		p.pos++
+		expr, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		p.skipSpaces()
+		if !p.eat(")") {
+			return nil, p.unexpected()
+		}
+		return expr, nil
+
+	case c == '@' || c == '$':
+		p.pos++
+		segments, err := p.parseSegments()
+		if err != nil {
+			return nil, err
+		}
+		return jsonPathQuery{
+			fromRoot: c == '$',
+			path:     JSONPath{segments: segments},
+		}, nil
+
+	case c == '\'' || c == '"':
+		s, err := p.parseString()
+		if err != nil {
+			return nil, err
+		}
+		return jsonPathLiteral{v: s}, nil
+
+	case c == '-' || ('0' <= c && c <= '9'):
+		start := p.pos
+		p.pos++
+		for p.pos < len(p.expr) && strings.IndexByte("0123456789.eE+-", p.expr[p.pos]) >= 0 {
+			p.pos++
+		}
+		// Kept as json.Number, so compared exactly
+		num := json.Number(p.expr[start:p.pos])
+		if _, err := num.Float64(); err != nil {
+			p.pos = start
+			return nil, p.error("invalid number")
+		}
+		return jsonPathLiteral{v: num}, nil
+	}
+
+	for _, lit := range []struct {
+		s string
+		v interface{}
+	}{{"true", true}, {"false", false}, {"null", nil}} {
+		if p.eat(lit.s) {
+			return jsonPathLiteral{v: lit.v}, nil
+		}
+	}
+	return nil, p.unexpected()
+}
@@ -0,0 +1,225 @@
+// Copyright (c) 2021, Maxime Soulé
+// All rights reserved.
+//
+// This source code is licensed under the BSD-style license found in the
+// LICENSE file in the root directory of this source tree.
+
+package util_test
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/maxatome/go-testdeep/internal/util"
+)
+
+func TestJSONPath(t *testing.T) {
+	var ref interface{}
+	err := json.Unmarshal([]byte(`
+{
+  "store": {
+    "book": [
+      {"category": "reference", "author": "Nigel Rees", "price": 8.95},
+      {"category": "fiction", "author": "Evelyn Waugh", "price": 12.99},
+      {"category": "fiction", "author": "Herman Melville", "price": 8.99,
+       "isbn": "0-553-21311-3"},
+      {"category": "fiction", "author": "J. R. R. Tolkien", "price": 22.99,
+       "isbn": "0-395-19395-8"}
+    ],
+    "bicycle": {"color": "red", "price": 399}
+  },
+  "users": [
+    {"name": "Bob", "role": "admin"},
+    {"name": "Alice", "role": "user"}
+  ],
+  "a b": {"it's": 1},
+  "limit": 10
+}`),
+		&ref)
+	if err != nil {
+		t.Fatalf("json.Unmarshal failed: %s", err)
+	}
+
+	check := func(expr string, expectedPaths []string, expectedValues []interface{}) {
+		t.Helper()
+
+		p, err := util.ParseJSONPath(expr)
+		if err != nil {
+			t.Errorf("%s: error <%s> received instead of nil", expr, err)
+			return
+		}
+
+		matches := p.Find(ref)
+		paths := make([]string, len(matches))
+		values := make([]interface{}, len(matches))
+		for i, m := range matches {
+			paths[i] = m.Path
+			values[i] = m.Value
+		}
+
+		if !reflect.DeepEqual(paths, expectedPaths) {
+			t.Errorf("%s: got paths: %q expected: %q", expr, paths, expectedPaths)
+		}
+		if expectedValues != nil && !reflect.DeepEqual(values, expectedValues) {
+			t.Errorf("%s: got values: %v expected: %v", expr, values, expectedValues)
+		}
+	}
+
+	check("$", []string{"$"}, []interface{}{ref})
+	check("$.limit", []string{"$.limit"}, []interface{}{float64(10)})
+	check("$['limit']", []string{"$.limit"}, nil)
+	check(`$["a b"]['it\'s']`, []string{`$['a b']['it\'s']`}, []interface{}{float64(1)})
+	check("$.unknown", []string{}, nil)
+	check("$.limit.foo", []string{}, nil)
+
+	check("$.store.book[*].author",
+		[]string{
+			"$.store.book[0].author",
+			"$.store.book[1].author",
+			"$.store.book[2].author",
+			"$.store.book[3].author",
+		},
+		[]interface{}{"Nigel Rees", "Evelyn Waugh", "Herman Melville", "J. R. R. Tolkien"})
+
+	check("$..author",
+		[]string{
+			"$.store.book[0].author",
+			"$.store.book[1].author",
+			"$.store.book[2].author",
+			"$.store.book[3].author",
+		}, nil)
+
+	check("$.store.*",
+		[]string{"$.store.bicycle", "$.store.book"}, nil)
+
+	check("$.store..price",
+		[]string{
+			"$.store.bicycle.price",
+			"$.store.book[0].price",
+			"$.store.book[1].price",
+			"$.store.book[2].price",
+			"$.store.book[3].price",
+		},
+		[]interface{}{399.0, 8.95, 12.99, 8.99, 22.99})
+
+	check("$..book[2].author", []string{"$.store.book[2].author"}, nil)
+	check("$..book[-1].author", []string{"$.store.book[3].author"}, nil)
+	check("$..book[4]", []string{}, nil)
+	check("$..book[-5]", []string{}, nil)
+	check("$..book[0,1].price",
+		[]string{"$.store.book[0].price", "$.store.book[1].price"}, nil)
+	check("$.users[0]['name','role']",
+		[]string{"$.users[0].name", "$.users[0].role"},
+		[]interface{}{"Bob", "admin"})
+
+	// Slices
+	check("$..book[:2].price",
+		[]string{"$.store.book[0].price", "$.store.book[1].price"}, nil)
+	check("$..book[1:3].price",
+		[]string{"$.store.book[1].price", "$.store.book[2].price"}, nil)
+	check("$..book[-2:].price",
+		[]string{"$.store.book[2].price", "$.store.book[3].price"}, nil)
+	check("$..book[::2].price",
+		[]string{"$.store.book[0].price", "$.store.book[2].price"}, nil)
+	check("$..book[::-1].price",
+		[]string{
+			"$.store.book[3].price",
+			"$.store.book[2].price",
+			"$.store.book[1].price",
+			"$.store.book[0].price",
+		}, nil)
+	check("$..book[10:20]", []string{}, nil)
+	check("$..book[0:4:0]", []string{}, nil)
+	check("$..book[ 1 : 2 ].price", []string{"$.store.book[1].price"}, nil)
+
+	// Filters
+	check(`$.users[?(@.role=="admin")].name`,
+		[]string{"$.users[0].name"}, []interface{}{"Bob"})
+	check(`$.users[?@.role != 'admin'].name`,
+		[]string{"$.users[1].name"}, []interface{}{"Alice"})
+	check(`$..book[?(@.isbn)].author`,
+		[]string{"$.store.book[2].author", "$.store.book[3].author"}, nil)
+	check(`$..book[?(!@.isbn)].author`,
+		[]string{"$.store.book[0].author", "$.store.book[1].author"}, nil)
+	check(`$..book[?(@.price < 10)].price`,
+		[]string{"$.store.book[0].price", "$.store.book[2].price"}, nil)
+	check(`$..book[?(@.price <= 8.95)].price`, []string{"$.store.book[0].price"}, nil)
+	check(`$..book[?(@.price > 20)].price`, []string{"$.store.book[3].price"}, nil)
+	check(`$..book[?(@.price >= 22.99)].price`, []string{"$.store.book[3].price"}, nil)
+	check(`$..book[?(@.price < $.limit)].price`,
+		[]string{"$.store.book[0].price", "$.store.book[2].price"}, nil)
+	check(`$..book[?(@.category == "fiction" && @.price < 10)].author`,
+		[]string{"$.store.book[2].author"}, nil)
+	check(`$..book[?(@.category == "reference" || @.price > 20)].author`,
+		[]string{"$.store.book[0].author", "$.store.book[3].author"}, nil)
+	check(`$..book[?(!(@.category == "fiction"))].author`,
+		[]string{"$.store.book[0].author"}, nil)
+	check(`$..book[?(@.author > "I")].author`,
+		[]string{"$.store.book[0].author", "$.store.book[3].author"}, nil)
+	check(`$..book[?(@.price < "10")]`, []string{}, nil)
+	check(`$..book[?(@.missing == null)]`, []string{}, nil)
+	check(`$..book[?(@.missing != null)]`,
+		[]string{
+			"$.store.book[0]",
+			"$.store.book[1]",
+			"$.store.book[2]",
+			"$.store.book[3]",
+		}, nil)
+	check(`$..book[?(@.missing == @.other)]`,
+		[]string{
+			"$.store.book[0]",
+			"$.store.book[1]",
+			"$.store.book[2]",
+			"$.store.book[3]",
+		}, nil)
+	check(`$.users[?(true)].name`, []string{"$.users[0].name", "$.users[1].name"}, nil)
+	check(`$.users[?(false)].name`, []string{}, nil)
+	check(`$[?(@ == 10)]`, []string{"$.limit"}, nil)
+	check(`$[?(@ == -1e1)]`, []string{}, nil)
+
+	// Numbers kept as json.Number are compared exactly
+	dec := json.NewDecoder(strings.NewReader(
+		`{"ids": [{"id": 9007199254740992}, {"id": 9007199254740993}, {"id": 1.5}]}`))
+	dec.UseNumber()
+	if err = dec.Decode(&ref); err != nil {
+		t.Fatalf("json.Decode failed: %s", err)
+	}
+	check(`$.ids[?(@.id == 9007199254740993)].id`,
+		[]string{"$.ids[1].id"}, []interface{}{json.Number("9007199254740993")})
+	check(`$.ids[?(@.id > 9007199254740992)].id`, []string{"$.ids[1].id"}, nil)
+	check(`$.ids[?(@.id <= 15e-1)].id`, []string{"$.ids[2].id"}, nil)
+
+	// Errors
+	checkErr := func(expr, errExpected string) {
+		t.Helper()
+
+		p, err := util.ParseJSONPath(expr)
+		if p != nil {
+			t.Errorf("%s: got: %v expected: nil", expr, p)
+		}
+		if err == nil {
+			t.Errorf("%s: error nil received instead of <%s>", expr, errExpected)
+		} else if err.Error() != errExpected {
+			t.Errorf("%s: error <%s> received instead of <%s>", expr, err, errExpected)
+		}
+	}
+
+	checkErr("", "JSONPath must start with $ at offset 0")
+	checkErr("foo", "JSONPath must start with $ at offset 0")
+	checkErr("$.", "member name expected at offset 2")
+	checkErr("$.a.", "member name expected at offset 4")
+	checkErr("$foo", `unexpected 'f' at offset 1`)
+	checkErr("$[", "unexpected end of JSONPath at offset 2")
+	checkErr("$[1", "unexpected end of JSONPath at offset 3")
+	checkErr("$[1;", `unexpected ';' at offset 3`)
+	checkErr("$[-]", "invalid integer at offset 2")
+	checkErr("$['abc", "unterminated string at offset 2")
+	checkErr(`$['\x']`, `invalid escape \x at offset 4`)
+	checkErr(`$['\u12']`, `invalid \u escape at offset 4`)
+	checkErr(`$[?(@.a == )]`, `unexpected ')' at offset 11`)
+	checkErr(`$[?(@.a == 1]`, `unexpected ']' at offset 12`)
+	checkErr(`$[?(@.a == 1-)]`, `invalid number at offset 11`)
+	checkErr(`$[?(@.a == 1)] x`, `unexpected 'x' at offset 15`)
+}
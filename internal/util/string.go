@@ -11,49 +11,13 @@ import (
 	"fmt"
 	"io"
 	"reflect"
-	"strconv"
 	"strings"
-
-	"github.com/maxatome/go-testdeep/helpers/tdutil"
-	"github.com/maxatome/go-testdeep/internal/dark"
-	"github.com/maxatome/go-testdeep/internal/types"
 )
 
-// ToString does its best to stringify val.
+// ToString does its best to stringify val. Global formatters and
+// options returned by GlobalFormatOpts are taken into account.
 func ToString(val interface{}) string {
-	if val == nil {
-		return "nil"
-	}
-
-	switch tval := val.(type) {
-	case reflect.Value:
-		newVal, ok := dark.GetInterface(tval, true)
-		if ok {
-			return ToString(newVal)
-		}
-
-	case []reflect.Value:
-		var buf bytes.Buffer
-		SliceToBuffer(&buf, tval)
-		return buf.String()
-
-		// no "(string) " prefix for printable strings
-	case string:
-		return tdutil.FormatString(tval)
-
-		// no "(int) " prefix for ints
-	case int:
-		return strconv.Itoa(tval)
-
-		// no "(bool)" prefix for booleans
-	case bool:
-		return TernStr(tval, "true", "false")
-
-	case types.TestDeepStringer:
-		return tval.String()
-	}
-
-	return tdutil.SpewString(val)
+	return ToStringOpts(val, FormatOpts{})
 }
 
 // IndentString indents str lines (from 2nd one = 1st line is not
@@ -71,6 +35,10 @@ func IndentStringIn(w io.Writer, str, indent string) {
 
 // SliceToBuffer stringifies items slice into buf then returns buf.
 func SliceToBuffer(buf *bytes.Buffer, items []reflect.Value) *bytes.Buffer {
+	return sliceToBuffer(buf, items, ToString)
+}
+
+func sliceToBuffer(buf *bytes.Buffer, items []reflect.Value, toString func(interface{}) string) *bytes.Buffer {
 	buf.WriteByte('(')
 
 	begLine := bytes.LastIndexByte(buf.Bytes(), '\n') + 1
@@ -78,14 +46,14 @@ func SliceToBuffer(buf *bytes.Buffer, items []reflect.Value) *bytes.Buffer {
 
 	if len(items) < 2 {
 		if len(items) > 0 {
-			buf.WriteString(IndentString(ToString(items[0]), prefix))
+			buf.WriteString(IndentString(toString(items[0]), prefix))
 		}
 	} else {
 		for idx, item := range items {
 			if idx != 0 {
 				buf.WriteString(prefix)
 			}
-			buf.WriteString(IndentString(ToString(item), prefix))
+			buf.WriteString(IndentString(toString(item), prefix))
 			buf.WriteString(",\n")
 		}
 		buf.Truncate(buf.Len() - 2)
@@ -99,12 +67,25 @@ func SliceToBuffer(buf *bytes.Buffer, items []reflect.Value) *bytes.Buffer {
 // instead of the last package part in t.String().
 func TypeFullName(t reflect.Type) string {
 	var b bytes.Buffer
-	typeFullName(&b, t)
+	typeFullName(&b, t, false)
 	return b.String()
 }
 
-func typeFullName(b *bytes.Buffer, t reflect.Type) {
+// TypeGoName returns the t type name as it can be written in Go
+// source code, so with named types qualified by their package name
+// instead of their package path, as in "[]time.Time".
+func TypeGoName(t reflect.Type) string {
+	var b bytes.Buffer
+	typeFullName(&b, t, true)
+	return b.String()
+}
+
+func typeFullName(b *bytes.Buffer, t reflect.Type, goName bool) {
 	if t.Name() != "" {
+		if goName {
+			b.WriteString(t.String())
+			return
+		}
 		if pkg := t.PkgPath(); pkg != "" {
 			fmt.Fprintf(b, "%s.", pkg)
 		}
@@ -115,21 +96,21 @@ func typeFullName(b *bytes.Buffer, t reflect.Type) {
 	switch t.Kind() {
 	case reflect.Ptr:
 		b.WriteByte('*')
-		typeFullName(b, t.Elem())
+		typeFullName(b, t.Elem(), goName)
 
 	case reflect.Slice:
 		b.WriteString("[]")
-		typeFullName(b, t.Elem())
+		typeFullName(b, t.Elem(), goName)
 
 	case reflect.Array:
 		fmt.Fprintf(b, "[%d]", t.Len())
-		typeFullName(b, t.Elem())
+		typeFullName(b, t.Elem(), goName)
 
 	case reflect.Map:
 		b.WriteString("map[")
-		typeFullName(b, t.Key())
+		typeFullName(b, t.Key(), goName)
 		b.WriteByte(']')
-		typeFullName(b, t.Elem())
+		typeFullName(b, t.Elem(), goName)
 
 	case reflect.Struct:
 		b.WriteString("struct {")
@@ -141,7 +122,7 @@ func typeFullName(b *bytes.Buffer, t reflect.Type) {
 					b.WriteString(sf.Name)
 				}
 				b.WriteByte(' ')
-				typeFullName(b, sf.Type)
+				typeFullName(b, sf.Type, goName)
 				b.WriteByte(';')
 			}
 			b.Truncate(b.Len() - 1)
@@ -155,9 +136,9 @@ func typeFullName(b *bytes.Buffer, t reflect.Type) {
 			for i := 0; i < num; i++ {
 				if i == num-1 && t.IsVariadic() {
 					b.WriteString("...")
-					typeFullName(b, t.In(i).Elem())
+					typeFullName(b, t.In(i).Elem(), goName)
 				} else {
-					typeFullName(b, t.In(i))
+					typeFullName(b, t.In(i), goName)
 				}
 				b.WriteString(", ")
 			}
@@ -172,7 +153,7 @@ func typeFullName(b *bytes.Buffer, t reflect.Type) {
 				b.WriteString(" (")
 			}
 			for i := 0; i < num; i++ {
-				typeFullName(b, t.Out(i))
+				typeFullName(b, t.Out(i), goName)
 				b.WriteString(", ")
 			}
 			b.Truncate(b.Len() - 2)
@@ -190,7 +171,7 @@ func typeFullName(b *bytes.Buffer, t reflect.Type) {
 		case reflect.BothDir:
 			b.WriteString("chan ")
 		}
-		typeFullName(b, t.Elem())
+		typeFullName(b, t.Elem(), goName)
 
 	default:
 		// Fallback to default implementation
@@ -8,6 +8,7 @@ package util_test
 
 import (
 	"bytes"
+	"encoding/json"
 	"reflect"
 	"runtime"
 	"strings"
@@ -51,6 +52,7 @@ func TestToString(t *testing.T) {
 		{paramGot: 42, expected: "42"},
 		{paramGot: true, expected: "true"},
 		{paramGot: false, expected: "false"},
+		{paramGot: json.Number("9007199254740993"), expected: "9007199254740993"},
 		{paramGot: int64(42), expected: "(int64) 42"},
 	} {
 		test.EqualStr(t, util.ToString(curTest.paramGot), curTest.expected)
@@ -176,3 +178,20 @@ func TestTypeFullName(t *testing.T) {
 		util.TypeFullName(reflect.TypeOf((*interface{})(nil))),
 		"*interface {}")
 }
+
+func TestTypeGoName(t *testing.T) {
+	type anon struct{ a []int } //nolint: structcheck,unused
+
+	test.EqualStr(t, util.TypeGoName(reflect.TypeOf(123)), "int")
+	test.EqualStr(t, util.TypeGoName(reflect.TypeOf([]anon{})), "[]util_test.anon")
+	test.EqualStr(t,
+		util.TypeGoName(reflect.TypeOf(map[string]*types.RawString{})),
+		"map[string]*types.RawString")
+	test.EqualStr(t, util.TypeGoName(reflect.TypeOf(struct {
+		anon
+		B []anon
+	}{})), "struct { util_test.anon; B []util_test.anon }")
+	test.EqualStr(t,
+		util.TypeGoName(reflect.TypeOf(func(a anon, b ...anon) {})),
+		"func(util_test.anon, ...util_test.anon)")
+}
@@ -0,0 +1,258 @@
+// Copyright (c) 2021, Maxime Soulé
+// All rights reserved.
+//
+// This source code is licensed under the BSD-style license found in the
+// LICENSE file in the root directory of this source tree.
+
+// Package xml parses XML documents into a canonical tree of elements,
+// suitable for comparisons: attributes are sorted, namespace
+// declarations are removed, names are resolved against their
+// namespace URL, comments and processing instructions are ignored
+// and leading and trailing whitespaces of text contents are
+// removed.
+//
+// When parsing expected documents, text contents and attribute
+// values starting with $ are placeholders or operators ($1, $name,
+// $^NotZero, $^Name(…)) handled by the internal/json package. $$
+// escapes a leading $.
+package xml
+
+import (
+	"bytes"
+	exml "encoding/xml"
+	"io"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/maxatome/go-testdeep/internal/json"
+)
+
+// Node is an XML element.
+type Node struct {
+	Name exml.Name
+	// Attrs are sorted by namespace then local name, namespace
+	// declarations excluded
+	Attrs []Attr
+	// Text is the concatenation of the character data of the element,
+	// with leading and trailing whitespaces removed. It is always a
+	// string, except for expected documents where it can be the
+	// result of a placeholder or an operator.
+	Text     interface{}
+	Children []*Node
+}
+
+// Attr is an XML attribute. Value is a string, except for expected
+// documents where it can be the result of a placeholder or an
+// operator.
+type Attr struct {
+	Name  exml.Name
+	Value interface{}
+}
+
+// Error is an XML parsing error.
+type Error struct {
+	mesg string
+	Pos  json.Position
+}
+
+func (e *Error) Error() string {
+	return e.mesg + " " + e.Pos.String()
+}
+
+type parser struct {
+	buf     []byte
+	opts    json.ParseOpts
+	resolve bool
+}
+
+// Parse parses the XML document "buf" and returns its root *Node.
+// Text contents and attribute values starting with $ are resolved
+// using "opts", see json.ParseOpts. Note that opts.StartPos is
+// ignored.
+func Parse(buf []byte, opts ...json.ParseOpts) (interface{}, error) {
+	p := parser{buf: buf, resolve: true}
+	if len(opts) > 0 {
+		p.opts = opts[0]
+	}
+	node, err := p.parse()
+	if err != nil {
+		return nil, err
+	}
+	return node, nil
+}
+
+// ParseRaw parses the XML document "buf" and returns its root
+// *Node. Contrary to Parse, $ is not handled specially.
+func ParseRaw(buf []byte) (*Node, error) {
+	p := parser{buf: buf}
+	return p.parse()
+}
+
+func (p *parser) parse() (*Node, error) {
+	d := exml.NewDecoder(bytes.NewReader(p.buf))
+
+	var (
+		root  *Node
+		stack []*Node
+		texts []*bytes.Buffer
+		// offset of the first non-space character data of each element
+		textOffsets []int
+	)
+
+	for {
+		offset := int(d.InputOffset())
+		tok, err := d.Token()
+		if err != nil {
+			if err == io.EOF {
+				break
+			}
+			mesg := err.Error()
+			if serr, ok := err.(*exml.SyntaxError); ok {
+				mesg = serr.Msg
+			}
+			return nil, &Error{
+				mesg: mesg,
+				Pos:  p.position(int(d.InputOffset())),
+			}
+		}
+
+		switch tok := tok.(type) {
+		case exml.StartElement:
+			if root != nil && len(stack) == 0 {
+				return nil, p.error("unexpected element after root element", offset)
+			}
+
+			node := &Node{Name: tok.Name}
+			for _, attr := range tok.Attr {
+				if attr.Name.Space == "xmlns" ||
+					(attr.Name.Space == "" && attr.Name.Local == "xmlns") {
+					continue
+				}
+				value, err := p.resolveValue(attr.Value,
+					p.attrOffset(offset, int(d.InputOffset()), attr.Value))
+				if err != nil {
+					return nil, err
+				}
+				node.Attrs = append(node.Attrs, Attr{
+					Name:  attr.Name,
+					Value: value,
+				})
+			}
+			sort.Slice(node.Attrs, func(i, j int) bool {
+				a, b := node.Attrs[i].Name, node.Attrs[j].Name
+				if a.Space != b.Space {
+					return a.Space < b.Space
+				}
+				return a.Local < b.Local
+			})
+
+			if len(stack) == 0 {
+				root = node
+			} else {
+				parent := stack[len(stack)-1]
+				parent.Children = append(parent.Children, node)
+			}
+			stack = append(stack, node)
+			texts = append(texts, &bytes.Buffer{})
+			textOffsets = append(textOffsets, -1)
+
+		case exml.EndElement:
+			last := len(stack) - 1
+			node := stack[last]
+
+			text := strings.TrimSpace(texts[last].String())
+			value, err := p.resolveValue(text, textOffsets[last])
+			if err != nil {
+				return nil, err
+			}
+			node.Text = value
+
+			stack, texts, textOffsets = stack[:last], texts[:last], textOffsets[:last]
+
+		case exml.CharData:
+			if len(stack) == 0 {
+				if len(bytes.TrimSpace(tok)) > 0 {
+					return nil, p.error("unexpected character data outside root element", offset)
+				}
+				continue
+			}
+			last := len(stack) - 1
+			if textOffsets[last] < 0 {
+				if trimmed := bytes.TrimLeft(tok, " \t\r\n"); len(trimmed) > 0 {
+					textOffsets[last] = offset + len(tok) - len(trimmed)
+				}
+			}
+			texts[last].Write(tok)
+		}
+	}
+
+	if root == nil {
+		return nil, p.error("no root element found", len(p.buf))
+	}
+	return root, nil
+}
+
+// resolveValue resolves "s" if it starts with $ and p.resolve is
+// true. "offset" is the byte offset of "s" in p.buf.
+func (p *parser) resolveValue(s string, offset int) (interface{}, error) {
+	if !p.resolve || !strings.HasPrefix(s, "$") {
+		return s, nil
+	}
+
+	// $$ escapes a $
+	if strings.HasPrefix(s, "$$") {
+		return s[1:], nil
+	}
+
+	opts := p.opts
+	opts.StartPos = p.position(offset)
+	return json.Parse([]byte(s), opts)
+}
+
+// attrOffset returns the byte offset of "value" in the start tag
+// located between "start" and "end" in p.buf. As "value" has already
+// been unescaped, this offset can be approximate.
+func (p *parser) attrOffset(start, end int, value string) int {
+	if end > len(p.buf) {
+		end = len(p.buf)
+	}
+	if idx := bytes.Index(p.buf[start:end], []byte(value)); idx >= 0 {
+		return start + idx
+	}
+	return start
+}
+
+func (p *parser) error(mesg string, offset int) error {
+	return &Error{
+		mesg: mesg,
+		Pos:  p.position(offset),
+	}
+}
+
+// position returns the json.Position corresponding to byte
+// "offset" in p.buf.
+func (p *parser) position(offset int) json.Position {
+	if offset < 0 || offset > len(p.buf) {
+		offset = len(p.buf)
+	}
+
+	pos, line, col := 0, 1, 0
+	for buf := p.buf[:offset]; len(buf) > 0; pos++ {
+		r, size := utf8.DecodeRune(buf)
+		buf = buf[size:]
+		switch r {
+		case '\r':
+			if len(buf) > 0 && buf[0] == '\n' {
+				buf = buf[1:]
+			}
+			fallthrough
+		case '\n':
+			line++
+			col = 0
+		default:
+			col++
+		}
+	}
+	return json.NewPosition(pos, line, col)
+}
@@ -0,0 +1,147 @@
+// Copyright (c) 2021, Maxime Soulé
+// All rights reserved.
+//
+// This source code is licensed under the BSD-style license found in the
+// LICENSE file in the root directory of this source tree.
+
+package xml_test
+
+import (
+	exml "encoding/xml"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/davecgh/go-spew/spew"
+
+	"github.com/maxatome/go-testdeep/internal/json"
+	"github.com/maxatome/go-testdeep/internal/test"
+	"github.com/maxatome/go-testdeep/internal/xml"
+)
+
+func name(local string) exml.Name {
+	return exml.Name{Local: local}
+}
+
+func checkParse(t *testing.T, got interface{}, err error, expected *xml.Node, src string) {
+	t.Helper()
+
+	if !test.NoError(t, err, "xml.Parse succeeds") {
+		return
+	}
+
+	if !reflect.DeepEqual(got, expected) {
+		test.EqualErrorMessage(t,
+			strings.TrimRight(spew.Sdump(got), "\n"),
+			strings.TrimRight(spew.Sdump(expected), "\n"),
+			"is OK:\n%s", src,
+		)
+	}
+}
+
+func TestParse(t *testing.T) {
+	t.Run("Canonical tree", func(t *testing.T) {
+		src := `<?xml version="1.0"?>
+<!DOCTYPE feed>
+<!-- comment -->
+<f:feed xmlns:f="urn:feed" xmlns="urn:default" z="3" a="1" f:m="2">
+  <title>  Hello <![CDATA[<world>]]>  </title>
+  <entry/>
+  <?pi ignored?>
+  <f:entry>$1</f:entry>
+</f:feed>
+`
+		expected := &xml.Node{
+			Name: exml.Name{Space: "urn:feed", Local: "feed"},
+			Attrs: []xml.Attr{
+				{Name: name("a"), Value: "1"},
+				{Name: name("z"), Value: "3"},
+				{Name: exml.Name{Space: "urn:feed", Local: "m"}, Value: "2"},
+			},
+			Text: "",
+			Children: []*xml.Node{
+				{
+					Name: exml.Name{Space: "urn:default", Local: "title"},
+					Text: "Hello <world>",
+				},
+				{
+					Name: exml.Name{Space: "urn:default", Local: "entry"},
+					Text: "",
+				},
+				{
+					Name: exml.Name{Space: "urn:feed", Local: "entry"},
+					Text: "$1",
+				},
+			},
+		}
+
+		got, err := xml.ParseRaw([]byte(src))
+		checkParse(t, got, err, expected, src)
+
+		expected.Children[2].Text = "first"
+		gotIf, err := xml.Parse([]byte(src), json.ParseOpts{
+			Placeholders: []interface{}{"first"},
+		})
+		checkParse(t, gotIf, err, expected, src)
+	})
+
+	t.Run("Placeholders and operators", func(t *testing.T) {
+		opts := json.ParseOpts{
+			Placeholders: []interface{}{"first", "second"},
+			PlaceholdersByName: map[string]interface{}{
+				"name": "named",
+			},
+			OpShortcutFn: func(name string, pos json.Position) (interface{}, error) {
+				return fmt.Sprintf("shortcut %s %s", name, pos), nil
+			},
+			OpFn: func(op json.Operator, pos json.Position) (interface{}, error) {
+				return fmt.Sprintf("op %s%v %s", op.Name, op.Params, pos), nil
+			},
+		}
+
+		src := `<a x="$1" y="$name">
+  <b>$2</b>
+  <c>$^NotZero</c>
+  <d> $^Between(1, 3) </d>
+  <e v="$$1">$$2</e>
+</a>`
+		got, err := xml.Parse([]byte(src), opts)
+		checkParse(t, got, err, &xml.Node{
+			Name: name("a"),
+			Attrs: []xml.Attr{
+				{Name: name("x"), Value: "first"},
+				{Name: name("y"), Value: "named"},
+			},
+			Text: "",
+			Children: []*xml.Node{
+				{Name: name("b"), Text: "second"},
+				{Name: name("c"), Text: "shortcut NotZero at line 3:5 (pos 38)"},
+				{Name: name("d"), Text: "op ^Between[1 3] at line 4:6 (pos 58)"},
+				{
+					Name:  name("e"),
+					Attrs: []xml.Attr{{Name: name("v"), Value: "$1"}},
+					Text:  "$2",
+				},
+			},
+		}, src)
+	})
+
+	t.Run("Errors", func(t *testing.T) {
+		for i, tst := range []struct{ xml, err string }{
+			{xml: ``, err: "no root element found at line 1:0 (pos 0)"},
+			{xml: `<!-- only -->`, err: "no root element found at line 1:13 (pos 13)"},
+			{xml: "<a/>\n<b/>", err: "unexpected element after root element at line 2:0 (pos 5)"},
+			{xml: "<a/>text", err: "unexpected character data outside root element at line 1:4 (pos 4)"},
+			{xml: "<a>\n<b></a>", err: "element <b> closed by </a> at line 2:7 (pos 11)"},
+			{xml: "<a>", err: "unexpected EOF at line 1:3 (pos 3)"},
+			{xml: "<a>$1</a>", err: `numeric placeholder "$1", but no params given at line 1:3 (pos 3)`},
+			{xml: "<a\n b='$^Op(1, ])'/>", err: `syntax error: unexpected ']' at line 2:12 (pos 15)`},
+		} {
+			_, err := xml.Parse([]byte(tst.xml))
+			if test.Error(t, err, "#%d, xml.Parse fails", i) {
+				test.EqualStr(t, err.Error(), tst.err, "#%d: %q", i, tst.xml)
+			}
+		}
+	})
+}
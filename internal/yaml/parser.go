@@ -0,0 +1,1033 @@
+// Copyright (c) 2021, Maxime Soulé
+// All rights reserved.
+//
+// This source code is licensed under the BSD-style license found in the
+// LICENSE file in the root directory of this source tree.
+
+// Package yaml implements a YAML parser limited to the subset needed
+// to describe JSON-like data, extended with go-testdeep placeholders
+// and operators.
+//
+// Supported: block mappings and sequences, flow mappings and
+// sequences, plain, single-quoted and double-quoted scalars, literal
+// (|) and folded (>) block scalars, comments and a single document
+// optionally delimited by --- and .... Not supported: anchors,
+// aliases, tags, directives, complex keys and multiple documents.
+//
+// Mapping keys are always strings. Plain scalars are resolved using
+// the YAML 1.2 core schema, all numbers being encoding/json.Number as
+// for JSON, except infinities and NaN that are float64.
+//
+// Scalars starting with $ are placeholders or operator shortcuts
+// ($1, $name, $^NotZero), and $^Name(…) is an operator whose
+// parameters are expressed in JSON, all of them are handled by the
+// internal/json package. $$ escapes a leading $.
+package yaml
+
+import (
+	"bytes"
+	ejson "encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/maxatome/go-testdeep/internal/json"
+)
+
+type state struct {
+	bpos int // byte position in buf
+	pos  int // rune position in buf
+	line int // starting at 1
+	col  int // starting at 0
+}
+
+type yaml struct {
+	state
+	buf  []byte
+	opts json.ParseOpts
+}
+
+// Error is a YAML parsing error.
+type Error struct {
+	mesg string
+	Pos  json.Position
+}
+
+func (e *Error) Error() string {
+	return e.mesg + " " + e.Pos.String()
+}
+
+// parseError is used to unwind the parser when an error occurs.
+type parseError struct {
+	error
+}
+
+// Parse parses the YAML document "buf". "opts" are used to resolve
+// placeholders and operators, see json.ParseOpts. Note that
+// opts.StartPos is ignored.
+func Parse(buf []byte, opts ...json.ParseOpts) (value interface{}, err error) {
+	y := yaml{
+		state: state{line: 1},
+		buf:   buf,
+	}
+	if len(opts) > 0 {
+		y.opts = opts[0]
+	}
+
+	defer func() {
+		if e := recover(); e != nil {
+			pe, ok := e.(parseError)
+			if !ok {
+				panic(e)
+			}
+			value, err = nil, pe.error
+		}
+	}()
+
+	// Skip UTF-8 BOM
+	if bytes.HasPrefix(buf, []byte("\xef\xbb\xbf")) {
+		y.bpos = 3
+	}
+
+	return y.parseDocument(), nil
+}
+
+func (y *yaml) position() json.Position {
+	pos := json.NewPosition(y.pos, y.line, y.col)
+	pos.File = y.opts.File
+	return pos
+}
+
+func (y *yaml) mark() state {
+	return y.state
+}
+
+func (y *yaml) reset(s state) {
+	y.state = s
+}
+
+func (y *yaml) fatal(mesg string, pos ...json.Position) {
+	err := Error{
+		mesg: mesg,
+		Pos:  y.position(),
+	}
+	if len(pos) > 0 {
+		err.Pos = pos[0]
+	}
+	panic(parseError{&err})
+}
+
+func (y *yaml) eof() bool {
+	return y.bpos >= len(y.buf)
+}
+
+func (y *yaml) peek() byte {
+	return y.peekAt(0)
+}
+
+func (y *yaml) peekAt(n int) byte {
+	if y.bpos+n >= len(y.buf) {
+		return 0
+	}
+	return y.buf[y.bpos+n]
+}
+
+func (y *yaml) advance() {
+	if y.eof() {
+		return
+	}
+	switch y.buf[y.bpos] {
+	case '\r':
+		if y.peekAt(1) == '\n' {
+			y.bpos++ // \r\n counts as only one rune
+		}
+		fallthrough
+	case '\n':
+		y.bpos++
+		y.pos++
+		y.line++
+		y.col = 0
+		return
+	}
+	_, size := utf8.DecodeRune(y.buf[y.bpos:])
+	y.bpos += size
+	y.pos++
+	y.col++
+}
+
+func isBlank(c byte) bool {
+	return c == ' ' || c == '\t'
+}
+
+// isSep returns true if "c" ends a token, 0 meaning end of buffer.
+func isSep(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == 0
+}
+
+func isFlowIndicator(c byte) bool {
+	return c == ',' || c == '[' || c == ']' || c == '{' || c == '}'
+}
+
+func (y *yaml) atNewline() bool {
+	c := y.peek()
+	return c == '\n' || c == '\r'
+}
+
+func (y *yaml) atEOL() bool {
+	return y.eof() || y.atNewline()
+}
+
+func (y *yaml) skipSpaces() {
+	for isBlank(y.peek()) {
+		y.advance()
+	}
+}
+
+func (y *yaml) skipToEOL() {
+	for !y.atEOL() {
+		y.advance()
+	}
+}
+
+// skipBlank skips spaces, comments and newlines. If "block" is true,
+// tabs used for indentation are forbidden.
+func (y *yaml) skipBlank(block bool) {
+	for {
+		lineStart := y.col == 0
+		var tabPos *json.Position
+		for c := y.peek(); isBlank(c); c = y.peek() {
+			if c == '\t' && block && lineStart && tabPos == nil {
+				pos := y.position()
+				tabPos = &pos
+			}
+			y.advance()
+		}
+		if y.peek() == '#' {
+			y.skipToEOL()
+		}
+		if !y.atNewline() {
+			if tabPos != nil && !y.eof() {
+				y.fatal("tabs are not allowed for indentation", *tabPos)
+			}
+			return
+		}
+		y.advance()
+	}
+}
+
+// endOfLine checks that only spaces and an optional comment remain on
+// the current line.
+func (y *yaml) endOfLine() {
+	y.skipSpaces()
+	if y.peek() == '#' {
+		y.skipToEOL()
+	}
+	if !y.atEOL() {
+		r, _ := utf8.DecodeRune(y.buf[y.bpos:])
+		y.fatal(fmt.Sprintf("unexpected %q after value", r))
+	}
+}
+
+func (y *yaml) isDocMarker() bool {
+	return y.col == 0 &&
+		(bytes.HasPrefix(y.buf[y.bpos:], []byte("---")) ||
+			bytes.HasPrefix(y.buf[y.bpos:], []byte("..."))) &&
+		isSep(y.peekAt(3))
+}
+
+func (y *yaml) isSeqIndicator() bool {
+	return y.peek() == '-' && isSep(y.peekAt(1))
+}
+
+func (y *yaml) parseDocument() interface{} {
+	y.skipBlank(true)
+	if y.peek() == '%' && y.col == 0 {
+		y.fatal("directives are not supported")
+	}
+
+	var value interface{}
+	if y.isDocMarker() && y.peek() == '-' {
+		y.advance()
+		y.advance()
+		y.advance()
+		value = y.parseBlockValue(-1, true)
+	} else if !y.eof() && !y.isDocMarker() {
+		value = y.parseNode(-1, true)
+	}
+
+	y.skipBlank(true)
+	if y.isDocMarker() {
+		if y.peek() == '-' {
+			y.fatal("multiple documents are not supported")
+		}
+		y.advance()
+		y.advance()
+		y.advance()
+		y.skipBlank(true)
+	}
+	if !y.eof() {
+		y.fatal("unexpected content after the end of document")
+	}
+	return value
+}
+
+// parseBlockValue parses the value following a mapping key (if
+// "isMapValue" is true) or a sequence entry indicator, "parentIndent"
+// being the indentation of this key or indicator.
+func (y *yaml) parseBlockValue(parentIndent int, isMapValue bool) interface{} {
+	y.skipSpaces()
+	if y.atEOL() || y.peek() == '#' {
+		y.skipBlank(true)
+		switch {
+		case y.eof() || y.isDocMarker():
+			return nil
+		case y.col > parentIndent:
+			return y.parseNode(parentIndent, true)
+		case isMapValue && y.col == parentIndent && y.isSeqIndicator():
+			return y.parseBlockSeq(y.col)
+		}
+		return nil
+	}
+	return y.parseNode(parentIndent, !isMapValue)
+}
+
+// parseNode parses a node starting at the current position. If
+// "block" is false, the node is on the same line as a mapping key, so
+// block collections are not allowed.
+func (y *yaml) parseNode(parentIndent int, block bool) interface{} {
+	switch c := y.peek(); c {
+	case '-':
+		if y.isSeqIndicator() {
+			if !block {
+				y.fatal("sequence entries are not allowed here")
+			}
+			return y.parseBlockSeq(y.col)
+		}
+	case '|', '>':
+		return y.parseBlockScalar(parentIndent)
+	case '[', '{':
+		v := y.parseFlowNode()
+		y.endOfLine()
+		return v
+	case ']', '}', ',':
+		y.fatal(fmt.Sprintf("unexpected %q", c))
+	case '$':
+		if y.isOperatorAhead() {
+			v := y.parseOperator()
+			y.endOfLine()
+			return v
+		}
+	default:
+		y.checkIndicator()
+	}
+
+	if y.isMapKeyAhead() {
+		if !block {
+			y.fatal("mapping values are not allowed here")
+		}
+		return y.parseBlockMap(y.col)
+	}
+
+	v := y.parseScalar(parentIndent, false)
+	y.endOfLine()
+	return v
+}
+
+// checkIndicator panics if the current character is a YAML indicator
+// of an unsupported feature.
+func (y *yaml) checkIndicator() {
+	switch y.peek() {
+	case '&', '*':
+		y.fatal("anchors and aliases are not supported")
+	case '!':
+		y.fatal("tags are not supported")
+	case '?':
+		if isSep(y.peekAt(1)) {
+			y.fatal("complex mapping keys are not supported")
+		}
+	case '@', '`':
+		y.fatal(fmt.Sprintf("reserved indicator %q cannot start a plain scalar", y.peek()))
+	}
+}
+
+func (y *yaml) parseBlockMap(indent int) interface{} {
+	m := map[string]interface{}{}
+	for {
+		keyPos := y.position()
+		key := y.parseKey()
+		if _, exists := m[key]; exists {
+			y.fatal(fmt.Sprintf("duplicate key %q", key), keyPos)
+		}
+		m[key] = y.parseBlockValue(indent, true)
+
+		y.skipBlank(true)
+		if y.eof() || y.col < indent || y.isDocMarker() {
+			return m
+		}
+		if y.col > indent {
+			y.fatal("bad indentation of a mapping entry")
+		}
+		if y.isSeqIndicator() {
+			y.fatal("sequence entries are not allowed in a mapping")
+		}
+		y.checkIndicator()
+	}
+}
+
+func (y *yaml) parseBlockSeq(indent int) interface{} {
+	s := []interface{}{}
+	for {
+		y.advance() // skip -
+		s = append(s, y.parseBlockValue(indent, false))
+
+		y.skipBlank(true)
+		if y.eof() || y.col < indent || y.isDocMarker() {
+			return s
+		}
+		if y.col > indent {
+			y.fatal("bad indentation of a sequence entry")
+		}
+		if !y.isSeqIndicator() {
+			return s
+		}
+	}
+}
+
+// isMapKeyAhead returns true if a block mapping key followed by ":"
+// starts at the current position.
+func (y *yaml) isMapKeyAhead() (ok bool) {
+	saved := y.mark()
+	defer func() {
+		y.reset(saved)
+		if e := recover(); e != nil {
+			if _, isParseErr := e.(parseError); !isParseErr {
+				panic(e)
+			}
+			ok = false
+		}
+	}()
+
+	switch y.peek() {
+	case '"':
+		y.parseDoubleQuoted()
+	case '\'':
+		y.parseSingleQuoted()
+	default:
+		if y.scanPlain(false) == "" {
+			return false
+		}
+	}
+	y.skipSpaces()
+	return y.peek() == ':' && isSep(y.peekAt(1))
+}
+
+// parseKey parses a block mapping key and its following ":".
+func (y *yaml) parseKey() string {
+	var key string
+	switch y.peek() {
+	case '"':
+		key = y.parseDoubleQuoted()
+	case '\'':
+		key = y.parseSingleQuoted()
+	default:
+		key = y.scanPlain(false)
+	}
+	y.skipSpaces()
+	if y.peek() != ':' {
+		y.fatal("missing ':' after mapping key")
+	}
+	y.advance()
+	return key
+}
+
+// parseScalar parses a plain or quoted scalar. "parentIndent" is used
+// to find continuation lines of plain scalars in block context.
+func (y *yaml) parseScalar(parentIndent int, flow bool) interface{} {
+	start := y.position()
+
+	var s string
+	switch y.peek() {
+	case '"':
+		s = y.parseDoubleQuoted()
+	case '\'':
+		s = y.parseSingleQuoted()
+	default:
+		s = y.scanPlain(flow)
+		if strings.HasPrefix(s, "$") {
+			return y.resolveDollar(s, start)
+		}
+		if !flow {
+			s = y.foldPlain(s, parentIndent)
+		}
+		return resolvePlain(s)
+	}
+
+	if strings.HasPrefix(s, "$") {
+		// Skip the opening quote
+		return y.resolveDollar(s, json.NewPosition(start.Pos+1, start.Line, start.Col+1))
+	}
+	return s
+}
+
+// scanPlain returns the plain scalar starting at the current
+// position, without its trailing spaces.
+func (y *yaml) scanPlain(flow bool) string {
+	start := y.mark()
+	end := start
+	prevBlank := false
+	for !y.atEOL() {
+		c := y.peek()
+		if c == ':' {
+			n := y.peekAt(1)
+			if isSep(n) || (flow && isFlowIndicator(n)) {
+				break
+			}
+		}
+		if (c == '#' && prevBlank) || (flow && isFlowIndicator(c)) {
+			break
+		}
+		prevBlank = isBlank(c)
+		y.advance()
+		if !prevBlank {
+			end = y.mark()
+		}
+	}
+	y.reset(end)
+	return string(y.buf[start.bpos:end.bpos])
+}
+
+// foldPlain appends to "s" the continuation lines of a multi-lines
+// plain scalar, they have to be more indented than "parentIndent".
+func (y *yaml) foldPlain(s string, parentIndent int) string {
+	for {
+		saved := y.mark()
+		y.skipSpaces()
+		if !y.atNewline() {
+			y.reset(saved)
+			return s
+		}
+
+		breaks := 0
+		for y.atNewline() {
+			y.advance()
+			breaks++
+			y.skipSpaces()
+		}
+
+		if y.eof() || y.col <= parentIndent || y.isDocMarker() ||
+			y.peek() == '#' || y.isMapKeyAhead() {
+			y.reset(saved)
+			return s
+		}
+
+		next := y.scanPlain(false)
+		if breaks == 1 {
+			s += " " + next
+		} else {
+			s += strings.Repeat("\n", breaks-1) + next
+		}
+	}
+}
+
+var (
+	intRe   = regexp.MustCompile(`^[-+]?[0-9]+$`)
+	floatRe = regexp.MustCompile(`^[-+]?(?:\.[0-9]+|[0-9]+(?:\.[0-9]*)?)(?:[eE][-+]?[0-9]+)?$`)
+)
+
+// resolvePlain resolves the plain scalar "s" using the YAML 1.2 core
+// schema. Numbers are json.Number, normalized to be valid JSON
+// literals, except infinities and NaN that are float64.
+func resolvePlain(s string) interface{} {
+	switch s {
+	case "", "~", "null", "Null", "NULL":
+		return nil
+	case "true", "True", "TRUE":
+		return true
+	case "false", "False", "FALSE":
+		return false
+	case ".inf", ".Inf", ".INF", "+.inf", "+.Inf", "+.INF":
+		return math.Inf(1)
+	case "-.inf", "-.Inf", "-.INF":
+		return math.Inf(-1)
+	case ".nan", ".NaN", ".NAN":
+		return math.NaN()
+	}
+
+	if len(s) > 2 && s[0] == '0' {
+		base := 0
+		switch s[1] {
+		case 'o':
+			base = 8
+		case 'x':
+			base = 16
+		}
+		if base != 0 {
+			if n, err := strconv.ParseUint(s[2:], base, 64); err == nil {
+				return ejson.Number(strconv.FormatUint(n, 10))
+			}
+			return s
+		}
+	}
+
+	if intRe.MatchString(s) || floatRe.MatchString(s) {
+		return jsonNumber(s)
+	}
+	return s
+}
+
+// jsonNumber returns the YAML number "s" as a json.Number, so without
+// any leading "+" or useless zeros and with a digit before and after
+// the decimal point, if any.
+func jsonNumber(s string) ejson.Number {
+	var sign, exp string
+	switch s[0] {
+	case '-':
+		sign = "-"
+		fallthrough
+	case '+':
+		s = s[1:]
+	}
+	if i := strings.IndexAny(s, "eE"); i >= 0 {
+		s, exp = s[:i], s[i:]
+	}
+
+	intPart, fracPart := s, ""
+	if i := strings.IndexByte(s, '.'); i >= 0 {
+		intPart, fracPart = s[:i], s[i+1:]
+	}
+	intPart = strings.TrimLeft(intPart, "0")
+	if intPart == "" {
+		intPart = "0"
+	}
+
+	s = sign + intPart
+	if fracPart != "" {
+		s += "." + fracPart
+	}
+	return ejson.Number(s + exp)
+}
+
+// resolveDollar resolves the placeholder or operator shortcut "s"
+// located at "pos", using the internal/json parser.
+func (y *yaml) resolveDollar(s string, pos json.Position) interface{} {
+	// $$ escapes a $
+	if strings.HasPrefix(s, "$$") {
+		return s[1:]
+	}
+	return y.parseJSON([]byte(s), pos)
+}
+
+func (y *yaml) parseJSON(buf []byte, pos json.Position) interface{} {
+	opts := y.opts
+	opts.StartPos = pos
+	v, err := json.Parse(buf, opts)
+	if err != nil {
+		panic(parseError{err})
+	}
+	return v
+}
+
+// isOperatorAhead returns true if an operator $^Name( starts at the
+// current position.
+func (y *yaml) isOperatorAhead() bool {
+	if y.peek() != '$' || y.peekAt(1) != '^' {
+		return false
+	}
+	i := 2
+	for c := y.peekAt(i); (c >= 'A' && c <= 'Z') || (c >= 'a' && c <= 'z'); c = y.peekAt(i) {
+		i++
+	}
+	return i > 2 && y.peekAt(i) == '('
+}
+
+// parseOperator parses $^Name(…) operator, its parameters being
+// expressed in JSON.
+func (y *yaml) parseOperator() interface{} {
+	start := y.mark()
+	startPos := y.position()
+
+	depth := 0
+	for {
+		if y.eof() {
+			y.fatal("unterminated operator", startPos)
+		}
+		switch y.peek() {
+		case '"': // skip JSON string
+			y.advance()
+			for !y.atEOL() && y.peek() != '"' {
+				if y.peek() == '\\' {
+					y.advance()
+				}
+				y.advance()
+			}
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				y.advance()
+				return y.parseJSON(y.buf[start.bpos:y.bpos], startPos)
+			}
+		}
+		y.advance()
+	}
+}
+
+func (y *yaml) parseFlowNode() interface{} {
+	y.skipBlank(false)
+	start := y.position()
+
+	switch c := y.peek(); c {
+	case '[':
+		y.advance()
+		s := []interface{}{}
+		for {
+			y.skipBlank(false)
+			if y.peek() == ']' {
+				y.advance()
+				return s
+			}
+			s = append(s, y.parseFlowNode())
+			y.skipBlank(false)
+			switch y.peek() {
+			case ',':
+				y.advance()
+			case ']':
+				y.advance()
+				return s
+			default:
+				if y.eof() {
+					y.fatal("unterminated flow sequence", start)
+				}
+				y.fatal("expected ',' or ']' in flow sequence")
+			}
+		}
+
+	case '{':
+		y.advance()
+		m := map[string]interface{}{}
+		for {
+			y.skipBlank(false)
+			switch y.peek() {
+			case '}':
+				y.advance()
+				return m
+			case 0:
+				y.fatal("unterminated flow mapping", start)
+			}
+
+			keyPos := y.position()
+			key := y.parseFlowKey()
+			if _, exists := m[key]; exists {
+				y.fatal(fmt.Sprintf("duplicate key %q", key), keyPos)
+			}
+
+			var value interface{}
+			y.skipBlank(false)
+			if y.peek() == ':' {
+				y.advance()
+				y.skipBlank(false)
+				if c := y.peek(); c != ',' && c != '}' {
+					value = y.parseFlowNode()
+				}
+			}
+			m[key] = value
+
+			y.skipBlank(false)
+			switch y.peek() {
+			case ',':
+				y.advance()
+			case '}':
+				y.advance()
+				return m
+			default:
+				if y.eof() {
+					y.fatal("unterminated flow mapping", start)
+				}
+				y.fatal("expected ',' or '}' in flow mapping")
+			}
+		}
+
+	case ']', '}', ',', ':':
+		y.fatal(fmt.Sprintf("unexpected %q", c))
+
+	case 0:
+		y.fatal("unexpected end of document")
+
+	case '$':
+		if y.isOperatorAhead() {
+			return y.parseOperator()
+		}
+
+	default:
+		y.checkIndicator()
+	}
+
+	return y.parseScalar(-1, true)
+}
+
+func (y *yaml) parseFlowKey() string {
+	switch y.peek() {
+	case '"':
+		return y.parseDoubleQuoted()
+	case '\'':
+		return y.parseSingleQuoted()
+	}
+	y.checkIndicator()
+	key := y.scanPlain(true)
+	if key == "" {
+		y.fatal("empty mapping key")
+	}
+	return key
+}
+
+func (y *yaml) parseSingleQuoted() string {
+	start := y.position()
+	y.advance() // skip '
+
+	var b bytes.Buffer
+	for {
+		switch {
+		case y.eof():
+			y.fatal("unterminated single-quoted string", start)
+		case y.peek() == '\'':
+			y.advance()
+			if y.peek() != '\'' {
+				return b.String()
+			}
+			b.WriteByte('\'')
+			y.advance()
+		case y.atNewline():
+			y.foldQuoted(&b)
+		default:
+			bpos := y.bpos
+			y.advance()
+			b.Write(y.buf[bpos:y.bpos])
+		}
+	}
+}
+
+var simpleEscapes = map[byte]string{
+	'0':  "\x00",
+	'a':  "\a",
+	'b':  "\b",
+	't':  "\t",
+	'\t': "\t",
+	'n':  "\n",
+	'v':  "\v",
+	'f':  "\f",
+	'r':  "\r",
+	'e':  "\x1b",
+	' ':  " ",
+	'"':  `"`,
+	'/':  "/",
+	'\\': `\`,
+	'N':  "\u0085",
+	'_':  "\u00a0",
+	'L':  "\u2028",
+	'P':  "\u2029",
+}
+
+func (y *yaml) parseDoubleQuoted() string {
+	start := y.position()
+	y.advance() // skip "
+
+	var b bytes.Buffer
+	for {
+		switch {
+		case y.eof():
+			y.fatal("unterminated double-quoted string", start)
+
+		case y.peek() == '"':
+			y.advance()
+			return b.String()
+
+		case y.peek() == '\\':
+			escPos := y.position()
+			y.advance()
+			if y.atNewline() { // escaped line break
+				y.advance()
+				y.skipSpaces()
+				continue
+			}
+
+			c := y.peek()
+			y.advance()
+			if s, ok := simpleEscapes[c]; ok {
+				b.WriteString(s)
+				continue
+			}
+
+			var size int
+			switch c {
+			case 'x':
+				size = 2
+			case 'u':
+				size = 4
+			case 'U':
+				size = 8
+			default:
+				y.fatal("invalid escape sequence", escPos)
+			}
+			if y.bpos+size > len(y.buf) {
+				y.fatal("invalid escape sequence", escPos)
+			}
+			r, err := strconv.ParseUint(string(y.buf[y.bpos:y.bpos+size]), 16, 32)
+			if err != nil || !utf8.ValidRune(rune(r)) {
+				y.fatal("invalid escape sequence", escPos)
+			}
+			for ; size > 0; size-- {
+				y.advance()
+			}
+			b.WriteRune(rune(r))
+
+		case y.atNewline():
+			y.foldQuoted(&b)
+
+		default:
+			bpos := y.bpos
+			y.advance()
+			b.Write(y.buf[bpos:y.bpos])
+		}
+	}
+}
+
+// foldQuoted folds the line breaks of a multi-lines quoted scalar: a
+// single line break becomes a space, otherwise each empty line
+// becomes a newline.
+func (y *yaml) foldQuoted(b *bytes.Buffer) {
+	data := b.Bytes()
+	n := len(data)
+	for n > 0 && isBlank(data[n-1]) {
+		n--
+	}
+	b.Truncate(n)
+
+	breaks := 0
+	for y.atNewline() {
+		y.advance()
+		breaks++
+		y.skipSpaces()
+	}
+	if breaks == 1 {
+		b.WriteByte(' ')
+	} else {
+		b.WriteString(strings.Repeat("\n", breaks-1))
+	}
+}
+
+// parseBlockScalar parses a literal (|) or folded (>) block scalar,
+// "parentIndent" being the indentation of its parent node.
+func (y *yaml) parseBlockScalar(parentIndent int) string {
+	literal := y.peek() == '|'
+	y.advance()
+
+	var chomp byte
+	indent := -1
+	for i := 0; i < 2; i++ {
+		switch c := y.peek(); {
+		case (c == '-' || c == '+') && chomp == 0:
+			chomp = c
+			y.advance()
+		case c >= '1' && c <= '9' && indent < 0:
+			indent = int(c - '0')
+			if parentIndent > 0 {
+				indent += parentIndent
+			}
+			y.advance()
+		}
+	}
+	y.skipSpaces()
+	if y.peek() == '#' {
+		y.skipToEOL()
+	}
+	if !y.atEOL() {
+		y.fatal("invalid block scalar header")
+	}
+	y.advance()
+
+	var lines []string
+	for !y.eof() {
+		lineStart := y.mark()
+		n := 0
+		for y.peek() == ' ' {
+			y.advance()
+			n++
+		}
+		if y.atEOL() {
+			lines = append(lines, "")
+			y.advance()
+			continue
+		}
+		if indent < 0 {
+			if n <= parentIndent {
+				y.reset(lineStart)
+				break
+			}
+			indent = n
+		}
+		if n < indent || (n == 0 && y.isDocMarker()) {
+			y.reset(lineStart)
+			break
+		}
+		bpos := y.bpos
+		y.skipToEOL()
+		lines = append(lines,
+			strings.Repeat(" ", n-indent)+string(y.buf[bpos:y.bpos]))
+		y.advance()
+	}
+
+	trail := 0
+	for trail < len(lines) && lines[len(lines)-1-trail] == "" {
+		trail++
+	}
+	content := lines[:len(lines)-trail]
+
+	var b bytes.Buffer
+	if literal {
+		b.WriteString(strings.Join(content, "\n"))
+	} else {
+		breaks := 0
+		prev := ""
+		for _, line := range content {
+			if line == "" {
+				breaks++
+				continue
+			}
+			if prev != "" {
+				moreIndented := isBlank(line[0]) || isBlank(prev[0])
+				switch {
+				case breaks == 0 && !moreIndented:
+					b.WriteByte(' ')
+				case moreIndented:
+					breaks++
+					fallthrough
+				default:
+					b.WriteString(strings.Repeat("\n", breaks))
+				}
+			} else {
+				b.WriteString(strings.Repeat("\n", breaks))
+			}
+			b.WriteString(line)
+			prev = line
+			breaks = 0
+		}
+	}
+
+	switch chomp {
+	case '-':
+	case '+':
+		if len(content) > 0 {
+			trail++
+		}
+		b.WriteString(strings.Repeat("\n", trail))
+	default:
+		if len(content) > 0 {
+			b.WriteByte('\n')
+		}
+	}
+	return b.String()
+}
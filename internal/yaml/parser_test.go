@@ -0,0 +1,296 @@
+// Copyright (c) 2021, Maxime Soulé
+// All rights reserved.
+//
+// This source code is licensed under the BSD-style license found in the
+// LICENSE file in the root directory of this source tree.
+
+package yaml_test
+
+import (
+	ejson "encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/davecgh/go-spew/spew"
+
+	"github.com/maxatome/go-testdeep/internal/json"
+	"github.com/maxatome/go-testdeep/internal/test"
+	"github.com/maxatome/go-testdeep/internal/yaml"
+)
+
+type (
+	M = map[string]interface{}
+	S = []interface{}
+	N = ejson.Number
+)
+
+func checkParse(t *testing.T, i int, src string, expected interface{}, opts ...json.ParseOpts) {
+	t.Helper()
+
+	got, err := yaml.Parse([]byte(src), opts...)
+	if !test.NoError(t, err, "#%d, yaml.Parse succeeds", i) {
+		return
+	}
+
+	if !reflect.DeepEqual(got, expected) {
+		test.EqualErrorMessage(t,
+			strings.TrimRight(spew.Sdump(got), "\n"),
+			strings.TrimRight(spew.Sdump(expected), "\n"),
+			"#%d is OK:\n%s", i, src,
+		)
+	}
+}
+
+func TestYAML(t *testing.T) {
+	t.Run("Scalars", func(t *testing.T) {
+		for i, tst := range []struct {
+			yaml     string
+			expected interface{}
+		}{
+			{yaml: ``, expected: nil},
+			{yaml: `  # only a comment`, expected: nil},
+			{yaml: `~`, expected: nil},
+			{yaml: `null`, expected: nil},
+			{yaml: `true`, expected: true},
+			{yaml: `False`, expected: false},
+			{yaml: `123`, expected: N("123")},
+			{yaml: `-12.5e2`, expected: N("-12.5e2")},
+			{yaml: `.5`, expected: N("0.5")},
+			{yaml: `+007.`, expected: N("7")},
+			{yaml: `-00.25E+3`, expected: N("-0.25E+3")},
+			{yaml: `9007199254740993`, expected: N("9007199254740993")},
+			{yaml: `0x1F`, expected: N("31")},
+			{yaml: `0o17`, expected: N("15")},
+			{yaml: `0xZZ`, expected: "0xZZ"},
+			{yaml: `-.inf`, expected: math.Inf(-1)},
+			{yaml: `1.2.3`, expected: "1.2.3"},
+			{yaml: `foo bar  # comment`, expected: "foo bar"},
+			{yaml: `foo#bar`, expected: "foo#bar"},
+			{yaml: `http://example.com:8080/`, expected: "http://example.com:8080/"},
+			{yaml: "foo\nbar\n\n  zip", expected: "foo bar\nzip"},
+			{yaml: "- a\n  - b", expected: S{"a - b"}},
+			{yaml: `'it''s ok'`, expected: "it's ok"},
+			{yaml: `'123'`, expected: "123"},
+			{yaml: `"tab\there \"é\" \u20ac \x41 \\"`, expected: "tab\there \"é\" € A \\"},
+			{yaml: "\"multi\n  line\n\n  string\"", expected: "multi line\nstring"},
+			{yaml: "\"escaped \\\n  break\"", expected: "escaped break"},
+			{yaml: "--- foo\n...\n", expected: "foo"},
+			{yaml: "\xef\xbb\xbffoo", expected: "foo"},
+			{yaml: "$$foo", expected: "$foo"},
+			{yaml: "'$$foo'", expected: "$foo"},
+		} {
+			checkParse(t, i, tst.yaml, tst.expected)
+		}
+	})
+
+	t.Run("Block scalars", func(t *testing.T) {
+		for i, tst := range []struct {
+			yaml     string
+			expected interface{}
+		}{
+			{yaml: "|\n  a\n   b\n\n  c\n\n", expected: "a\n b\n\nc\n"},
+			{yaml: "|-\n  a\n  b\n\n", expected: "a\nb"},
+			{yaml: "|+\n  a\n  b\n\n", expected: "a\nb\n\n"},
+			{yaml: ">\n  a\n  b\n\n  c\n    d\n  e\n", expected: "a b\nc\n  d\ne\n"},
+			{yaml: "key: |2\n    a\n   b\nnext: 1", expected: M{"key": "  a\n b\n", "next": N("1")}},
+			{yaml: "key: > # comment\n  a\n  b\nnext: 1", expected: M{"key": "a b\n", "next": N("1")}},
+			{yaml: "- |\n  a\n- b", expected: S{"a\n", "b"}},
+			{yaml: "key: |\nnext: 1", expected: M{"key": "", "next": N("1")}},
+		} {
+			checkParse(t, i, tst.yaml, tst.expected)
+		}
+	})
+
+	t.Run("Collections", func(t *testing.T) {
+		for i, tst := range []struct {
+			yaml     string
+			expected interface{}
+		}{
+			{
+				yaml: `
+# A person
+name: Bob   # the name
+age: 42
+"quoted key": 'single'
+empty:
+children:
+  - name: Alice
+    age: 12
+  -   name: Brian
+      age: 10
+  -
+  - - nested
+    - seq
+tags:
+- a
+- b
+address:
+  city: Paris
+  zip:  "75001"
+`,
+				expected: M{
+					"name":       "Bob",
+					"age":        N("42"),
+					"quoted key": "single",
+					"empty":      nil,
+					"children": S{
+						M{"name": "Alice", "age": N("12")},
+						M{"name": "Brian", "age": N("10")},
+						nil,
+						S{"nested", "seq"},
+					},
+					"tags": S{"a", "b"},
+					"address": M{
+						"city": "Paris",
+						"zip":  "75001",
+					},
+				},
+			},
+			{
+				yaml: `{name: Bob, "age": 42, list: [1, "two", [3], {a: b}], empty: {}, none: [], k}`,
+				expected: M{
+					"name":  "Bob",
+					"age":   N("42"),
+					"list":  S{N("1"), "two", S{N("3")}, M{"a": "b"}},
+					"empty": M{},
+					"none":  S{},
+					"k":     nil,
+				},
+			},
+			{
+				yaml: `{"name":"Bob","list":[1,2]}`,
+				expected: M{
+					"name": "Bob",
+					"list": S{N("1"), N("2")},
+				},
+			},
+			{
+				yaml: `
+key: [
+  1,  # one
+  2,
+]
+other: {
+  a: 1
+}
+`,
+				expected: M{
+					"key":   S{N("1"), N("2")},
+					"other": M{"a": N("1")},
+				},
+			},
+			{
+				yaml: `
+---
+- a
+- b: 1
+  c: 2
+...
+`,
+				expected: S{"a", M{"b": N("1"), "c": N("2")}},
+			},
+			{
+				yaml:     "a:\r\n  b: 1\r\n",
+				expected: M{"a": M{"b": N("1")}},
+			},
+		} {
+			checkParse(t, i, tst.yaml, tst.expected)
+		}
+	})
+
+	t.Run("Placeholders and operators", func(t *testing.T) {
+		opts := json.ParseOpts{
+			Placeholders: []interface{}{"first", "second"},
+			PlaceholdersByName: map[string]interface{}{
+				"name": "named",
+			},
+			OpShortcutFn: func(name string, pos json.Position) (interface{}, error) {
+				return fmt.Sprintf("shortcut %s %s", name, pos), nil
+			},
+			OpFn: func(op json.Operator, pos json.Position) (interface{}, error) {
+				return fmt.Sprintf("op %s%v %s", op.Name, op.Params, pos), nil
+			},
+		}
+
+		checkParse(t, 0, `
+a: $1
+b: "$2"
+c: $name
+d: $^NotZero
+e: [$^Between(1, 3), '$^Between(2, 4)']
+f: $^Re("^(a|b)$")   # comment
+g: $^All(
+    Gt(1),
+    Lt($1)
+  )
+`,
+			M{
+				"a": "first",
+				"b": "second",
+				"c": "named",
+				"d": "shortcut NotZero at line 5:3 (pos 27)",
+				"e": S{
+					"op ^Between[1 3] at line 6:4 (pos 41)",
+					"op ^Between[2 4] at line 6:22 (pos 59)",
+				},
+				"f": "op ^Re[^(a|b)$] at line 7:3 (pos 80)",
+				"g": "op ^All[op Gt[1] at line 9:4 (pos 122) op Lt[first] at line 10:4 (pos 133)] at line 8:3 (pos 111)",
+			},
+			opts)
+	})
+
+	t.Run("Errors", func(t *testing.T) {
+		for i, tst := range []struct{ yaml, err string }{
+			{yaml: "a: 1\n  b: 2", err: "bad indentation of a mapping entry at line 2:2 (pos 7)"},
+			{yaml: "a:\n  - x\n - y", err: "bad indentation of a mapping entry at line 3:1 (pos 10)"},
+			{yaml: "- [a]\n  - b", err: "bad indentation of a sequence entry at line 2:2 (pos 8)"},
+			{yaml: "a: 1\n- b", err: "sequence entries are not allowed in a mapping at line 2:0 (pos 5)"},
+			{yaml: "a: b: c", err: "mapping values are not allowed here at line 1:3 (pos 3)"},
+			{yaml: "a: - b", err: "sequence entries are not allowed here at line 1:3 (pos 3)"},
+			{yaml: "a: 1\na: 2", err: `duplicate key "a" at line 2:0 (pos 5)`},
+			{yaml: "{a: 1, a: 2}", err: `duplicate key "a" at line 1:7 (pos 7)`},
+			{yaml: "a:\n\tb: 1", err: "tabs are not allowed for indentation at line 2:0 (pos 3)"},
+			{yaml: `"unterminated`, err: "unterminated double-quoted string at line 1:0 (pos 0)"},
+			{yaml: `'unterminated`, err: "unterminated single-quoted string at line 1:0 (pos 0)"},
+			{yaml: `"bad \q escape"`, err: "invalid escape sequence at line 1:5 (pos 5)"},
+			{yaml: `"bad \u12 escape"`, err: "invalid escape sequence at line 1:5 (pos 5)"},
+			{yaml: `[1, 2`, err: "unterminated flow sequence at line 1:0 (pos 0)"},
+			{yaml: `["a" "b"]`, err: "expected ',' or ']' in flow sequence at line 1:5 (pos 5)"},
+			{yaml: `{a: 1`, err: "unterminated flow mapping at line 1:0 (pos 0)"},
+			{yaml: `{a: "1" b}`, err: "expected ',' or '}' in flow mapping at line 1:8 (pos 8)"},
+			{yaml: `[1, ]]`, err: `unexpected ']' after value at line 1:5 (pos 5)`},
+			{yaml: `[,]`, err: `unexpected ',' at line 1:1 (pos 1)`},
+			{yaml: `a: &anchor 1`, err: "anchors and aliases are not supported at line 1:3 (pos 3)"},
+			{yaml: `*alias`, err: "anchors and aliases are not supported at line 1:0 (pos 0)"},
+			{yaml: `!!str 1`, err: "tags are not supported at line 1:0 (pos 0)"},
+			{yaml: `? complex`, err: "complex mapping keys are not supported at line 1:0 (pos 0)"},
+			{yaml: "%YAML 1.2\n---\na", err: "directives are not supported at line 1:0 (pos 0)"},
+			{yaml: "a\n---\nb", err: "multiple documents are not supported at line 2:0 (pos 2)"},
+			{yaml: "a\n...\nb", err: "unexpected content after the end of document at line 3:0 (pos 6)"},
+			{yaml: "|x\n  a", err: "invalid block scalar header at line 1:1 (pos 1)"},
+			{yaml: "a: $^Op(1, 2", err: "unterminated operator at line 1:3 (pos 3)"},
+			{yaml: "a: $1", err: `numeric placeholder "$1", but no params given at line 1:3 (pos 3)`},
+			{yaml: "a:\n  - '$1'", err: `numeric placeholder "$1", but no params given at line 2:5 (pos 8)`},
+			{yaml: "a: $^Op(1, ])", err: `syntax error: unexpected ']' at line 1:11 (pos 11)`},
+		} {
+			_, err := yaml.Parse([]byte(tst.yaml))
+			if test.Error(t, err, "#%d, yaml.Parse fails", i) {
+				test.EqualStr(t, err.Error(), tst.err, "#%d: %q", i, tst.yaml)
+			}
+		}
+
+		// Errors are located in the file the document comes from
+		opts := json.ParseOpts{File: "doc.yaml"}
+		_, err := yaml.Parse([]byte("a: 1\na: 2"), opts)
+		if test.Error(t, err) {
+			test.EqualStr(t, err.Error(), `duplicate key "a" at line 2:0 (pos 5) in doc.yaml`)
+		}
+		_, err = yaml.Parse([]byte("a: $^Op(1, ])"), opts)
+		if test.Error(t, err) {
+			test.EqualStr(t, err.Error(), `syntax error: unexpected ']' at line 1:11 (pos 11) in doc.yaml`)
+		}
+	})
+}
@@ -9,6 +9,7 @@ package td
 import (
 	"bytes"
 	"fmt"
+	"os"
 	"reflect"
 	"strings"
 
@@ -17,6 +18,7 @@ import (
 	"github.com/maxatome/go-testdeep/internal/ctxerr"
 	"github.com/maxatome/go-testdeep/internal/flat"
 	"github.com/maxatome/go-testdeep/internal/trace"
+	"github.com/maxatome/go-testdeep/internal/util"
 )
 
 func init() {
@@ -80,6 +82,16 @@ func stripTrace(s trace.Stack) trace.Stack {
 		return s[:len(s)-1]
 	}
 
+	// Remove (*T).RunGoroutines() goroutine stack
+	//
+	// ✓ xxx     TestWorkers.func1
+	// ✗ …/td    (*T).RunGoroutines.func1
+	// ✗ runtime goexit
+	if s.Match(-1, "runtime", "goexit") &&
+		s.Match(-2, tdPkg, "(*T).RunGoroutines.func*") {
+		return s[:len(s)-2]
+	}
+
 	// Remove testing.Cleanup() stack
 	//
 	// ✓ xxx     TestCleanup.func2
@@ -115,6 +127,23 @@ func stripTrace(s trace.Stack) trace.Stack {
 
 func formatError(t TestingT, isFatal bool, err *ctxerr.Error, args ...interface{}) {
 	t.Helper()
+	formatErrorInfo(t, isFatal, err, nil, args...)
+}
+
+// failureInfo holds the root got and expected values of a failure,
+// used to enrich its report.
+type failureInfo struct {
+	got, expected interface{}
+	gotAsGo       bool
+}
+
+// formatErrorInfo works as formatError but uses "info", if non-nil,
+// to append additional information to the failure report, just after
+// the errors and before the stack trace. If "info" is nil, got and
+// expected values of "err" are used when dumping them.
+func formatErrorInfo(t TestingT, isFatal bool, err *ctxerr.Error,
+	info *failureInfo, args ...interface{}) {
+	t.Helper()
 
 	const failedTest = "Failed test"
 
@@ -133,6 +162,16 @@ func formatError(t TestingT, isFatal bool, err *ctxerr.Error, args ...interface{
 
 	err.Append(&buf, "")
 
+	if info == nil {
+		info = &failureInfo{got: err.Got, expected: err.Expected}
+	}
+	if info.gotAsGo {
+		appendGotAsGo(&buf, reflect.ValueOf(info.got))
+	}
+	if dir := os.Getenv(envDumpDir); dir != "" {
+		appendDump(&buf, t, dir, dumpName(t, args), info.got, info.expected)
+	}
+
 	// Stask trace
 	if s := stripTrace(trace.Retrieve(0, "testing.tRunner")); len(s) > 1 {
 		buf.WriteString("\nThis is how we got here:\n")
@@ -161,17 +200,37 @@ func formatError(t TestingT, isFatal bool, err *ctxerr.Error, args ...interface{
 
 func cmpDeeply(ctx ctxerr.Context, t TestingT, got, expected interface{},
 	args ...interface{}) bool {
-	err := deepValueEqualFinal(ctx,
-		reflect.ValueOf(got), reflect.ValueOf(expected))
+	vgot, vexpected := reflect.ValueOf(got), reflect.ValueOf(expected)
+
+	var err *ctxerr.Error
+	if doc, ok := jsonDiffDoc(ctx, vgot); ok {
+		err = jsonDiff(ctx, doc, func(ctx ctxerr.Context) *ctxerr.Error {
+			return deepValueEqual(ctx, vgot, vexpected)
+		})
+		if err == nil {
+			// Try to merge pending error
+			err = ctx.MergeErrors()
+		}
+	} else {
+		err = deepValueEqualFinal(ctx, vgot, vexpected)
+	}
 	if err == nil {
 		return true
 	}
 
 	t.Helper()
-	formatError(t, ctx.FailureIsFatal, err, args...)
+	formatErrorInfo(t, ctx.FailureIsFatal, err,
+		&failureInfo{got: got, expected: expected, gotAsGo: ctx.GotAsGo},
+		args...)
 	return false
 }
 
+// appendGotAsGo appends "got" rendered as Go source code to "buf".
+func appendGotAsGo(buf *bytes.Buffer, got reflect.Value) {
+	buf.WriteString("\nGot as Go:\n\t")
+	util.IndentStringIn(buf, util.GoLiteral(got), "\t")
+}
+
 // Cmp returns true if "got" matches "expected". "expected" can
 // be the same type as "got" is, or contains some TestDeep
 // operators. If "got" does not match "expected", it returns false and
@@ -12,7 +12,7 @@ import (
 	"time"
 )
 
-// allOperators lists the 61 operators.
+// allOperators lists the 69 operators.
 // nil means not usable in JSON().
 var allOperators = map[string]interface{}{
 	"All":         All,
@@ -22,7 +22,7 @@ var allOperators = map[string]interface{}{
 	"Bag":         Bag,
 	"Between":     Between,
 	"Cap":         nil,
-	"Catch":       nil,
+	"Catch":       Catch,
 	"Code":        nil,
 	"Contains":    Contains,
 	"ContainsKey": ContainsKey,
@@ -35,7 +35,10 @@ var allOperators = map[string]interface{}{
 	"Ignore":      Ignore,
 	"Isa":         nil,
 	"JSON":        nil,
+	"JSONLines":   JSONLines,
+	"JSONPath":    JSONPath,
 	"JSONPointer": JSONPointer,
+	"JSONSchema":  JSONSchema,
 	"Keys":        Keys,
 	"Lax":         nil,
 	"Len":         Len,
@@ -57,24 +60,29 @@ var allOperators = map[string]interface{}{
 	"Ptr":         nil,
 	"Re":          Re,
 	"ReAll":       ReAll,
+	"SJSONPath":   SJSONPath,
 	"SStruct":     nil,
 	"Set":         Set,
 	"Shallow":     nil,
 	"Slice":       nil,
-	"Smuggle":     nil,
-	"String":      nil,
+	"Smuggle":     Smuggle,
+	"String":      String,
 	"Struct":      nil,
 	"SubBagOf":    SubBagOf,
 	"SubJSONOf":   nil,
 	"SubMapOf":    SubMapOf,
 	"SubSetOf":    SubSetOf,
+	"SubYAMLOf":   nil,
 	"SuperBagOf":  SuperBagOf,
 	"SuperJSONOf": nil,
 	"SuperMapOf":  SuperMapOf,
 	"SuperSetOf":  SuperSetOf,
-	"Tag":         nil,
+	"SuperYAMLOf": nil,
+	"Tag":         Tag,
 	"TruncTime":   nil,
 	"Values":      Values,
+	"XML":         nil,
+	"YAML":        nil,
 	"Zero":        Zero,
 }
 
@@ -405,6 +413,44 @@ func CmpJSON(t TestingT, got, expectedJSON interface{}, params []interface{}, ar
 	return Cmp(t, got, JSON(expectedJSON, params...), args...)
 }
 
+// CmpJSONLines is a shortcut for:
+//
+//   td.Cmp(t, got, td.JSONLines(expectedValue), args...)
+//
+// See https://pkg.go.dev/github.com/maxatome/go-testdeep/td#JSONLines for details.
+//
+// Returns true if the test is OK, false if it fails.
+//
+// "args..." are optional and allow to name the test. This name is
+// used in case of failure to qualify the test. If len(args) > 1 and
+// the first item of "args" is a string and contains a '%' rune then
+// fmt.Fprintf is used to compose the name, else "args" are passed to
+// fmt.Fprint. Do not forget it is the name of the test, not the
+// reason of a potential failure.
+func CmpJSONLines(t TestingT, got, expectedValue interface{}, args ...interface{}) bool {
+	t.Helper()
+	return Cmp(t, got, JSONLines(expectedValue), args...)
+}
+
+// CmpJSONPath is a shortcut for:
+//
+//   td.Cmp(t, got, td.JSONPath(expr, expectedValue), args...)
+//
+// See https://pkg.go.dev/github.com/maxatome/go-testdeep/td#JSONPath for details.
+//
+// Returns true if the test is OK, false if it fails.
+//
+// "args..." are optional and allow to name the test. This name is
+// used in case of failure to qualify the test. If len(args) > 1 and
+// the first item of "args" is a string and contains a '%' rune then
+// fmt.Fprintf is used to compose the name, else "args" are passed to
+// fmt.Fprint. Do not forget it is the name of the test, not the
+// reason of a potential failure.
+func CmpJSONPath(t TestingT, got interface{}, expr string, expectedValue interface{}, args ...interface{}) bool {
+	t.Helper()
+	return Cmp(t, got, JSONPath(expr, expectedValue), args...)
+}
+
 // CmpJSONPointer is a shortcut for:
 //
 //   td.Cmp(t, got, td.JSONPointer(pointer, expectedValue), args...)
@@ -424,6 +470,25 @@ func CmpJSONPointer(t TestingT, got interface{}, pointer string, expectedValue i
 	return Cmp(t, got, JSONPointer(pointer, expectedValue), args...)
 }
 
+// CmpJSONSchema is a shortcut for:
+//
+//   td.Cmp(t, got, td.JSONSchema(schema), args...)
+//
+// See https://pkg.go.dev/github.com/maxatome/go-testdeep/td#JSONSchema for details.
+//
+// Returns true if the test is OK, false if it fails.
+//
+// "args..." are optional and allow to name the test. This name is
+// used in case of failure to qualify the test. If len(args) > 1 and
+// the first item of "args" is a string and contains a '%' rune then
+// fmt.Fprintf is used to compose the name, else "args" are passed to
+// fmt.Fprint. Do not forget it is the name of the test, not the
+// reason of a potential failure.
+func CmpJSONSchema(t TestingT, got, schema interface{}, args ...interface{}) bool {
+	t.Helper()
+	return Cmp(t, got, JSONSchema(schema), args...)
+}
+
 // CmpKeys is a shortcut for:
 //
 //   td.Cmp(t, got, td.Keys(val), args...)
@@ -869,6 +934,25 @@ func CmpShallow(t TestingT, got, expectedPtr interface{}, args ...interface{}) b
 	return Cmp(t, got, Shallow(expectedPtr), args...)
 }
 
+// CmpSJSONPath is a shortcut for:
+//
+//   td.Cmp(t, got, td.SJSONPath(expr, expectedValue), args...)
+//
+// See https://pkg.go.dev/github.com/maxatome/go-testdeep/td#SJSONPath for details.
+//
+// Returns true if the test is OK, false if it fails.
+//
+// "args..." are optional and allow to name the test. This name is
+// used in case of failure to qualify the test. If len(args) > 1 and
+// the first item of "args" is a string and contains a '%' rune then
+// fmt.Fprintf is used to compose the name, else "args" are passed to
+// fmt.Fprint. Do not forget it is the name of the test, not the
+// reason of a potential failure.
+func CmpSJSONPath(t TestingT, got interface{}, expr string, expectedValue interface{}, args ...interface{}) bool {
+	t.Helper()
+	return Cmp(t, got, SJSONPath(expr, expectedValue), args...)
+}
+
 // CmpSlice is a shortcut for:
 //
 //   td.Cmp(t, got, td.Slice(model, expectedEntries), args...)
@@ -1040,6 +1124,25 @@ func CmpSubSetOf(t TestingT, got interface{}, expectedItems []interface{}, args
 	return Cmp(t, got, SubSetOf(expectedItems...), args...)
 }
 
+// CmpSubYAMLOf is a shortcut for:
+//
+//   td.Cmp(t, got, td.SubYAMLOf(expectedYAML, params...), args...)
+//
+// See https://pkg.go.dev/github.com/maxatome/go-testdeep/td#SubYAMLOf for details.
+//
+// Returns true if the test is OK, false if it fails.
+//
+// "args..." are optional and allow to name the test. This name is
+// used in case of failure to qualify the test. If len(args) > 1 and
+// the first item of "args" is a string and contains a '%' rune then
+// fmt.Fprintf is used to compose the name, else "args" are passed to
+// fmt.Fprint. Do not forget it is the name of the test, not the
+// reason of a potential failure.
+func CmpSubYAMLOf(t TestingT, got, expectedYAML interface{}, params []interface{}, args ...interface{}) bool {
+	t.Helper()
+	return Cmp(t, got, SubYAMLOf(expectedYAML, params...), args...)
+}
+
 // CmpSuperBagOf is a shortcut for:
 //
 //   td.Cmp(t, got, td.SuperBagOf(expectedItems...), args...)
@@ -1116,6 +1219,25 @@ func CmpSuperSetOf(t TestingT, got interface{}, expectedItems []interface{}, arg
 	return Cmp(t, got, SuperSetOf(expectedItems...), args...)
 }
 
+// CmpSuperYAMLOf is a shortcut for:
+//
+//   td.Cmp(t, got, td.SuperYAMLOf(expectedYAML, params...), args...)
+//
+// See https://pkg.go.dev/github.com/maxatome/go-testdeep/td#SuperYAMLOf for details.
+//
+// Returns true if the test is OK, false if it fails.
+//
+// "args..." are optional and allow to name the test. This name is
+// used in case of failure to qualify the test. If len(args) > 1 and
+// the first item of "args" is a string and contains a '%' rune then
+// fmt.Fprintf is used to compose the name, else "args" are passed to
+// fmt.Fprint. Do not forget it is the name of the test, not the
+// reason of a potential failure.
+func CmpSuperYAMLOf(t TestingT, got, expectedYAML interface{}, params []interface{}, args ...interface{}) bool {
+	t.Helper()
+	return Cmp(t, got, SuperYAMLOf(expectedYAML, params...), args...)
+}
+
 // CmpTruncTime is a shortcut for:
 //
 //   td.Cmp(t, got, td.TruncTime(expectedTime, trunc), args...)
@@ -1158,6 +1280,44 @@ func CmpValues(t TestingT, got, val interface{}, args ...interface{}) bool {
 	return Cmp(t, got, Values(val), args...)
 }
 
+// CmpXML is a shortcut for:
+//
+//   td.Cmp(t, got, td.XML(expectedXML, params...), args...)
+//
+// See https://pkg.go.dev/github.com/maxatome/go-testdeep/td#XML for details.
+//
+// Returns true if the test is OK, false if it fails.
+//
+// "args..." are optional and allow to name the test. This name is
+// used in case of failure to qualify the test. If len(args) > 1 and
+// the first item of "args" is a string and contains a '%' rune then
+// fmt.Fprintf is used to compose the name, else "args" are passed to
+// fmt.Fprint. Do not forget it is the name of the test, not the
+// reason of a potential failure.
+func CmpXML(t TestingT, got, expectedXML interface{}, params []interface{}, args ...interface{}) bool {
+	t.Helper()
+	return Cmp(t, got, XML(expectedXML, params...), args...)
+}
+
+// CmpYAML is a shortcut for:
+//
+//   td.Cmp(t, got, td.YAML(expectedYAML, params...), args...)
+//
+// See https://pkg.go.dev/github.com/maxatome/go-testdeep/td#YAML for details.
+//
+// Returns true if the test is OK, false if it fails.
+//
+// "args..." are optional and allow to name the test. This name is
+// used in case of failure to qualify the test. If len(args) > 1 and
+// the first item of "args" is a string and contains a '%' rune then
+// fmt.Fprintf is used to compose the name, else "args" are passed to
+// fmt.Fprint. Do not forget it is the name of the test, not the
+// reason of a potential failure.
+func CmpYAML(t TestingT, got, expectedYAML interface{}, params []interface{}, args ...interface{}) bool {
+	t.Helper()
+	return Cmp(t, got, YAML(expectedYAML, params...), args...)
+}
+
 // CmpZero is a shortcut for:
 //
 //   td.Cmp(t, got, td.Zero(), args...)
@@ -0,0 +1,259 @@
+// Copyright (c) 2021, Maxime Soulé
+// All rights reserved.
+//
+// This source code is licensed under the BSD-style license found in the
+// LICENSE file in the root directory of this source tree.
+
+package td
+
+import (
+	"reflect"
+	"strings"
+
+	"github.com/maxatome/go-testdeep/internal/color"
+	"github.com/maxatome/go-testdeep/internal/ctxerr"
+	"github.com/maxatome/go-testdeep/internal/dark"
+	"github.com/maxatome/go-testdeep/internal/types"
+)
+
+// PathSegmentKind is the kind of a PathSegment.
+type PathSegmentKind uint8
+
+const (
+	// PathField is a struct field access, as in DATA.Field.
+	PathField PathSegmentKind = iota
+	// PathIndex is an array or slice index access, as in DATA[12].
+	PathIndex
+	// PathMapKey is a map key access, as in DATA["key"].
+	PathMapKey
+	// PathFunc is a function call, as in len(DATA).
+	PathFunc
+	// PathCustom is a custom level, as the root one (DATA) or one
+	// added by an operator like JSONPointer or Smuggle.
+	PathCustom
+)
+
+// String implements fmt.Stringer.
+func (k PathSegmentKind) String() string {
+	switch k {
+	case PathField:
+		return "field"
+	case PathIndex:
+		return "index"
+	case PathMapKey:
+		return "map key"
+	case PathFunc:
+		return "function"
+	case PathCustom:
+		return "custom"
+	default:
+		return "?"
+	}
+}
+
+// PathSegment is one level of the path leading to a Mismatch.
+type PathSegment struct {
+	// Kind is the kind of this level.
+	Kind PathSegmentKind
+	// Name is the struct field name for PathField, the index for
+	// PathIndex, the stringified key for PathMapKey, the function
+	// name for PathFunc or the raw level for PathCustom.
+	Name string
+	// JSONName is the json struct tag name (or the Go field name if
+	// no tag) for PathField, "" for an embedded struct whose fields
+	// are promoted; and the raw key for PathMapKey.
+	JSONName string
+	// Pointers is the number of pointer dereferences done at this level.
+	Pointers int
+}
+
+// Mismatch describes one difference found by Compare.
+type Mismatch struct {
+	// Path is the path of the mismatch, as DATA.Items[3].Name,
+	// rendered according to ContextConfig.PathStyle.
+	Path string
+	// Segments is Path split in typed levels. The first segment is
+	// always the root one (PathCustom kind).
+	Segments []PathSegment
+	// Message describes the mismatch, as "values differ".
+	Message string
+	// Got is the got value, if any. Note that it can be a string
+	// representing the got value, as a type name for a type mismatch.
+	Got interface{}
+	// Expected is the expected value, if any. Note that it can be a
+	// string representing the expected value, as a type name for a
+	// type mismatch.
+	Expected interface{}
+	// GotString is the representation of Got, as displayed in
+	// failure reports. It is empty if Summary is not empty.
+	GotString string
+	// ExpectedString is the representation of Expected, as displayed
+	// in failure reports. It is empty if Summary is not empty.
+	ExpectedString string
+	// Summary replaces GotString and ExpectedString for some
+	// mismatches, as missing or extra items.
+	Summary string
+	// Location is the location of the operator originator of the
+	// mismatch, as "Between at file.go:23". It is empty if the
+	// mismatch does not come from an operator.
+	Location string
+	// Origin is the mismatch this one comes from, if any.
+	Origin *Mismatch
+}
+
+// Diff is the result of Compare. It lists all mismatches found
+// between got and expected values.
+type Diff struct {
+	// Mismatches contains all the mismatches found, in the order they
+	// were encountered.
+	Mismatches []Mismatch
+	// TooManyErrors is true if the comparison stopped before its end
+	// as ContextConfig.MaxErrors mismatches were found.
+	TooManyErrors bool
+
+	err *ctxerr.Error
+}
+
+// IsEmpty returns true if no mismatches were found, so if got
+// matches expected.
+func (d Diff) IsEmpty() bool {
+	return d.err == nil
+}
+
+// Err returns nil if no mismatches were found, else an error whose
+// message is the same as the one reported by Cmp in case of failure.
+func (d Diff) Err() error {
+	if d.err == nil {
+		return nil
+	}
+	return d.err
+}
+
+// String implements fmt.Stringer. It returns the same report as the
+// one returned by Cmp in case of failure, or "" if no mismatches were
+// found.
+func (d Diff) String() string {
+	if d.err == nil {
+		return ""
+	}
+	return d.err.Error()
+}
+
+// Compare returns the differences found between "got" and
+// "expected". "expected" can be the same type as "got" is, or
+// contains some TestDeep operators. Contrary to Cmp, it does not need
+// a testing.TB and so can be used outside tests, for example in
+// configuration validators or contract checkers.
+//
+//   diff := td.Compare(got, td.JSON(`{"name": "Bob", "age": Gt(18)}`))
+//   if !diff.IsEmpty() {
+//     for _, m := range diff.Mismatches {
+//       fmt.Printf("%s: %s\n", m.Path, m.Message)
+//     }
+//   }
+//
+// "config" is an optional argument and, if passed, must be
+// unique. It allows to configure the comparison as for Cmp, so
+// MaxErrors, UseEqual, BeLax and RootName are honored. As hooks are
+// recorded in a *T Config, passing it allows to use them too:
+//
+//   t := td.NewT(tt).WithCmpHooks((time.Time).Equal)
+//   diff := td.Compare(got, expected, t.Config)
+//
+// If "config" is omitted, DefaultContextConfig is used.
+func Compare(got, expected interface{}, config ...ContextConfig) Diff {
+	var ctx ctxerr.Context
+	switch len(config) {
+	case 0:
+		ctx = newContext()
+	case 1:
+		ctx = newContextWithConfig(config[0])
+	default:
+		panic(color.TooManyParams("Compare(GOT, EXPECTED[, ContextConfig])"))
+	}
+
+	return newDiff(deepValueEqualFinal(ctx,
+		reflect.ValueOf(got), reflect.ValueOf(expected)))
+}
+
+func newDiff(err *ctxerr.Error) Diff {
+	diff := Diff{err: err}
+	for ; err != nil; err = err.Next {
+		if err == ctxerr.ErrTooManyErrors {
+			diff.TooManyErrors = true
+			break
+		}
+		diff.Mismatches = append(diff.Mismatches, newMismatch(err))
+	}
+	return diff
+}
+
+func newMismatch(err *ctxerr.Error) Mismatch {
+	path := err.Context.PathString()
+
+	m := Mismatch{
+		Path:     path,
+		Segments: pathSegments(err.Context.Path),
+		Message:  strings.Replace(err.Message, "%%", path, 1),
+	}
+
+	if err.Summary != nil {
+		m.Summary = err.SummaryString()
+	} else {
+		m.Got, m.Expected = mismatchValue(err.Got), mismatchValue(err.Expected)
+		m.GotString, m.ExpectedString = err.GotString(), err.ExpectedString()
+	}
+
+	if err.Location.IsInitialized() {
+		m.Location = err.Location.String()
+	}
+
+	if err.Origin != nil {
+		origin := newMismatch(err.Origin)
+		m.Origin = &origin
+	}
+
+	return m
+}
+
+func mismatchValue(v interface{}) interface{} {
+	switch tv := v.(type) {
+	case reflect.Value:
+		if vi, ok := dark.GetInterface(tv, true); ok {
+			return vi
+		}
+	case types.RawString:
+		return string(tv)
+	case types.RawInt:
+		return int(tv)
+	}
+	return v
+}
+
+func pathSegments(path ctxerr.Path) []PathSegment {
+	if len(path) == 0 {
+		return nil
+	}
+
+	segments := make([]PathSegment, len(path))
+	for i, level := range path {
+		segments[i] = PathSegment{
+			Name:     level.Content,
+			JSONName: level.JSONName,
+			Pointers: level.Pointers,
+		}
+		switch level.Kind {
+		case ctxerr.LevelStruct:
+			segments[i].Kind = PathField
+		case ctxerr.LevelArray:
+			segments[i].Kind = PathIndex
+		case ctxerr.LevelMap:
+			segments[i].Kind = PathMapKey
+		case ctxerr.LevelFunc:
+			segments[i].Kind = PathFunc
+		default:
+			segments[i].Kind = PathCustom
+		}
+	}
+	return segments
+}
@@ -0,0 +1,196 @@
+// Copyright (c) 2021, Maxime Soulé
+// All rights reserved.
+//
+// This source code is licensed under the BSD-style license found in the
+// LICENSE file in the root directory of this source tree.
+
+package td_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/maxatome/go-testdeep/internal/test"
+	"github.com/maxatome/go-testdeep/td"
+)
+
+func TestCompare(t *testing.T) {
+	type Item struct {
+		Name  string
+		Price *int
+	}
+	type Cart struct {
+		Items []Item
+		Tags  map[string]bool
+	}
+
+	price := func(n int) *int { return &n }
+
+	got := Cart{
+		Items: []Item{
+			{Name: "foo", Price: price(12)},
+			{Name: "bar", Price: price(13)},
+		},
+		Tags: map[string]bool{"x": true},
+	}
+
+	t.Run("OK", func(t *testing.T) {
+		diff := td.Compare(got, td.Struct(Cart{}, td.StructFields{
+			"Items": td.Len(2),
+		}))
+		test.IsTrue(t, diff.IsEmpty())
+		test.NoError(t, diff.Err())
+		test.EqualStr(t, diff.String(), "")
+		test.EqualInt(t, len(diff.Mismatches), 0)
+		test.IsFalse(t, diff.TooManyErrors)
+	})
+
+	t.Run("Several mismatches", func(t *testing.T) {
+		diff := td.Compare(got,
+			Cart{
+				Items: []Item{
+					{Name: "foo", Price: price(12)},
+					{Name: "buz", Price: price(14)},
+				},
+				Tags: map[string]bool{"x": false},
+			},
+			td.ContextConfig{MaxErrors: -1})
+
+		test.IsFalse(t, diff.IsEmpty())
+		test.Error(t, diff.Err())
+		test.IsTrue(t, strings.Contains(diff.String(), "DATA.Items[1].Name: values differ"))
+		test.IsFalse(t, diff.TooManyErrors)
+
+		if !test.EqualInt(t, len(diff.Mismatches), 3) {
+			return
+		}
+
+		m := diff.Mismatches[0]
+		test.EqualStr(t, m.Path, "DATA.Items[1].Name")
+		test.EqualStr(t, m.Message, "values differ")
+		test.EqualStr(t, m.GotString, `"bar"`)
+		test.EqualStr(t, m.ExpectedString, `"buz"`)
+		test.IsTrue(t, m.Got == "bar")
+		test.IsTrue(t, m.Expected == "buz")
+		test.EqualStr(t, m.Location, "")
+		test.IsTrue(t, m.Origin == nil)
+		if test.EqualInt(t, len(m.Segments), 4) {
+			test.IsTrue(t, m.Segments[0] == td.PathSegment{Kind: td.PathCustom, Name: "DATA"})
+			test.IsTrue(t, m.Segments[1] == td.PathSegment{Kind: td.PathField, Name: "Items", JSONName: "Items"})
+			test.IsTrue(t, m.Segments[2] == td.PathSegment{Kind: td.PathIndex, Name: "1"})
+			test.IsTrue(t, m.Segments[3] == td.PathSegment{Kind: td.PathField, Name: "Name", JSONName: "Name"})
+		}
+
+		m = diff.Mismatches[1]
+		test.EqualStr(t, m.Path, "*DATA.Items[1].Price")
+		test.EqualStr(t, m.GotString, "13")
+		test.EqualStr(t, m.ExpectedString, "14")
+		if test.EqualInt(t, len(m.Segments), 4) {
+			test.IsTrue(t, m.Segments[3] == td.PathSegment{
+				Kind:     td.PathField,
+				Name:     "Price",
+				JSONName: "Price",
+				Pointers: 1,
+			})
+		}
+
+		m = diff.Mismatches[2]
+		test.EqualStr(t, m.Path, `DATA.Tags["x"]`)
+		if test.EqualInt(t, len(m.Segments), 3) {
+			test.IsTrue(t, m.Segments[2] == td.PathSegment{
+				Kind:     td.PathMapKey,
+				Name:     `"x"`,
+				JSONName: "x",
+			})
+		}
+	})
+
+	t.Run("Summary, operator & type mismatch", func(t *testing.T) {
+		diff := td.Compare([]int{1, 2, 3}, td.Bag(1, 2, 4))
+		if test.EqualInt(t, len(diff.Mismatches), 1) {
+			m := diff.Mismatches[0]
+			test.EqualStr(t, m.Message, "comparing DATA as a Bag")
+			test.IsTrue(t, m.Got == nil)
+			test.EqualStr(t, m.GotString, "")
+			test.IsTrue(t, strings.Contains(m.Summary, "Missing item: (4)"))
+			test.IsTrue(t, strings.HasPrefix(m.Location, "Bag at compare_test.go:"))
+		}
+
+		diff = td.Compare(12, "12")
+		if test.EqualInt(t, len(diff.Mismatches), 1) {
+			m := diff.Mismatches[0]
+			test.EqualStr(t, m.Message, "type mismatch")
+			test.IsTrue(t, m.Got == "int")
+			test.IsTrue(t, m.Expected == "string")
+		}
+	})
+
+	t.Run("MaxErrors", func(t *testing.T) {
+		diff := td.Compare([]int{1, 2, 3, 4}, []int{5, 6, 7, 8},
+			td.ContextConfig{MaxErrors: 2})
+		test.EqualInt(t, len(diff.Mismatches), 2)
+		test.IsTrue(t, diff.TooManyErrors)
+
+		diff = td.Compare([]int{1, 2, 3, 4}, []int{5, 6, 7, 8},
+			td.ContextConfig{MaxErrors: 1})
+		test.EqualInt(t, len(diff.Mismatches), 1)
+		test.IsFalse(t, diff.TooManyErrors)
+	})
+
+	t.Run("Config", func(t *testing.T) {
+		test.IsFalse(t, td.Compare(int64(12), 12).IsEmpty())
+		test.IsTrue(t, td.Compare(int64(12), 12, td.ContextConfig{BeLax: true}).IsEmpty())
+
+		d1 := time.Date(2021, 4, 1, 12, 0, 0, 0, time.UTC)
+		d2 := d1.In(time.FixedZone("X", 3600))
+		test.IsFalse(t, td.Compare(d1, d2).IsEmpty())
+		test.IsTrue(t, td.Compare(d1, d2, td.ContextConfig{UseEqual: true}).IsEmpty())
+
+		tt := td.NewT(t).WithCmpHooks((time.Time).Equal)
+		test.IsTrue(t, td.Compare(d1, d2, tt.Config).IsEmpty())
+
+		diff := td.Compare(1, 2, td.ContextConfig{RootName: "CONF"})
+		if test.EqualInt(t, len(diff.Mismatches), 1) {
+			test.EqualStr(t, diff.Mismatches[0].Path, "CONF")
+		}
+
+		type Tagged struct {
+			Field int `json:"field"`
+		}
+		diff = td.Compare(Tagged{1}, Tagged{2},
+			td.ContextConfig{PathStyle: td.PathStyleJSONPointer})
+		if test.EqualInt(t, len(diff.Mismatches), 1) {
+			test.EqualStr(t, diff.Mismatches[0].Path, "DATA/field")
+			test.EqualStr(t, diff.Mismatches[0].Segments[1].Name, "Field")
+			test.EqualStr(t, diff.Mismatches[0].Segments[1].JSONName, "field")
+		}
+	})
+
+	t.Run("Origin", func(t *testing.T) {
+		diff := td.Compare(12, td.Smuggle(func(n int) (int, error) {
+			return n, nil
+		}, td.Between(20, 30)))
+		if test.EqualInt(t, len(diff.Mismatches), 1) {
+			test.EqualStr(t, diff.Mismatches[0].Path, "DATA<smuggled>")
+			if test.EqualInt(t, len(diff.Mismatches[0].Segments), 2) {
+				test.IsTrue(t, diff.Mismatches[0].Segments[1] == td.PathSegment{
+					Kind: td.PathCustom,
+					Name: "<smuggled>",
+				})
+			}
+		}
+	})
+
+	test.CheckPanic(t, func() { td.Compare(1, 1, td.ContextConfig{}, td.ContextConfig{}) },
+		"usage: Compare(")
+}
+
+func TestPathSegmentKind(t *testing.T) {
+	test.EqualStr(t, td.PathField.String(), "field")
+	test.EqualStr(t, td.PathIndex.String(), "index")
+	test.EqualStr(t, td.PathMapKey.String(), "map key")
+	test.EqualStr(t, td.PathFunc.String(), "function")
+	test.EqualStr(t, td.PathCustom.String(), "custom")
+	test.EqualStr(t, td.PathSegmentKind(100).String(), "?")
+}
@@ -8,17 +8,37 @@ package td
 
 import (
 	"os"
+	"reflect"
 	"strconv"
+	"strings"
+	"sync"
 
 	"github.com/maxatome/go-testdeep/internal/anchors"
+	"github.com/maxatome/go-testdeep/internal/color"
 	"github.com/maxatome/go-testdeep/internal/ctxerr"
 	"github.com/maxatome/go-testdeep/internal/hooks"
+	"github.com/maxatome/go-testdeep/internal/trace"
 	"github.com/maxatome/go-testdeep/internal/visited"
 )
 
 // ContextConfig allows to configure finely how tests failures are rendered.
 //
 // See NewT function to use it.
+//
+// Two environment variables, not configurable here, are also taken
+// into account when a test fails:
+//   - TESTDEEP_DUMP_DIR: if set, got and expected values of each
+//     failing assertion are dumped into this directory, as JSON when
+//     possible, else using spew. Files are named from the test name
+//     and the assertion name, and their paths appear in the failure
+//     report;
+//   - TESTDEEP_DIFF_TOOL: if set with TESTDEEP_DUMP_DIR, this command
+//     is launched in background on dumped files, after having
+//     replaced %got and %expected placeholders by their paths, as in
+//     "meld %got %expected". It is not waited for, so a GUI diff tool
+//     does not block the test, and its output goes to the standard
+//     output and error of the test. As in a shell, arguments
+//     containing spaces can be single- or double-quoted.
 type ContextConfig struct {
 	// RootName is the string used to represent the root of got data. It
 	// defaults to "DATA". For an HTTP response body, it could be "BODY"
@@ -60,6 +80,276 @@ type ContextConfig struct {
 	// function/method and Lax operator to set this flag without
 	// providing a specific configuration.
 	BeLax bool
+	// PathStyle allows to choose how paths are rendered in failure
+	// reports. See PathStyle type for details.
+	//
+	// It defaults to PathStyleDefault (so Go style) except if the
+	// environment variable TESTDEEP_PATH_STYLE is set to "go",
+	// "json-tags" or "json-pointer".
+	PathStyle PathStyle
+	// GotAsGo allows to additionally print, when a Cmp* fails, the got
+	// value rendered as gofmt'ed Go source code, ready to be pasted as
+	// an expected value. It is typically useful when bootstrapping
+	// expectations.
+	//
+	// It defaults to false except if the environment variable
+	// TESTDEEP_GOT_AS_GO is set to a true value as "1" or "true".
+	GotAsGo bool
+	// JSONDiff allows to render failures of JSON, SubJSONOf,
+	// SuperJSONOf, YAML, SubYAMLOf and SuperYAMLOf operators as the
+	// got JSON document, indented, in which mismatching members are
+	// highlighted and annotated with the reason of the mismatch and the
+	// expected value. If the got value of a Cmp* function can be
+	// represented as a JSON object or array, the whole comparison
+	// failure is rendered this way, as tdhttp CmpJSONBody does.
+	//
+	// It defaults to false except if the environment variable
+	// TESTDEEP_JSON_DIFF is set to a true value as "1" or "true".
+	JSONDiff bool
+	// PreferStringer allows to use Error() or String() method, if any,
+	// to render got and expected values (as well as their contents) in
+	// failure reports, instead of go-spew default rendering.
+	//
+	// Note that DefaultContextConfig.PreferStringer also applies to
+	// operators String() output. See also AddFormatters function and
+	// WithFormatters method to render specific types.
+	PreferStringer bool
+	// PathRules allows to replace, during the comparison, the expected
+	// value of each path matching a rule by the expected value of this
+	// rule, typically Ignore() or any other operator. It allows to
+	// compare plain expected values while ignoring or specifically
+	// checking some parts of them. When several rules match a same
+	// path, the last one wins. See PathRule type for details and
+	// IgnorePaths and OverridePath methods of *T. An invalid pattern
+	// makes NewT panic, or the first comparison using it if the
+	// config is not passed through NewT.
+	PathRules []PathRule
+	// FloatAbsTolerance allows float32, float64, complex64 and
+	// complex128 values (as well as real and imaginary parts of
+	// complex ones) to be considered equal if the absolute value of
+	// their difference is lower or equal to FloatAbsTolerance.
+	//
+	// It applies everywhere in got and expected values, including
+	// inside maps, slices or JSON decoded values, but not to operators
+	// having their own logic as N, Between or Gt for example.
+	FloatAbsTolerance float64
+	// FloatRelTolerance works as FloatAbsTolerance, but the absolute
+	// value of the difference is compared to FloatRelTolerance times
+	// the greatest absolute value of both compared values. 1e-9 means
+	// values are considered equal if they differ by at most 1e-7%.
+	FloatRelTolerance float64
+	// FloatULPTolerance works as FloatAbsTolerance, but considers
+	// values equal if there are at most FloatULPTolerance
+	// representable floats between them, a ULP being the "unit in
+	// the last place". Note that ULPs of float32 and float64 values
+	// differ.
+	//
+	// If several tolerances are set, values are considered equal as
+	// soon as one of them is satisfied.
+	FloatULPTolerance uint
+	// NaNEqual allows to consider NaN float values as equal, contrary
+	// to what Go does.
+	NaNEqual bool
+	// NilEqualsEmpty allows to consider nil and empty slices (resp.
+	// maps) as equal, as encoding/json or databases round-trips often
+	// turn one into the other. If false (default), such cases are
+	// reported as:
+	//
+	//   DATA.Tags: nil slice
+	//        got: nil
+	//   expected: empty, use NilEqualsEmpty to ignore
+	//
+	// (resp. "nil map"). When the non-nil side is not empty, "not nil"
+	// is displayed instead, as NilEqualsEmpty does not apply.
+	NilEqualsEmpty bool
+	// UnexportedFields allows to choose whether unexported struct
+	// fields are compared or not, when structs are compared
+	// directly. Struct and SStruct operators are not concerned, as
+	// they always compare the fields they are given. See
+	// UnexportedFields type for details.
+	UnexportedFields UnexportedFields
+	// UnorderedSlices allows to compare some or all slices and arrays
+	// regardless of the order of their items, as Bag operator does,
+	// including when the expected value is a plain one. See
+	// UnorderedSlices type for details.
+	UnorderedSlices UnorderedSlices
+	// Hooks contains sets of hooks, typically shared between packages,
+	// used during comparisons. Hooks of a set take precedence over
+	// hooks of previous sets, and hooks recorded on a *T instance (using
+	// WithCmpHooks for example) take precedence over all of them. If
+	// nil, DefaultContextConfig.Hooks is used. See Hooks type for
+	// details.
+	Hooks []*Hooks
+}
+
+// UnorderedSlices defines which slices and arrays are compared
+// regardless of the order of their items, as Bag operator does. A
+// slice or an array is concerned as soon as one of the following
+// conditions is true.
+type UnorderedSlices struct {
+	// All means all slices and arrays are concerned.
+	All bool
+	// Paths contains path patterns of concerned slices and arrays, as
+	// "DATA.Items" or "DATA.**.Tags". See PathRule type for patterns
+	// syntax.
+	Paths []string
+	// ElemTypes contains the types of items of concerned slices and
+	// arrays.
+	ElemTypes []reflect.Type
+}
+
+// compile compiles "u" into its ctxerr counterpart, or returns nil if
+// no slices are concerned. It panics if a path pattern is invalid.
+func (u UnorderedSlices) compile() *ctxerr.Unordered {
+	if !u.All && len(u.Paths) == 0 && len(u.ElemTypes) == 0 {
+		return nil
+	}
+	unordered := ctxerr.Unordered{
+		All:       u.All,
+		ElemTypes: u.ElemTypes,
+	}
+	for _, path := range u.Paths {
+		pattern, err := parsePathPattern(path)
+		if err != nil {
+			panic(color.Bad("invalid unordered slices path %q: %s", path, err))
+		}
+		unordered.Patterns = append(unordered.Patterns, pattern)
+	}
+	return &unordered
+}
+
+// UnexportedFields defines how unexported struct fields are compared.
+//
+// There is no specific error message for unexported fields: a
+// mismatch in a compared unexported field is reported as for an
+// exported one, whatever its depth, the path naming the field as in:
+//
+//   DATA.secret: values differ
+//        got: 1
+//   expected: 2
+//
+// and ignored fields never produce errors.
+type UnexportedFields uint8
+
+const (
+	// CompareUnexported means unexported fields are compared as
+	// exported ones. It is the default.
+	CompareUnexported UnexportedFields = iota
+	// IgnoreUnexported means unexported fields are never compared.
+	IgnoreUnexported
+	// IgnoreForeignUnexported means unexported fields declared in
+	// other packages than the one of the test function are not
+	// compared. Note that a test function of package foo_test is
+	// considered as belonging to package foo. If the package of the
+	// test function cannot be determined, as when *T is not created
+	// from a test function, it acts as IgnoreUnexported.
+	IgnoreForeignUnexported
+)
+
+// PathRule associates a path pattern to an expected value. During a
+// comparison, each time the current path matches Path, Expected is
+// used instead of the original expected value.
+//
+// Path uses the Go syntax of paths displayed in failure reports, as
+// in "DATA.Items[3].ID", plus:
+//   - ".*" matching any struct field;
+//   - "[*]" matching any array/slice index or map key;
+//   - ".**" matching zero or more levels, whatever they are.
+//
+// The root name, "DATA" by default, must match ContextConfig.RootName.
+// A struct field matches its Go name as well as its json tag name. A
+// map key matches its rendering in paths, as in ["key"], as well as
+// its raw string value, as in [key]. Pointer dereferences are
+// ignored. So
+//   []td.PathRule{
+//     {Path: "DATA.Items[*].ID", Expected: td.Ignore()},
+//     {Path: "DATA.**.UpdatedAt", Expected: td.Between(begin, end)},
+//   }
+// ignores the ID field of each item of Items and checks all
+// UpdatedAt fields are in the [begin .. end] range, whatever their
+// depth.
+type PathRule struct {
+	Path     string
+	Expected interface{}
+}
+
+// pathPatterns caches the already parsed path patterns, so a pattern
+// is parsed only once whatever the number of comparisons using it.
+var pathPatterns = struct {
+	sync.Mutex
+	parsed map[string]ctxerr.PathPattern
+}{
+	parsed: map[string]ctxerr.PathPattern{},
+}
+
+// parsePathPattern returns the parsed "pattern", using the
+// pathPatterns cache.
+func parsePathPattern(pattern string) (ctxerr.PathPattern, error) {
+	pathPatterns.Lock()
+	defer pathPatterns.Unlock()
+
+	pp, ok := pathPatterns.parsed[pattern]
+	if !ok {
+		var err error
+		pp, err = ctxerr.ParsePathPattern(pattern)
+		if err != nil {
+			return nil, err
+		}
+		pathPatterns.parsed[pattern] = pp
+	}
+	return pp, nil
+}
+
+// compilePathRules compiles "rules" into their ctxerr counterpart. It
+// panics if a path pattern is invalid.
+func compilePathRules(rules []PathRule) []ctxerr.PathRule {
+	if len(rules) == 0 {
+		return nil
+	}
+	compiled := make([]ctxerr.PathRule, len(rules))
+	for i, rule := range rules {
+		pattern, err := parsePathPattern(rule.Path)
+		if err != nil {
+			panic(color.Bad("invalid path rule %q: %s", rule.Path, err))
+		}
+		compiled[i] = ctxerr.PathRule{
+			Pattern:  pattern,
+			Expected: reflect.ValueOf(rule.Expected),
+		}
+	}
+	return compiled
+}
+
+// PathStyle defines how paths are rendered in failure reports.
+type PathStyle uint8
+
+const (
+	// PathStyleDefault means no style has been explicitly chosen. Go
+	// style is then used, except in some contexts as tdhttp
+	// CmpJSONBody where JSON pointer style is preferred.
+	PathStyleDefault PathStyle = iota
+	// PathStyleGo renders paths using Go syntax and Go field names, as
+	// in DATA.Items[3].CreatedAt.
+	PathStyleGo
+	// PathStyleJSONTags renders paths using Go syntax but json struct
+	// tag names instead of Go field names, as in
+	// DATA.items[3].created_at. Pointers are not rendered.
+	PathStyleJSONTags
+	// PathStyleJSONPointer renders paths as RFC 6901 JSON pointers
+	// using json struct tag names, following the root name, as in
+	// DATA/items/3/created_at. Pointers are not rendered.
+	PathStyleJSONPointer
+)
+
+func (s PathStyle) ctxerr() ctxerr.PathStyle {
+	switch s {
+	case PathStyleJSONTags:
+		return ctxerr.PathStyleJSONTags
+	case PathStyleJSONPointer:
+		return ctxerr.PathStyleJSONPointer
+	default:
+		return ctxerr.PathStyleGo
+	}
 }
 
 // Equal returns true if both ContextConfig are equal. Only public
@@ -69,13 +359,29 @@ func (c ContextConfig) Equal(o ContextConfig) bool {
 		c.MaxErrors == o.MaxErrors &&
 		c.FailureIsFatal == o.FailureIsFatal &&
 		c.UseEqual == o.UseEqual &&
-		c.BeLax == o.BeLax
+		c.BeLax == o.BeLax &&
+		c.PathStyle == o.PathStyle &&
+		c.GotAsGo == o.GotAsGo &&
+		c.JSONDiff == o.JSONDiff &&
+		c.PreferStringer == o.PreferStringer &&
+		reflect.DeepEqual(c.PathRules, o.PathRules) &&
+		c.FloatAbsTolerance == o.FloatAbsTolerance &&
+		c.FloatRelTolerance == o.FloatRelTolerance &&
+		c.FloatULPTolerance == o.FloatULPTolerance &&
+		c.NaNEqual == o.NaNEqual &&
+		c.NilEqualsEmpty == o.NilEqualsEmpty &&
+		c.UnexportedFields == o.UnexportedFields &&
+		reflect.DeepEqual(c.UnorderedSlices, o.UnorderedSlices) &&
+		sameHooks(c.Hooks, o.Hooks)
 }
 
 const (
 	contextDefaultRootName = "DATA"
 	contextPanicRootName   = "FUNCTION"
 	envMaxErrors           = "TESTDEEP_MAX_ERRORS"
+	envPathStyle           = "TESTDEEP_PATH_STYLE"
+	envGotAsGo             = "TESTDEEP_GOT_AS_GO"
+	envJSONDiff            = "TESTDEEP_JSON_DIFF"
 )
 
 func getMaxErrorsFromEnv() int {
@@ -89,6 +395,29 @@ func getMaxErrorsFromEnv() int {
 	return 10
 }
 
+func getPathStyleFromEnv() PathStyle {
+	switch os.Getenv(envPathStyle) {
+	case "go":
+		return PathStyleGo
+	case "json-tags":
+		return PathStyleJSONTags
+	case "json-pointer":
+		return PathStyleJSONPointer
+	default:
+		return PathStyleDefault
+	}
+}
+
+func getGotAsGoFromEnv() bool {
+	b, _ := strconv.ParseBool(os.Getenv(envGotAsGo))
+	return b
+}
+
+func getJSONDiffFromEnv() bool {
+	b, _ := strconv.ParseBool(os.Getenv(envJSONDiff))
+	return b
+}
+
 // DefaultContextConfig is the default configuration used to render
 // tests failures. If overridden, new settings will impact all Cmp*
 // functions and *T methods (if not specifically configured.)
@@ -98,6 +427,9 @@ var DefaultContextConfig = ContextConfig{
 	FailureIsFatal: false,
 	UseEqual:       false,
 	BeLax:          false,
+	PathStyle:      getPathStyleFromEnv(),
+	GotAsGo:        getGotAsGoFromEnv(),
+	JSONDiff:       getJSONDiffFromEnv(),
 }
 
 func (c *ContextConfig) sanitize() {
@@ -107,6 +439,12 @@ func (c *ContextConfig) sanitize() {
 	if c.MaxErrors == 0 {
 		c.MaxErrors = DefaultContextConfig.MaxErrors
 	}
+	if c.PathStyle == PathStyleDefault {
+		c.PathStyle = DefaultContextConfig.PathStyle
+	}
+	if c.Hooks == nil {
+		c.Hooks = DefaultContextConfig.Hooks
+	}
 }
 
 // newContext creates a new ctxerr.Context using DefaultContextConfig
@@ -125,22 +463,53 @@ func newContextWithConfig(config ContextConfig) (ctx ctxerr.Context) {
 		Visited:        visited.NewVisited(),
 		MaxErrors:      config.MaxErrors,
 		Anchors:        config.anchors,
-		Hooks:          config.hooks,
+		Hooks:          hooksWithSets(config.Hooks, config.hooks),
 		FailureIsFatal: config.FailureIsFatal,
 		UseEqual:       config.UseEqual,
 		BeLax:          config.BeLax,
+		PathStyle:      config.PathStyle.ctxerr(),
+		GotAsGo:        config.GotAsGo,
+		JSONDiff:       config.JSONDiff,
+		PreferStringer: config.PreferStringer,
+		PathRules:      compilePathRules(config.PathRules),
+		FloatTolerance: ctxerr.FloatTolerance{
+			Abs: config.FloatAbsTolerance,
+			Rel: config.FloatRelTolerance,
+			ULP: config.FloatULPTolerance,
+		},
+		NaNEqual:         config.NaNEqual,
+		NilEqualsEmpty:   config.NilEqualsEmpty,
+		Unordered:        config.UnorderedSlices.compile(),
+		IgnoreUnexported: config.UnexportedFields != CompareUnexported,
+	}
+	if config.UnexportedFields == IgnoreForeignUnexported {
+		ctx.LocalPackage = testPackage()
 	}
 
 	ctx.InitErrors()
 	return
 }
 
-// newBooleanContext creates a new boolean ctxerr.Context.
+// testPackage returns the package of the first caller outside
+// go-testdeep, typically the test function one. A "_test" suffix is
+// removed, so external test packages are seen as the tested one.
+func testPackage() string {
+	s := trace.Retrieve(0, "testing.tRunner")
+	if len(s) == 0 {
+		return ""
+	}
+	return strings.TrimSuffix(s[0].Package, "_test")
+}
+
+// newBooleanContext creates a new boolean ctxerr.Context using
+// DefaultContextConfig configuration, so comparison settings are
+// the same as the ones of newContext.
 func newBooleanContext() ctxerr.Context {
-	return ctxerr.Context{
-		Visited:      visited.NewVisited(),
-		BooleanError: true,
-		UseEqual:     DefaultContextConfig.UseEqual,
-		BeLax:        DefaultContextConfig.BeLax,
+	ctx := newContext()
+	ctx.BooleanError = true
+	// Paths are useless in boolean context, except to match path rules
+	if !ctx.UsesPath() {
+		ctx.Path = nil
 	}
+	return ctx
 }
@@ -50,3 +50,88 @@ func TestGetMaxErrorsFromEnv(t *testing.T) {
 	os.Setenv(envMaxErrors, "-8")
 	test.EqualInt(t, getMaxErrorsFromEnv(), -8)
 }
+
+func TestGetPathStyleFromEnv(t *testing.T) {
+	oldEnv, set := os.LookupEnv(envPathStyle)
+	defer func() {
+		if set {
+			os.Setenv(envPathStyle, oldEnv)
+		} else {
+			os.Unsetenv(envPathStyle)
+		}
+	}()
+
+	os.Setenv(envPathStyle, "")
+	test.IsTrue(t, getPathStyleFromEnv() == PathStyleDefault)
+
+	os.Setenv(envPathStyle, "aaa")
+	test.IsTrue(t, getPathStyleFromEnv() == PathStyleDefault)
+
+	os.Setenv(envPathStyle, "go")
+	test.IsTrue(t, getPathStyleFromEnv() == PathStyleGo)
+
+	os.Setenv(envPathStyle, "json-tags")
+	test.IsTrue(t, getPathStyleFromEnv() == PathStyleJSONTags)
+
+	os.Setenv(envPathStyle, "json-pointer")
+	test.IsTrue(t, getPathStyleFromEnv() == PathStyleJSONPointer)
+}
+
+func TestGetGotAsGoFromEnv(t *testing.T) {
+	oldEnv, set := os.LookupEnv(envGotAsGo)
+	defer func() {
+		if set {
+			os.Setenv(envGotAsGo, oldEnv)
+		} else {
+			os.Unsetenv(envGotAsGo)
+		}
+	}()
+
+	os.Setenv(envGotAsGo, "")
+	test.IsFalse(t, getGotAsGoFromEnv())
+
+	os.Setenv(envGotAsGo, "aaa")
+	test.IsFalse(t, getGotAsGoFromEnv())
+
+	os.Setenv(envGotAsGo, "1")
+	test.IsTrue(t, getGotAsGoFromEnv())
+
+	os.Setenv(envGotAsGo, "true")
+	test.IsTrue(t, getGotAsGoFromEnv())
+}
+
+func TestGetJSONDiffFromEnv(t *testing.T) {
+	oldEnv, set := os.LookupEnv(envJSONDiff)
+	defer func() {
+		if set {
+			os.Setenv(envJSONDiff, oldEnv)
+		} else {
+			os.Unsetenv(envJSONDiff)
+		}
+	}()
+
+	os.Setenv(envJSONDiff, "")
+	test.IsFalse(t, getJSONDiffFromEnv())
+
+	os.Setenv(envJSONDiff, "aaa")
+	test.IsFalse(t, getJSONDiffFromEnv())
+
+	os.Setenv(envJSONDiff, "1")
+	test.IsTrue(t, getJSONDiffFromEnv())
+
+	os.Setenv(envJSONDiff, "true")
+	test.IsTrue(t, getJSONDiffFromEnv())
+}
+
+func TestParsePathPattern(t *testing.T) {
+	pp1, err := parsePathPattern("DATA.**.ID")
+	test.NoError(t, err)
+	pp2, err := parsePathPattern("DATA.**.ID")
+	test.NoError(t, err)
+	test.IsTrue(t, &pp1[0] == &pp2[0], "pattern parsed only once")
+
+	_, err = parsePathPattern("DATA[")
+	test.Error(t, err)
+	_, cached := pathPatterns.parsed["DATA["]
+	test.IsFalse(t, cached)
+}
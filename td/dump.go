@@ -0,0 +1,273 @@
+// Copyright (c) 2021, Maxime Soulé
+// All rights reserved.
+//
+// This source code is licensed under the BSD-style license found in the
+// LICENSE file in the root directory of this source tree.
+
+package td
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/maxatome/go-testdeep/helpers/tdutil"
+	"github.com/maxatome/go-testdeep/internal/types"
+)
+
+const (
+	// If set, got and expected values of each failing assertion are
+	// dumped in files in this directory.
+	envDumpDir = "TESTDEEP_DUMP_DIR"
+	// If set, command launched on dumped files, as "meld %got %expected".
+	envDiffTool = "TESTDEEP_DIFF_TOOL"
+
+	dumpNameMaxLen = 100
+)
+
+var dumpNames = struct {
+	sync.Mutex
+	m map[string]int
+}{
+	m: map[string]int{},
+}
+
+// dumpName returns the base name of dump files, built from the name
+// of "t", if available, and the test name built from "args".
+func dumpName(t TestingT, args []interface{}) string {
+	var name string
+	if tn, ok := t.(interface{ Name() string }); ok {
+		name = tn.Name()
+	}
+	if testName := tdutil.BuildTestName(args...); testName != "" {
+		if name != "" {
+			name += "-"
+		}
+		name += testName
+	}
+
+	buf := make([]byte, 0, len(name))
+	for i := 0; i < len(name) && len(buf) < dumpNameMaxLen; i++ {
+		c := name[i]
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9',
+			c == '.', c == '-', c == '_':
+			buf = append(buf, c)
+		default:
+			buf = append(buf, '_')
+		}
+	}
+	if len(buf) == 0 {
+		return "failure"
+	}
+	return string(buf)
+}
+
+// appendDump dumps "got" and "expected" in "dir" directory, in files
+// whose names are based on "name", then appends the dump file paths
+// to "buf". If the environment variable TESTDEEP_DIFF_TOOL is set, the
+// corresponding command is launched on these files, see
+// appendDiffTool.
+func appendDump(buf *bytes.Buffer, t TestingT, dir, name string, got, expected interface{}) {
+	// Several failures can have the same name in a same test
+	dumpNames.Lock()
+	key := filepath.Join(dir, name)
+	num := dumpNames.m[key]
+	dumpNames.m[key]++
+	dumpNames.Unlock()
+
+	// Forget the name at the end of the test, so a new run of the
+	// same test (as with go test -count=N) overwrites the same files
+	if num == 0 {
+		if tc, ok := t.(interface{ Cleanup(func()) }); ok {
+			tc.Cleanup(func() {
+				dumpNames.Lock()
+				delete(dumpNames.m, key)
+				dumpNames.Unlock()
+			})
+		}
+	}
+	if num > 0 {
+		name += "-" + strconv.Itoa(num+1)
+	}
+
+	gotFile, err := dumpValue(dir, name+".got", got)
+	var expectedFile string
+	if err == nil {
+		expectedFile, err = dumpValue(dir, name+".expected", expected)
+	}
+	if err != nil {
+		buf.WriteString("\nCannot dump got and expected values: ")
+		buf.WriteString(err.Error())
+		return
+	}
+
+	buf.WriteString("\nGot and expected values dumped to:\n\t     got: ")
+	buf.WriteString(gotFile)
+	buf.WriteString("\n\texpected: ")
+	buf.WriteString(expectedFile)
+
+	if tool := os.Getenv(envDiffTool); tool != "" {
+		appendDiffTool(buf, tool, gotFile, expectedFile)
+	}
+}
+
+// dumpValue writes "v" in a file in "dir" directory. The file name
+// is "base" followed by ".json" if "v" can be JSON marshaled, else by
+// ".txt". It returns the path of the written file.
+func dumpValue(dir, base string, v interface{}) (string, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+
+	var (
+		data []byte
+		ext  = ".txt"
+	)
+	switch tv := v.(type) {
+	case types.TestDeepStringer:
+		data = []byte(tv.String())
+	case error:
+		// Most errors have no exported fields, so would be dumped as {}
+		data = []byte(tdutil.SpewString(v))
+	default:
+		var err error
+		data, err = json.MarshalIndent(v, "", "  ")
+		if err == nil && (string(data) != "{}" || isEmptyMapOrStruct(v)) {
+			ext = ".json"
+		} else {
+			data = []byte(tdutil.SpewString(v))
+		}
+	}
+
+	file := filepath.Join(dir, base+ext)
+	return file, ioutil.WriteFile(file, append(data, '\n'), 0644)
+}
+
+// isEmptyMapOrStruct returns true if "v", or what it points to, is
+// an empty map or a struct without any field, so is legitimately
+// JSON marshaled as {}.
+func isEmptyMapOrStruct(v interface{}) bool {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Ptr || rv.Kind() == reflect.Interface {
+		rv = rv.Elem()
+	}
+	switch rv.Kind() {
+	case reflect.Map:
+		return rv.Len() == 0
+	case reflect.Struct:
+		return rv.NumField() == 0
+	}
+	return false
+}
+
+// appendDiffTool launches in background the "tool" command after
+// having replaced %got and %expected placeholders by "gotFile" and
+// "expectedFile", then appends the launched command to "buf". As the
+// command is not waited for, a GUI diff tool does not block the
+// test. Its output goes to the standard output and error of the test.
+//
+// As in a shell, arguments of "tool" are separated by spaces, and
+// can be quoted using single or double quotes, or escaped using
+// backslashes.
+func appendDiffTool(buf *bytes.Buffer, tool, gotFile, expectedFile string) {
+	args, err := splitCommand(tool)
+	if err != nil {
+		buf.WriteString("\nCannot launch ")
+		buf.WriteString(envDiffTool)
+		buf.WriteString(" command: ")
+		buf.WriteString(err.Error())
+		return
+	}
+
+	repl := strings.NewReplacer("%got", gotFile, "%expected", expectedFile)
+	for i, arg := range args {
+		args[i] = repl.Replace(arg)
+	}
+
+	cmd := exec.Command(args[0], args[1:]...) //nolint: gosec
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Start(); err != nil {
+		buf.WriteString("\nCannot launch ")
+		buf.WriteString(envDiffTool)
+		buf.WriteString(" command: ")
+		buf.WriteString(err.Error())
+		return
+	}
+	// A diff tool generally exits with a non-zero status when files
+	// differ, so its exit status is ignored
+	go cmd.Wait() //nolint: errcheck
+
+	buf.WriteString("\n")
+	buf.WriteString(envDiffTool)
+	buf.WriteString(" launched: ")
+	buf.WriteString(strings.Join(args, " "))
+}
+
+// splitCommand splits "cmd" into arguments, as a shell would do but
+// without any expansion.
+func splitCommand(cmd string) ([]string, error) {
+	var (
+		args  []string
+		arg   []byte
+		inArg bool
+		quote byte
+	)
+	for i := 0; i < len(cmd); i++ {
+		c := cmd[i]
+		switch {
+		case quote == '\'':
+			if c == '\'' {
+				quote = 0
+				continue
+			}
+
+		case quote == '"':
+			if c == '"' {
+				quote = 0
+				continue
+			}
+			if c == '\\' && i+1 < len(cmd) && (cmd[i+1] == '"' || cmd[i+1] == '\\') {
+				i++
+				c = cmd[i]
+			}
+
+		case c == ' ' || c == '\t' || c == '\n':
+			if inArg {
+				args = append(args, string(arg))
+				arg, inArg = arg[:0], false
+			}
+			continue
+
+		case c == '\'' || c == '"':
+			quote, inArg = c, true
+			continue
+
+		case c == '\\':
+			if i+1 < len(cmd) {
+				i++
+				c = cmd[i]
+			}
+		}
+		arg, inArg = append(arg, c), true
+	}
+	if quote != 0 {
+		return nil, errors.New("unterminated " + string(quote) + " quote")
+	}
+	if inArg {
+		args = append(args, string(arg))
+	}
+	if len(args) == 0 {
+		return nil, errors.New("empty command")
+	}
+	return args, nil
+}
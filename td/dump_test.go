@@ -0,0 +1,146 @@
+// Copyright (c) 2021, Maxime Soulé
+// All rights reserved.
+//
+// This source code is licensed under the BSD-style license found in the
+// LICENSE file in the root directory of this source tree.
+
+package td_test
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/maxatome/go-testdeep/internal/test"
+	"github.com/maxatome/go-testdeep/td"
+)
+
+func setEnv(name, value string) func() {
+	oldEnv, set := os.LookupEnv(name)
+	if value == "" {
+		os.Unsetenv(name)
+	} else {
+		os.Setenv(name, value)
+	}
+	return func() {
+		if set {
+			os.Setenv(name, oldEnv)
+		} else {
+			os.Unsetenv(name)
+		}
+	}
+}
+
+func checkFile(t *testing.T, file, expected string) {
+	t.Helper()
+	b, err := ioutil.ReadFile(file)
+	if test.NoError(t, err) {
+		test.EqualStr(t, string(b), expected)
+	}
+}
+
+func TestDump(t *testing.T) {
+	dir, err := ioutil.TempDir("", "testdeep-dump")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir) //nolint: errcheck
+
+	defer setEnv("TESTDEEP_DUMP_DIR", dir)()
+	defer setEnv("TESTDEEP_DIFF_TOOL", "")()
+
+	ttt := test.NewTestingTB("TestDump/sub test")
+
+	type Item struct {
+		Name string `json:"name"`
+	}
+
+	// JSON
+	test.IsFalse(t, td.Cmp(ttt, Item{Name: "Bob"}, Item{Name: "Alice"}, "my %s", "item"))
+	gotFile := filepath.Join(dir, "TestDump_sub_test-my_item.got.json")
+	expectedFile := filepath.Join(dir, "TestDump_sub_test-my_item.expected.json")
+	test.IsTrue(t, strings.Contains(ttt.LastMessage(),
+		"\nGot and expected values dumped to:\n\t     got: "+gotFile+
+			"\n\texpected: "+expectedFile))
+	checkFile(t, gotFile, "{\n  \"name\": \"Bob\"\n}\n")
+	checkFile(t, expectedFile, "{\n  \"name\": \"Alice\"\n}\n")
+
+	// Same name → suffixed
+	test.IsFalse(t, td.Cmp(ttt, 1, 2, "my item"))
+	checkFile(t, filepath.Join(dir, "TestDump_sub_test-my_item-2.got.json"), "1\n")
+	checkFile(t, filepath.Join(dir, "TestDump_sub_test-my_item-2.expected.json"), "2\n")
+
+	// Operator & not jsonifiable
+	test.IsFalse(t, td.Cmp(ttt, func() {}, td.Nil(), "op"))
+	test.IsTrue(t, strings.Contains(ttt.LastMessage(),
+		filepath.Join(dir, "TestDump_sub_test-op.got.txt")))
+	checkFile(t, filepath.Join(dir, "TestDump_sub_test-op.expected.txt"), "nil\n")
+
+	// Errors and structs without exported fields are not dumped as {}
+	test.IsFalse(t, td.Cmp(ttt, errors.New("boom"), nil, "error"))
+	b, err := ioutil.ReadFile(filepath.Join(dir, "TestDump_sub_test-error.got.txt"))
+	if test.NoError(t, err) {
+		test.IsTrue(t, strings.Contains(string(b), "boom"), string(b))
+	}
+	type private struct{ name string }
+	test.IsFalse(t, td.Cmp(ttt, private{name: "Bob"}, private{}, "private"))
+	b, err = ioutil.ReadFile(filepath.Join(dir, "TestDump_sub_test-private.got.txt"))
+	if test.NoError(t, err) {
+		test.IsTrue(t, strings.Contains(string(b), "Bob"), string(b))
+	}
+
+	// Empty maps and structs are still dumped as JSON
+	test.IsFalse(t, td.Cmp(ttt, map[string]int{}, struct{}{}, "empty"))
+	checkFile(t, filepath.Join(dir, "TestDump_sub_test-empty.got.json"), "{}\n")
+	checkFile(t, filepath.Join(dir, "TestDump_sub_test-empty.expected.json"), "{}\n")
+
+	// Not a Cmp
+	test.IsFalse(t, td.CmpNoError(ttt, os.ErrNotExist, "no error"))
+	checkFile(t, filepath.Join(dir, "TestDump_sub_test-no_error.expected.txt"), "nil\n")
+
+	// Diff tool, launched in background
+	copyFile := filepath.Join(dir, "copy of got.json")
+	defer setEnv("TESTDEEP_DIFF_TOOL", `cp %got '`+copyFile+`'`)()
+	test.IsFalse(t, td.Cmp(ttt, 1, 2, "diff"))
+	test.IsTrue(t, strings.HasSuffix(ttt.LastMessage(),
+		"\nTESTDEEP_DIFF_TOOL launched: cp "+
+			filepath.Join(dir, "TestDump_sub_test-diff.got.json")+" "+copyFile))
+	for i := 0; ; i++ {
+		if _, err := os.Stat(copyFile); err == nil || i == 100 {
+			break
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	checkFile(t, copyFile, "1\n")
+
+	setEnv("TESTDEEP_DIFF_TOOL", "/this/command/does/not/exist %got")
+	test.IsFalse(t, td.Cmp(ttt, 1, 2, "bad diff"))
+	test.IsTrue(t, strings.Contains(ttt.LastMessage(),
+		"\nCannot launch TESTDEEP_DIFF_TOOL command: "))
+
+	setEnv("TESTDEEP_DIFF_TOOL", "meld 'oops")
+	test.IsFalse(t, td.Cmp(ttt, 1, 2, "bad diff"))
+	test.IsTrue(t, strings.Contains(ttt.LastMessage(),
+		"\nCannot launch TESTDEEP_DIFF_TOOL command: unterminated ' quote"))
+
+	// Same name in a new run of the test → not suffixed
+	setEnv("TESTDEEP_DIFF_TOOL", "")
+	ttt2 := test.NewTestingTB("TestDump/run")
+	test.IsFalse(t, td.Cmp(ttt2, 1, 2, "item"))
+	test.IsTrue(t, strings.Contains(ttt2.LastMessage(), "TestDump_run-item.got.json"))
+	ttt2.RunCleanups()
+	ttt2 = test.NewTestingTB("TestDump/run")
+	test.IsFalse(t, td.Cmp(ttt2, 1, 2, "item"))
+	test.IsTrue(t, strings.Contains(ttt2.LastMessage(), "TestDump_run-item.got.json"),
+		ttt2.LastMessage())
+
+	// Cannot dump
+	setEnv("TESTDEEP_DUMP_DIR", filepath.Join(dir, "TestDump_sub_test-diff.got.json"))
+	test.IsFalse(t, td.Cmp(ttt, 1, 2))
+	test.IsTrue(t, strings.Contains(ttt.LastMessage(),
+		"\nCannot dump got and expected values: "))
+}
@@ -21,10 +21,13 @@ import (
 	"github.com/maxatome/go-testdeep/internal/types"
 )
 
-func isNilStr(isNil bool) types.RawString {
-	if isNil {
+func isNilStr(v reflect.Value) types.RawString {
+	if v.IsNil() {
 		return "nil"
 	}
+	if v.Len() == 0 {
+		return "empty, use NilEqualsEmpty to ignore"
+	}
 	return "not nil"
 }
 
@@ -122,6 +125,11 @@ func deepValueEqual(ctx ctxerr.Context, got, expected reflect.Value) (err *ctxer
 			"can only use it in expected one!"))
 	}
 
+	// Check if a path rule replaces expected at the current path
+	if newCtx, newExpected, ok := ctx.PathRule(); ok {
+		ctx, expected = newCtx, newExpected
+	}
+
 	if !got.IsValid() || !expected.IsValid() {
 		if got.IsValid() == expected.IsValid() {
 			return
@@ -132,7 +140,9 @@ func deepValueEqual(ctx ctxerr.Context, got, expected reflect.Value) (err *ctxer
 	// Check if a Smuggle hook matches got type
 	if handled, e := ctx.Hooks.Smuggle(&got); handled {
 		if e != nil {
-			// ctx.BooleanError is always false here as hooks cannot be set globally
+			if ctx.BooleanError {
+				return ctxerr.BooleanError
+			}
 			return ctx.CollectError(&ctxerr.Error{
 				Message:  e.Error(),
 				Got:      got,
@@ -146,7 +156,9 @@ func deepValueEqual(ctx ctxerr.Context, got, expected reflect.Value) (err *ctxer
 		if e == nil {
 			return
 		}
-		// ctx.BooleanError is always false here as hooks cannot be set globally
+		if ctx.BooleanError {
+			return ctxerr.BooleanError
+		}
 		return ctx.CollectError(&ctxerr.Error{
 			Message:  e.Error(),
 			Got:      got,
@@ -172,6 +184,13 @@ func deepValueEqual(ctx ctxerr.Context, got, expected reflect.Value) (err *ctxer
 		}
 	}
 
+	// JSON numbers are compared exactly
+	if ctx.BeLax {
+		if handled, e := jsonNumberEqual(ctx, got, expected); handled {
+			return e
+		}
+	}
+
 	if got.Type() != expected.Type() {
 		if expected.Type().Implements(testDeeper) {
 			curOperator := dark.MustGetInterface(expected).(TestDeep)
@@ -185,6 +204,12 @@ func deepValueEqual(ctx ctxerr.Context, got, expected reflect.Value) (err *ctxer
 				}
 			}
 
+			// Operators expect usual Go numbers, not json.Number
+			if ctx.JSONNumbers {
+				got = jsonGoNumbers(got)
+				ctx.JSONNumbers = false
+			}
+
 			ctx.CurOperator = curOperator
 			return curOperator.Match(ctx, got)
 		}
@@ -223,6 +248,9 @@ func deepValueEqual(ctx ctxerr.Context, got, expected reflect.Value) (err *ctxer
 
 	switch got.Kind() {
 	case reflect.Array:
+		if ctx.Unordered.Match(ctx.Path, got.Type()) {
+			return unorderedEqual(ctx, got, expected)
+		}
 		for i, l := 0, got.Len(); i < l; i++ {
 			err = deepValueEqual(ctx.AddArrayIndex(i),
 				got.Index(i), expected.Index(i))
@@ -233,17 +261,21 @@ func deepValueEqual(ctx ctxerr.Context, got, expected reflect.Value) (err *ctxer
 		return
 
 	case reflect.Slice:
-		if got.IsNil() != expected.IsNil() {
+		if got.IsNil() != expected.IsNil() && !ctx.NilEqualsEmpty {
 			if ctx.BooleanError {
 				return ctxerr.BooleanError
 			}
 			return ctx.CollectError(&ctxerr.Error{
 				Message:  "nil slice",
-				Got:      isNilStr(got.IsNil()),
-				Expected: isNilStr(expected.IsNil()),
+				Got:      isNilStr(got),
+				Expected: isNilStr(expected),
 			})
 		}
 
+		if got.Type() != tupleType && ctx.Unordered.Match(ctx.Path, got.Type()) {
+			return unorderedEqual(ctx, got, expected)
+		}
+
 		var (
 			gotLen      = got.Len()
 			expectedLen = expected.Len()
@@ -302,7 +334,7 @@ func deepValueEqual(ctx ctxerr.Context, got, expected reflect.Value) (err *ctxer
 
 			return ctx.CollectError(&ctxerr.Error{
 				Message: fmt.Sprintf("comparing slices, from index #%d", maxLen),
-				Summary: res.Summary(),
+				Summary: res.Summary(ctx),
 			})
 		}
 		return
@@ -319,7 +351,11 @@ func deepValueEqual(ctx ctxerr.Context, got, expected reflect.Value) (err *ctxer
 	case reflect.Struct:
 		sType := got.Type()
 		for i, n := 0, got.NumField(); i < n; i++ {
-			err = deepValueEqual(ctx.AddField(sType.Field(i).Name),
+			sf := sType.Field(i)
+			if ctx.IgnoreField(sf) {
+				continue
+			}
+			err = deepValueEqual(ctx.AddStructField(sf),
 				got.Field(i), expected.Field(i))
 			if err != nil {
 				return
@@ -328,14 +364,14 @@ func deepValueEqual(ctx ctxerr.Context, got, expected reflect.Value) (err *ctxer
 		return
 
 	case reflect.Map:
-		if got.IsNil() != expected.IsNil() {
+		if got.IsNil() != expected.IsNil() && !ctx.NilEqualsEmpty {
 			if ctx.BooleanError {
 				return ctxerr.BooleanError
 			}
 			return ctx.CollectError(&ctxerr.Error{
 				Message:  "nil map",
-				Got:      isNilStr(got.IsNil()),
-				Expected: isNilStr(expected.IsNil()),
+				Got:      isNilStr(got),
+				Expected: isNilStr(expected),
 			})
 		}
 
@@ -376,7 +412,7 @@ func deepValueEqual(ctx ctxerr.Context, got, expected reflect.Value) (err *ctxer
 					Kind:    keysSetResult,
 					Missing: notFoundKeys,
 					Sort:    true,
-				}).Summary(),
+				}).Summary(ctx),
 			})
 		}
 
@@ -400,7 +436,7 @@ func deepValueEqual(ctx ctxerr.Context, got, expected reflect.Value) (err *ctxer
 
 		return ctx.CollectError(&ctxerr.Error{
 			Message: "comparing map",
-			Summary: res.Summary(),
+			Summary: res.Summary(ctx),
 		})
 
 	case reflect.Func:
@@ -416,6 +452,9 @@ func deepValueEqual(ctx ctxerr.Context, got, expected reflect.Value) (err *ctxer
 			Summary: ctxerr.NewSummary("<can not be compared>"),
 		})
 
+	case reflect.Float32, reflect.Float64, reflect.Complex64, reflect.Complex128:
+		return floatValuesEqual(ctx, got, expected)
+
 	default:
 		// Normal equality suffices
 		if dark.MustGetInterface(got) == dark.MustGetInterface(expected) {
@@ -0,0 +1,126 @@
+// Copyright (c) 2021, Maxime Soulé
+// All rights reserved.
+//
+// This source code is licensed under the BSD-style license found in the
+// LICENSE file in the root directory of this source tree.
+
+package td
+
+import (
+	"math"
+	"reflect"
+
+	"github.com/maxatome/go-testdeep/internal/ctxerr"
+)
+
+// floatValuesEqual compares float or complex "got" and "expected"
+// using ctx float tolerances and NaNEqual flag. "got" and "expected"
+// must have the same kind.
+func floatValuesEqual(ctx ctxerr.Context, got, expected reflect.Value) *ctxerr.Error {
+	var equal bool
+	switch got.Kind() {
+	case reflect.Float32:
+		equal = floatEqual(ctx, got.Float(), expected.Float(), true)
+	case reflect.Float64:
+		equal = floatEqual(ctx, got.Float(), expected.Float(), false)
+	default: // complex64 & complex128
+		is32 := got.Kind() == reflect.Complex64
+		g, e := got.Complex(), expected.Complex()
+		equal = floatEqual(ctx, real(g), real(e), is32) &&
+			floatEqual(ctx, imag(g), imag(e), is32)
+	}
+	if equal {
+		return nil
+	}
+
+	if ctx.BooleanError {
+		return ctxerr.BooleanError
+	}
+	msg := "values differ"
+	if !ctx.FloatTolerance.IsZero() {
+		msg += " (tolerance: " + ctx.FloatTolerance.String() + ")"
+	}
+	return ctx.CollectError(&ctxerr.Error{
+		Message:  msg,
+		Got:      got,
+		Expected: expected,
+	})
+}
+
+// floatEqual returns true if "got" and "expected" are equal, taking
+// into account ctx float tolerances and NaNEqual flag. If "is32" is
+// true, the values come from float32 ones, so ULPs are float32 ones.
+func floatEqual(ctx ctxerr.Context, got, expected float64, is32 bool) bool {
+	if got == expected {
+		return true
+	}
+
+	if math.IsNaN(got) || math.IsNaN(expected) {
+		return ctx.NaNEqual && math.IsNaN(got) && math.IsNaN(expected)
+	}
+
+	// Infinities are only equal to themselves
+	if math.IsInf(got, 0) || math.IsInf(expected, 0) {
+		return false
+	}
+
+	tol := ctx.FloatTolerance
+	diff := math.Abs(got - expected)
+	if tol.Abs > 0 && diff <= tol.Abs {
+		return true
+	}
+	if tol.Rel > 0 &&
+		diff <= tol.Rel*math.Max(math.Abs(got), math.Abs(expected)) {
+		return true
+	}
+	if tol.ULP > 0 {
+		var ulps uint64
+		if is32 {
+			ulps = ulpDistance32(float32(got), float32(expected))
+		} else {
+			ulps = ulpDistance64(got, expected)
+		}
+		return ulps <= uint64(tol.ULP)
+	}
+	return false
+}
+
+// ulpDistance64 returns the number of representable float64 between
+// "a" and "b".
+func ulpDistance64(a, b float64) uint64 {
+	ia, ib := orderedFloat64(a), orderedFloat64(b)
+	if ia < ib {
+		ia, ib = ib, ia
+	}
+	return uint64(ia) - uint64(ib)
+}
+
+// orderedFloat64 maps "f" bits to an int64, so that the order of
+// floats is kept between int64s.
+func orderedFloat64(f float64) int64 {
+	i := int64(math.Float64bits(f))
+	if i < 0 {
+		i = math.MinInt64 - i
+	}
+	return i
+}
+
+// ulpDistance32 returns the number of representable float32 between
+// "a" and "b".
+func ulpDistance32(a, b float32) uint64 {
+	ia, ib := int64(orderedFloat32(a)), int64(orderedFloat32(b))
+	if ia < ib {
+		ia, ib = ib, ia
+	}
+	return uint64(ia - ib)
+}
+
+// orderedFloat32 maps "f" bits to an int32, so that the order of
+// floats is kept between int32s.
+func orderedFloat32(f float32) int32 {
+	i := int32(math.Float32bits(f))
+	if i < 0 {
+		i = math.MinInt32 - i
+	}
+	return i
+}
@@ -7,6 +7,7 @@
 package td_test
 
 import (
+	"math"
 	"testing"
 	"time"
 
@@ -836,3 +837,48 @@ func TestBeLaxGlobalt(t *testing.T) {
 			age:  42,
 		})
 }
+
+func TestEqDeeplyDefaultContextConfig(t *testing.T) {
+	defer func(old td.ContextConfig) { td.DefaultContextConfig = old }(td.DefaultContextConfig)
+
+	check := func(t *testing.T, got, expected interface{}) {
+		t.Helper()
+		test.IsTrue(t, td.EqDeeply(got, expected))
+		test.IsTrue(t, td.EqDeeplyError(got, expected) == nil)
+	}
+
+	t.Run("NilEqualsEmpty", func(t *testing.T) {
+		test.IsFalse(t, td.EqDeeply([]int(nil), []int{}))
+		td.DefaultContextConfig.NilEqualsEmpty = true
+		defer func() { td.DefaultContextConfig.NilEqualsEmpty = false }()
+		check(t, []int(nil), []int{})
+	})
+
+	t.Run("FloatAbsTolerance", func(t *testing.T) {
+		test.IsFalse(t, td.EqDeeply(1.0, 1.05))
+		td.DefaultContextConfig.FloatAbsTolerance = 0.1
+		defer func() { td.DefaultContextConfig.FloatAbsTolerance = 0 }()
+		check(t, 1.0, 1.05)
+	})
+
+	t.Run("NaNEqual", func(t *testing.T) {
+		test.IsFalse(t, td.EqDeeply(math.NaN(), math.NaN()))
+		td.DefaultContextConfig.NaNEqual = true
+		defer func() { td.DefaultContextConfig.NaNEqual = false }()
+		check(t, math.NaN(), math.NaN())
+	})
+
+	t.Run("PathRules", func(t *testing.T) {
+		test.IsFalse(t, td.EqDeeply([]int{1, 2}, []int{1, 3}))
+		td.DefaultContextConfig.PathRules = []td.PathRule{{Path: "DATA[1]", Expected: td.Gt(1)}}
+		defer func() { td.DefaultContextConfig.PathRules = nil }()
+		check(t, []int{1, 2}, []int{1, 3})
+	})
+
+	t.Run("UnorderedSlices", func(t *testing.T) {
+		test.IsFalse(t, td.EqDeeply([]int{1, 2}, []int{2, 1}))
+		td.DefaultContextConfig.UnorderedSlices = td.UnorderedSlices{All: true}
+		defer func() { td.DefaultContextConfig.UnorderedSlices = td.UnorderedSlices{} }()
+		check(t, []int{1, 2}, []int{2, 1})
+	})
+}
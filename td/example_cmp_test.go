@@ -1055,6 +1055,101 @@ func ExampleCmpJSON_file() {
 	// Full match from io.Reader: true
 }
 
+func ExampleCmpJSONLines() {
+	t := &testing.T{}
+
+	got := `{"level":"info","msg":"started"}
+{"level":"warn","msg":"slow request","duration_ms":1234}
+{"level":"info","msg":"stopped"}
+`
+
+	ok := td.CmpJSONLines(t, got, []interface{}{
+		td.SuperJSONOf(`{"level":"info"}`),
+		td.SuperJSONOf(`{"level":"warn","duration_ms":Gt(1000)}`),
+		td.JSON(`{"level":"info","msg":"stopped"}`),
+	})
+	fmt.Println("check each record:", ok)
+
+	ok = td.CmpJSONLines(t, got, td.ArrayEach(td.ContainsKey("msg")))
+	fmt.Println("check all records have a msg:", ok)
+
+	ok = td.CmpJSONLines(t, got, td.Bag(
+		td.SuperJSONOf(`{"msg":"stopped"}`),
+		td.SuperJSONOf(`{"msg":"started"}`),
+		td.SuperJSONOf(`{"msg":"slow request"}`),
+	))
+	fmt.Println("check records in any order:", ok)
+
+	ok = td.CmpJSONLines(t, "{}\n{\"level\":}\n", td.Ignore())
+	fmt.Println("check invalid JSON Lines:", ok)
+
+	type Log struct {
+		Level string `json:"level"`
+		Msg   string `json:"msg"`
+	}
+	ok = td.CmpJSONLines(t, got, []Log{
+		{Level: "info", Msg: "started"},
+		{Level: "warn", Msg: "slow request"},
+		{Level: "info", Msg: "stopped"},
+	})
+	fmt.Println("check records as structs:", ok)
+
+	// Output:
+	// check each record: true
+	// check all records have a msg: true
+	// check records in any order: true
+	// check invalid JSON Lines: false
+	// check records as structs: true
+}
+
+func ExampleCmpJSONPath() {
+	t := &testing.T{}
+
+	got := json.RawMessage(`
+{
+  "name": "Bob",
+  "age": 42,
+  "children": [
+    {
+      "name": "Alice",
+      "age": 16
+    },
+    {
+      "name": "Britt",
+      "age": 21,
+      "children": [
+        {
+          "name": "John",
+          "age": 1
+        }
+      ]
+    }
+  ]
+}`)
+
+	ok := td.CmpJSONPath(t, got, `$.children[*].name`, []string{"Alice", "Britt"})
+	fmt.Println("Bob's children names:", ok)
+
+	ok = td.CmpJSONPath(t, got, `$..name`, td.Bag("Bob", "Alice", "Britt", "John"))
+	fmt.Println("All names:", ok)
+
+	ok = td.CmpJSONPath(t, got, `$..children[?(@.age >= 18)].name`, []string{"Britt"})
+	fmt.Println("Adult children:", ok)
+
+	ok = td.CmpJSONPath(t, got, `$..age`, td.ArrayEach(td.Between(1, 42)))
+	fmt.Println("All ages between 1 and 42:", ok)
+
+	ok = td.CmpJSONPath(t, got, `$.children[?(@.children)].name`, []string{"Alice"})
+	fmt.Println("Alice has children:", ok)
+
+	// Output:
+	// Bob's children names: true
+	// All names: true
+	// Adult children: true
+	// All ages between 1 and 42: true
+	// Alice has children: false
+}
+
 func ExampleCmpJSONPointer_rfc6901() {
 	t := &testing.T{}
 
@@ -1237,6 +1332,49 @@ func ExampleCmpJSONPointer_has_hasnt() {
 	// Britt hasn't children: false
 }
 
+func ExampleCmpJSONSchema() {
+	t := &testing.T{}
+
+	type Person struct {
+		Name     string   `json:"name"`
+		Age      int      `json:"age"`
+		Children []string `json:"children,omitempty"`
+	}
+
+	schema := `
+{
+  "type": "object",
+  "properties": {
+    "name":     {"type": "string", "minLength": 1},
+    "age":      {"$ref": "#/$defs/age"},
+    "children": {"type": "array", "items": {"type": "string"}}
+  },
+  "required": ["name", "age"],
+  "additionalProperties": false,
+  "$defs": {
+    "age": {"type": "integer", "minimum": 0, "maximum": 150}
+  }
+}`
+
+	got := Person{Name: "Bob", Age: 42, Children: []string{"Alice", "Brian"}}
+
+	ok := td.CmpJSONSchema(t, got, schema)
+	fmt.Println("check got conforms to schema:", ok)
+
+	got.Age = 200
+	ok = td.CmpJSONSchema(t, got, schema)
+	fmt.Println("check got with a too high age:", ok)
+
+	ok = td.Cmp(t, map[string]interface{}{"name": "Bob", "age": 42, "zip": 75001},
+		td.JSONSchema(schema))
+	fmt.Println("check map with an additional property:", ok)
+
+	// Output:
+	// check got conforms to schema: true
+	// check got with a too high age: false
+	// check map with an additional property: false
+}
+
 func ExampleCmpKeys() {
 	t := &testing.T{}
 
@@ -2236,6 +2374,41 @@ func ExampleCmpShallow_string() {
 	// are = but do not point to same area: false
 }
 
+func ExampleCmpSJSONPath() {
+	t := &testing.T{}
+
+	got := json.RawMessage(`
+{
+  "name": "Bob",
+  "age": 42,
+  "children": [
+    {
+      "name": "Alice",
+      "age": 16
+    },
+    {
+      "name": "Britt",
+      "age": 21
+    }
+  ]
+}`)
+
+	ok := td.CmpSJSONPath(t, got, `$.children[?(@.name == "Britt")].age`, 21)
+	fmt.Println("Britt is 21:", ok)
+
+	ok = td.CmpSJSONPath(t, got, `$.children[-1].name`, td.HasPrefix("Br"))
+	fmt.Println("Last child name starts with Br:", ok)
+
+	// Exactly one value has to be matched
+	ok = td.CmpSJSONPath(t, got, `$.children[*].age`, td.Lt(18))
+	fmt.Println("Only one child:", ok)
+
+	// Output:
+	// Britt is 21: true
+	// Last child name starts with Br: true
+	// Only one child: false
+}
+
 func ExampleCmpSlice_slice() {
 	t := &testing.T{}
 
@@ -2882,6 +3055,37 @@ func ExampleCmpSubSetOf() {
 	// true
 }
 
+func ExampleCmpSubYAMLOf() {
+	t := &testing.T{}
+
+	got := &struct {
+		Fullname string `json:"fullname"`
+		Age      int    `json:"age"`
+	}{
+		Fullname: "Bob",
+		Age:      42,
+	}
+
+	ok := td.CmpSubYAMLOf(t, got, `
+# A person:
+fullname: Bob  # The name of this person
+age:      42
+gender:   male # This field is ignored as SubYAMLOf
+`, nil)
+	fmt.Println("check got with YAML:", ok)
+
+	ok = td.CmpSubYAMLOf(t, got, `{fullname: $^HasPrefix("B"), age: $1, gender: male}`, []interface{}{td.Between(40, 45)})
+	fmt.Println("check got with operators:", ok)
+
+	ok = td.CmpSubYAMLOf(t, got, `{fullname: Bob, gender: male}`, nil)
+	fmt.Println("check got without age field:", ok)
+
+	// Output:
+	// check got with YAML: true
+	// check got with operators: true
+	// check got without age field: false
+}
+
 func ExampleCmpSuperBagOf() {
 	t := &testing.T{}
 
@@ -3105,6 +3309,38 @@ func ExampleCmpSuperSetOf() {
 	// true
 }
 
+func ExampleCmpSuperYAMLOf() {
+	t := &testing.T{}
+
+	got := &struct {
+		Fullname string `json:"fullname"`
+		Age      int    `json:"age"`
+		Gender   string `json:"gender"`
+	}{
+		Fullname: "Bob",
+		Age:      42,
+		Gender:   "male",
+	}
+
+	ok := td.CmpSuperYAMLOf(t, got, `
+# A person:
+fullname: Bob  # The name of this person
+age:      42
+`, nil)
+	fmt.Println("check got with YAML:", ok)
+
+	ok = td.CmpSuperYAMLOf(t, got, `{fullname: $^HasPrefix("B"), age: $1}`, []interface{}{td.Between(40, 45)})
+	fmt.Println("check got with operators:", ok)
+
+	ok = td.CmpSuperYAMLOf(t, got, `{fullname: Bob, city: Paris}`, nil)
+	fmt.Println("check got with city field:", ok)
+
+	// Output:
+	// check got with YAML: true
+	// check got with operators: true
+	// check got with city field: false
+}
+
 func ExampleCmpTruncTime() {
 	t := &testing.T{}
 
@@ -3170,6 +3406,101 @@ func ExampleCmpValues() {
 	// Each value is between 1 and 3: true
 }
 
+func ExampleCmpXML() {
+	t := &testing.T{}
+
+	got := `<?xml version="1.0" encoding="UTF-8"?>
+<feed xmlns="http://www.w3.org/2005/Atom">
+  <title>Example Feed</title>
+  <entry><title>First</title><link href="/1"/></entry>
+  <entry><title>Second</title><link href="/2"/></entry>
+</feed>`
+
+	ok := td.CmpXML(t, got, `
+<feed xmlns="http://www.w3.org/2005/Atom">
+  <title>Example Feed</title>
+  <entry>
+    <title>First</title>
+    <link href="/1"/>
+  </entry>
+  <entry>
+    <title>Second</title>
+    <link href="/2"/>
+  </entry>
+</feed>`, nil)
+	fmt.Println("check got with XML:", ok)
+
+	ok = td.CmpXML(t, got, `
+<feed xmlns="http://www.w3.org/2005/Atom">
+  <title>$^HasPrefix("Example")</title>
+  <entry>
+    <title>$1</title>
+    <link href="$^Re(&quot;^/\\d+$&quot;)"/>
+  </entry>
+  <entry>
+    <title>$^NotEmpty</title>
+    <link href="$2"/>
+  </entry>
+</feed>`, []interface{}{"First", td.Any("/2", "/3")})
+	fmt.Println("check got with placeholders and operators:", ok)
+
+	type Link struct {
+		Href string `xml:"href,attr"`
+	}
+	type Entry struct {
+		Title string `xml:"title"`
+		Link  Link   `xml:"link"`
+	}
+	entry := Entry{Title: "First", Link: Link{Href: "/1"}}
+
+	ok = td.CmpXML(t, entry, `<Entry><title>First</title><link href="$^NotEmpty"/></Entry>`, nil)
+	fmt.Println("check struct with XML:", ok)
+
+	// Output:
+	// check got with XML: true
+	// check got with placeholders and operators: true
+	// check struct with XML: true
+}
+
+func ExampleCmpYAML() {
+	t := &testing.T{}
+
+	got := &struct {
+		Fullname string   `json:"fullname"`
+		Age      int      `json:"age"`
+		Children []string `json:"children"`
+	}{
+		Fullname: "Bob",
+		Age:      42,
+		Children: []string{"Alice", "Brian"},
+	}
+
+	ok := td.CmpYAML(t, got, `
+# A person:
+fullname: Bob  # The name of this person
+age:      42
+children:
+  - Alice
+  - Brian
+`, nil)
+	fmt.Println("check got with YAML:", ok)
+
+	ok = td.CmpYAML(t, got, `{fullname: Bob, age: 42, children: [Alice, Brian]}`, nil)
+	fmt.Println("check got with flow YAML:", ok)
+
+	ok = td.CmpYAML(t, got, `
+fullname: $name
+age:      $^Between(40, 45)
+children: $^Bag("Brian", HasPrefix("A"))
+`, []interface{}{td.Tag("name", td.Re(`^Bo`))})
+	fmt.Println("check got with placeholders and operators:", ok)
+
+	// Output:
+	// check got with YAML: true
+	// check got with flow YAML: true
+	// check got with placeholders and operators: true
+}
+
 func ExampleCmpZero() {
 	t := &testing.T{}
 
@@ -1175,6 +1175,101 @@ func ExampleJSON_file() {
 	// Full match from io.Reader: true
 }
 
+func ExampleJSONLines() {
+	t := &testing.T{}
+
+	got := `{"level":"info","msg":"started"}
+{"level":"warn","msg":"slow request","duration_ms":1234}
+{"level":"info","msg":"stopped"}
+`
+
+	ok := td.Cmp(t, got, td.JSONLines([]interface{}{
+		td.SuperJSONOf(`{"level":"info"}`),
+		td.SuperJSONOf(`{"level":"warn","duration_ms":Gt(1000)}`),
+		td.JSON(`{"level":"info","msg":"stopped"}`),
+	}))
+	fmt.Println("check each record:", ok)
+
+	ok = td.Cmp(t, got, td.JSONLines(td.ArrayEach(td.ContainsKey("msg"))))
+	fmt.Println("check all records have a msg:", ok)
+
+	ok = td.Cmp(t, got, td.JSONLines(td.Bag(
+		td.SuperJSONOf(`{"msg":"stopped"}`),
+		td.SuperJSONOf(`{"msg":"started"}`),
+		td.SuperJSONOf(`{"msg":"slow request"}`),
+	)))
+	fmt.Println("check records in any order:", ok)
+
+	ok = td.Cmp(t, "{}\n{\"level\":}\n", td.JSONLines(td.Ignore()))
+	fmt.Println("check invalid JSON Lines:", ok)
+
+	type Log struct {
+		Level string `json:"level"`
+		Msg   string `json:"msg"`
+	}
+	ok = td.Cmp(t, got, td.JSONLines([]Log{
+		{Level: "info", Msg: "started"},
+		{Level: "warn", Msg: "slow request"},
+		{Level: "info", Msg: "stopped"},
+	}))
+	fmt.Println("check records as structs:", ok)
+
+	// Output:
+	// check each record: true
+	// check all records have a msg: true
+	// check records in any order: true
+	// check invalid JSON Lines: false
+	// check records as structs: true
+}
+
+func ExampleJSONPath() {
+	t := &testing.T{}
+
+	got := json.RawMessage(`
+{
+  "name": "Bob",
+  "age": 42,
+  "children": [
+    {
+      "name": "Alice",
+      "age": 16
+    },
+    {
+      "name": "Britt",
+      "age": 21,
+      "children": [
+        {
+          "name": "John",
+          "age": 1
+        }
+      ]
+    }
+  ]
+}`)
+
+	ok := td.Cmp(t, got, td.JSONPath(`$.children[*].name`, []string{"Alice", "Britt"}))
+	fmt.Println("Bob's children names:", ok)
+
+	ok = td.Cmp(t, got, td.JSONPath(`$..name`, td.Bag("Bob", "Alice", "Britt", "John")))
+	fmt.Println("All names:", ok)
+
+	ok = td.Cmp(t, got, td.JSONPath(`$..children[?(@.age >= 18)].name`, []string{"Britt"}))
+	fmt.Println("Adult children:", ok)
+
+	ok = td.Cmp(t, got, td.JSONPath(`$..age`, td.ArrayEach(td.Between(1, 42))))
+	fmt.Println("All ages between 1 and 42:", ok)
+
+	ok = td.Cmp(t, got, td.JSONPath(`$.children[?(@.children)].name`, []string{"Alice"}))
+	fmt.Println("Alice has children:", ok)
+
+	// Output:
+	// Bob's children names: true
+	// All names: true
+	// Adult children: true
+	// All ages between 1 and 42: true
+	// Alice has children: false
+}
+
 func ExampleJSONPointer_rfc6901() {
 	t := &testing.T{}
 
@@ -1359,6 +1454,49 @@ func ExampleJSONPointer_has_hasnt() {
 	// Britt hasn't children: false
 }
 
+func ExampleJSONSchema() {
+	t := &testing.T{}
+
+	type Person struct {
+		Name     string   `json:"name"`
+		Age      int      `json:"age"`
+		Children []string `json:"children,omitempty"`
+	}
+
+	schema := `
+{
+  "type": "object",
+  "properties": {
+    "name":     {"type": "string", "minLength": 1},
+    "age":      {"$ref": "#/$defs/age"},
+    "children": {"type": "array", "items": {"type": "string"}}
+  },
+  "required": ["name", "age"],
+  "additionalProperties": false,
+  "$defs": {
+    "age": {"type": "integer", "minimum": 0, "maximum": 150}
+  }
+}`
+
+	got := Person{Name: "Bob", Age: 42, Children: []string{"Alice", "Brian"}}
+
+	ok := td.Cmp(t, got, td.JSONSchema(schema))
+	fmt.Println("check got conforms to schema:", ok)
+
+	got.Age = 200
+	ok = td.Cmp(t, got, td.JSONSchema(schema))
+	fmt.Println("check got with a too high age:", ok)
+
+	ok = td.Cmp(t, map[string]interface{}{"name": "Bob", "age": 42, "zip": 75001},
+		td.JSONSchema(schema))
+	fmt.Println("check map with an additional property:", ok)
+
+	// Output:
+	// check got conforms to schema: true
+	// check got with a too high age: false
+	// check map with an additional property: false
+}
+
 func ExampleKeys() {
 	t := &testing.T{}
 
@@ -2858,6 +2996,41 @@ func ExampleStruct() {
 	// true
 }
 
+func ExampleSJSONPath() {
+	t := &testing.T{}
+
+	got := json.RawMessage(`
+{
+  "name": "Bob",
+  "age": 42,
+  "children": [
+    {
+      "name": "Alice",
+      "age": 16
+    },
+    {
+      "name": "Britt",
+      "age": 21
+    }
+  ]
+}`)
+
+	ok := td.Cmp(t, got, td.SJSONPath(`$.children[?(@.name == "Britt")].age`, 21))
+	fmt.Println("Britt is 21:", ok)
+
+	ok = td.Cmp(t, got, td.SJSONPath(`$.children[-1].name`, td.HasPrefix("Br")))
+	fmt.Println("Last child name starts with Br:", ok)
+
+	// Exactly one value has to be matched
+	ok = td.Cmp(t, got, td.SJSONPath(`$.children[*].age`, td.Lt(18)))
+	fmt.Println("Only one child:", ok)
+
+	// Output:
+	// Britt is 21: true
+	// Last child name starts with Br: true
+	// Only one child: false
+}
+
 func ExampleSStruct() {
 	t := &testing.T{}
 
@@ -3172,6 +3345,38 @@ func ExampleSubSetOf() {
 	// true
 }
 
+func ExampleSubYAMLOf() {
+	t := &testing.T{}
+
+	got := &struct {
+		Fullname string `json:"fullname"`
+		Age      int    `json:"age"`
+	}{
+		Fullname: "Bob",
+		Age:      42,
+	}
+
+	ok := td.Cmp(t, got, td.SubYAMLOf(`
+# A person:
+fullname: Bob  # The name of this person
+age:      42
+gender:   male # This field is ignored as SubYAMLOf
+`))
+	fmt.Println("check got with YAML:", ok)
+
+	ok = td.Cmp(t, got, td.SubYAMLOf(`{fullname: $^HasPrefix("B"), age: $1, gender: male}`,
+		td.Between(40, 45)))
+	fmt.Println("check got with operators:", ok)
+
+	ok = td.Cmp(t, got, td.SubYAMLOf(`{fullname: Bob, gender: male}`))
+	fmt.Println("check got without age field:", ok)
+
+	// Output:
+	// check got with YAML: true
+	// check got with operators: true
+	// check got without age field: false
+}
+
 func ExampleSuperBagOf() {
 	t := &testing.T{}
 
@@ -3422,6 +3627,39 @@ func ExampleSuperSetOf() {
 	// true
 }
 
+func ExampleSuperYAMLOf() {
+	t := &testing.T{}
+
+	got := &struct {
+		Fullname string `json:"fullname"`
+		Age      int    `json:"age"`
+		Gender   string `json:"gender"`
+	}{
+		Fullname: "Bob",
+		Age:      42,
+		Gender:   "male",
+	}
+
+	ok := td.Cmp(t, got, td.SuperYAMLOf(`
+# A person:
+fullname: Bob  # The name of this person
+age:      42
+`))
+	fmt.Println("check got with YAML:", ok)
+
+	ok = td.Cmp(t, got, td.SuperYAMLOf(`{fullname: $^HasPrefix("B"), age: $1}`,
+		td.Between(40, 45)))
+	fmt.Println("check got with operators:", ok)
+
+	ok = td.Cmp(t, got, td.SuperYAMLOf(`{fullname: Bob, city: Paris}`))
+	fmt.Println("check got with city field:", ok)
+
+	// Output:
+	// check got with YAML: true
+	// check got with operators: true
+	// check got with city field: false
+}
+
 func ExampleTruncTime() {
 	t := &testing.T{}
 
@@ -3487,6 +3725,103 @@ func ExampleValues() {
 	// Each value is between 1 and 3: true
 }
 
+func ExampleXML() {
+	t := &testing.T{}
+
+	got := `<?xml version="1.0" encoding="UTF-8"?>
+<feed xmlns="http://www.w3.org/2005/Atom">
+  <title>Example Feed</title>
+  <entry><title>First</title><link href="/1"/></entry>
+  <entry><title>Second</title><link href="/2"/></entry>
+</feed>`
+
+	ok := td.Cmp(t, got, td.XML(`
+<feed xmlns="http://www.w3.org/2005/Atom">
+  <title>Example Feed</title>
+  <entry>
+    <title>First</title>
+    <link href="/1"/>
+  </entry>
+  <entry>
+    <title>Second</title>
+    <link href="/2"/>
+  </entry>
+</feed>`))
+	fmt.Println("check got with XML:", ok)
+
+	ok = td.Cmp(t, got, td.XML(`
+<feed xmlns="http://www.w3.org/2005/Atom">
+  <title>$^HasPrefix("Example")</title>
+  <entry>
+    <title>$1</title>
+    <link href="$^Re(&quot;^/\\d+$&quot;)"/>
+  </entry>
+  <entry>
+    <title>$^NotEmpty</title>
+    <link href="$2"/>
+  </entry>
+</feed>`,
+		"First",
+		td.Any("/2", "/3")))
+	fmt.Println("check got with placeholders and operators:", ok)
+
+	type Link struct {
+		Href string `xml:"href,attr"`
+	}
+	type Entry struct {
+		Title string `xml:"title"`
+		Link  Link   `xml:"link"`
+	}
+	entry := Entry{Title: "First", Link: Link{Href: "/1"}}
+
+	ok = td.Cmp(t, entry, td.XML(`<Entry><title>First</title><link href="$^NotEmpty"/></Entry>`))
+	fmt.Println("check struct with XML:", ok)
+
+	// Output:
+	// check got with XML: true
+	// check got with placeholders and operators: true
+	// check struct with XML: true
+}
+
+func ExampleYAML() {
+	t := &testing.T{}
+
+	got := &struct {
+		Fullname string   `json:"fullname"`
+		Age      int      `json:"age"`
+		Children []string `json:"children"`
+	}{
+		Fullname: "Bob",
+		Age:      42,
+		Children: []string{"Alice", "Brian"},
+	}
+
+	ok := td.Cmp(t, got, td.YAML(`
+# A person:
+fullname: Bob  # The name of this person
+age:      42
+children:
+  - Alice
+  - Brian
+`))
+	fmt.Println("check got with YAML:", ok)
+
+	ok = td.Cmp(t, got, td.YAML(`{fullname: Bob, age: 42, children: [Alice, Brian]}`))
+	fmt.Println("check got with flow YAML:", ok)
+
+	ok = td.Cmp(t, got, td.YAML(`
+fullname: $name
+age:      $^Between(40, 45)
+children: $^Bag("Brian", HasPrefix("A"))
+`, td.Tag("name", td.Re(`^Bo`))))
+	fmt.Println("check got with placeholders and operators:", ok)
+
+	// Output:
+	// check got with YAML: true
+	// check got with flow YAML: true
+	// check got with placeholders and operators: true
+}
+
 func ExampleZero() {
 	t := &testing.T{}
 
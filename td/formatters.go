@@ -0,0 +1,56 @@
+// Copyright (c) 2021, Maxime Soulé
+// All rights reserved.
+//
+// This source code is licensed under the BSD-style license found in the
+// LICENSE file in the root directory of this source tree.
+
+package td
+
+import (
+	"github.com/maxatome/go-testdeep/internal/color"
+	"github.com/maxatome/go-testdeep/internal/hooks"
+	"github.com/maxatome/go-testdeep/internal/util"
+)
+
+var globalFormatters = hooks.NewInfo()
+
+func init() {
+	util.GlobalFormatOpts = func() util.FormatOpts {
+		return util.FormatOpts{
+			Formatters:     globalFormatters,
+			PreferStringer: DefaultContextConfig.PreferStringer,
+		}
+	}
+}
+
+// AddFormatters records globally new formatters using functions
+// passed in "fns". These formatters are used to render got and
+// expected values (as well as their contents) in all failure
+// reports, and in operators String() output, instead of the default
+// go-spew rendering. They do not alter comparisons, so a mismatch
+// deep inside a formatted value is still reported at its own path.
+//
+// Each function in "fns" has to be a function with the following
+// signature:
+//
+//   func (A) string
+//
+// If A is an interface, the formatter applies to all types
+// implementing it. Precedence rules are the same as WithCmpHooks ones.
+//
+//   td.AddFormatters(
+//     func(d *decimal.Big) string { return d.String() },
+//     func(id uuid.UUID) string { return id.String() },
+//   )
+//
+// Formatters recorded by WithFormatters method of *T take
+// precedence over these global ones.
+//
+// AddFormatters panics if an item of "fns" is not a function or if
+// its signature does not match the expected one.
+func AddFormatters(fns ...interface{}) {
+	err := globalFormatters.AddFormatHooks(fns)
+	if err != nil {
+		panic(color.Bad("AddFormatters " + err.Error()))
+	}
+}
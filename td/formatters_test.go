@@ -0,0 +1,119 @@
+// Copyright (c) 2021, Maxime Soulé
+// All rights reserved.
+//
+// This source code is licensed under the BSD-style license found in the
+// LICENSE file in the root directory of this source tree.
+
+package td_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/maxatome/go-testdeep/internal/test"
+	"github.com/maxatome/go-testdeep/td"
+)
+
+type globalFormatted struct {
+	v int
+}
+
+func TestAddFormatters(tt *testing.T) {
+	td.AddFormatters(func(g globalFormatted) string {
+		if g.v == 0 {
+			return "G<zero>"
+		}
+		return "G<non-zero>"
+	})
+
+	ttt := test.NewTestingTB(tt.Name())
+
+	test.IsFalse(tt, td.Cmp(ttt, globalFormatted{v: 1}, td.Any(globalFormatted{})))
+	test.IsTrue(tt, strings.Contains(ttt.LastMessage(), `
+	     got: G<non-zero>
+	expected: Any(G<zero>)`))
+
+	// Formatters only affect rendering, not the comparison itself
+	test.IsFalse(tt, td.Cmp(ttt, globalFormatted{v: 1}, globalFormatted{}))
+	test.IsTrue(tt, strings.Contains(ttt.LastMessage(), `DATA.v: values differ
+	     got: 1
+	expected: 0`))
+
+	// Operators String() output
+	test.EqualStr(tt, td.Bag(globalFormatted{}).String(), "Bag(G<zero>)")
+
+	// Local formatters take precedence
+	t := td.NewT(ttt).WithFormatters(func(g globalFormatted) string {
+		return "L"
+	})
+	test.IsFalse(tt, t.Cmp(globalFormatted{v: 1}, td.Nil()))
+	test.IsTrue(tt, strings.Contains(ttt.LastMessage(), `
+	     got: L
+	expected: nil`))
+
+	test.CheckPanic(tt, func() { td.AddFormatters(12) },
+		"AddFormatters expects a function, not a int (@0)")
+}
+
+func TestWithFormatters(tt *testing.T) {
+	type ID [2]byte
+	type Item struct {
+		ID   ID
+		Name string
+	}
+
+	ttt := test.NewTestingTB(tt.Name())
+
+	t := td.NewT(ttt).WithFormatters(func(id ID) string {
+		return "ID#" + string('0'+id[0]) + string('0'+id[1])
+	})
+	test.IsFalse(tt, t.Cmp(Item{ID: ID{1, 2}},
+		td.Struct(Item{}, td.StructFields{"ID": td.Any(ID{2, 1})})))
+	test.IsTrue(tt, strings.Contains(ttt.LastMessage(), `DATA.ID: comparing with Any
+	     got: ID#12
+`))
+
+	// Formatters only affect rendering, nested errors are kept
+	test.IsFalse(tt, t.Cmp(Item{ID: ID{1, 2}}, Item{ID: ID{2, 1}}))
+	test.IsTrue(tt, strings.Contains(ttt.LastMessage(), `DATA.ID[0]: values differ
+	     got: (uint8) 1
+	expected: (uint8) 2`))
+
+	test.IsFalse(tt, t.Cmp([]Item{{ID: ID{1, 2}}}, []Item{}))
+	test.IsTrue(tt, strings.Contains(ttt.LastMessage(), `
+	Extra item: ((td_test.Item) {
+	              ID: ID#12,
+	              Name: (string) ""
+	             })`))
+
+	// Original *T not altered
+	test.IsFalse(tt, td.NewT(ttt).Cmp(ID{1, 2}, ID{2, 1}))
+	test.IsFalse(tt, strings.Contains(ttt.LastMessage(), "ID#12"))
+
+	test.CheckPanic(tt, func() { td.NewT(ttt).WithFormatters(func(int) {}) },
+		"WithFormatters expects: func (A) string not func(int) (@0)")
+}
+
+func TestPreferStringer(tt *testing.T) {
+	ttt := test.NewTestingTB(tt.Name())
+
+	t := td.NewT(ttt)
+	test.IsFalse(tt, t.CmpNoError(errors.New("boom!")))
+	test.IsFalse(tt, strings.Contains(ttt.LastMessage(), "got: boom!"))
+
+	t = td.NewT(ttt).PreferStringer()
+	test.IsFalse(tt, t.CmpNoError(errors.New("boom!")))
+	test.IsTrue(tt, strings.Contains(ttt.LastMessage(), "got: boom!"))
+
+	t = td.NewT(ttt).PreferStringer(false)
+	test.IsFalse(tt, t.CmpNoError(errors.New("boom!")))
+	test.IsFalse(tt, strings.Contains(ttt.LastMessage(), "got: boom!"))
+
+	t = td.NewT(ttt, td.ContextConfig{PreferStringer: true})
+	test.IsFalse(tt, t.Cmp([]error{errors.New("boom!")}, nil))
+	test.IsTrue(tt, strings.Contains(ttt.LastMessage(), `
+	     got: ([]error) (len=1) {
+	           boom!
+	          }`))
+}
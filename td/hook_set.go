@@ -0,0 +1,183 @@
+// Copyright (c) 2021, Maxime Soulé
+// All rights reserved.
+//
+// This source code is licensed under the BSD-style license found in the
+// LICENSE file in the root directory of this source tree.
+
+package td
+
+import (
+	"reflect"
+
+	"github.com/maxatome/go-testdeep/internal/color"
+	"github.com/maxatome/go-testdeep/internal/hooks"
+)
+
+// Hooks is a named set of Cmp, Smuggle and Format hooks. It is
+// typically built once in a shared testing package, then applied to
+// *T instances using WithHooks method, to all Cmp* functions and
+// *T instances using DefaultContextConfig.Hooks field, or to
+// tdsuite and tdhttp helpers using ContextConfig.Hooks field or their
+// WithHooks methods.
+//
+//   package testutil
+//
+//   var Hooks = td.NewHooks("testutil").
+//     AddCmpHooks((time.Time).Equal).
+//     AddSmuggleHooks(func(d decimal.Decimal) string { return d.String() }).
+//     AddFormatters((uuid.UUID).String)
+//
+// then in tests of any package:
+//
+//   func TestMyFunc(tt *testing.T) {
+//     t := td.NewT(tt).WithHooks(testutil.Hooks)
+//     t.Cmp(got, expected)
+//   }
+//
+// or, for all tests of a package:
+//
+//   func init() {
+//     td.DefaultContextConfig.Hooks = []*td.Hooks{testutil.Hooks}
+//   }
+//
+// A *Hooks instance is safe for concurrent use, but hooks should be
+// added before it is used by any comparison.
+type Hooks struct {
+	name string
+	info *hooks.Info
+}
+
+// NewHooks returns a new empty named set of hooks. "name" is only
+// used to identify the set, for example in panic messages.
+func NewHooks(name string) *Hooks {
+	return &Hooks{
+		name: name,
+		info: hooks.NewInfo(),
+	}
+}
+
+// Name returns the name of the set of hooks.
+func (h *Hooks) Name() string {
+	return h.name
+}
+
+func (h *Hooks) check(method string, err error) {
+	if err != nil {
+		panic(color.Bad("Hooks(%q).%s %s", h.name, method, err))
+	}
+}
+
+// AddCmpHooks records new Cmp hooks using functions passed in "fns"
+// and returns "h". See (*T).WithCmpHooks for details about "fns"
+// items.
+//
+// AddCmpHooks panics if an item of "fns" is not a function or if its
+// signature does not match the expected ones.
+func (h *Hooks) AddCmpHooks(fns ...interface{}) *Hooks {
+	h.check("AddCmpHooks", h.info.AddCmpHooks(fns))
+	return h
+}
+
+// AddCmpHookIf records a new Cmp hook "fn" applying to each type for
+// which "match" returns true and returns "h". See (*T).WithCmpHookIf
+// for details.
+//
+// AddCmpHookIf panics if "match" is nil, if "fn" is not a function or
+// if its signature does not match the expected ones.
+func (h *Hooks) AddCmpHookIf(match func(reflect.Type) bool, fn interface{}) *Hooks {
+	h.check("AddCmpHookIf", h.info.AddCmpHookIf(match, fn))
+	return h
+}
+
+// AddSmuggleHooks records new Smuggle hooks using functions passed in
+// "fns" and returns "h". See (*T).WithSmuggleHooks for details about
+// "fns" items.
+//
+// AddSmuggleHooks panics if an item of "fns" is not a function or if
+// its signature does not match the expected ones.
+func (h *Hooks) AddSmuggleHooks(fns ...interface{}) *Hooks {
+	h.check("AddSmuggleHooks", h.info.AddSmuggleHooks(fns))
+	return h
+}
+
+// AddSmuggleHookIf records a new Smuggle hook "fn" applying to each
+// type for which "match" returns true and returns "h". See
+// (*T).WithSmuggleHookIf for details.
+//
+// AddSmuggleHookIf panics if "match" is nil, if "fn" is not a
+// function or if its signature does not match the expected ones.
+func (h *Hooks) AddSmuggleHookIf(match func(reflect.Type) bool, fn interface{}) *Hooks {
+	h.check("AddSmuggleHookIf", h.info.AddSmuggleHookIf(match, fn))
+	return h
+}
+
+// AddFormatters records new formatters using functions passed in
+// "fns" and returns "h". See (*T).WithFormatters for details about
+// "fns" items.
+//
+// AddFormatters panics if an item of "fns" is not a function or if
+// its signature does not match the expected one.
+func (h *Hooks) AddFormatters(fns ...interface{}) *Hooks {
+	h.check("AddFormatters", h.info.AddFormatHooks(fns))
+	return h
+}
+
+// sameHooks returns true if "a" and "b" contain the same sets of
+// hooks in the same order.
+func sameHooks(a, b []*Hooks) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i, set := range a {
+		if set != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// hooksWithSets returns a new *hooks.Info inheriting from hooks of
+// "sets", then from "local", so "local" hooks take precedence over
+// "sets" ones, and hooks of a set take precedence over previous sets
+// ones. If "sets" is empty, "local" is returned as is.
+func hooksWithSets(sets []*Hooks, local *hooks.Info) *hooks.Info {
+	if len(sets) == 0 {
+		return local
+	}
+
+	parents := make([]*hooks.Info, 0, len(sets)+1)
+	for _, set := range sets {
+		if set != nil {
+			parents = append(parents, set.info)
+		}
+	}
+	parents = append(parents, local)
+
+	info := hooks.NewInfo()
+	info.Inherit(parents...)
+	return info
+}
+
+// hookTypes returns the types of "models" items. A reflect.Type item
+// is used as is, a nil pointer to an interface means this interface,
+// as in (*fmt.Stringer)(nil), the type of any other item is used
+// instead. "method" is used in panic messages if an item is nil.
+func hookTypes(method string, models []interface{}) []reflect.Type {
+	types := make([]reflect.Type, len(models))
+	for i, model := range models {
+		switch m := model.(type) {
+		case nil:
+			panic(color.BadUsage(method+"(MODEL|REFLECT_TYPE...)", nil, i+1, true))
+		case reflect.Type:
+			types[i] = m
+		default:
+			typ := reflect.TypeOf(model)
+			if typ.Kind() == reflect.Ptr && typ.Elem().Kind() == reflect.Interface &&
+				reflect.ValueOf(model).IsNil() {
+				typ = typ.Elem()
+			}
+			types[i] = typ
+		}
+	}
+	return types
+}
@@ -0,0 +1,120 @@
+// Copyright (c) 2021, Maxime Soulé
+// All rights reserved.
+//
+// This source code is licensed under the BSD-style license found in the
+// LICENSE file in the root directory of this source tree.
+
+package td_test
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/maxatome/go-testdeep/internal/test"
+	"github.com/maxatome/go-testdeep/td"
+)
+
+func TestHooks(tt *testing.T) {
+	ttt := test.NewTestingTB(tt.Name())
+
+	set := td.NewHooks("shared").
+		AddCmpHooks(func(got, expected fmt.Stringer) bool {
+			return got.String() == expected.String()
+		}).
+		AddSmuggleHooks(strconv.Atoi).
+		AddFormatters(func(s []int) string { return fmt.Sprintf("ints%d", len(s)) })
+	test.EqualStr(tt, set.Name(), "shared")
+
+	t := td.NewT(ttt).WithHooks(set)
+	test.IsTrue(tt, t.Cmp(hookedStringer(12), hookedStringer(2)))
+	test.IsTrue(tt, t.Cmp("123", 123))
+	test.IsFalse(tt, t.Cmp([]int{1, 2}, td.Nil()))
+	test.IsTrue(tt, strings.Contains(ttt.LastMessage(), "got: ints2"))
+
+	// Original *T not altered
+	test.IsFalse(tt, td.NewT(ttt).Cmp("123", 123))
+
+	// Hooks recorded on *T take precedence over sets ones
+	t2 := t.WithSmuggleHooks(func(s string) int { return len(s) })
+	test.IsTrue(tt, t2.Cmp("123", 3))
+	test.IsTrue(tt, t.Cmp("123", 123))
+
+	// A later set takes precedence over a previous one
+	set2 := td.NewHooks("shared2").
+		AddSmuggleHooks(func(s string) bool { return s != "" })
+	t3 := t.WithHooks(set2)
+	test.IsTrue(tt, t3.Cmp("123", true))
+	test.IsTrue(tt, t3.Cmp(hookedStringer(12), hookedStringer(2)))
+
+	// Sub-tests use the same sets
+	t.Run("sub", func(t *td.T) {
+		test.IsTrue(tt, t.Cmp("123", 123))
+	})
+
+	// Removing hooks
+	t4 := t.RemoveSmuggleHooks("")
+	test.IsFalse(tt, t4.Cmp("123", 123))
+	test.IsTrue(tt, t4.Cmp(hookedStringer(12), hookedStringer(2)))
+	test.IsTrue(tt, t.Cmp("123", 123))
+
+	t4 = t.RemoveCmpHooks((*fmt.Stringer)(nil))
+	test.IsFalse(tt, t4.Cmp(hookedStringer(12), hookedStringer(2)))
+	test.IsTrue(tt, t.Cmp(hookedStringer(12), hookedStringer(2)))
+
+	t4 = t.RemoveFormatters(reflect.TypeOf([]int(nil)))
+	test.IsFalse(tt, t4.Cmp([]int{1, 2}, []int{1}))
+	test.IsFalse(tt, strings.Contains(ttt.LastMessage(), "ints2"))
+
+	// Overriding a removed hook
+	t4 = t.RemoveSmuggleHooks("").WithSmuggleHooks(func(s string) int { return len(s) })
+	test.IsTrue(tt, t4.Cmp("123", 3))
+
+	// Hooks added to a set afterwards are used
+	set.AddCmpHooks(func(got, expected bool) bool { return true })
+	test.IsTrue(tt, t.Cmp(true, false))
+
+	test.CheckPanic(tt, func() { t.RemoveCmpHooks(nil) },
+		"usage: RemoveCmpHooks(MODEL|REFLECT_TYPE...), but received nil as 1st parameter")
+	test.CheckPanic(tt, func() { td.NewHooks("bad").AddCmpHooks(12) },
+		`Hooks("bad").AddCmpHooks expects a function, not a int`)
+	test.CheckPanic(tt, func() { td.NewHooks("bad").AddSmuggleHookIf(nil, strconv.Atoi) },
+		`Hooks("bad").AddSmuggleHookIf expects a non-nil predicate`)
+}
+
+func TestHooksDefaultContextConfig(tt *testing.T) {
+	set := td.NewHooks("default").AddSmuggleHooks(strconv.Atoi)
+
+	test.IsFalse(tt, td.EqDeeply("123", 123))
+
+	old := td.DefaultContextConfig.Hooks
+	defer func() { td.DefaultContextConfig.Hooks = old }()
+	td.DefaultContextConfig.Hooks = []*td.Hooks{set}
+
+	test.IsTrue(tt, td.EqDeeply("123", 123))
+	test.IsTrue(tt, td.EqDeeplyError("123", 123) == nil)
+
+	// Hook errors in boolean context
+	test.IsFalse(tt, td.EqDeeply("abc", 123))
+	err := td.EqDeeplyError("abc", 123)
+	if test.Error(tt, err) {
+		test.IsTrue(tt, strings.Contains(err.Error(), `invalid syntax`), err.Error())
+	}
+	set.AddCmpHooks(func(got, expected bool) error { return errors.New("bool hook error") })
+	test.IsFalse(tt, td.EqDeeply(true, true))
+	err = td.EqDeeplyError(true, true)
+	if test.Error(tt, err) {
+		test.IsTrue(tt, strings.Contains(err.Error(), "bool hook error"), err.Error())
+	}
+
+	ttt := test.NewTestingTB(tt.Name())
+	test.IsTrue(tt, td.Cmp(ttt, "123", 123))
+	test.IsTrue(tt, td.NewT(ttt).Cmp("123", 123))
+	test.IsTrue(tt, td.NewT(ttt, td.ContextConfig{}).Cmp("123", 123))
+
+	// An empty non-nil slice disables default sets
+	test.IsFalse(tt, td.NewT(ttt, td.ContextConfig{Hooks: []*td.Hooks{}}).Cmp("123", 123))
+}
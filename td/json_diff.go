@@ -0,0 +1,154 @@
+// Copyright (c) 2021, Maxime Soulé
+// All rights reserved.
+//
+// This source code is licensed under the BSD-style license found in the
+// LICENSE file in the root directory of this source tree.
+
+package td
+
+import (
+	"bytes"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/maxatome/go-testdeep/internal/color"
+	"github.com/maxatome/go-testdeep/internal/ctxerr"
+	"github.com/maxatome/go-testdeep/internal/json"
+	"github.com/maxatome/go-testdeep/internal/util"
+)
+
+// jsonDiffDoc returns the JSON document "got" has to be rendered as
+// when a JSON diff is required, that is when ContextConfig.JSONDiff
+// is enabled. false is returned if no JSON diff has to be done, "got"
+// not being representable as a JSON object or array.
+func jsonDiffDoc(ctx ctxerr.Context, got reflect.Value) (interface{}, bool) {
+	if !ctx.JSONDiff || ctx.BooleanError || !got.IsValid() {
+		return nil, false
+	}
+
+	doc, err := jsonify(ctx, got)
+	if err != nil {
+		return nil, false
+	}
+
+	switch doc.(type) {
+	case map[string]interface{}, []interface{}:
+		return doc, true
+	}
+	return nil, false
+}
+
+// jsonDiff calls "compare" to compare the value "doc" is the JSON
+// representation of. In case of failure, all the errors are
+// collected and reported in only one error whose summary is "doc"
+// rendered as indented JSON, in which each mismatching member is
+// highlighted and annotated with the reason of the mismatch.
+//
+// "compare" is called with a copy of "ctx" collecting all errors and
+// disallowing nested JSON diffs.
+func jsonDiff(ctx ctxerr.Context, doc interface{},
+	compare func(ctx ctxerr.Context) *ctxerr.Error) *ctxerr.Error {
+	dctx := ctx
+	dctx.JSONDiff = false
+	dctx.MaxErrors = -1
+	dctx.InitErrors()
+
+	err := compare(dctx)
+	if err == nil {
+		err = dctx.MergeErrors()
+		if err == nil {
+			return nil
+		}
+	}
+
+	summary := jsonDiffSummary{
+		doc:      doc,
+		comments: map[string]string{},
+	}
+	num := 0
+	for ; err != nil; err = err.Next {
+		if err == ctxerr.ErrTooManyErrors {
+			continue
+		}
+		num++
+
+		pointer := err.Context.Path.JSONPointer(len(ctx.Path))
+		found := true
+		for {
+			if _, e := util.JSONPointer(doc, pointer); e == nil {
+				break
+			}
+			found = false
+			pointer = pointer[:strings.LastIndexByte(pointer, '/')]
+		}
+
+		comment := jsonDiffComment(err, found)
+		if prev, ok := summary.comments[pointer]; ok {
+			comment = prev + "\n" + comment
+		}
+		summary.comments[pointer] = comment
+	}
+
+	message := "1 JSON mismatch"
+	if num > 1 {
+		message = strconv.Itoa(num) + " JSON mismatches"
+	}
+	return ctx.CollectError(&ctxerr.Error{
+		Message: message,
+		Summary: summary,
+	})
+}
+
+// jsonDiffComment returns the comment describing "err". If "found"
+// is false, the member "err" is about is missing in the JSON
+// document, so its path is mentioned.
+func jsonDiffComment(err *ctxerr.Error, found bool) string {
+	var buf bytes.Buffer
+	buf.WriteString("✗ ")
+
+	if pos := strings.Index(err.Message, "%%"); pos >= 0 {
+		buf.WriteString(err.Message[:pos])
+		buf.WriteString(err.Context.PathString())
+		buf.WriteString(err.Message[pos+2:])
+	} else {
+		if !found {
+			buf.WriteString(err.Context.PathString())
+			buf.WriteString(": ")
+		}
+		buf.WriteString(err.Message)
+	}
+
+	if err.Summary != nil {
+		buf.WriteByte('\n')
+		err.Summary.AppendSummary(&buf, "  ")
+	} else {
+		buf.WriteString("\n  expected: ")
+		util.IndentStringIn(&buf, err.ExpectedString(), "            ")
+	}
+	return buf.String()
+}
+
+// jsonDiffSummary implements the ctxerr.ErrorSummary interface and
+// renders a JSON document annotated with comments.
+type jsonDiffSummary struct {
+	doc      interface{}
+	comments map[string]string
+}
+
+// AppendSummary implements ctxerr.ErrorSummary interface.
+func (s jsonDiffSummary) AppendSummary(buf *bytes.Buffer, prefix string) {
+	color.Init()
+
+	var b bytes.Buffer
+	json.AppendMarshalOpts(&b, s.doc, json.MarshalOpts{ //nolint: errcheck
+		Comments:     s.comments,
+		HighlightOn:  color.BadOn,
+		HighlightOff: color.BadOff,
+		CommentOn:    color.OKOn,
+		CommentOff:   color.OKOff,
+	})
+
+	buf.WriteString(prefix)
+	util.IndentStringIn(buf, b.String(), prefix)
+}
@@ -0,0 +1,119 @@
+// Copyright (c) 2021, Maxime Soulé
+// All rights reserved.
+//
+// This source code is licensed under the BSD-style license found in the
+// LICENSE file in the root directory of this source tree.
+
+package td_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/maxatome/go-testdeep/internal/test"
+	"github.com/maxatome/go-testdeep/td"
+)
+
+func TestJSONDiffOutput(tt *testing.T) {
+	type Person struct {
+		Name     string   `json:"name"`
+		Age      int      `json:"age"`
+		Children []string `json:"children"`
+	}
+	got := Person{Name: "Bob", Age: 42, Children: []string{"Alice", "Brian"}}
+
+	ttt := test.NewTestingTB(tt.Name())
+	t := td.NewT(ttt).JSONDiff()
+
+	//
+	// JSON operator at root
+	test.IsFalse(tt, t.Cmp(got, td.JSON(`
+{
+  "name":     "Alice",
+  "age":      Between(1, 10),
+  "children": ["Alice", "Charles", "Daniel"]
+}`)))
+	test.EqualStr(tt, ttt.LastMessage(), `Failed test
+DATA: 4 JSON mismatches
+	{
+	  "age": 42,  // ✗ values differ
+	              //   expected: 1 ≤ got ≤ 10
+	  "children": [
+	                "Alice",
+	                "Brian"  // ✗ values differ
+	                         //   expected: "Charles"
+	              ],  // ✗ comparing slices, from index #2
+	                  //   Missing item: ("Daniel")
+	  "name": "Bob"  // ✗ values differ
+	                 //   expected: "Alice"
+	}`)
+
+	//
+	// Missing key
+	test.IsFalse(tt, t.Cmp(got, td.SuperJSONOf(`{"name": "Bob", "zip": 12}`)))
+	test.EqualStr(tt, ttt.LastMessage(), `Failed test
+DATA: 1 JSON mismatch
+	{
+	  "age": 42,
+	  "children": [
+	                "Alice",
+	                "Brian"
+	              ],
+	  "name": "Bob"
+	}  // ✗ comparing hash keys of DATA
+	   //   Missing key: ("zip")`)
+
+	//
+	// Plain expected value
+	test.IsFalse(tt, t.Cmp(&got, &Person{Name: "Bob", Age: 43}))
+	test.EqualStr(tt, ttt.LastMessage(), `Failed test
+DATA: 2 JSON mismatches
+	{
+	  "age": 42,  // ✗ values differ
+	              //   expected: 43
+	  "children": [
+	                "Alice",
+	                "Brian"
+	              ],  // ✗ nil slice
+	                  //   expected: nil
+	  "name": "Bob"
+	}`)
+
+	//
+	// Success
+	test.IsTrue(tt, t.Cmp(got, td.SuperJSONOf(`{"name": "Bob"}`)))
+
+	//
+	// Not a JSON object nor array at root: classic output
+	test.IsFalse(tt, t.Cmp(12, 13))
+	test.EqualStr(tt, ttt.LastMessage(), `Failed test
+DATA: values differ
+	     got: 12
+	expected: 13`)
+
+	//
+	// Got cannot be marshaled, but JSON operator is deeper
+	type Wrapper struct {
+		Fn     func()
+		Person Person
+	}
+	test.IsFalse(tt, t.Cmp(Wrapper{Person: got},
+		td.Struct(Wrapper{}, td.StructFields{
+			"Person": td.SubJSONOf(`{"name": "Alice", "age": 42, "children": Ignore()}`),
+		})))
+	test.IsTrue(tt, strings.Contains(ttt.LastMessage(), `DATA.Person: 1 JSON mismatch
+	{
+	  "age": 42,
+	  "children": [
+	                "Alice",
+	                "Brian"
+	              ],
+	  "name": "Bob"  // ✗ values differ
+	                 //   expected: "Alice"
+	}
+[under operator SubJSONOf at json_diff_test.go:`), ttt.LastMessage())
+
+	//
+	// Boolean context
+	test.IsFalse(tt, td.EqDeeply(got, td.JSON(`{"name": "Alice"}`)))
+}
@@ -0,0 +1,90 @@
+// Copyright (c) 2021, Maxime Soulé
+// All rights reserved.
+//
+// This source code is licensed under the BSD-style license found in the
+// LICENSE file in the root directory of this source tree.
+
+package td
+
+import (
+	"reflect"
+	"sync"
+
+	"github.com/maxatome/go-testdeep/internal/color"
+)
+
+var jsonOperators = struct {
+	sync.RWMutex
+	ops map[string]interface{}
+}{
+	ops: map[string]interface{}{},
+}
+
+// RegisterJSONOperator registers "fn" as the operator named "name",
+// usable inside JSON, SubJSONOf and SuperJSONOf as $^name(…), or as
+// $^name if "fn" takes no parameters:
+//
+//   func init() {
+//     td.RegisterJSONOperator("CountryCode", func() td.TestDeep {
+//       return td.Re(`^[A-Z]{2}\z`)
+//     })
+//     td.RegisterJSONOperator("Age", func(min, max float64) td.TestDeep {
+//       return td.Between(min, max)
+//     })
+//   }
+//
+// then in tests:
+//
+//   td.Cmp(t, got, td.JSON(`{"country": $^CountryCode, "age": $^Age(18, 77)}`))
+//
+// "fn" has to be a function returning a TestDeep operator, typically
+// a custom operator (see Base) or a built-in one. As for built-in
+// operators embedded in JSON, the number and types of parameters are
+// checked against the "fn" signature: JSON numbers are converted to
+// the numeric type required (int, int64, float64, etc.) or are
+// float64 for interface{} parameters, strings are string, booleans
+// are bool, arrays are []interface{} and objects are
+// map[string]interface{}. Parameters of interface{} type accept
+// anything, including operators and placeholders.
+//
+// "name" can only contain ASCII letters. Registering again the same
+// name replaces the previously registered operator. The names of the
+// built-in operator shortcuts ($^NotZero and co) cannot be used.
+//
+// RegisterJSONOperator is safe for concurrent use, but operators
+// should be registered before being used, typically in an init
+// function.
+//
+// RegisterJSONOperator panics if "name" is not valid or if "fn" is
+// not a function returning a TestDeep operator.
+func RegisterJSONOperator(name string, fn interface{}) {
+	if name == "" {
+		panic(color.Bad("RegisterJSONOperator(): empty name"))
+	}
+	for _, r := range name {
+		if (r < 'A' || r > 'Z') && (r < 'a' || r > 'z') {
+			panic(color.Bad("RegisterJSONOperator(): invalid name %q, only ASCII letters allowed", name))
+		}
+	}
+	if _, exists := jsonOpShortcuts[name]; exists {
+		panic(color.Bad("RegisterJSONOperator(): %s is a built-in operator shortcut", name))
+	}
+
+	vfn := reflect.ValueOf(fn)
+	if vfn.Kind() != reflect.Func || vfn.IsNil() ||
+		vfn.Type().NumOut() != 1 || !vfn.Type().Out(0).Implements(testDeeper) {
+		panic(color.BadUsage("RegisterJSONOperator(NAME, FUNC)", fn, 2, true))
+	}
+
+	jsonOperators.Lock()
+	jsonOperators.ops[name] = fn
+	jsonOperators.Unlock()
+}
+
+// getJSONOperator returns the operator registered as "name" using
+// RegisterJSONOperator, or nil if it does not exist.
+func getJSONOperator(name string) interface{} {
+	jsonOperators.RLock()
+	defer jsonOperators.RUnlock()
+	return jsonOperators.ops[name]
+}
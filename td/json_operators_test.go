@@ -0,0 +1,120 @@
+// Copyright (c) 2021, Maxime Soulé
+// All rights reserved.
+//
+// This source code is licensed under the BSD-style license found in the
+// LICENSE file in the root directory of this source tree.
+
+package td_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/maxatome/go-testdeep/internal/test"
+	"github.com/maxatome/go-testdeep/td"
+)
+
+func init() {
+	td.RegisterJSONOperator("CountryCode", func() td.TestDeep {
+		return td.Re(`^[A-Z]{2}\z`)
+	})
+	td.RegisterJSONOperator("AgeBetween", func(min, max float64) td.TestDeep {
+		return td.Between(min, max)
+	})
+	td.RegisterJSONOperator("Prefixed", func(prefix string, others ...interface{}) td.TestDeep {
+		if len(others) > 0 {
+			return td.All(append([]interface{}{td.HasPrefix(prefix)}, others...)...)
+		}
+		return td.HasPrefix(prefix)
+	})
+	td.RegisterJSONOperator("Not", func(expected interface{}) td.TestDeep {
+		return td.Not(expected)
+	})
+	td.RegisterJSONOperator("Broken", func() *evenOp {
+		panic("broken!")
+	})
+}
+
+func TestRegisterJSONOperator(t *testing.T) {
+	type person struct {
+		Name    string `json:"name"`
+		Age     int    `json:"age"`
+		Country string `json:"country"`
+	}
+	got := person{Name: "Bob", Age: 42, Country: "FR"}
+
+	checkOK(t, got, td.JSON(`{"name": "Bob", "age": 42, "country": $^CountryCode}`))
+	checkOK(t, got, td.JSON(`{"name": "Bob", "age": 42, "country": "$^CountryCode"}`))
+	checkOK(t, got, td.JSON(`{"name": "Bob", "age": $^AgeBetween(40, 45), "country": "FR"}`))
+	checkOK(t, got, td.JSON(`{"name": $^Prefixed("B"), "age": 42, "country": "FR"}`))
+	checkOK(t, got,
+		td.JSON(`{"name": $^Prefixed("B", Gt("A"), $1), "age": 42, "country": "FR"}`,
+			td.HasSuffix("b")))
+	checkOK(t, got, td.SubJSONOf(`{"name": "Bob", "age": 42, "country": $^CountryCode, "x": 1}`))
+	checkOK(t, got, td.SuperJSONOf(`{"country": $^CountryCode}`))
+
+	// Operator with an interface{} parameter, not clashing with Not()
+	checkOK(t, got, td.JSON(`{"name": $^Not(HasPrefix("A")), "age": Not(12), "country": "FR"}`))
+
+	checkError(t, got, td.JSON(`{"name": "Bob", "age": $^AgeBetween(18, 40), "country": "FR"}`),
+		expectedError{
+			Message:  mustBe("values differ"),
+			Path:     mustBe(`DATA["age"]`),
+			Got:      mustBe("42"),
+			Expected: mustBe("18 ≤ got ≤ 40"),
+			Located:  true,
+		})
+
+	err := td.EqDeeplyError(got, td.JSON(`{"name": "Bob", "age": 42, "country": $^AgeBetween(1, 2)}`))
+	if test.IsTrue(t, err != nil) {
+		test.IsTrue(t, strings.Contains(err.Error(),
+			"[under operator Between at line 1:38 (pos 38) inside operator JSON at json_operators_test.go:"),
+			err.Error())
+	}
+
+	// String
+	test.EqualStr(t,
+		td.JSON(`{"country": $^CountryCode, "age": $^AgeBetween(1, 2)}`).String(),
+		`JSON({
+       "age": 1 ≤ got ≤ 2,
+       "country": "$^CountryCode"
+     })`)
+
+	//
+	// Errors
+	test.CheckPanic(t, func() { td.JSON(`$^Unknown(1)`) },
+		`JSON(): JSON unmarshal error: unknown operator $^Unknown() at line 1:0 (pos 0)`)
+	test.CheckPanic(t, func() { td.JSON(`$^Unknown`) },
+		`JSON(): JSON unmarshal error: bad operator shortcut "$^Unknown" at line 1:0 (pos 0)`)
+	test.CheckPanic(t, func() { td.JSON(`$^AgeBetween`) },
+		`JSON(): JSON unmarshal error: $^AgeBetween() requires 2 parameters at line 1:0 (pos 0)`)
+	test.CheckPanic(t, func() { td.JSON(`$^UnknownOp`) },
+		`JSON(): JSON unmarshal error: bad operator shortcut "$^UnknownOp" at line 1:0 (pos 0)`)
+	test.CheckPanic(t, func() { td.JSON(`$^AgeBetween(1)`) },
+		`JSON(): JSON unmarshal error: $^AgeBetween() requires 2 parameters at line 1:0 (pos 0)`)
+	test.CheckPanic(t, func() { td.JSON(`$^AgeBetween("1", 2)`) },
+		`JSON(): JSON unmarshal error: $^AgeBetween() bad #1 parameter type: float64 required but string received at line 1:0 (pos 0)`)
+	test.CheckPanic(t, func() { td.JSON(`$^CountryCode(1)`) },
+		`JSON(): JSON unmarshal error: $^CountryCode() requires no parameters at line 1:0 (pos 0)`)
+	test.CheckPanic(t, func() { td.JSON(`$^Prefixed()`) },
+		`JSON(): JSON unmarshal error: $^Prefixed() requires at least one parameter at line 1:0 (pos 0)`)
+	test.CheckPanic(t, func() { td.JSON(`$^Broken()`) },
+		`JSON(): JSON unmarshal error: $^Broken() broken! at line 1:0 (pos 0)`)
+
+	//
+	// Bad usage
+	test.CheckPanic(t, func() { td.RegisterJSONOperator("", td.Empty) },
+		"RegisterJSONOperator(): empty name")
+	test.CheckPanic(t, func() { td.RegisterJSONOperator("My_Op", td.Empty) },
+		`RegisterJSONOperator(): invalid name "My_Op", only ASCII letters allowed`)
+	test.CheckPanic(t, func() { td.RegisterJSONOperator("NotZero", td.Empty) },
+		"RegisterJSONOperator(): NotZero is a built-in operator shortcut")
+	test.CheckPanic(t, func() { td.RegisterJSONOperator("MyOp", nil) },
+		"usage: RegisterJSONOperator(NAME, FUNC), but received nil as 2nd parameter")
+	test.CheckPanic(t, func() { td.RegisterJSONOperator("MyOp", 42) },
+		"usage: RegisterJSONOperator(NAME, FUNC), but received int as 2nd parameter")
+	test.CheckPanic(t, func() { td.RegisterJSONOperator("MyOp", func() int { return 0 }) },
+		"usage: RegisterJSONOperator(NAME, FUNC), but received func() int (func) as 2nd parameter")
+	test.CheckPanic(t, func() { td.RegisterJSONOperator("MyOp", (func() td.TestDeep)(nil)) },
+		"usage: RegisterJSONOperator(NAME, FUNC), but received func() td.TestDeep (func) as 2nd parameter")
+}
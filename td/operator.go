@@ -0,0 +1,183 @@
+// Copyright (c) 2021, Maxime Soulé
+// All rights reserved.
+//
+// This source code is licensed under the BSD-style license found in the
+// LICENSE file in the root directory of this source tree.
+
+package td
+
+import (
+	"reflect"
+
+	"github.com/maxatome/go-testdeep/internal/ctxerr"
+	"github.com/maxatome/go-testdeep/internal/types"
+)
+
+// Context is the comparison context passed to the Match method of
+// operators. Its methods AddField, AddArrayIndex, AddMapKey,
+// AddFunctionCall and AddCustomLevel return a new Context with one
+// more level in the path of the compared data, to be used for nested
+// comparisons (see DeepMatch). Its BooleanError field is true when
+// the error details are not needed, for example when an operator is
+// used inside Bag or Any.
+//
+// See Base type for an example of custom operator.
+type Context = ctxerr.Context
+
+// Error is the error returned by the Match method of operators. It
+// is typically built using NewError, NewErrorSummary, NewErrorReason
+// or NewTypeMismatchError functions, or returned by DeepMatch.
+type Error = ctxerr.Error
+
+// RawString is a string displayed as is, without quotes, in failure
+// reports. It is typically used as "got" or "expected" parameter of
+// NewError to describe what is expected, as in:
+//
+//   td.NewError(ctx, "nil value", nil, td.RawString("a struct"))
+type RawString = types.RawString
+
+// Base is the building block of custom operators. Embedded in a
+// struct implementing Match and String methods, it provides the other
+// methods of TestDeep interface, so the operator gets the same
+// location tracking as built-in ones. It has to be initialized using
+// NewBase.
+//
+// The resulting operator does not handle untyped nil values, see
+// BaseOKNil to handle them in Match method.
+//
+//   type evenOp struct {
+//     td.Base
+//   }
+//
+//   func Even() td.TestDeep {
+//     return &evenOp{Base: td.NewBase(0)}
+//   }
+//
+//   func (e *evenOp) Match(ctx td.Context, got reflect.Value) *td.Error {
+//     if got.Kind() != reflect.Int {
+//       return td.NewTypeMismatchError(ctx, got.Type(), reflect.TypeOf(0))
+//     }
+//     if got.Int()%2 != 0 {
+//       return td.NewErrorReason(ctx, "not even", got, "it is odd")
+//     }
+//     return nil
+//   }
+//
+//   func (e *evenOp) String() string {
+//     return "Even()"
+//   }
+//
+//   func (e *evenOp) TypeBehind() reflect.Type {
+//     return reflect.TypeOf(0)
+//   }
+//
+// TypeBehind has only to be overridden when the operator knows the
+// type it handles.
+type Base struct {
+	base
+}
+
+// NewBase returns a new Base, recording the location of the operator
+// creation. It has to be called by the operator constructor, the one
+// called by the user. "callDepth" is the number of additional call
+// levels between this constructor and NewBase, so typically 0.
+func NewBase(callDepth int) Base {
+	return Base{base: newBase(4 + callDepth)}
+}
+
+// BaseOKNil is the same as Base, except that the operator handles
+// untyped nil values: in this case, Match method is called with an
+// invalid reflect.Value. It has to be initialized using NewBaseOKNil.
+type BaseOKNil struct {
+	baseOKNil
+}
+
+// NewBaseOKNil returns a new BaseOKNil, recording the location of
+// the operator creation. See NewBase for "callDepth" meaning.
+func NewBaseOKNil(callDepth int) BaseOKNil {
+	return BaseOKNil{baseOKNil: newBaseOKNil(4 + callDepth)}
+}
+
+// NewError returns the error to be returned by the Match method of an
+// operator when "got" does not match "expected". "got" and
+// "expected" are the values to display in the failure report, see
+// RawString to display a description instead of a value.
+//
+// As NewErrorSummary, NewErrorReason and NewTypeMismatchError, it
+// takes care of the boolean context (see Context) and of errors
+// accumulation: so it can return nil, which has to be returned as is
+// by Match.
+func NewError(ctx Context, message string, got, expected interface{}) *Error {
+	if ctx.BooleanError {
+		return ctxerr.BooleanError
+	}
+	return ctx.CollectError(&ctxerr.Error{
+		Message:  message,
+		Got:      got,
+		Expected: expected,
+	})
+}
+
+// NewErrorSummary returns the error to be returned by the Match
+// method of an operator, displaying "summary" instead of got and
+// expected values in the failure report. See NewError for details.
+func NewErrorSummary(ctx Context, message, summary string) *Error {
+	if ctx.BooleanError {
+		return ctxerr.BooleanError
+	}
+	return ctx.CollectError(&ctxerr.Error{
+		Message: message,
+		Summary: ctxerr.NewSummary(summary),
+	})
+}
+
+// NewErrorReason returns the error to be returned by the Match method
+// of an operator, displaying "got" value and "reason" in the failure
+// report, as in:
+//
+//           value: 43
+//   it failed coz: it is odd
+//
+// See NewError for details.
+func NewErrorReason(ctx Context, message string, got interface{}, reason string) *Error {
+	if ctx.BooleanError {
+		return ctxerr.BooleanError
+	}
+	return ctx.CollectError(&ctxerr.Error{
+		Message: message,
+		Summary: ctxerr.NewSummaryReason(got, reason),
+	})
+}
+
+// NewTypeMismatchError returns the "type mismatch" error to be
+// returned by the Match method of an operator, when "got" type is not
+// the "expected" one. See NewError for details.
+func NewTypeMismatchError(ctx Context, got, expected reflect.Type) *Error {
+	if ctx.BooleanError {
+		return ctxerr.BooleanError
+	}
+	return ctx.CollectError(ctxerr.TypeMismatch(got, expected))
+}
+
+// DeepMatch compares "got" against "expected" using all go-testdeep
+// features, as Cmp does. It is intended to be used by the Match
+// method of custom operators for nested comparisons. "expected" can
+// be a TestDeep operator. The returned error, possibly nil even if
+// "got" does not match (see NewError), has to be returned as is by
+// Match.
+//
+//   func (o *myOp) Match(ctx td.Context, got reflect.Value) *td.Error {
+//     …
+//     return td.DeepMatch(ctx.AddField("Name"), got.FieldByName("Name"), o.name)
+//   }
+//
+// See DeepMatchOK to only know if "got" matches "expected".
+func DeepMatch(ctx Context, got reflect.Value, expected interface{}) *Error {
+	return deepValueEqual(ctx, got, reflect.ValueOf(expected))
+}
+
+// DeepMatchOK returns true if "got" matches "expected", as DeepMatch
+// does, but without producing any error.
+func DeepMatchOK(ctx Context, got reflect.Value, expected interface{}) bool {
+	return deepValueEqualFinalOK(ctx, got, reflect.ValueOf(expected))
+}
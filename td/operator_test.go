@@ -0,0 +1,154 @@
+// Copyright (c) 2021, Maxime Soulé
+// All rights reserved.
+//
+// This source code is licensed under the BSD-style license found in the
+// LICENSE file in the root directory of this source tree.
+
+package td_test
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/maxatome/go-testdeep/internal/test"
+	"github.com/maxatome/go-testdeep/td"
+)
+
+type evenOp struct {
+	td.Base
+}
+
+func even() td.TestDeep {
+	return &evenOp{Base: td.NewBase(0)}
+}
+
+func (e *evenOp) Match(ctx td.Context, got reflect.Value) *td.Error {
+	if got.Kind() != reflect.Int {
+		return td.NewTypeMismatchError(ctx, got.Type(), reflect.TypeOf(0))
+	}
+	if got.Int()%2 != 0 {
+		return td.NewErrorReason(ctx, "not even", got, "it is odd")
+	}
+	return nil
+}
+
+func (e *evenOp) String() string {
+	return "Even()"
+}
+
+func (e *evenOp) TypeBehind() reflect.Type {
+	return reflect.TypeOf(0)
+}
+
+type nameOp struct {
+	td.BaseOKNil
+	name interface{}
+}
+
+func nameIs(name interface{}) td.TestDeep {
+	return newNameOp(name)
+}
+
+func newNameOp(name interface{}) td.TestDeep {
+	return &nameOp{BaseOKNil: td.NewBaseOKNil(1), name: name}
+}
+
+func (n *nameOp) Match(ctx td.Context, got reflect.Value) *td.Error {
+	if !got.IsValid() {
+		return td.NewError(ctx, "nil value", nil, td.RawString("a struct"))
+	}
+	if got.Kind() != reflect.Struct {
+		return td.NewErrorSummary(ctx, "bad kind", "got "+got.Kind().String())
+	}
+	field := got.FieldByName("Name")
+	if !field.IsValid() {
+		return td.NewErrorSummary(ctx, "no Name field", "got "+got.Type().String())
+	}
+	if !td.DeepMatchOK(ctx, field, n.name) {
+		return td.DeepMatch(ctx.AddField("Name"), field, n.name)
+	}
+	return nil
+}
+
+func (n *nameOp) String() string {
+	return fmt.Sprintf("NameIs(%v)", n.name)
+}
+
+func TestOperator(t *testing.T) {
+	checkOK(t, 42, even())
+
+	checkError(t, 43, even(),
+		expectedError{
+			Message: mustBe("not even"),
+			Path:    mustBe("DATA"),
+			Summary: mustBe("        value: 43\nit failed coz: it is odd"),
+			Located: true,
+		})
+
+	checkError(t, "43", even(),
+		expectedError{
+			Message:  mustBe("type mismatch"),
+			Path:     mustBe("DATA"),
+			Got:      mustBe("string"),
+			Expected: mustBe("int"),
+			Located:  true,
+		})
+
+	checkOK(t, []int{2, 4}, td.ArrayEach(even()))
+	test.IsFalse(t, td.EqDeeply([]int{2, 3}, td.ArrayEach(even())))
+
+	equalTypes(t, even(), 0)
+	equalTypes(t, nameIs("Bob"), nil)
+	test.EqualStr(t, even().String(), "Even()")
+
+	type person struct {
+		Name string
+	}
+
+	checkOK(t, person{Name: "Bob"}, nameIs("Bob"))
+	checkOK(t, person{Name: "Bob"}, nameIs(td.HasPrefix("B")))
+
+	checkError(t, person{Name: "Alice"}, nameIs("Bob"),
+		expectedError{
+			Message:  mustBe("values differ"),
+			Path:     mustBe("DATA.Name"),
+			Got:      mustBe(`"Alice"`),
+			Expected: mustBe(`"Bob"`),
+		})
+
+	checkError(t, person{Name: "Alice"}, nameIs(td.HasPrefix("B")),
+		expectedError{
+			Message:  mustBe("has not prefix"),
+			Path:     mustBe("DATA.Name"),
+			Got:      mustBe(`"Alice"`),
+			Expected: mustBe(`HasPrefix("B")`),
+			Located:  true,
+		})
+
+	checkError(t, 12, nameIs("Bob"),
+		expectedError{
+			Message: mustBe("bad kind"),
+			Path:    mustBe("DATA"),
+			Summary: mustBe("got int"),
+			Located: true,
+		})
+
+	// BaseOKNil allows to handle nil values
+	checkError(t, nil, nameIs("Bob"),
+		expectedError{
+			Message:  mustBe("nil value"),
+			Path:     mustBe("DATA"),
+			Got:      mustBe("nil"),
+			Expected: mustBe("a struct"),
+			Located:  true,
+		})
+
+	// Location is the one of the nameIs() call, not the newNameOp() one
+	err := td.EqDeeplyError(12, nameIs("Bob"))
+	if test.IsTrue(t, err != nil) {
+		test.IsTrue(t, strings.Contains(err.Error(), "nameIs at operator_test.go:"),
+			err.Error())
+	}
+}
@@ -7,6 +7,7 @@
 package td
 
 import (
+	"reflect"
 	"testing"
 
 	"github.com/maxatome/go-testdeep/internal/test"
@@ -60,3 +61,34 @@ func TestPkgFunc(t *testing.T) {
 	test.EqualStr(t, pkg, "no/func")
 	test.EqualStr(t, fn, "")
 }
+
+func TestSplitCommand(t *testing.T) {
+	for i, tc := range []struct {
+		cmd      string
+		expected []string
+	}{
+		{cmd: "meld %got %expected", expected: []string{"meld", "%got", "%expected"}},
+		{cmd: "  diff\t-u  %got\n%expected ", expected: []string{"diff", "-u", "%got", "%expected"}},
+		{cmd: `'/my tools/diff' "-x" %got`, expected: []string{"/my tools/diff", "-x", "%got"}},
+		{cmd: `a"b c"d 'e\f' "g\"h\\i\j"`, expected: []string{"ab cd", `e\f`, `g"h\i\j`}},
+		{cmd: `a\ b\\c ''`, expected: []string{"a b\\c", ""}},
+	} {
+		args, err := splitCommand(tc.cmd)
+		if test.NoError(t, err, "#%d", i) {
+			test.IsTrue(t, reflect.DeepEqual(args, tc.expected),
+				"#%d: %q", i, args)
+		}
+	}
+
+	for i, tc := range []struct{ cmd, err string }{
+		{cmd: "", err: "empty command"},
+		{cmd: "  ", err: "empty command"},
+		{cmd: `diff 'foo`, err: "unterminated ' quote"},
+		{cmd: `diff "foo`, err: `unterminated " quote`},
+	} {
+		_, err := splitCommand(tc.cmd)
+		if test.Error(t, err, "#%d", i) {
+			test.EqualStr(t, err.Error(), tc.err, "#%d", i)
+		}
+	}
+}
@@ -339,6 +339,44 @@ func (t *T) JSON(got, expectedJSON interface{}, params []interface{}, args ...in
 	return t.Cmp(got, JSON(expectedJSON, params...), args...)
 }
 
+// JSONLines is a shortcut for:
+//
+//   t.Cmp(got, td.JSONLines(expectedValue), args...)
+//
+// See https://pkg.go.dev/github.com/maxatome/go-testdeep/td#JSONLines for details.
+//
+// Returns true if the test is OK, false if it fails.
+//
+// "args..." are optional and allow to name the test. This name is
+// used in case of failure to qualify the test. If len(args) > 1 and
+// the first item of "args" is a string and contains a '%' rune then
+// fmt.Fprintf is used to compose the name, else "args" are passed to
+// fmt.Fprint. Do not forget it is the name of the test, not the
+// reason of a potential failure.
+func (t *T) JSONLines(got, expectedValue interface{}, args ...interface{}) bool {
+	t.Helper()
+	return t.Cmp(got, JSONLines(expectedValue), args...)
+}
+
+// JSONPath is a shortcut for:
+//
+//   t.Cmp(got, td.JSONPath(expr, expectedValue), args...)
+//
+// See https://pkg.go.dev/github.com/maxatome/go-testdeep/td#JSONPath for details.
+//
+// Returns true if the test is OK, false if it fails.
+//
+// "args..." are optional and allow to name the test. This name is
+// used in case of failure to qualify the test. If len(args) > 1 and
+// the first item of "args" is a string and contains a '%' rune then
+// fmt.Fprintf is used to compose the name, else "args" are passed to
+// fmt.Fprint. Do not forget it is the name of the test, not the
+// reason of a potential failure.
+func (t *T) JSONPath(got interface{}, expr string, expectedValue interface{}, args ...interface{}) bool {
+	t.Helper()
+	return t.Cmp(got, JSONPath(expr, expectedValue), args...)
+}
+
 // JSONPointer is a shortcut for:
 //
 //   t.Cmp(got, td.JSONPointer(pointer, expectedValue), args...)
@@ -358,6 +396,25 @@ func (t *T) JSONPointer(got interface{}, pointer string, expectedValue interface
 	return t.Cmp(got, JSONPointer(pointer, expectedValue), args...)
 }
 
+// JSONSchema is a shortcut for:
+//
+//   t.Cmp(got, td.JSONSchema(schema), args...)
+//
+// See https://pkg.go.dev/github.com/maxatome/go-testdeep/td#JSONSchema for details.
+//
+// Returns true if the test is OK, false if it fails.
+//
+// "args..." are optional and allow to name the test. This name is
+// used in case of failure to qualify the test. If len(args) > 1 and
+// the first item of "args" is a string and contains a '%' rune then
+// fmt.Fprintf is used to compose the name, else "args" are passed to
+// fmt.Fprint. Do not forget it is the name of the test, not the
+// reason of a potential failure.
+func (t *T) JSONSchema(got, schema interface{}, args ...interface{}) bool {
+	t.Helper()
+	return t.Cmp(got, JSONSchema(schema), args...)
+}
+
 // Keys is a shortcut for:
 //
 //   t.Cmp(got, td.Keys(val), args...)
@@ -803,6 +860,25 @@ func (t *T) Shallow(got, expectedPtr interface{}, args ...interface{}) bool {
 	return t.Cmp(got, Shallow(expectedPtr), args...)
 }
 
+// SJSONPath is a shortcut for:
+//
+//   t.Cmp(got, td.SJSONPath(expr, expectedValue), args...)
+//
+// See https://pkg.go.dev/github.com/maxatome/go-testdeep/td#SJSONPath for details.
+//
+// Returns true if the test is OK, false if it fails.
+//
+// "args..." are optional and allow to name the test. This name is
+// used in case of failure to qualify the test. If len(args) > 1 and
+// the first item of "args" is a string and contains a '%' rune then
+// fmt.Fprintf is used to compose the name, else "args" are passed to
+// fmt.Fprint. Do not forget it is the name of the test, not the
+// reason of a potential failure.
+func (t *T) SJSONPath(got interface{}, expr string, expectedValue interface{}, args ...interface{}) bool {
+	t.Helper()
+	return t.Cmp(got, SJSONPath(expr, expectedValue), args...)
+}
+
 // Slice is a shortcut for:
 //
 //   t.Cmp(got, td.Slice(model, expectedEntries), args...)
@@ -974,6 +1050,25 @@ func (t *T) SubSetOf(got interface{}, expectedItems []interface{}, args ...inter
 	return t.Cmp(got, SubSetOf(expectedItems...), args...)
 }
 
+// SubYAMLOf is a shortcut for:
+//
+//   t.Cmp(got, td.SubYAMLOf(expectedYAML, params...), args...)
+//
+// See https://pkg.go.dev/github.com/maxatome/go-testdeep/td#SubYAMLOf for details.
+//
+// Returns true if the test is OK, false if it fails.
+//
+// "args..." are optional and allow to name the test. This name is
+// used in case of failure to qualify the test. If len(args) > 1 and
+// the first item of "args" is a string and contains a '%' rune then
+// fmt.Fprintf is used to compose the name, else "args" are passed to
+// fmt.Fprint. Do not forget it is the name of the test, not the
+// reason of a potential failure.
+func (t *T) SubYAMLOf(got, expectedYAML interface{}, params []interface{}, args ...interface{}) bool {
+	t.Helper()
+	return t.Cmp(got, SubYAMLOf(expectedYAML, params...), args...)
+}
+
 // SuperBagOf is a shortcut for:
 //
 //   t.Cmp(got, td.SuperBagOf(expectedItems...), args...)
@@ -1050,6 +1145,25 @@ func (t *T) SuperSetOf(got interface{}, expectedItems []interface{}, args ...int
 	return t.Cmp(got, SuperSetOf(expectedItems...), args...)
 }
 
+// SuperYAMLOf is a shortcut for:
+//
+//   t.Cmp(got, td.SuperYAMLOf(expectedYAML, params...), args...)
+//
+// See https://pkg.go.dev/github.com/maxatome/go-testdeep/td#SuperYAMLOf for details.
+//
+// Returns true if the test is OK, false if it fails.
+//
+// "args..." are optional and allow to name the test. This name is
+// used in case of failure to qualify the test. If len(args) > 1 and
+// the first item of "args" is a string and contains a '%' rune then
+// fmt.Fprintf is used to compose the name, else "args" are passed to
+// fmt.Fprint. Do not forget it is the name of the test, not the
+// reason of a potential failure.
+func (t *T) SuperYAMLOf(got, expectedYAML interface{}, params []interface{}, args ...interface{}) bool {
+	t.Helper()
+	return t.Cmp(got, SuperYAMLOf(expectedYAML, params...), args...)
+}
+
 // TruncTime is a shortcut for:
 //
 //   t.Cmp(got, td.TruncTime(expectedTime, trunc), args...)
@@ -1092,6 +1206,44 @@ func (t *T) Values(got, val interface{}, args ...interface{}) bool {
 	return t.Cmp(got, Values(val), args...)
 }
 
+// XML is a shortcut for:
+//
+//   t.Cmp(got, td.XML(expectedXML, params...), args...)
+//
+// See https://pkg.go.dev/github.com/maxatome/go-testdeep/td#XML for details.
+//
+// Returns true if the test is OK, false if it fails.
+//
+// "args..." are optional and allow to name the test. This name is
+// used in case of failure to qualify the test. If len(args) > 1 and
+// the first item of "args" is a string and contains a '%' rune then
+// fmt.Fprintf is used to compose the name, else "args" are passed to
+// fmt.Fprint. Do not forget it is the name of the test, not the
+// reason of a potential failure.
+func (t *T) XML(got, expectedXML interface{}, params []interface{}, args ...interface{}) bool {
+	t.Helper()
+	return t.Cmp(got, XML(expectedXML, params...), args...)
+}
+
+// YAML is a shortcut for:
+//
+//   t.Cmp(got, td.YAML(expectedYAML, params...), args...)
+//
+// See https://pkg.go.dev/github.com/maxatome/go-testdeep/td#YAML for details.
+//
+// Returns true if the test is OK, false if it fails.
+//
+// "args..." are optional and allow to name the test. This name is
+// used in case of failure to qualify the test. If len(args) > 1 and
+// the first item of "args" is a string and contains a '%' rune then
+// fmt.Fprintf is used to compose the name, else "args" are passed to
+// fmt.Fprint. Do not forget it is the name of the test, not the
+// reason of a potential failure.
+func (t *T) YAML(got, expectedYAML interface{}, params []interface{}, args ...interface{}) bool {
+	t.Helper()
+	return t.Cmp(got, YAML(expectedYAML, params...), args...)
+}
+
 // Zero is a shortcut for:
 //
 //   t.Cmp(got, td.Zero(), args...)
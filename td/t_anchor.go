@@ -60,6 +60,27 @@ func AddAnchorableStructType(fn interface{}) {
 //   - a go value: returning type is the type of the value, whatever the value is
 //   - a reflect.Type
 //
+// All types can be anchored, except bool, unsafe.Pointer, slices of
+// zero-sized items and interfaces with unexported methods:
+//   - numbers and strings anchors are values close to the limits of
+//     their type;
+//   - channels, functions, maps, pointers and slices anchors are
+//     identified by their address. Note that calling an anchored
+//     function panics;
+//   - interfaces anchors are values of a dedicated struct type
+//     implementing the interface, so reflect.TypeOf((*io.Reader)(nil)).Elem()
+//     can be used as "model" to anchor an io.Reader;
+//   - structs anchors are built by the function recorded using
+//     AddAnchorableStructType if any, as for time.Time. Otherwise one
+//     exported field is set to an anchor, all others keeping their
+//     zero value. So a struct can be anchored as soon as it contains
+//     an exported field able to be anchored;
+//   - arrays anchors have their first item set to an anchor.
+//
+// As a bool cannot be anchored, a struct containing a bool field
+// needing an operator has to be anchored as a whole, typically using
+// Struct or SStruct operators.
+//
 // It returns a typed value ready to be embed in a go data structure to
 // be compared using T.Cmp or T.CmpLax:
 //
@@ -124,10 +145,20 @@ func AddAnchorableStructType(fn interface{}) {
 // checking of golang.
 //
 // By default, the value returned by Anchor can only be used in the
-// next T.Cmp or T.CmpLax call. To make it persistent across calls,
-// see SetAnchorsPersist and AnchorsPersistTemporarily methods.
+// next T.Cmp or T.CmpLax call using it. To make it persistent across
+// calls, see SetAnchorsPersist and AnchorsPersistTemporarily methods.
+//
+// Anchor must not be called concurrently by several goroutines of a
+// same test: as soon as a comparison ends and no other is in
+// progress, all non-persistent anchors are freed, including the ones
+// another goroutine did not use yet. *T instances created by NewT for
+// a same test share their anchors, so use RunGoroutines method
+// instead, giving each goroutine its own anchors.
 //
 // See A method for a shorter synonym of Anchor.
+//
+// Since go 1.18, the generic Anchor and A functions avoid the type
+// assertion, as in td.A[int](t, td.Between(40, 50)).
 func (t *T) Anchor(operator TestDeep, model ...interface{}) interface{} {
 	if operator == nil {
 		panic(color.Bad("Cannot anchor a nil TestDeep operator"))
@@ -147,8 +178,11 @@ func (t *T) Anchor(operator TestDeep, model ...interface{}) interface{} {
 			}
 		}
 
+		// An operator working on a type implementing an interface can
+		// be anchored as this interface
 		typeBehind := operator.TypeBehind()
-		if typeBehind != nil && typeBehind != typ {
+		if typeBehind != nil && typeBehind != typ &&
+			(typ.Kind() != reflect.Interface || !typeBehind.Implements(typ)) {
 			panic(color.Bad("Operator %s TypeBehind() returned %s which differs from model type %s. Omit model or ensure its type is %[2]s",
 				operator.GetLocation().Func, typeBehind, typ))
 		}
@@ -189,8 +223,14 @@ func (t *T) A(operator TestDeep, model ...interface{}) interface{} {
 	return t.Anchor(operator, model...)
 }
 
-func (t *T) resetNonPersistentAnchors() {
-	t.Config.anchors.ResetAnchors(false)
+// useAnchors records a comparison using anchors is in progress, and
+// returns a function to be deferred, freeing all anchors at the end
+// of the last comparison in progress, unless anchors persistence is
+// enabled. Goroutines needing their own anchors should use distinct
+// *T instances, as RunGoroutines provides.
+func (t *T) useAnchors() func() {
+	t.Config.anchors.BeginUse()
+	return t.Config.anchors.EndUse
 }
 
 // ResetAnchors frees all operators anchored with Anchor
@@ -198,7 +238,9 @@ func (t *T) resetNonPersistentAnchors() {
 // with SetAnchorsPersist, there is no need to call this
 // method. Anchored operators are automatically freed after each Cmp,
 // CmpDeeply and CmpPanic call (or others methods calling them behind
-// the scene).
+// the scene) using them. Note that when several goroutines compare
+// concurrently, anchored operators are freed when the last
+// comparison ends.
 func (t *T) ResetAnchors() {
 	t.Config.anchors.ResetAnchors(true)
 }
@@ -0,0 +1,59 @@
+// Copyright (c) 2021, Maxime Soulé
+// All rights reserved.
+//
+// This source code is licensed under the BSD-style license found in the
+// LICENSE file in the root directory of this source tree.
+
+//go:build go1.18
+// +build go1.18
+
+package td
+
+import (
+	"reflect"
+)
+
+// Anchor is the generic counterpart of (*T).Anchor method. It
+// returns a value of type X allowing to anchor the TestDeep operator
+// "operator" in a go classic litteral like a struct, slice, array or
+// map value, without any type assertion:
+//
+//   func TestFunc(tt *testing.T) {
+//     got := Func()
+//
+//     t := td.NewT(tt)
+//     t.Cmp(got, &MyStruct{
+//       Name:    "Bob",
+//       Details: &MyDetails{
+//         Nick: td.Anchor[string](t, td.HasPrefix("Bobby")),
+//         Age:  td.Anchor[int](t, td.Between(40, 50)),
+//       },
+//     })
+//   }
+//
+// If the TypeBehind method of "operator" returns non-nil, it has to
+// be X.
+//
+// See (*T).Anchor method for details, and A function for a shorter
+// synonym of Anchor.
+func Anchor[X any](t *T, operator TestDeep) X {
+	return t.Anchor(operator, reflect.TypeOf((*X)(nil)).Elem()).(X)
+}
+
+// A is a synonym for Anchor function.
+//
+//   func TestFunc(tt *testing.T) {
+//     got := Func()
+//
+//     t := td.NewT(tt)
+//     t.Cmp(got, &MyStruct{
+//       Name:    "Bob",
+//       Details: &MyDetails{
+//         Nick: td.A[string](t, td.HasPrefix("Bobby")),
+//         Age:  td.A[int](t, td.Between(40, 50)),
+//       },
+//     })
+//   }
+func A[X any](t *T, operator TestDeep) X {
+	return Anchor[X](t, operator)
+}
@@ -0,0 +1,69 @@
+// Copyright (c) 2021, Maxime Soulé
+// All rights reserved.
+//
+// This source code is licensed under the BSD-style license found in the
+// LICENSE file in the root directory of this source tree.
+
+//go:build go1.18
+// +build go1.18
+
+package td_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/maxatome/go-testdeep/internal/test"
+	"github.com/maxatome/go-testdeep/td"
+)
+
+func TestAnchorGeneric(tt *testing.T) {
+	ttt := test.NewTestingTB(tt.Name())
+	t := td.NewT(ttt)
+
+	type MyStruct struct {
+		PNum *int
+		Num  int64
+		Str  string
+		Time time.Time
+	}
+	n := 42
+	got := MyStruct{
+		PNum: &n,
+		Num:  136,
+		Str:  "Pipo bingo",
+		Time: time.Date(2019, 1, 2, 11, 22, 33, 123456, time.UTC),
+	}
+
+	// Using td.Anchor()
+	td.CmpTrue(tt,
+		t.Cmp(got, MyStruct{
+			PNum: td.Anchor[*int](t, td.Ptr(td.Between(40, 45))),
+			Num:  td.Anchor[int64](t, td.Between(int64(135), int64(137))),
+			Str:  td.Anchor[string](t, td.HasPrefix("Pipo")),
+			Time: td.Anchor[time.Time](t, td.TruncTime(time.Date(2019, 1, 2, 11, 22, 0, 0, time.UTC), time.Minute)),
+		}))
+
+	// Using td.A()
+	td.CmpTrue(tt,
+		t.Cmp(got, MyStruct{
+			PNum: td.A[*int](t, td.Ptr(td.Between(40, 45))),
+			Num:  td.A[int64](t, td.Between(int64(135), int64(137))),
+			Str:  td.A[string](t, td.HasPrefix("Pipo")),
+			Time: td.A[time.Time](t, td.Ignore()),
+		}))
+
+	td.CmpFalse(tt,
+		t.Cmp(got, MyStruct{
+			PNum: &n,
+			Num:  td.A[int64](t, td.Gt(int64(136))),
+			Str:  "Pipo bingo",
+			Time: got.Time,
+		}))
+
+	test.CheckPanic(tt, func() { td.A[int](t, nil) },
+		"Cannot anchor a nil TestDeep operator")
+
+	test.CheckPanic(tt, func() { td.A[float64](t, td.Between(1, 2)) },
+		"Operator Between TypeBehind() returned int which differs from model type float64. Omit model or ensure its type is int")
+}
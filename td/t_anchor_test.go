@@ -7,6 +7,10 @@
 package td_test
 
 import (
+	"io"
+	"io/ioutil"
+	"reflect"
+	"strings"
 	"testing"
 	"time"
 
@@ -100,6 +104,81 @@ func TestAnchor(tt *testing.T) {
 	})
 }
 
+func TestAnchorNotReached(tt *testing.T) {
+	type S struct{ A int }
+
+	ttt := test.NewTestingTB(tt.Name())
+	t := td.NewT(ttt)
+
+	// The anchor is not reached by the failing comparison, but is
+	// freed at its end anyway
+	v := t.A(td.Gt(1000), 0).(int)
+	td.CmpFalse(tt, t.Cmp("string", S{A: v}))
+
+	td.CmpFalse(tt, t.Cmp(S{A: 5}, S{A: v}))
+	td.CmpNot(tt, ttt.LastMessage(), td.Contains("> 1000"))
+	td.CmpTrue(tt, t.Cmp(S{A: v}, S{A: v}))
+}
+
+func TestAnchorAnyType(tt *testing.T) {
+	ttt := test.NewTestingTB(tt.Name())
+	t := td.NewT(ttt)
+
+	type Sub struct {
+		Enabled bool
+		Label   string
+	}
+	type MyStruct struct {
+		Fn     func() int
+		Reader io.Reader
+		Any    interface{}
+		Sub    Sub
+		Ch     chan int
+		Arr    [2]int
+	}
+
+	got := MyStruct{
+		Fn:     func() int { return 42 },
+		Reader: strings.NewReader("foo"),
+		Any:    12,
+		Sub:    Sub{Enabled: true, Label: "bar"},
+		Ch:     make(chan int),
+		Arr:    [2]int{1, 2},
+	}
+
+	readerType := reflect.TypeOf((*io.Reader)(nil)).Elem()
+	anyType := reflect.TypeOf((*interface{})(nil)).Elem()
+
+	td.CmpTrue(tt,
+		t.Cmp(got, MyStruct{
+			Fn:     t.A(td.Smuggle(func(fn func() int) int { return fn() }, 42), got.Fn).(func() int),
+			Reader: t.A(td.Smuggle(ioutil.ReadAll, []byte("foo")), readerType).(io.Reader),
+			Any:    t.A(td.Between(10, 20), anyType),
+			Sub:    t.A(td.Struct(Sub{Enabled: true}, td.StructFields{"Label": td.HasPrefix("b")})).(Sub),
+			Ch:     t.A(td.NotNil(), got.Ch).(chan int),
+			Arr:    t.A(td.Contains(2), [2]int{}).([2]int),
+		}))
+
+	td.CmpFalse(tt,
+		t.Cmp(got, MyStruct{
+			Fn:     t.A(td.NotNil(), got.Fn).(func() int),
+			Reader: got.Reader,
+			Any:    12,
+			Sub:    t.A(td.SStruct(Sub{Enabled: false}, td.StructFields{"Label": td.Ignore()})).(Sub),
+			Ch:     got.Ch,
+			Arr:    got.Arr,
+		}))
+	test.IsTrue(tt, strings.Contains(ttt.LastMessage(), "DATA.Sub.Enabled: values differ"))
+
+	// Operator type behind implements the interface
+	td.CmpTrue(tt,
+		t.Cmp(MyStruct{Reader: strings.NewReader("x")},
+			MyStruct{Reader: t.A(td.Isa(&strings.Reader{}), readerType).(io.Reader)}))
+
+	test.CheckPanic(tt, func() { t.A(td.Ignore(), true) },
+		"bool kind is not supported as an anchor")
+}
+
 type privStruct struct {
 	num int64
 }
@@ -0,0 +1,211 @@
+// Copyright (c) 2021, Maxime Soulé
+// All rights reserved.
+//
+// This source code is licensed under the BSD-style license found in the
+// LICENSE file in the root directory of this source tree.
+
+package td
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/maxatome/go-testdeep/internal/anchors"
+	"github.com/maxatome/go-testdeep/internal/color"
+	"github.com/maxatome/go-testdeep/internal/trace"
+)
+
+// goroutineTB is the testing.TB used by each goroutine launched by
+// RunGoroutines. Logs and failures are collected, to be reported by
+// the goroutine running the test once all goroutines end.
+type goroutineTB struct {
+	testing.TB
+	index int
+
+	mu     sync.Mutex
+	logs   []string
+	failed bool
+	fatal  bool
+}
+
+var _ testing.TB = (*goroutineTB)(nil)
+
+func (g *goroutineTB) log(s string) {
+	s = strings.TrimSuffix(s, "\n")
+	if st := trace.Retrieve(0, ""); len(st) > 0 && st[0].FileLine != "" {
+		s = st[0].FileLine + ": " + s
+	}
+
+	g.mu.Lock()
+	g.logs = append(g.logs, s)
+	g.mu.Unlock()
+}
+
+func (g *goroutineTB) fail(fatal bool) {
+	g.mu.Lock()
+	g.failed = true
+	g.fatal = g.fatal || fatal
+	g.mu.Unlock()
+}
+
+func (g *goroutineTB) Helper() {}
+
+func (g *goroutineTB) Log(args ...interface{}) {
+	g.log(fmt.Sprintln(args...))
+}
+
+func (g *goroutineTB) Logf(format string, args ...interface{}) {
+	g.log(fmt.Sprintf(format, args...))
+}
+
+func (g *goroutineTB) Error(args ...interface{}) {
+	g.log(fmt.Sprintln(args...))
+	g.fail(false)
+}
+
+func (g *goroutineTB) Errorf(format string, args ...interface{}) {
+	g.log(fmt.Sprintf(format, args...))
+	g.fail(false)
+}
+
+func (g *goroutineTB) Fail() {
+	g.fail(false)
+}
+
+// FailNow marks the goroutine as failed and stops it. The test itself
+// is stopped once all goroutines end.
+func (g *goroutineTB) FailNow() {
+	g.fail(true)
+	runtime.Goexit()
+}
+
+func (g *goroutineTB) Fatal(args ...interface{}) {
+	g.log(fmt.Sprintln(args...))
+	g.FailNow()
+}
+
+func (g *goroutineTB) Fatalf(format string, args ...interface{}) {
+	g.log(fmt.Sprintf(format, args...))
+	g.FailNow()
+}
+
+func (g *goroutineTB) Failed() bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.failed
+}
+
+// SkipNow only stops the goroutine, the test itself is not skipped.
+func (g *goroutineTB) SkipNow() {
+	runtime.Goexit()
+}
+
+func (g *goroutineTB) Skip(args ...interface{}) {
+	g.log(fmt.Sprintln(args...))
+	g.SkipNow()
+}
+
+func (g *goroutineTB) Skipf(format string, args ...interface{}) {
+	g.log(fmt.Sprintf(format, args...))
+	g.SkipNow()
+}
+
+// report reports collected logs and failures of g to "t".
+func (g *goroutineTB) report(t *T) {
+	t.Helper()
+
+	if len(g.logs) == 0 {
+		if g.failed {
+			t.Errorf("goroutine #%d failed", g.index)
+		}
+		return
+	}
+
+	msg := fmt.Sprintf("goroutine #%d", g.index)
+	if g.failed {
+		msg += " failed"
+	}
+	msg += ":\n\t" + strings.Replace(strings.Join(g.logs, "\n"), "\n", "\n\t", -1)
+
+	if g.failed {
+		t.Error(msg)
+	} else {
+		t.Log(msg)
+	}
+}
+
+// RunGoroutines runs "fn" in "n" goroutines, then waits for all of
+// them to end. Each goroutine receives its index, from 0 to n-1, and
+// its own *T instance, sharing the configuration of t but having its
+// own anchors.
+//
+// Each goroutine *T does not report directly to the underlying
+// testing.TB: its logs and failures are collected, then reported by
+// t, once all goroutines ended, prefixed by the goroutine index as in
+// "goroutine #3 failed:". If a goroutine calls FailNow, directly or
+// not (as with Fatal or when the FailureIsFatal flag is set), only
+// this goroutine is stopped; t.FailNow is then called once all
+// goroutines ended. A panic in a goroutine is reported as a failure
+// of this goroutine.
+//
+//   func TestWorkers(tt *testing.T) {
+//     t := td.NewT(tt)
+//
+//     t.RunGoroutines(8, func(t *td.T, i int) {
+//       res := worker(i)
+//       t.Cmp(res, Result{
+//         ID:    t.A(td.Gt(0), 0).(int),
+//         Index: i,
+//       })
+//     })
+//   }
+//
+// It returns true if no goroutine failed.
+//
+// RunGoroutines panics if "n" is negative or if "fn" is nil.
+func (t *T) RunGoroutines(n int, fn func(t *T, i int)) bool {
+	t.Helper()
+
+	if n < 0 || fn == nil {
+		panic(color.Bad("usage: RunGoroutines(N >= 0, FUNC)"))
+	}
+
+	gtbs := make([]*goroutineTB, n)
+
+	var wg sync.WaitGroup
+	wg.Add(n)
+	for i := range gtbs {
+		gtb := &goroutineTB{TB: t.TB, index: i}
+		gtbs[i] = gtb
+
+		nt := NewT(gtb, t.Config)
+		nt.Config.anchors = anchors.NewInfo()
+
+		go func(i int) {
+			defer wg.Done()
+			defer func() {
+				if r := recover(); r != nil {
+					gtb.log(fmt.Sprintf("panic: %v", r))
+					gtb.fail(false)
+				}
+			}()
+			fn(nt, i)
+		}(i)
+	}
+	wg.Wait()
+
+	ok, fatal := true, false
+	for _, gtb := range gtbs {
+		gtb.report(t)
+		ok = ok && !gtb.failed
+		fatal = fatal || gtb.fatal
+	}
+
+	if fatal {
+		t.FailNow()
+	}
+	return ok
+}
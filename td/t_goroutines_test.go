@@ -0,0 +1,130 @@
+// Copyright (c) 2021, Maxime Soulé
+// All rights reserved.
+//
+// This source code is licensed under the BSD-style license found in the
+// LICENSE file in the root directory of this source tree.
+
+package td_test
+
+import (
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/maxatome/go-testdeep/internal/test"
+	"github.com/maxatome/go-testdeep/td"
+)
+
+func TestRunGoroutines(tt *testing.T) {
+	type Result struct {
+		ID    int
+		Index int
+	}
+
+	tt.Run("success", func(tt *testing.T) {
+		ttt := test.NewTestingTB(tt.Name())
+		t := td.NewT(ttt)
+
+		var mu sync.Mutex
+		seen := map[int]bool{}
+
+		ok := t.RunGoroutines(20, func(t *td.T, i int) {
+			mu.Lock()
+			seen[i] = true
+			mu.Unlock()
+
+			for j := 0; j < 10; j++ {
+				t.Cmp(Result{ID: 1000 + i, Index: i}, Result{
+					ID:    t.A(td.Gt(999)).(int),
+					Index: i,
+				})
+			}
+		})
+		test.IsTrue(tt, ok)
+		test.IsFalse(tt, ttt.Failed())
+		test.EqualInt(tt, len(seen), 20)
+		test.EqualInt(tt, len(ttt.Messages), 0)
+	})
+
+	tt.Run("failures", func(tt *testing.T) {
+		ttt := test.NewTestingTB(tt.Name())
+		t := td.NewT(ttt)
+
+		ok := t.RunGoroutines(4, func(t *td.T, i int) {
+			switch i {
+			case 1:
+				t.Cmp(i, 12)
+			case 2:
+				t.Log("a log")
+			case 3:
+				panic("boom!")
+			}
+		})
+		test.IsFalse(tt, ok)
+		test.IsTrue(tt, ttt.Failed())
+		test.IsFalse(tt, ttt.IsFatal)
+		test.EqualInt(tt, len(ttt.Messages), 3)
+
+		test.IsTrue(tt, strings.HasPrefix(ttt.Messages[0], "goroutine #1 failed:\n\ttd/t_goroutines_test.go:"))
+		test.IsTrue(tt, strings.Contains(ttt.Messages[0], "\n\tDATA: values differ\n"))
+		test.IsFalse(tt, strings.Contains(ttt.Messages[0], "This is how we got here"))
+		test.IsTrue(tt, strings.HasPrefix(ttt.Messages[1], "goroutine #2:\n\ttd/t_goroutines_test.go:"))
+		test.IsTrue(tt, strings.HasSuffix(ttt.Messages[1], ": a log"))
+		test.IsTrue(tt, strings.HasPrefix(ttt.Messages[2], "goroutine #3 failed:\n\t"))
+		test.IsTrue(tt, strings.HasSuffix(ttt.Messages[2], "panic: boom!"))
+	})
+
+	tt.Run("fatal", func(tt *testing.T) {
+		ttt := test.NewTestingTB(tt.Name())
+		t := td.NewT(ttt).FailureIsFatal()
+
+		var mu sync.Mutex
+		reached := 0
+
+		ok := t.RunGoroutines(3, func(t *td.T, i int) {
+			t.Cmp(i, 1)
+			mu.Lock()
+			reached++
+			mu.Unlock()
+		})
+		test.IsFalse(tt, ok)
+		test.IsTrue(tt, ttt.IsFatal)
+		test.EqualInt(tt, reached, 1)
+		test.EqualInt(tt, len(ttt.Messages), 2)
+		test.IsTrue(tt, strings.HasPrefix(ttt.Messages[0], "goroutine #0 failed:\n\t"))
+		test.IsTrue(tt, strings.HasPrefix(ttt.Messages[1], "goroutine #2 failed:\n\t"))
+	})
+
+	tt.Run("errors", func(tt *testing.T) {
+		t := td.NewT(test.NewTestingTB(tt.Name()))
+
+		test.CheckPanic(tt, func() { t.RunGoroutines(-1, func(*td.T, int) {}) },
+			"usage: RunGoroutines(N >= 0, FUNC)")
+		test.CheckPanic(tt, func() { t.RunGoroutines(1, nil) },
+			"usage: RunGoroutines(N >= 0, FUNC)")
+	})
+}
+
+func TestConcurrentAnchors(tt *testing.T) {
+	ttt := test.NewTestingTB(tt.Name())
+	t := td.NewT(ttt)
+
+	// Goroutine #1 anchors and compares while the anchor of goroutine
+	// #0 is not used yet: as each goroutine has its own anchors, the
+	// end of goroutine #1 comparison does not free goroutine #0 one
+	anchored, compared := make(chan struct{}), make(chan struct{})
+	ok := t.RunGoroutines(2, func(t *td.T, i int) {
+		if i == 0 {
+			id := t.A(td.Gt(999)).(int)
+			close(anchored)
+			<-compared
+			t.Cmp(1000, id)
+			return
+		}
+		<-anchored
+		t.Cmp(2000, t.A(td.Gt(999)).(int))
+		close(compared)
+	})
+	test.IsTrue(tt, ok)
+	test.IsFalse(tt, ttt.Failed())
+}
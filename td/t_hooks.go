@@ -7,6 +7,8 @@
 package td
 
 import (
+	"reflect"
+
 	"github.com/maxatome/go-testdeep/internal/color"
 )
 
@@ -19,8 +21,14 @@ import (
 //   func (A, A) error
 // First arg is always "got", and second is always "expected".
 //
-// A cannot be an interface. This retriction can be removed in the
-// future, if really needed.
+// If A is an interface, the hook applies to all types implementing
+// it, so func (got, expected proto.Message) bool applies to all
+// protobuf messages. See WithCmpHookIf to apply a hook depending on a
+// predicate. When several hooks match a same type, the hook recorded
+// for this exact type wins, then the last recorded one whose A
+// interface is implemented by this type, then the last recorded one
+// whose predicate matches. Interface hooks never apply to interface
+// types, but to the values they contain.
 //
 // This function is called as soon as possible each time the type A is
 // encountered for "got" while "expected" type is assignable to A.
@@ -66,7 +74,8 @@ import (
 //   }
 //
 // There is no way to add or remove hooks of an existing *T instance,
-// only create a new one with this method or WithSmuggleHooks to add some.
+// only create a new one with this method or WithSmuggleHooks to add
+// some, or RemoveCmpHooks to remove some.
 //
 // WithCmpHooks panics if an item of "fns" is not a function or if its
 // signature does not match the expected ones.
@@ -89,8 +98,9 @@ func (t *T) WithCmpHooks(fns ...interface{}) *T {
 //   func (A) B
 //   func (A) (B, error)
 //
-// A cannot be an interface. This retriction can be removed in the
-// future, if really needed.
+// If A is an interface, the hook applies to all types implementing
+// it. See WithSmuggleHookIf to apply a hook depending on a
+// predicate. Precedence rules are the same as WithCmpHooks ones.
 //
 // B cannot be an interface. If you have a use case, we can talk about it.
 //
@@ -129,7 +139,8 @@ func (t *T) WithCmpHooks(fns ...interface{}) *T {
 //   }
 //
 // There is no way to add or remove hooks of an existing *T instance,
-// only create a new one with this method or WithCmpHooks to add some.
+// only create a new one with this method or WithCmpHooks to add some,
+// or RemoveSmuggleHooks to remove some.
 //
 // WithSmuggleHooks panics if an item of "fns" is not a function or if its
 // signature does not match the expected ones.
@@ -144,6 +155,192 @@ func (t *T) WithSmuggleHooks(fns ...interface{}) *T {
 	return t
 }
 
+// WithCmpHookIf returns a new *T instance with a new Cmp hook "fn"
+// recorded, applying to each type for which "match" returns true.
+//
+// "fn" has the same possible signatures as WithCmpHooks ones:
+//   func (A, A) bool
+//   func (A, A) error
+// but the hook only applies to types assignable to A, so A is
+// typically interface{} or another interface. "match" is called once
+// per type, its result being cached. See WithCmpHooks for precedence
+// rules.
+//
+//   // Compare all pointers to structs of package "mypkg" using their
+//   // Equal method
+//   t = t.WithCmpHookIf(
+//     func(typ reflect.Type) bool {
+//       return typ.Kind() == reflect.Ptr &&
+//         typ.Elem().PkgPath() == "example.com/mypkg"
+//     },
+//     func(got, expected interface{}) bool {
+//       return got.(interface{ Equal(interface{}) bool }).Equal(expected)
+//     })
+//
+// WithCmpHookIf panics if "match" is nil, if "fn" is not a function or
+// if its signature does not match the expected ones.
+func (t *T) WithCmpHookIf(match func(reflect.Type) bool, fn interface{}) *T {
+	t = t.copyWithHooks()
+
+	err := t.Config.hooks.AddCmpHookIf(match, fn)
+	if err != nil {
+		panic(color.Bad("WithCmpHookIf " + err.Error()))
+	}
+
+	return t
+}
+
+// WithSmuggleHookIf returns a new *T instance with a new Smuggle hook
+// "fn" recorded, applying to each type for which "match" returns
+// true.
+//
+// "fn" has the same possible signatures as WithSmuggleHooks ones:
+//   func (A) B
+//   func (A) (B, error)
+// but the hook only applies to types assignable to A, so A is
+// typically interface{} or another interface. "match" is called once
+// per type, its result being cached. See WithCmpHooks for precedence
+// rules.
+//
+//   // Each encountered signed integer is converted to int64
+//   t = t.WithSmuggleHookIf(
+//     func(typ reflect.Type) bool {
+//       return typ.Kind() >= reflect.Int && typ.Kind() <= reflect.Int64
+//     },
+//     func(got interface{}) int64 {
+//       return reflect.ValueOf(got).Int()
+//     })
+//
+// WithSmuggleHookIf panics if "match" is nil, if "fn" is not a
+// function or if its signature does not match the expected ones.
+func (t *T) WithSmuggleHookIf(match func(reflect.Type) bool, fn interface{}) *T {
+	t = t.copyWithHooks()
+
+	err := t.Config.hooks.AddSmuggleHookIf(match, fn)
+	if err != nil {
+		panic(color.Bad("WithSmuggleHookIf " + err.Error()))
+	}
+
+	return t
+}
+
+// WithFormatters returns a new *T instance with new formatters
+// recorded using functions passed in "fns". These formatters are used
+// to render got and expected values (as well as their contents) in
+// failure reports, instead of the default go-spew rendering. Only
+// rendering is affected, not comparisons.
+//
+// Each function in "fns" has to be a function with the following
+// signature:
+//   func (A) string
+//
+// If A is an interface, the formatter applies to all types
+// implementing it. Precedence rules are the same as WithCmpHooks ones.
+//
+//   func TestFormatter(tt *testing.T) {
+//     t := td.NewT(tt)
+//
+//     // Each encountered uuid.UUID is rendered using its String method
+//     t = t.WithFormatters((uuid.UUID).String)
+//     t.Cmp(got, expected)
+//   }
+//
+// These formatters take precedence over global ones recorded using
+// AddFormatters function. See also PreferStringer method.
+//
+// There is no way to add or remove formatters of an existing *T
+// instance, only create a new one with this method to add some.
+//
+// WithFormatters panics if an item of "fns" is not a function or if
+// its signature does not match the expected one.
+func (t *T) WithFormatters(fns ...interface{}) *T {
+	t = t.copyWithHooks()
+
+	err := t.Config.hooks.AddFormatHooks(fns)
+	if err != nil {
+		panic(color.Bad("WithFormatters " + err.Error()))
+	}
+
+	return t
+}
+
+// WithHooks returns a new *T instance using the hooks of "sets" in
+// addition to the sets already used by t, including those of
+// DefaultContextConfig.Hooks. Hooks of a set take precedence over
+// hooks of previous sets, and hooks recorded on t using WithCmpHooks,
+// WithSmuggleHooks, WithFormatters and so on take precedence over all
+// of them.
+//
+//   // In a shared package
+//   var Hooks = td.NewHooks("testutil").
+//     AddCmpHooks((time.Time).Equal).
+//     AddSmuggleHooks(func(d decimal.Decimal) string { return d.String() })
+//
+//   // In tests
+//   func TestMyFunc(tt *testing.T) {
+//     t := td.NewT(tt).WithHooks(testutil.Hooks)
+//     t.Cmp(got, expected)
+//   }
+//
+// Sets are shared, not copied: hooks added to a set later are also
+// used by t. See Hooks type for details.
+func (t *T) WithHooks(sets ...*Hooks) *T {
+	nt := NewT(t)
+	nt.Config.Hooks = append(append([]*Hooks{}, t.Config.Hooks...), sets...)
+	return nt
+}
+
+// RemoveCmpHooks returns a new *T instance in which Cmp hooks for
+// types of "models" are removed, whether they are recorded on t or
+// in sets of hooks it uses (see WithHooks). Each item of "models" can
+// be a reflect.Type or a value whose type is used. A nil pointer to
+// an interface, as in (*fmt.Stringer)(nil), designates the interface
+// hook of this interface.
+//
+//   t = t.WithHooks(testutil.Hooks)
+//   // Compare time.Time values field by field, as testutil.Hooks
+//   // defines a Cmp hook for time.Time
+//   t = t.RemoveCmpHooks(time.Time{})
+//
+// Overriding a hook only requires to add a new one using
+// WithCmpHooks, as hooks recorded on t take precedence over the sets
+// ones.
+//
+// RemoveCmpHooks panics if an item of "models" is nil.
+func (t *T) RemoveCmpHooks(models ...interface{}) *T {
+	types := hookTypes("RemoveCmpHooks", models)
+	t = t.copyWithHooks()
+	t.Config.hooks.RemoveCmpHooks(types)
+	return t
+}
+
+// RemoveSmuggleHooks returns a new *T instance in which Smuggle
+// hooks for types of "models" are removed, whether they are recorded
+// on t or in sets of hooks it uses (see WithHooks). See
+// RemoveCmpHooks for details about "models" items.
+//
+// RemoveSmuggleHooks panics if an item of "models" is nil.
+func (t *T) RemoveSmuggleHooks(models ...interface{}) *T {
+	types := hookTypes("RemoveSmuggleHooks", models)
+	t = t.copyWithHooks()
+	t.Config.hooks.RemoveSmuggleHooks(types)
+	return t
+}
+
+// RemoveFormatters returns a new *T instance in which formatters
+// for types of "models" are removed, whether they are recorded on t
+// or in sets of hooks it uses (see WithHooks). Global formatters
+// recorded by AddFormatters are not concerned. See RemoveCmpHooks
+// for details about "models" items.
+//
+// RemoveFormatters panics if an item of "models" is nil.
+func (t *T) RemoveFormatters(models ...interface{}) *T {
+	types := hookTypes("RemoveFormatters", models)
+	t = t.copyWithHooks()
+	t.Config.hooks.RemoveFormatHooks(types)
+	return t
+}
+
 func (t *T) copyWithHooks() *T {
 	nt := NewT(t)
 	nt.Config.hooks = t.Config.hooks.Copy()
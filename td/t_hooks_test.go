@@ -212,3 +212,75 @@ func TestWithSmuggleHooks(tt *testing.T) {
 		})
 	}
 }
+
+type hookedStringer int
+
+func (h hookedStringer) String() string {
+	return "#" + strconv.Itoa(int(h)%10)
+}
+
+func TestWithCmpHooksInterface(tt *testing.T) {
+	ttt := test.NewTestingTB(tt.Name())
+
+	t := td.NewT(ttt).WithCmpHooks(func(got, expected fmt.Stringer) bool {
+		return got.String() == expected.String()
+	})
+
+	test.IsTrue(tt, t.Cmp(hookedStringer(12), hookedStringer(2)))
+	test.IsTrue(tt, t.Cmp([]interface{}{hookedStringer(13)}, []interface{}{hookedStringer(3)}))
+	test.IsFalse(tt, t.Cmp(hookedStringer(12), hookedStringer(3)))
+	test.IsTrue(tt, strings.Contains(ttt.LastMessage(), "DATA: CmpHook(got, expected) failed\n"))
+
+	// Operators are not passed to hooks
+	test.IsTrue(tt, t.Cmp(hookedStringer(12), td.Between(hookedStringer(10), hookedStringer(20))))
+
+	// Original *T not altered
+	test.IsFalse(tt, td.NewT(ttt).Cmp(hookedStringer(12), hookedStringer(2)))
+}
+
+func TestWithCmpHookIf(tt *testing.T) {
+	ttt := test.NewTestingTB(tt.Name())
+
+	type Item struct{ ID, Name string }
+
+	t := td.NewT(ttt).WithCmpHookIf(
+		func(typ reflect.Type) bool {
+			return typ.Kind() == reflect.Ptr && typ.Elem().Kind() == reflect.Struct
+		},
+		func(got, expected interface{}) bool {
+			return got.(*Item).Name == expected.(*Item).Name
+		})
+
+	test.IsTrue(tt, t.Cmp(&Item{ID: "1", Name: "foo"}, &Item{Name: "foo"}))
+	test.IsFalse(tt, t.Cmp(Item{ID: "1", Name: "foo"}, Item{Name: "foo"}))
+
+	test.CheckPanic(tt, func() { _ = t.WithCmpHookIf(nil, func(a, b int) bool { return true }) },
+		"WithCmpHookIf expects a non-nil predicate")
+	test.CheckPanic(tt,
+		func() {
+			_ = t.WithCmpHookIf(func(reflect.Type) bool { return true }, func(a int) bool { return true })
+		},
+		"WithCmpHookIf expects: func (T, T) bool|error not func(int) bool")
+}
+
+func TestWithSmuggleHookIf(tt *testing.T) {
+	ttt := test.NewTestingTB(tt.Name())
+
+	t := td.NewT(ttt).WithSmuggleHookIf(
+		func(typ reflect.Type) bool {
+			return typ.Kind() >= reflect.Int && typ.Kind() <= reflect.Int64
+		},
+		func(got interface{}) int64 { return reflect.ValueOf(got).Int() })
+
+	test.IsTrue(tt, t.Cmp([]interface{}{int8(1), 2}, []interface{}{int64(1), int64(2)}))
+	test.IsTrue(tt, t.Cmp(struct{ A, B interface{} }{int8(1), 2},
+		struct{ A, B interface{} }{int64(1), int64(2)}))
+
+	// Interface smuggle hook
+	t = td.NewT(ttt).WithSmuggleHooks(func(got fmt.Stringer) string { return got.String() })
+	test.IsTrue(tt, t.Cmp(hookedStringer(12), "#2"))
+
+	test.CheckPanic(tt,
+		func() { _ = t.WithSmuggleHookIf(func(reflect.Type) bool { return true }, 12) },
+		"WithSmuggleHookIf expects a function, not a int")
+}
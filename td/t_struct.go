@@ -18,6 +18,16 @@ import (
 // T is a type that encapsulates testing.TB interface (which is
 // implemented by *testing.T and *testing.B) allowing to easily use
 // *testing.T methods as well as T ones.
+//
+// A *T instance can be used concurrently by several goroutines for
+// Cmp* calls, as long as its Config is not modified at the same
+// time. It is not the case for Anchor calls, as anchors are shared by
+// all *T instances of a same test: use RunGoroutines method to give
+// each goroutine its own anchors. As with testing.T, FailNow (so
+// Fatal and the FailureIsFatal flag) must not be called from other
+// goroutines than the one running the test. RunGoroutines also
+// overcomes this limitation and gets failures attributed to each
+// goroutine.
 type T struct {
 	testing.TB
 	Config ContextConfig // defaults to DefaultContextConfig
@@ -130,6 +140,9 @@ var _ testing.TB = T{}
 // Of course "t" can already be a *T, in this special case if "config"
 // is omitted, the Config of the new instance is a copy of the "t"
 // Config, including hooks.
+//
+// NewT panics if a path pattern of Config PathRules or
+// UnorderedSlices is invalid.
 func NewT(t testing.TB, config ...ContextConfig) *T {
 	var newT T
 
@@ -159,6 +172,11 @@ func NewT(t testing.TB, config ...ContextConfig) *T {
 	}
 	newT.Config.sanitize()
 
+	// Check path patterns as soon as possible, parsed ones being
+	// cached, next comparisons do not have to parse them again
+	compilePathRules(newT.Config.PathRules)
+	newT.Config.UnorderedSlices.compile()
+
 	newT.initAnchors()
 
 	return &newT
@@ -311,6 +329,228 @@ func (t *T) BeLax(enable ...bool) *T {
 	return &new
 }
 
+// PathStyle allows to choose how paths are rendered in the next
+// failure reports. See PathStyle type for details.
+//
+// It returns a new instance of *T so does not alter the original t
+// and used as follows:
+//
+//   t.PathStyle(td.PathStyleJSONPointer).
+//     Cmp(got, expected)
+//
+// produces, in case of failure, paths like DATA/items/3/created_at
+// instead of DATA.Items[3].CreatedAt.
+func (t *T) PathStyle(style PathStyle) *T {
+	new := *t
+	new.Config.PathStyle = style
+	return &new
+}
+
+// GotAsGo allows to additionally print, in the next failure
+// reports, the got value rendered as gofmt'ed Go source code, ready
+// to be pasted as an expected value. See ContextConfig.GotAsGo for
+// details.
+//
+// It returns a new instance of *T so does not alter the original t.
+//
+// Note that t.GotAsGo() acts as t.GotAsGo(true).
+func (t *T) GotAsGo(enable ...bool) *T {
+	new := *t
+	new.Config.GotAsGo = len(enable) == 0 || enable[0]
+	return &new
+}
+
+// JSONDiff allows to render the next failure reports of JSON
+// operators family as the got JSON document, in which mismatching
+// members are highlighted and annotated. See ContextConfig.JSONDiff
+// for details.
+//
+// It returns a new instance of *T so does not alter the original t.
+//
+// Note that t.JSONDiff() acts as t.JSONDiff(true).
+func (t *T) JSONDiff(enable ...bool) *T {
+	new := *t
+	new.Config.JSONDiff = len(enable) == 0 || enable[0]
+	return &new
+}
+
+// PreferStringer allows to use Error() or String() method, if any, to
+// render got and expected values (as well as their contents) in the
+// next failure reports, instead of go-spew default rendering. See
+// ContextConfig.PreferStringer for details.
+//
+// It returns a new instance of *T so does not alter the original t.
+//
+// Note that t.PreferStringer() acts as t.PreferStringer(true).
+func (t *T) PreferStringer(enable ...bool) *T {
+	new := *t
+	new.Config.PreferStringer = len(enable) == 0 || enable[0]
+	return &new
+}
+
+// IgnorePaths allows to ignore, in the next comparisons, all values
+// whose paths match one of "patterns". It is a shortcut for:
+//
+//   t.OverridePath(pattern, td.Ignore())
+//
+// for each pattern of "patterns". See PathRule type for patterns
+// syntax.
+//
+//   t.IgnorePaths("DATA.Items[*].ID", "DATA.**.UpdatedAt").
+//     Cmp(got, expected)
+//
+// It returns a new instance of *T so does not alter the original t.
+//
+// IgnorePaths panics if a pattern is invalid.
+func (t *T) IgnorePaths(patterns ...string) *T {
+	rules := make([]PathRule, len(patterns))
+	for i, pattern := range patterns {
+		rules[i] = PathRule{Path: pattern, Expected: Ignore()}
+	}
+	return t.addPathRules(rules)
+}
+
+// OverridePath allows to replace, in the next comparisons, the
+// expected value of all values whose paths match "pattern" by
+// "expected", typically a TestDeep operator. See PathRule type for
+// patterns syntax.
+//
+//   t.OverridePath("DATA.**.CreatedAt", td.Between(begin, time.Now())).
+//     Cmp(got, expected)
+//
+// If several rules match a same path, the last recorded one wins.
+//
+// It returns a new instance of *T so does not alter the original t.
+//
+// OverridePath panics if "pattern" is invalid.
+func (t *T) OverridePath(pattern string, expected interface{}) *T {
+	return t.addPathRules([]PathRule{{Path: pattern, Expected: expected}})
+}
+
+func (t *T) addPathRules(rules []PathRule) *T {
+	compilePathRules(rules) // panics if a pattern is invalid
+
+	new := *t
+	new.Config.PathRules = make([]PathRule, 0, len(t.Config.PathRules)+len(rules))
+	new.Config.PathRules = append(new.Config.PathRules, t.Config.PathRules...)
+	new.Config.PathRules = append(new.Config.PathRules, rules...)
+	return &new
+}
+
+// FloatTolerance allows, in the next comparisons, float and complex
+// values to be considered equal if the absolute value of their
+// difference is lower or equal to "abs", or to "rel" times the
+// greatest absolute value of both. A zero "abs" or "rel" disables the
+// corresponding check. See ContextConfig.FloatAbsTolerance and
+// ContextConfig.FloatRelTolerance for details.
+//
+//   t.FloatTolerance(1e-9, 0).Cmp(got, expected)
+//
+// It returns a new instance of *T so does not alter the original t.
+func (t *T) FloatTolerance(abs, rel float64) *T {
+	new := *t
+	new.Config.FloatAbsTolerance = abs
+	new.Config.FloatRelTolerance = rel
+	return &new
+}
+
+// FloatULPTolerance allows, in the next comparisons, float and
+// complex values to be considered equal if there are at most "ulps"
+// representable floats between them. See
+// ContextConfig.FloatULPTolerance for details.
+//
+// It returns a new instance of *T so does not alter the original t.
+func (t *T) FloatULPTolerance(ulps uint) *T {
+	new := *t
+	new.Config.FloatULPTolerance = ulps
+	return &new
+}
+
+// NaNEqual allows, in the next comparisons, NaN float values to be
+// considered equal. See ContextConfig.NaNEqual for details.
+//
+// It returns a new instance of *T so does not alter the original t.
+//
+// Note that t.NaNEqual() acts as t.NaNEqual(true).
+func (t *T) NaNEqual(enable ...bool) *T {
+	new := *t
+	new.Config.NaNEqual = len(enable) == 0 || enable[0]
+	return &new
+}
+
+// NilEqualsEmpty allows, in the next comparisons, nil and empty
+// slices (resp. maps) to be considered equal. See
+// ContextConfig.NilEqualsEmpty for details.
+//
+// It returns a new instance of *T so does not alter the original t.
+//
+// Note that t.NilEqualsEmpty() acts as t.NilEqualsEmpty(true).
+func (t *T) NilEqualsEmpty(enable ...bool) *T {
+	new := *t
+	new.Config.NilEqualsEmpty = len(enable) == 0 || enable[0]
+	return &new
+}
+
+// UnexportedFields allows to choose whether unexported struct fields
+// are compared in the next comparisons. See UnexportedFields type for
+// details.
+//
+//   t.UnexportedFields(td.IgnoreForeignUnexported).
+//     Cmp(got, expected)
+//
+// compares unexported fields of structs declared in the package of
+// the test, but ignores those of structs declared elsewhere.
+//
+// It returns a new instance of *T so does not alter the original t.
+func (t *T) UnexportedFields(mode UnexportedFields) *T {
+	new := *t
+	new.Config.UnexportedFields = mode
+	return &new
+}
+
+// UnorderedSlices allows, in the next comparisons, to compare some
+// or all slices and arrays regardless of the order of their items,
+// as Bag operator does. See ContextConfig.UnorderedSlices for
+// details.
+//
+// Each item of "targets" can be:
+//   - a string, a path pattern of concerned slices and arrays (see
+//     PathRule type for patterns syntax);
+//   - a reflect.Type, the type of items of concerned slices and
+//     arrays.
+//
+// If "targets" is empty, all slices and arrays are concerned.
+//
+//   t.UnorderedSlices("DATA.Items", reflect.TypeOf(Tag{})).
+//     Cmp(got, expected)
+//
+// It returns a new instance of *T so does not alter the original t.
+//
+// UnorderedSlices panics if a path pattern is invalid or if an item
+// of "targets" is neither a string nor a reflect.Type.
+func (t *T) UnorderedSlices(targets ...interface{}) *T {
+	var u UnorderedSlices
+	if len(targets) == 0 {
+		u.All = true
+	}
+	for i, target := range targets {
+		switch ttarget := target.(type) {
+		case string:
+			u.Paths = append(u.Paths, ttarget)
+		case reflect.Type:
+			u.ElemTypes = append(u.ElemTypes, ttarget)
+		default:
+			panic(color.BadUsage("UnorderedSlices(PATH_PATTERN|REFLECT_TYPE...)",
+				target, i+1, false))
+		}
+	}
+	u.compile() // panics if a pattern is invalid
+
+	new := *t
+	new.Config.UnorderedSlices = u
+	return &new
+}
+
 // Cmp is mostly a shortcut for:
 //
 //   Cmp(t.TB, got, expected, args...)
@@ -326,7 +566,7 @@ func (t *T) BeLax(enable ...bool) *T {
 // reason of a potential failure.
 func (t *T) Cmp(got, expected interface{}, args ...interface{}) bool {
 	t.Helper()
-	defer t.resetNonPersistentAnchors()
+	defer t.useAnchors()()
 	return cmpDeeply(newContextWithConfig(t.Config),
 		t.TB, got, expected, args...)
 }
@@ -335,7 +575,7 @@ func (t *T) Cmp(got, expected interface{}, args ...interface{}) bool {
 // compatibility purpose. Use shorter Cmp in new code.
 func (t *T) CmpDeeply(got, expected interface{}, args ...interface{}) bool {
 	t.Helper()
-	defer t.resetNonPersistentAnchors()
+	defer t.useAnchors()()
 	return cmpDeeply(newContextWithConfig(t.Config),
 		t.TB, got, expected, args...)
 }
@@ -441,7 +681,7 @@ func (t *T) CmpNoError(got error, args ...interface{}) bool {
 // reason of a potential failure.
 func (t *T) CmpPanic(fn func(), expected interface{}, args ...interface{}) bool {
 	t.Helper()
-	defer t.resetNonPersistentAnchors()
+	defer t.useAnchors()()
 	return cmpPanic(newContextWithConfig(t.Config), t, fn, expected, args...)
 }
 
@@ -7,6 +7,9 @@
 package td_test
 
 import (
+	"math"
+	"reflect"
+	"strings"
 	"testing"
 	"time"
 
@@ -422,3 +425,398 @@ func TestBeLax(tt *testing.T) {
 	t = td.NewT(ttt).BeLax(false)
 	test.IsFalse(tt, t.Cmp(int64(123), 123))
 }
+
+func TestPathStyle(tt *testing.T) {
+	type Item struct {
+		CreatedAt int `json:"created_at"`
+	}
+	type Cart struct {
+		Items []*Item `json:"items"`
+	}
+
+	ttt := test.NewTestingTB(tt.Name())
+
+	got := Cart{Items: []*Item{{CreatedAt: 1}}}
+	expected := Cart{Items: []*Item{{CreatedAt: 2}}}
+
+	// Using default config
+	t := td.NewT(ttt)
+	test.IsFalse(tt, t.Cmp(got, expected))
+	test.IsTrue(tt, strings.Contains(ttt.LastMessage(), "DATA.Items[0].CreatedAt: values differ"))
+
+	t = td.NewT(ttt).PathStyle(td.PathStyleJSONTags)
+	test.IsFalse(tt, t.Cmp(got, expected))
+	test.IsTrue(tt, strings.Contains(ttt.LastMessage(), "DATA.items[0].created_at: values differ"))
+
+	t = td.NewT(ttt).PathStyle(td.PathStyleJSONPointer)
+	test.IsFalse(tt, t.Cmp(got, expected))
+	test.IsTrue(tt, strings.Contains(ttt.LastMessage(), "DATA/items/0/created_at: values differ"))
+
+	// Struct operator
+	test.IsFalse(tt, t.Cmp(got, td.Struct(Cart{}, td.StructFields{
+		"Items": td.ArrayEach(td.Struct(&Item{}, td.StructFields{
+			"CreatedAt": 3,
+		})),
+	})))
+	test.IsTrue(tt, strings.Contains(ttt.LastMessage(), "DATA/items/0/created_at: values differ"))
+
+	t = td.NewT(ttt, td.ContextConfig{PathStyle: td.PathStyleGo})
+	test.IsFalse(tt, t.Cmp(got, expected))
+	test.IsTrue(tt, strings.Contains(ttt.LastMessage(), "DATA.Items[0].CreatedAt: values differ"))
+}
+
+func TestGotAsGo(tt *testing.T) {
+	type Item struct {
+		Name string
+		Age  int
+	}
+
+	ttt := test.NewTestingTB(tt.Name())
+
+	// Using default config
+	t := td.NewT(ttt)
+	test.IsFalse(tt, t.Cmp(Item{Name: "Bob"}, Item{}))
+	test.IsFalse(tt, strings.Contains(ttt.LastMessage(), "Got as Go:"))
+
+	t = td.NewT(ttt).GotAsGo()
+	test.IsFalse(tt, t.Cmp(Item{Name: "Bob"}, Item{}))
+	test.IsTrue(tt, strings.Contains(ttt.LastMessage(), `
+Got as Go:
+	td_test.Item{
+		Name: "Bob",
+	}`))
+
+	t = td.NewT(ttt).GotAsGo(false)
+	test.IsFalse(tt, t.Cmp(Item{Name: "Bob"}, Item{}))
+	test.IsFalse(tt, strings.Contains(ttt.LastMessage(), "Got as Go:"))
+
+	t = td.NewT(ttt, td.ContextConfig{GotAsGo: true})
+	test.IsFalse(tt, t.Cmp(12, 13))
+	test.IsTrue(tt, strings.Contains(ttt.LastMessage(), "\nGot as Go:\n\t12"))
+}
+
+func TestJSONDiff(tt *testing.T) {
+	type Item struct {
+		Name string `json:"name"`
+		Age  int    `json:"age"`
+	}
+
+	ttt := test.NewTestingTB(tt.Name())
+
+	// Using default config
+	t := td.NewT(ttt)
+	test.IsFalse(tt, t.Cmp(Item{Name: "Bob"}, Item{}))
+	test.IsFalse(tt, strings.Contains(ttt.LastMessage(), "JSON mismatch"))
+
+	t = td.NewT(ttt).JSONDiff()
+	test.IsFalse(tt, t.Cmp(Item{Name: "Bob"}, Item{}))
+	test.IsTrue(tt, strings.Contains(ttt.LastMessage(), `DATA: 1 JSON mismatch
+	{
+	  "age": 0,
+	  "name": "Bob"  // ✗ values differ
+	                 //   expected: ""
+	}`))
+
+	t = td.NewT(ttt).JSONDiff(false)
+	test.IsFalse(tt, t.Cmp(Item{Name: "Bob"}, Item{}))
+	test.IsFalse(tt, strings.Contains(ttt.LastMessage(), "JSON mismatch"))
+
+	t = td.NewT(ttt, td.ContextConfig{JSONDiff: true})
+	test.IsFalse(tt, t.Cmp(Item{Name: "Bob"}, Item{}))
+	test.IsTrue(tt, strings.Contains(ttt.LastMessage(), "DATA: 1 JSON mismatch"))
+}
+
+func TestPathRules(tt *testing.T) {
+	type Item struct {
+		ID        int
+		Name      string
+		UpdatedAt int `json:"updated_at"`
+	}
+	type Cart struct {
+		Items []*Item
+		Meta  map[string]Item
+	}
+
+	ttt := test.NewTestingTB(tt.Name())
+
+	got := Cart{
+		Items: []*Item{
+			{ID: 12, Name: "foo", UpdatedAt: 1},
+			{ID: 13, Name: "bar", UpdatedAt: 2},
+		},
+		Meta: map[string]Item{"x": {ID: 14, Name: "x", UpdatedAt: 3}},
+	}
+	expected := Cart{
+		Items: []*Item{{Name: "foo"}, {Name: "bar"}},
+		Meta:  map[string]Item{"x": {Name: "x"}},
+	}
+
+	t := td.NewT(ttt)
+	test.IsFalse(tt, t.Cmp(got, expected))
+
+	t = td.NewT(ttt).IgnorePaths("DATA.Items[*].ID", "DATA.**.updated_at")
+	test.IsFalse(tt, t.Cmp(got, expected))
+	test.IsTrue(tt, strings.Contains(ttt.LastMessage(), `DATA.Meta["x"].ID: values differ`))
+
+	t = t.IgnorePaths(`DATA.Meta[x].ID`)
+	test.IsTrue(tt, t.Cmp(got, expected))
+
+	// Operators
+	t = td.NewT(ttt).
+		IgnorePaths("DATA.**.ID").
+		OverridePath("DATA.**.UpdatedAt", td.Between(1, 2))
+	test.IsFalse(tt, t.Cmp(got, expected))
+	test.IsTrue(tt, strings.Contains(ttt.LastMessage(),
+		`DATA.Meta["x"].UpdatedAt: values differ`))
+
+	// The last matching rule wins
+	t = t.OverridePath(`DATA.Meta[*].UpdatedAt`, td.Gt(2))
+	test.IsTrue(tt, t.Cmp(got, expected))
+
+	// Operators using the same path do not loop
+	t = td.NewT(ttt).OverridePath("DATA.Items", td.Any(td.Len(2), td.Nil()))
+	test.IsTrue(tt, t.Cmp(got, td.Struct(Cart{}, td.StructFields{"Meta": td.Ignore()})))
+
+	// Using ContextConfig
+	t = td.NewT(ttt, td.ContextConfig{
+		PathRules: []td.PathRule{
+			{Path: "DATA.Items[*].*", Expected: td.Ignore()},
+			{Path: "DATA.Meta", Expected: td.Len(1)},
+		},
+	})
+	test.IsTrue(tt, t.Cmp(got, Cart{Items: []*Item{{}, {}}}))
+
+	// Original *T not altered
+	test.IsFalse(tt, td.NewT(ttt).Cmp(got, expected))
+
+	test.CheckPanic(tt, func() { td.NewT(ttt).IgnorePaths("DATA[") },
+		`invalid path rule "DATA[": unterminated '[' at offset 5`)
+
+	// Bad patterns are detected as soon as NewT is called
+	test.CheckPanic(tt,
+		func() {
+			td.NewT(ttt, td.ContextConfig{
+				PathRules: []td.PathRule{{Path: "DATA[", Expected: td.Ignore()}},
+			})
+		},
+		`invalid path rule "DATA[": unterminated '[' at offset 5`)
+	func() {
+		defer func(old []td.PathRule) { td.DefaultContextConfig.PathRules = old }(td.DefaultContextConfig.PathRules)
+		td.DefaultContextConfig.PathRules = []td.PathRule{{Path: "DATA[", Expected: td.Ignore()}}
+		test.CheckPanic(tt, func() { td.NewT(ttt) },
+			`invalid path rule "DATA[": unterminated '[' at offset 5`)
+	}()
+}
+
+func TestFloatTolerance(tt *testing.T) {
+	type Point struct {
+		X, Y float64
+		Z    float32
+		C    complex128
+	}
+
+	ttt := test.NewTestingTB(tt.Name())
+
+	got := Point{X: 1.0000001, Y: 100.001, Z: 1, C: complex(1, 2.0000001)}
+	expected := Point{X: 1, Y: 100, Z: 1, C: complex(1, 2)}
+
+	t := td.NewT(ttt)
+	test.IsFalse(tt, t.Cmp(got, expected))
+	test.IsTrue(tt, strings.Contains(ttt.LastMessage(), "DATA.X: values differ\n"))
+
+	t = td.NewT(ttt).FloatTolerance(1e-6, 0)
+	test.IsFalse(tt, t.Cmp(got, expected))
+	test.IsTrue(tt, strings.Contains(ttt.LastMessage(),
+		"DATA.Y: values differ (tolerance: abs=1e-06)\n"))
+
+	t = td.NewT(ttt).FloatTolerance(1e-6, 1e-4)
+	test.IsTrue(tt, t.Cmp(got, expected))
+
+	// Inside maps, slices & interfaces
+	test.IsTrue(tt, t.Cmp(
+		map[string]interface{}{"a": []interface{}{1.0000001}},
+		map[string]interface{}{"a": []interface{}{1.0}}))
+
+	// JSON numbers
+	t = td.NewT(ttt).FloatTolerance(1e-3, 0)
+	test.IsTrue(tt, t.Cmp(map[string]float64{"a": 0.1001}, td.JSON(`{"a": 0.1}`)))
+	test.IsTrue(tt, t.Cmp(map[string]float64{"a": 0.1001}, td.JSONPointer("/a", 0.1)))
+	test.IsTrue(tt, t.Cmp(map[string]float64{"a": 0.1001}, td.SJSONPath("$.a", 0.1)))
+	test.IsFalse(tt, t.Cmp(map[string]float64{"a": 0.102}, td.JSON(`{"a": 0.1}`)))
+	test.IsTrue(tt, strings.Contains(ttt.LastMessage(),
+		`DATA["a"]: values differ (tolerance: abs=0.001)`))
+	// but integers are still compared exactly
+	test.IsFalse(tt, t.Cmp(map[string]int64{"a": 1<<53 + 1}, td.JSON(`{"a": 9007199254740992}`)))
+
+	// ULPs
+	t = td.NewT(ttt).FloatULPTolerance(2)
+	x := 1.0
+	test.IsTrue(tt, t.Cmp(math.Nextafter(math.Nextafter(x, 2), 2), x))
+	test.IsFalse(tt, t.Cmp(math.Nextafter(math.Nextafter(math.Nextafter(x, 2), 2), 2), x))
+	test.IsTrue(tt, strings.Contains(ttt.LastMessage(), "values differ (tolerance: ulp=2)"))
+	test.IsTrue(tt, t.Cmp(math.Nextafter(0, 1), math.Nextafter(0, -1)))
+	test.IsTrue(tt, t.Cmp(math.Nextafter32(1, 2), float32(1)))
+	test.IsFalse(tt, t.Cmp(math.Inf(1), math.MaxFloat64))
+
+	// NaN
+	nan := math.NaN()
+	test.IsFalse(tt, td.NewT(ttt).Cmp(nan, nan))
+	test.IsTrue(tt, td.NewT(ttt).NaNEqual().Cmp(nan, nan))
+	test.IsTrue(tt, td.NewT(ttt).NaNEqual().Cmp(float32(nan), float32(nan)))
+	test.IsFalse(tt, td.NewT(ttt).NaNEqual().Cmp(nan, 1.0))
+	test.IsFalse(tt, td.NewT(ttt).NaNEqual(false).Cmp(nan, nan))
+	test.IsTrue(tt, td.NewT(ttt).NaNEqual().
+		Cmp([]complex64{complex(float32(nan), 1)}, []complex64{complex(float32(nan), 1)}))
+
+	// Using ContextConfig
+	t = td.NewT(ttt, td.ContextConfig{FloatRelTolerance: 1e-5})
+	test.IsTrue(tt, t.Cmp(got, expected))
+}
+
+func TestNilEqualsEmpty(tt *testing.T) {
+	type Item struct {
+		Tags  []string
+		Attrs map[string]int
+	}
+
+	ttt := test.NewTestingTB(tt.Name())
+
+	t := td.NewT(ttt)
+	test.IsFalse(tt, t.Cmp(Item{}, Item{Tags: []string{}}))
+	test.IsTrue(tt, strings.Contains(ttt.LastMessage(), `DATA.Tags: nil slice
+	     got: nil
+	expected: empty, use NilEqualsEmpty to ignore`))
+	test.IsFalse(tt, t.Cmp(Item{Attrs: map[string]int{}}, Item{}))
+	test.IsTrue(tt, strings.Contains(ttt.LastMessage(), `DATA.Attrs: nil map
+	     got: empty, use NilEqualsEmpty to ignore
+	expected: nil`))
+	test.IsFalse(tt, t.Cmp(Item{}, Item{Tags: []string{"a"}}))
+	test.IsTrue(tt, strings.Contains(ttt.LastMessage(), `DATA.Tags: nil slice
+	     got: nil
+	expected: not nil`))
+
+	t = td.NewT(ttt).NilEqualsEmpty()
+	test.IsTrue(tt, t.Cmp(Item{}, Item{Tags: []string{}, Attrs: map[string]int{}}))
+	test.IsTrue(tt, t.Cmp(Item{Tags: []string{}, Attrs: map[string]int{}}, Item{}))
+
+	test.IsFalse(tt, t.Cmp(Item{}, Item{Tags: []string{"a"}}))
+	test.IsTrue(tt, strings.Contains(ttt.LastMessage(), "DATA.Tags: comparing slices, from index #0"))
+	test.IsFalse(tt, t.Cmp(Item{}, Item{Attrs: map[string]int{"a": 1}}))
+	test.IsTrue(tt, strings.Contains(ttt.LastMessage(), "DATA.Attrs: comparing map"))
+
+	t = td.NewT(ttt).NilEqualsEmpty(false)
+	test.IsFalse(tt, t.Cmp(Item{}, Item{Tags: []string{}}))
+
+	t = td.NewT(ttt, td.ContextConfig{NilEqualsEmpty: true})
+	test.IsTrue(tt, t.Cmp([]string(nil), []string{}))
+}
+
+func TestUnexportedFields(tt *testing.T) {
+	type Local struct {
+		Name   string
+		secret int
+		Reader strings.Reader
+	}
+
+	ttt := test.NewTestingTB(tt.Name())
+
+	got := Local{Name: "Bob", secret: 1, Reader: *strings.NewReader("a")}
+	expected := Local{Name: "Bob", secret: 2, Reader: *strings.NewReader("b")}
+
+	t := td.NewT(ttt)
+	test.IsFalse(tt, t.Cmp(got, expected))
+	test.IsTrue(tt, strings.Contains(ttt.LastMessage(), "DATA.secret: values differ"))
+
+	t = td.NewT(ttt).UnexportedFields(td.IgnoreUnexported)
+	test.IsTrue(tt, t.Cmp(got, expected))
+	test.IsFalse(tt, t.Cmp(got, Local{Name: "Alice"}))
+	test.IsTrue(tt, strings.Contains(ttt.LastMessage(), "DATA.Name: values differ"))
+
+	// strings.Reader unexported fields are ignored, but not local ones
+	t = td.NewT(ttt).UnexportedFields(td.IgnoreForeignUnexported)
+	test.IsFalse(tt, t.Cmp(got, expected))
+	test.IsTrue(tt, strings.Contains(ttt.LastMessage(), "DATA.secret: values differ"))
+	expected.secret = 1
+	test.IsTrue(tt, t.Cmp(got, expected))
+
+	t = td.NewT(ttt).UnexportedFields(td.CompareUnexported)
+	test.IsFalse(tt, t.Cmp(got, expected))
+
+	t = td.NewT(ttt, td.ContextConfig{UnexportedFields: td.IgnoreUnexported})
+	test.IsTrue(tt, t.Cmp(got, Local{Name: "Bob"}))
+}
+
+func TestUnorderedSlices(tt *testing.T) {
+	type Tag struct {
+		Name string
+	}
+	type Item struct {
+		Tags   []Tag
+		Scores []int
+		Codes  [3]string
+	}
+
+	ttt := test.NewTestingTB(tt.Name())
+
+	got := Item{
+		Tags:   []Tag{{"a"}, {"b"}},
+		Scores: []int{1, 2, 3},
+		Codes:  [3]string{"x", "y", "z"},
+	}
+	expected := Item{
+		Tags:   []Tag{{"b"}, {"a"}},
+		Scores: []int{3, 2, 1},
+		Codes:  [3]string{"z", "x", "y"},
+	}
+
+	t := td.NewT(ttt)
+	test.IsFalse(tt, t.Cmp(got, expected))
+
+	test.IsTrue(tt, td.NewT(ttt).UnorderedSlices().Cmp(got, expected))
+
+	// Paths
+	t = td.NewT(ttt).UnorderedSlices("DATA.Tags", "DATA.**.Scores")
+	test.IsFalse(tt, t.Cmp(got, expected))
+	test.IsTrue(tt, strings.Contains(ttt.LastMessage(), "DATA.Codes[0]: values differ"))
+
+	// Element types
+	t = td.NewT(ttt).UnorderedSlices(reflect.TypeOf(Tag{}), reflect.TypeOf(0))
+	test.IsFalse(tt, t.Cmp(got, expected))
+	test.IsTrue(tt, strings.Contains(ttt.LastMessage(), "DATA.Codes[0]: values differ"))
+
+	t = t.UnorderedSlices("DATA.Codes", reflect.TypeOf(Tag{}), reflect.TypeOf(0))
+	test.IsTrue(tt, t.Cmp(got, expected))
+
+	// Missing & Extra items
+	t = td.NewT(ttt).UnorderedSlices()
+	expected.Scores = []int{3, 2, 4}
+	test.IsFalse(tt, t.Cmp(got, expected))
+	test.IsTrue(tt, strings.Contains(ttt.LastMessage(), `comparing DATA.Scores as a Bag
+	Missing item: (4)
+	  Extra item: (1)`))
+
+	// Nested slices, with path rules
+	t = td.NewT(ttt).UnorderedSlices().IgnorePaths("DATA[*].ID")
+	test.IsTrue(tt, t.Cmp(
+		[]struct{ ID, V int }{{ID: 1, V: 1}, {ID: 2, V: 2}},
+		[]struct{ ID, V int }{{V: 2}, {V: 1}}))
+
+	// Using ContextConfig
+	t = td.NewT(ttt, td.ContextConfig{
+		UnorderedSlices: td.UnorderedSlices{Paths: []string{"DATA"}},
+	})
+	test.IsTrue(tt, t.Cmp([]int{1, 2}, []int{2, 1}))
+	test.IsFalse(tt, t.Cmp([][]int{{1, 2}}, [][]int{{2, 1}}))
+
+	test.CheckPanic(tt, func() { td.NewT(ttt).UnorderedSlices(12) },
+		"usage: UnorderedSlices(PATH_PATTERN|REFLECT_TYPE...), but received int as 1st parameter")
+	test.CheckPanic(tt, func() { td.NewT(ttt).UnorderedSlices("DATA[") },
+		`invalid unordered slices path "DATA[": unterminated '[' at offset 5`)
+	test.CheckPanic(tt,
+		func() {
+			td.NewT(ttt, td.ContextConfig{
+				UnorderedSlices: td.UnorderedSlices{Paths: []string{"DATA["}},
+			})
+		},
+		`invalid unordered slices path "DATA[": unterminated '[' at offset 5`)
+}
@@ -57,6 +57,16 @@ var _ TestDeep = &tdCatch{}
 //     td.JSON(`{"id": $1, "name": "test"}`, td.Catch(&id, td.Ignore()))) {
 //     t.Logf("Created record ID is %d", id)
 //   }
+//
+// Catch can also be embedded in JSON, "target" being then a named
+// placeholder referencing a Tag of the pointer:
+//
+//   var id int64
+//   if td.Cmp(t, CreateRecord("test"),
+//     td.JSON(`{"id": Catch($id, NotZero()), "name": "test"}`,
+//       td.Tag("id", &id))) {
+//     t.Logf("Created record ID is %d", id)
+//   }
 func Catch(target, expectedValue interface{}) TestDeep {
 	vt := reflect.ValueOf(target)
 	if vt.Kind() != reflect.Ptr || vt.IsNil() || !vt.Elem().CanSet() {
@@ -23,6 +23,8 @@ import (
 	"github.com/maxatome/go-testdeep/internal/json"
 	"github.com/maxatome/go-testdeep/internal/location"
 	"github.com/maxatome/go-testdeep/internal/types"
+	"github.com/maxatome/go-testdeep/internal/util"
+	"github.com/maxatome/go-testdeep/internal/yaml"
 )
 
 // forbiddenOpsInJSON contains operators forbidden inside JSON,
@@ -31,7 +33,6 @@ import (
 var forbiddenOpsInJSON = map[string]string{
 	"Array":       "literal []",
 	"Cap":         "",
-	"Catch":       "",
 	"Code":        "",
 	"Delay":       "",
 	"Isa":         "",
@@ -43,13 +44,14 @@ var forbiddenOpsInJSON = map[string]string{
 	"SStruct":     "",
 	"Shallow":     "",
 	"Slice":       "literal []",
-	"Smuggle":     "",
-	"String":      `literal ""`,
 	"SubJSONOf":   "SubMapOf operator",
+	"SubYAMLOf":   "SubMapOf operator",
 	"SuperJSONOf": "SuperMapOf operator",
+	"SuperYAMLOf": "SuperMapOf operator",
 	"Struct":      "",
-	"Tag":         "",
 	"TruncTime":   "",
+	"XML":         "",
+	"YAML":        "literal JSON",
 }
 
 // jsonOpShortcuts contains operator that can be used as
@@ -67,18 +69,48 @@ var jsonOpShortcuts = map[string]func() TestDeep{
 }
 
 // tdJSONUnmarshaler handles the JSON unmarshaling of JSON, SubJSONOf
-// and SuperJSONOf first parameter.
+// and SuperJSONOf first parameter. It also handles the YAML
+// unmarshaling of YAML, SubYAMLOf and SuperYAMLOf first parameter,
+// as well as the XML parsing of XML first parameter.
 type tdJSONUnmarshaler struct {
-	location.Location // position of the operator
+	location.Location          // position of the operator
+	format            string   // "JSON", "YAML" or "XML"
+	exts              []string // file name extensions
+	parse             func([]byte, ...json.ParseOpts) (interface{}, error)
 }
 
 // newJSONUnmarshaler returns a new instance of tdJSONUnmarshaler.
 func newJSONUnmarshaler(pos location.Location) tdJSONUnmarshaler {
 	return tdJSONUnmarshaler{
 		Location: pos,
+		format:   "JSON",
+		exts:     []string{".json"},
+		parse:    json.Parse,
 	}
 }
 
+// newYAMLUnmarshaler returns a new instance of tdJSONUnmarshaler
+// for YAML data.
+func newYAMLUnmarshaler(pos location.Location) tdJSONUnmarshaler {
+	return tdJSONUnmarshaler{
+		Location: pos,
+		format:   "YAML",
+		exts:     []string{".yaml", ".yml"},
+		parse:    yaml.Parse,
+	}
+}
+
+// isFilename returns true if "s" ends with one of the file name
+// extensions handled by u.
+func (u tdJSONUnmarshaler) isFilename(s string) bool {
+	for _, ext := range u.exts {
+		if strings.HasSuffix(s, ext) {
+			return true
+		}
+	}
+	return false
+}
+
 // replaceLocation replaces the location of tdOp by the
 // JSON/SubJSONOf/SuperJSONOf one then add the position of the
 // operator inside the JSON string.
@@ -95,8 +127,10 @@ func (u tdJSONUnmarshaler) replaceLocation(tdOp TestDeep, posInJSON json.Positio
 	tdOp.replaceLocation(newPos)
 }
 
-// unmarshal unmarshals "expectedJSON" using placeholder parameters "params".
-func (u tdJSONUnmarshaler) unmarshal(expectedJSON interface{}, params []interface{}) interface{} {
+// read returns the content of "expectedJSON" that can be a string
+// (containing data or a file name), a []byte or an io.Reader. "usage"
+// is used to report a bad "expectedJSON" type.
+func (u tdJSONUnmarshaler) read(expectedJSON interface{}, usage string) []byte {
 	var (
 		err error
 		b   []byte
@@ -106,12 +140,12 @@ func (u tdJSONUnmarshaler) unmarshal(expectedJSON interface{}, params []interfac
 	case string:
 		// Try to load this file (if it seems it can be a filename and not
 		// a JSON content)
-		if strings.HasSuffix(data, ".json") {
+		if u.isFilename(data) {
 			// It could be a file name, try to read from it
 			b, err = ioutil.ReadFile(data)
 			if err != nil {
-				panic(color.Bad("%s(): JSON file %s cannot be read: %s",
-					u.Func, data, err))
+				panic(color.Bad("%s(): %s file %s cannot be read: %s",
+					u.Func, u.format, data, err))
 			}
 			break
 		}
@@ -123,17 +157,25 @@ func (u tdJSONUnmarshaler) unmarshal(expectedJSON interface{}, params []interfac
 	case io.Reader:
 		b, err = ioutil.ReadAll(data)
 		if err != nil {
-			panic(color.Bad("%s(): JSON read error: %s", u.Func, err))
+			panic(color.Bad("%s(): %s read error: %s", u.Func, u.format, err))
 		}
 
 	default:
-		panic(color.BadUsage(
-			u.Func+"(STRING_JSON|STRING_FILENAME|[]byte|io.Reader, ...)",
-			expectedJSON, 1, false))
+		panic(color.BadUsage(usage, expectedJSON, 1, false))
 	}
 
+	return b
+}
+
+// unmarshal unmarshals "expectedJSON" using placeholder parameters "params".
+func (u tdJSONUnmarshaler) unmarshal(expectedJSON interface{}, params []interface{}) interface{} {
+	b := u.read(expectedJSON,
+		u.Func+"(STRING_"+u.format+"|STRING_FILENAME|[]byte|io.Reader, ...)")
+
 	params = flat.Interfaces(params...)
-	var byTag map[string]interface{}
+	// byTag is also filled by Tag operators embedded in JSON, so later
+	// $name placeholders can reference them
+	byTag := map[string]interface{}{}
 
 	for i, p := range params {
 		switch op := p.(type) {
@@ -141,9 +183,6 @@ func (u tdJSONUnmarshaler) unmarshal(expectedJSON interface{}, params []interfac
 			if byTag[op.tag] != nil {
 				panic(color.Bad(`%s(): 2 params have the same tag "%s"`, u.Func, op.tag))
 			}
-			if byTag == nil {
-				byTag = map[string]interface{}{}
-			}
 			byTag[op.tag] = &tdJSONPlaceholder{
 				TestDeep: op,
 				name:     op.tag,
@@ -168,27 +207,59 @@ func (u tdJSONUnmarshaler) unmarshal(expectedJSON interface{}, params []interfac
 			}
 			// As Marshal succeeded, Unmarshal in an interface{} cannot fail
 			params[i] = nil
-			ejson.Unmarshal(bp, &params[i]) //nolint: errcheck
+			jsonUnmarshalNumbers(bp, &params[i]) //nolint: errcheck
 		}
 	}
 
-	final, err := json.Parse(b, json.ParseOpts{
+	opts := json.ParseOpts{
 		Placeholders:       params,
 		PlaceholdersByName: byTag,
 		OpShortcutFn:       u.resolveOpShortcut(),
-		OpFn:               u.resolveOp(),
-	})
+		OpFn:               u.resolveOp(byTag),
+	}
+	// Include() directives are relative to the expected file, if any
+	if s, ok := expectedJSON.(string); ok && u.isFilename(s) {
+		opts.File = s
+	}
 
+	final, err := u.parse(b, opts)
 	if err != nil {
-		panic(color.Bad("%s(): JSON unmarshal error: %s", u.Func, err))
+		panic(color.Bad("%s(): %s unmarshal error: %s", u.Func, u.format, err))
 	}
 
 	return final
 }
 
-// resolveOp returns a closure usable as json.ParseOpts.OpFn.
-func (u tdJSONUnmarshaler) resolveOp() func(json.Operator, json.Position) (interface{}, error) {
+// resolveOp returns a closure usable as json.ParseOpts.OpFn. Tag
+// operators embedded in JSON are recorded in "byTag".
+func (u tdJSONUnmarshaler) resolveOp(byTag map[string]interface{}) func(json.Operator, json.Position) (interface{}, error) {
 	return func(jop json.Operator, posInJSON json.Position) (interface{}, error) {
+		// User defined operator: $^MyOp(…)
+		if strings.HasPrefix(jop.Name, "^") {
+			name := "$" + jop.Name
+			op := getJSONOperator(jop.Name[1:])
+			if op == nil {
+				// In YAML and XML, built-in operators are also called using $^Op(…)
+				if _, exists := allOperators[jop.Name[1:]]; !exists || u.format == "JSON" {
+					return nil, fmt.Errorf("unknown operator %s()", name)
+				}
+				jop.Name = jop.Name[1:]
+				return u.resolveOp(byTag)(jop, posInJSON)
+			}
+			// Contrary to built-in operators, variadic parameters are optional
+			min, max := -1, -1
+			if tfn := reflect.TypeOf(op); tfn.IsVariadic() {
+				min = tfn.NumIn() - 1
+			}
+			tdOp, err := callJSONOperator(name, op, jop.Params, min, max, false)
+			if err != nil {
+				return nil, err
+			}
+			// replace the location by the JSON/SubJSONOf/SuperJSONOf one
+			u.replaceLocation(tdOp, posInJSON)
+			return &tdJSONEmbedded{tdOp}, nil
+		}
+
 		op, exists := allOperators[jop.Name]
 		if !exists {
 			return nil, fmt.Errorf("unknown operator %s()", jop.Name)
@@ -196,17 +267,14 @@ func (u tdJSONUnmarshaler) resolveOp() func(json.Operator, json.Position) (inter
 
 		if hint, exists := forbiddenOpsInJSON[jop.Name]; exists {
 			if hint == "" {
-				return nil, fmt.Errorf("%s() is not usable in JSON()", jop.Name)
+				return nil, fmt.Errorf("%s() is not usable in %s()", jop.Name, u.format)
 			}
-			return nil, fmt.Errorf("%s() is not usable in JSON(), use %s instead",
-				jop.Name, hint)
+			return nil, fmt.Errorf("%s() is not usable in %s(), use %s instead",
+				jop.Name, u.format, hint)
 		}
 
-		vfn := reflect.ValueOf(op)
-		tfn := vfn.Type()
-
 		// Special cases
-		var min, max int
+		min, max := -1, -1
 		addNilParam := false
 		switch jop.Name {
 		case "Between":
@@ -233,96 +301,303 @@ func (u tdJSONUnmarshaler) resolveOp() func(json.Operator, json.Position) (inter
 			min, max, addNilParam = 1, 1, true
 		case "SuperMapOf":
 			min, max, addNilParam = 1, 1, true
-		default:
-			min = tfn.NumIn()
-			if tfn.IsVariadic() {
-				// for All(expected ...interface{}) → min == 1, as All() is a non-sense
-				max = -1
-			} else {
-				max = min
+		case "Catch":
+			if len(jop.Params) == 2 {
+				target, err := jsonCatchTarget(jop.Params[0])
+				if err != nil {
+					return nil, err
+				}
+				jop.Params[0] = target
 			}
-		}
-		if len(jop.Params) < min || (max >= 0 && len(jop.Params) > max) {
-			switch {
-			case max < 0:
-				return nil, fmt.Errorf("%s() requires at least one parameter", jop.Name)
-			case max == 0:
-				return nil, fmt.Errorf("%s() requires no parameters", jop.Name)
-			case min == max:
-				if min == 1 {
-					return nil, fmt.Errorf("%s() requires only one parameter", jop.Name)
+		case "Smuggle":
+			// Functions cannot be expressed in JSON, so only fields-paths
+			// and JSON pointers are accepted
+			if len(jop.Params) == 2 {
+				path, ok := jop.Params[0].(string)
+				if !ok {
+					return nil, fmt.Errorf(
+						"Smuggle() bad #1 parameter type: fields-path or JSON pointer string required but %s received",
+						jsonParamType(jop.Params[0]))
+				}
+				if strings.HasPrefix(path, "/") {
+					op = allOperators["JSONPointer"]
+				}
+			}
+		case "Tag":
+			if len(jop.Params) == 2 {
+				tag, ok := jop.Params[0].(string)
+				if !ok {
+					return nil, fmt.Errorf(
+						"Tag() bad #1 parameter type: string required but %s received",
+						jsonParamType(jop.Params[0]))
+				}
+				if err := util.CheckTag(tag); err != nil {
+					return nil, fmt.Errorf("Tag() %s", err)
+				}
+				if byTag[tag] != nil {
+					return nil, fmt.Errorf(`Tag() "%s" is already used as a placeholder name`, tag)
 				}
-				return nil, fmt.Errorf("%s() requires %d parameters", jop.Name, min)
-			default:
-				return nil, fmt.Errorf("%s() requires %d or %d parameters", jop.Name, min, max)
 			}
 		}
 
-		var in []reflect.Value
-		if len(jop.Params) > 0 {
-			in = make([]reflect.Value, len(jop.Params))
-			for i, p := range jop.Params {
-				in[i] = reflect.ValueOf(p)
+		tdOp, err := callJSONOperator(jop.Name, op, jop.Params, min, max, addNilParam)
+		if err != nil {
+			return nil, err
+		}
+
+		// Record the tag, so it can be referenced later using $name
+		if tag, ok := tdOp.(*tdTag); ok {
+			byTag[tag.tag] = &tdJSONPlaceholder{
+				TestDeep: tag,
+				name:     tag.tag,
+			}
+		}
+		// replace the location by the JSON/SubJSONOf/SuperJSONOf one
+		u.replaceLocation(tdOp, posInJSON)
+		return &tdJSONEmbedded{tdOp}, nil
+	}
+}
+
+// jsonCatchTarget returns the pointer behind "param", the first
+// parameter of Catch() embedded in JSON. As pointers cannot be
+// expressed in JSON, "param" has to be a named placeholder
+// referencing a Tag of a non-nil pointer, as in Tag("id", &id).
+func jsonCatchTarget(param interface{}) (interface{}, error) {
+	if p, ok := param.(*tdJSONPlaceholder); ok {
+		if tag, ok := p.TestDeep.(*tdTag); ok && !tag.isTestDeeper &&
+			tag.expectedValue.Kind() == reflect.Ptr && !tag.expectedValue.IsNil() {
+			return tag.expectedValue.Interface(), nil
+		}
+	}
+	return nil, fmt.Errorf(
+		"Catch() bad #1 parameter: $name placeholder referencing a Tag of a non-nil pointer required but %s received",
+		jsonParamType(param))
+}
+
+// jsonParamType returns the type of "param", a parameter of an
+// operator embedded in JSON, for error messages.
+func jsonParamType(param interface{}) string {
+	switch p := param.(type) {
+	case nil:
+		return "nil"
+	case *tdJSONPlaceholder:
+		if p.num == 0 {
+			return "$" + p.name + " placeholder"
+		}
+		return fmt.Sprintf("$%d placeholder", p.num)
+	case *tdJSONEmbedded:
+		return p.GetLocation().Func + "() operator"
+	}
+	return reflect.TypeOf(param).String()
+}
+
+// callJSONOperator calls the operator constructor "op" named "name"
+// with "params" parameters, after checking their number and types.
+// If "min" is negative, the number of parameters is deduced from "op"
+// signature, else a negative "max" means no maximum. If "addNilParam" is true, a nil MapEntries is appended
+// to "params".
+func callJSONOperator(name string, op interface{}, params []interface{}, min, max int, addNilParam bool) (TestDeep, error) {
+	vfn := reflect.ValueOf(op)
+	tfn := vfn.Type()
+
+	if min < 0 {
+		min = tfn.NumIn()
+		if tfn.IsVariadic() {
+			// for All(expected ...interface{}) → min == 1, as All() is a non-sense
+			max = -1
+		} else {
+			max = min
+		}
+	}
+	if len(params) < min || (max >= 0 && len(params) > max) {
+		switch {
+		case max < 0:
+			if min > 1 {
+				return nil, fmt.Errorf("%s() requires at least %d parameters", name, min)
 			}
-			if addNilParam {
-				in = append(in, reflect.ValueOf(MapEntries(nil)))
+			return nil, fmt.Errorf("%s() requires at least one parameter", name)
+		case max == 0:
+			return nil, fmt.Errorf("%s() requires no parameters", name)
+		case min == max:
+			if min == 1 {
+				return nil, fmt.Errorf("%s() requires only one parameter", name)
 			}
+			return nil, fmt.Errorf("%s() requires %d parameters", name, min)
+		default:
+			return nil, fmt.Errorf("%s() requires %d or %d parameters", name, min, max)
+		}
+	}
 
-			// If the function is variadic, no need to check each param as all
-			// variadic operator is always a ...interface{}
-			numCheck := len(in)
-			if tfn.IsVariadic() {
-				numCheck = tfn.NumIn() - 1
+	var in []reflect.Value
+	if len(params) > 0 {
+		in = make([]reflect.Value, len(params))
+		var untyped []int
+		for i, p := range params {
+			if n, ok := p.(ejson.Number); ok {
+				v, err := jsonOperatorNumberParam(tfn, i, n)
+				if err != nil {
+					return nil, fmt.Errorf("%s() bad #%d parameter: %s", name, i+1, err)
+				}
+				if !jsonIsNumberParamType(jsonOperatorParamType(tfn, i)) {
+					untyped = append(untyped, i)
+				}
+				in[i] = v
+				continue
 			}
-			for i, p := range in[:numCheck] {
-				fpt := tfn.In(i)
-				if fpt.Kind() != reflect.Interface && p.Type() != fpt {
-					return nil, fmt.Errorf(
-						"%s() bad #%d parameter type: %s required but %s received",
-						jop.Name, i+1,
-						fpt, p.Type(),
-					)
+			in[i] = reflect.ValueOf(p)
+		}
+		jsonSameTypeNumberParams(in, params, untyped)
+		if addNilParam {
+			in = append(in, reflect.ValueOf(MapEntries(nil)))
+		}
+
+		// If the function is variadic, no need to check each param as all
+		// variadic operator is always a ...interface{}
+		numCheck := len(in)
+		if tfn.IsVariadic() {
+			numCheck = tfn.NumIn() - 1
+		}
+		for i, p := range in[:numCheck] {
+			fpt := tfn.In(i)
+			if fpt.Kind() != reflect.Interface && (!p.IsValid() || p.Type() != fpt) {
+				received := "nil"
+				if p.IsValid() {
+					received = p.Type().String()
 				}
+				return nil, fmt.Errorf(
+					"%s() bad #%d parameter type: %s required but %s received",
+					name, i+1,
+					fpt, received,
+				)
 			}
 		}
+	}
 
-		var (
-			panicArg interface{}
-			tdOp     TestDeep
-		)
-		func() {
-			defer func() { panicArg = recover() }()
-			tdOp = vfn.Call(in)[0].Interface().(TestDeep)
-		}()
+	var (
+		panicArg interface{}
+		tdOp     TestDeep
+	)
+	func() {
+		defer func() { panicArg = recover() }()
+		tdOp, _ = vfn.Call(in)[0].Interface().(TestDeep)
+	}()
 
-		if tdOp != nil {
-			// replace the location by the JSON/SubJSONOf/SuperJSONOf one
-			u.replaceLocation(tdOp, posInJSON)
-			return &tdJSONEmbedded{tdOp}, nil
+	if tdOp != nil {
+		return tdOp, nil
+	}
+	if s, ok := panicArg.(string); ok {
+		panicArg = color.UnBad(s)
+	}
+	return nil, fmt.Errorf("%s() %v", name, panicArg)
+}
+
+// jsonOperatorParamType returns the type of the "i"th parameter of
+// the operator constructor type "tfn", or nil if "tfn" does not
+// accept so many parameters.
+func jsonOperatorParamType(tfn reflect.Type, i int) reflect.Type {
+	switch last := tfn.NumIn() - 1; {
+	case tfn.IsVariadic() && i >= last:
+		return tfn.In(last).Elem()
+	case i <= last:
+		return tfn.In(i)
+	}
+	return nil
+}
+
+// jsonIsNumberParamType returns true if a JSON number can be
+// converted to "typ", a predeclared numeric type or json.Number.
+func jsonIsNumberParamType(typ reflect.Type) bool {
+	return typ == types.JsonNumber ||
+		(typ != nil && typ.PkgPath() == "" && json.IsNumberKind(typ.Kind()))
+}
+
+// jsonOperatorNumberParam converts the JSON number "n" to the type
+// of the "i"th parameter of the operator constructor type "tfn", if
+// this type is a predeclared numeric type or json.Number, to the Go
+// value returned by json.NumberValue otherwise, so integers not
+// exactly representable by a float64 keep their precision.
+func jsonOperatorNumberParam(tfn reflect.Type, i int, n ejson.Number) (reflect.Value, error) {
+	fpt := jsonOperatorParamType(tfn, i)
+	switch {
+	case fpt == types.JsonNumber:
+		return reflect.ValueOf(n), nil
+	case jsonIsNumberParamType(fpt):
+		return json.NumberConvert(n, fpt)
+	}
+	v := json.NumberValue(n)
+	if _, ok := v.(ejson.Number); ok {
+		return json.NumberConvert(n, types.Float64)
+	}
+	return reflect.ValueOf(v), nil
+}
+
+// jsonSameTypeNumberParams ensures the JSON numbers "params" at
+// indexes "untyped", converted by jsonOperatorNumberParam into "in",
+// all have the same type, as operators like Between require it. If
+// their types differ, they are all converted to int64 if they fit,
+// else to uint64 if they fit, else to float64.
+func jsonSameTypeNumberParams(in []reflect.Value, params []interface{}, untyped []int) {
+	if len(untyped) < 2 {
+		return
+	}
+	typ := in[untyped[0]].Type()
+	for _, i := range untyped[1:] {
+		if in[i].Type() != typ {
+			typ = nil
+			break
+		}
+	}
+	if typ != nil {
+		return
+	}
+
+	for _, typ := range []reflect.Type{
+		reflect.TypeOf(int64(0)), reflect.TypeOf(uint64(0)), types.Float64,
+	} {
+		conv := make([]reflect.Value, len(untyped))
+		for j, i := range untyped {
+			v, err := json.NumberConvert(params[i].(ejson.Number), typ)
+			if err != nil {
+				conv = nil
+				break
+			}
+			conv[j] = v
 		}
-		if s, ok := panicArg.(string); ok {
-			panicArg = color.UnBad(s)
+		if conv != nil {
+			for j, i := range untyped {
+				in[i] = conv[j]
+			}
+			return
 		}
-		return nil, fmt.Errorf("%s() %v", jop.Name, panicArg)
 	}
 }
 
 // resolveOpShortcut returns a closure usable as json.ParseOpts.OpShortcutFn.
-func (u tdJSONUnmarshaler) resolveOpShortcut() func(string, json.Position) (interface{}, bool) {
-	return func(opName string, posInJSON json.Position) (interface{}, bool) {
-		opFn := jsonOpShortcuts[opName]
-		if opFn != nil {
-			tdOp := opFn()
+func (u tdJSONUnmarshaler) resolveOpShortcut() func(string, json.Position) (interface{}, error) {
+	return func(opName string, posInJSON json.Position) (interface{}, error) {
+		var tdOp TestDeep
+		if opFn := jsonOpShortcuts[opName]; opFn != nil {
+			tdOp = opFn()
+		} else {
+			// User defined operator without parameters: $^MyOp
+			op := getJSONOperator(opName)
+			if op == nil {
+				return nil, fmt.Errorf(`bad operator shortcut "$^%s"`, opName)
+			}
+			var err error
+			tdOp, err = callJSONOperator("$^"+opName, op, nil, -1, -1, false)
+			if err != nil {
+				return nil, err
+			}
+		}
 
-			// replace the location by the JSON/SubJSONOf/SuperJSONOf one
-			u.replaceLocation(tdOp, posInJSON)
+		// replace the location by the JSON/SubJSONOf/SuperJSONOf one
+		u.replaceLocation(tdOp, posInJSON)
 
-			return &tdJSONPlaceholder{
-				TestDeep: tdOp,
-				name:     "^" + opName,
-			}, true
-		}
-		return nil, false
+		return &tdJSONPlaceholder{
+			TestDeep: tdOp,
+			name:     "^" + opName,
+		}, nil
 	}
 }
 
@@ -383,6 +658,9 @@ func gotViaJSON(ctx ctxerr.Context, pGot *reflect.Value) *ctxerr.Error {
 	return nil
 }
 
+// jsonify returns "got" json.Marshal'ed then json.Unmarshal'ed in
+// an interface{}, numbers being kept as json.Number so without any
+// loss of precision.
 func jsonify(ctx ctxerr.Context, got reflect.Value) (interface{}, *ctxerr.Error) {
 	gotIf, ok := dark.GetInterface(got, true)
 	if !ok {
@@ -402,10 +680,174 @@ func jsonify(ctx ctxerr.Context, got reflect.Value) (interface{}, *ctxerr.Error)
 
 	// As Marshal succeeded, Unmarshal in an interface{} cannot fail
 	var vgot interface{}
-	ejson.Unmarshal(b, &vgot) //nolint: errcheck
+	jsonUnmarshalNumbers(b, &vgot) //nolint: errcheck
 	return vgot, nil
 }
 
+// jsonUnmarshalNumbers unmarshals "b" in "v" keeping numbers as
+// json.Number, so without any loss of precision.
+func jsonUnmarshalNumbers(b []byte, v *interface{}) error {
+	dec := ejson.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	return dec.Decode(v)
+}
+
+// jsonGoNumbers returns "got" in which all json.Number values, even
+// deeply nested in []interface{} and map[string]interface{}, are
+// converted to float64, int64 or uint64 using json.NumberValue. "got"
+// is never modified, a copy is returned instead.
+func jsonGoNumbers(got reflect.Value) reflect.Value {
+	if !got.CanInterface() {
+		return got
+	}
+	switch v := got.Interface().(type) {
+	case ejson.Number, []interface{}, map[string]interface{}:
+		return reflect.ValueOf(jsonGoNumbersIf(v))
+	}
+	return got
+}
+
+func jsonGoNumbersIf(v interface{}) interface{} {
+	switch v := v.(type) {
+	case ejson.Number:
+		return json.NumberValue(v)
+
+	case []interface{}:
+		n := make([]interface{}, len(v))
+		for i, item := range v {
+			n[i] = jsonGoNumbersIf(item)
+		}
+		return n
+
+	case map[string]interface{}:
+		n := make(map[string]interface{}, len(v))
+		for k, item := range v {
+			n[k] = jsonGoNumbersIf(item)
+		}
+		return n
+	}
+	return v
+}
+
+// jsonNumberEqual handles the case where "got" is a json.Number, as
+// produced by JSON, SubJSONOf, SuperJSONOf, JSONPointer and JSONPath
+// operators, compared in Lax mode. Two json.Number are compared
+// exactly, as are a json.Number and a numeric value, unless float
+// tolerances apply. If "expected" is a TestDeep operator, it is
+// called with "got" converted to the numeric type behind it. The
+// symmetric case, where "expected" is a json.Number and "got" a
+// numeric value, is handled too. It returns false if the comparison
+// has not been done and has to be continued by the caller.
+func jsonNumberEqual(ctx ctxerr.Context, got, expected reflect.Value) (bool, *ctxerr.Error) {
+	if got.Kind() == reflect.Interface {
+		if got.IsNil() {
+			return false, nil
+		}
+		got = got.Elem()
+	}
+	if expected.Kind() == reflect.Interface {
+		if expected.IsNil() {
+			return false, nil
+		}
+		expected = expected.Elem()
+	}
+
+	var num, expectedNum ejson.Number
+	switch {
+	case got.Type() == types.JsonNumber:
+		num = ejson.Number(got.String())
+
+	case expected.Type() == types.JsonNumber:
+		var ok bool
+		if num, ok = json.NumberOf(got); !ok {
+			return false, nil
+		}
+		expectedNum = ejson.Number(expected.String())
+		return true, jsonNumbersCompare(ctx, num, expectedNum, got, expected)
+
+	default:
+		return false, nil
+	}
+
+	switch {
+	case expected.Type() == types.JsonNumber:
+		expectedNum = ejson.Number(expected.String())
+
+	case expected.Type().Implements(testDeeper):
+		op := dark.MustGetInterface(expected).(TestDeep)
+
+		var newGot reflect.Value
+		switch typ := op.TypeBehind(); {
+		case typ == nil || typ.Kind() == reflect.Interface:
+			newGot = reflect.ValueOf(json.NumberValue(num))
+
+		case json.IsNumberKind(typ.Kind()):
+			var err error
+			if newGot, err = json.NumberConvert(num, typ); err != nil {
+				if ctx.BooleanError {
+					return true, ctxerr.BooleanError
+				}
+				return true, ctx.CollectError(&ctxerr.Error{
+					Message:  "JSON number does not fit in expected type",
+					Got:      got,
+					Expected: types.RawString(typ.String()),
+				})
+			}
+
+		default:
+			return false, nil
+		}
+
+		ctx.CurOperator = op
+		ctx.JSONNumbers = false
+		return true, op.Match(ctx, newGot)
+
+	default:
+		var ok bool
+		expectedNum, ok = json.NumberOf(expected)
+		if !ok {
+			if ctx.BooleanError {
+				return true, ctxerr.BooleanError
+			}
+			return true, ctx.CollectError(ctxerr.TypeMismatch(got.Type(), expected.Type()))
+		}
+	}
+
+	return true, jsonNumbersCompare(ctx, num, expectedNum, got, expected)
+}
+
+// jsonNumbersCompare compares "num" and "expectedNum", respectively
+// the JSON numbers of "got" and "expected". If float tolerances are
+// set and at least one number is not an integer, the numbers are
+// compared as float64 using them, else they are compared exactly.
+func jsonNumbersCompare(ctx ctxerr.Context, num, expectedNum ejson.Number, got, expected reflect.Value) *ctxerr.Error {
+	if json.NumberEqual(num, expectedNum) {
+		return nil
+	}
+
+	msg := "values differ"
+	if !ctx.FloatTolerance.IsZero() &&
+		(!json.NumberIsInteger(num) || !json.NumberIsInteger(expectedNum)) {
+		g, errG := num.Float64()
+		e, errE := expectedNum.Float64()
+		if errG == nil && errE == nil &&
+			floatEqual(ctx, g, e,
+				got.Kind() == reflect.Float32 || expected.Kind() == reflect.Float32) {
+			return nil
+		}
+		msg += " (tolerance: " + ctx.FloatTolerance.String() + ")"
+	}
+
+	if ctx.BooleanError {
+		return ctxerr.BooleanError
+	}
+	return ctx.CollectError(&ctxerr.Error{
+		Message:  msg,
+		Got:      got,
+		Expected: expected,
+	})
+}
+
 // summary(JSON): compares against JSON representation
 // input(JSON): nil,bool,str,int,float,array,slice,map,struct,ptr
 
@@ -473,6 +915,22 @@ func jsonify(ctx ctxerr.Context, got reflect.Value) (interface{}, *ctxerr.Error)
 // Note that Lax mode is automatically enabled by JSON operator to
 // simplify numeric tests.
 //
+// Numbers are never converted to float64, neither in "expectedJSON"
+// nor in the JSON representation of got data, so int64 values above
+// 2^53 or high-precision decimals are compared exactly: 1.0 and 1 are
+// equal, but 9007199254740993 and 9007199254740992 are not. Float
+// tolerances (see T.FloatTolerance) still apply as soon as one of
+// the compared numbers is not an integer. When a number is compared
+// against an operator, it is first converted to the type behind this
+// operator, as int64 for Between(int64(1), int64(10)), an error being
+// raised if it does not fit in this type. Arrays and objects given
+// to an operator, as Code or Smuggle, contain float64 numbers, or
+// int64 or uint64 ones when a float64 cannot represent them exactly.
+// The same goes for numeric parameters of operators embedded in
+// JSON, unless a registered operator requires another numeric type,
+// parameters of a same operator being converted to a common type
+// when needed, as int64 for Between(1, 9007199254740993).
+//
 // Comments can be embedded in JSON data:
 //
 //   td.Cmp(t, gotValue,
@@ -515,15 +973,28 @@ func jsonify(ctx ctxerr.Context, got reflect.Value) (interface{}, *ctxerr.Error)
 //
 // A few notes about operators embedding:
 //   - SubMapOf and SuperMapOf take only one parameter, a JSON object;
+//   - Catch first parameter has to be a named placeholder referencing
+//     a Tag of a non-nil pointer, as in Catch($id, Gt(0)) with
+//     td.Tag("id", &id) passed in "params";
+//   - Tag names a sub-expression that can then be referenced later in
+//     the same JSON using $name, as in
+//     {"min": Tag("pos", Between(0, 10)), "max": $pos};
+//   - Smuggle only accepts a fields-path or a JSON pointer (starting
+//     with "/") as first parameter, as in Smuggle("/user/name", "Bob")
+//     or Smuggle("[user][name]", "Bob");
 //   - the optional 3rd parameter of Between has to be specified as a string
 //     and can be: "[]" or "BoundsInIn" (default), "[[" or "BoundsInOut",
 //     "]]" or "BoundsOutIn", "][" or "BoundsOutOut";
 //   - not all operators are embeddable only the following are;
-//   - All, Any, ArrayEach, Bag, Between, Contains, ContainsKey, Empty, Gt,
-//     Gte, HasPrefix, HasSuffix, Ignore, JSONPointer, Keys, Len, Lt, Lte,
-//     MapEach, N, NaN, Nil, None, Not, NotAny, NotEmpty, NotNaN, NotNil,
-//     NotZero, Re, ReAll, Set, SubBagOf, SubMapOf, SubSetOf, SuperBagOf,
-//     SuperMapOf, SuperSetOf, Values and Zero.
+//   - All, Any, ArrayEach, Bag, Between, Catch, Contains, ContainsKey,
+//     Empty, Gt, Gte, HasPrefix, HasSuffix, Ignore, JSONPath,
+//     JSONPointer, Keys, Len, Lt, Lte, MapEach, N, NaN, Nil, None, Not,
+//     NotAny, NotEmpty, NotNaN, NotNil, NotZero, Re, ReAll, Set,
+//     SJSONPath, Smuggle, String, SubBagOf, SubMapOf, SubSetOf,
+//     SuperBagOf, SuperMapOf, SuperSetOf, Tag, Values and Zero;
+//   - as strings starting with $ are placeholders, $ has to be doubled
+//     in JSONPath and SJSONPath expressions, as in
+//     JSONPath("$$..name", ["Bob"]).
 //
 // Operators taking no parameters can also be directly embedded in
 // JSON data using $^OperatorName or "$^OperatorName" notation. They
@@ -554,6 +1025,62 @@ func jsonify(ctx ctxerr.Context, got reflect.Value) (interface{}, *ctxerr.Error)
 //   - NotZero  → $^NotZero
 //   - Zero     → $^Zero
 //
+// Operators registered using RegisterJSONOperator are also available,
+// as $^MyOp or "$^MyOp" if they take no parameters, and as
+// $^MyOp(PARAMS…) otherwise:
+//
+//   td.RegisterJSONOperator("CountryCode", func() td.TestDeep {
+//     return td.Re(`^[A-Z]{2}\z`)
+//   })
+//   td.RegisterJSONOperator("Age", func(min, max float64) td.TestDeep {
+//     return td.Between(min, max)
+//   })
+//
+//   td.Cmp(t, gotValue, td.JSON(`{"country": $^CountryCode, "age": $^Age(18, 77)}`))
+//
+// Expectations can be composed of other JSON files using the
+// Include directive, and be parameterized using the Param one:
+//
+//   // pagination.json
+//   {
+//     "page":     Param("page", 1),    // defaults to 1
+//     "per_page": Param("per_page", 20),
+//     "total":    Param("total")       // mandatory
+//   }
+//
+//   // users.json
+//   {
+//     "users":      $^NotEmpty,
+//     "pagination": Include("pagination.json", {"total": Gt(0)})
+//   }
+//
+//   td.Cmp(t, gotValue, td.JSON("testdata/users.json"))
+//
+// Include(FILE) or Include(FILE, PARAMS) is replaced by the content
+// of FILE, whose name, if relative, is relative to the directory of
+// the including file, or to the current working directory if
+// "expectedJSON" is not a file name. PARAMS is a JSON object whose
+// members are the values of the Param(NAME) or Param(NAME, DEFAULT)
+// directives of FILE. A Param without DEFAULT requires its parameter
+// to be passed, and unused parameters are reported as errors, as
+// well as include cycles. Placeholders and operators can be used in
+// included files too. Errors inside a file, included or
+// "expectedJSON" itself, report its name in addition to the
+// position.
+//
+// If JSON diff is enabled (see ContextConfig.JSONDiff and
+// (*T).JSONDiff), a failure is rendered as the got JSON document,
+// indented, in which each mismatching member is highlighted and
+// annotated with the reason of the mismatch and the expected value:
+//
+//   DATA: 2 JSON mismatches
+//     {
+//       "age": 42,  // ✗ values differ
+//                   //   expected: 18 ≤ got ≤ 40
+//       "name": "Bob"  // ✗ values differ
+//                      //   expected: "Alice"
+//     }
+//
 // TypeBehind method returns the reflect.Type of the "expectedJSON"
 // json.Unmarshal'ed. So it can be bool, string, float64,
 // []interface{}, map[string]interface{} or interface{} in case
@@ -576,17 +1103,23 @@ func (j *tdJSON) Match(ctx ctxerr.Context, got reflect.Value) *ctxerr.Error {
 	}
 
 	ctx.BeLax = true
+	ctx.JSONNumbers = true
 
+	if doc, ok := jsonDiffDoc(ctx, got); ok {
+		return jsonDiff(ctx, doc, func(ctx ctxerr.Context) *ctxerr.Error {
+			return deepValueEqual(ctx, got, j.expected)
+		})
+	}
 	return deepValueEqual(ctx, got, j.expected)
 }
 
 func (j *tdJSON) String() string {
-	return jsonStringify("JSON", j.expected)
+	return jsonStringify(j.GetLocation().Func, j.expected)
 }
 
 func jsonStringify(opName string, v reflect.Value) string {
 	if !v.IsValid() {
-		return "JSON(null)"
+		return opName + "(null)"
 	}
 
 	var b bytes.Buffer
@@ -601,6 +1134,11 @@ func jsonStringify(opName string, v reflect.Value) string {
 
 func (j *tdJSON) TypeBehind() reflect.Type {
 	if j.expected.IsValid() {
+		// Numbers are internally kept as json.Number to be compared
+		// exactly, but they are float64 from the user point of view
+		if j.expected.Type() == types.JsonNumber {
+			return types.Float64
+		}
 		return j.expected.Type()
 	}
 	return types.Interface
@@ -695,6 +1233,7 @@ var _ TestDeep = &tdMapJSON{}
 //
 // Note that Lax mode is automatically enabled by SubJSONOf operator to
 // simplify numeric tests.
+// As for JSON operator, numbers are compared exactly.
 //
 // Comments can be embedded in JSON data:
 //
@@ -850,6 +1389,7 @@ func SubJSONOf(expectedJSON interface{}, params ...interface{}) TestDeep {
 //
 // Note that Lax mode is automatically enabled by SuperJSONOf operator to
 // simplify numeric tests.
+// As for JSON operator, numbers are compared exactly.
 //
 // Comments can be embedded in JSON data:
 //
@@ -940,7 +1480,13 @@ func (m *tdMapJSON) Match(ctx ctxerr.Context, got reflect.Value) *ctxerr.Error {
 	}
 
 	ctx.BeLax = true
+	ctx.JSONNumbers = true
 
+	if doc, ok := jsonDiffDoc(ctx, got); ok {
+		return jsonDiff(ctx, doc, func(ctx ctxerr.Context) *ctxerr.Error {
+			return m.match(ctx, got)
+		})
+	}
 	return m.match(ctx, got)
 }
 
@@ -0,0 +1,236 @@
+// Copyright (c) 2021, Maxime Soulé
+// All rights reserved.
+//
+// This source code is licensed under the BSD-style license found in the
+// LICENSE file in the root directory of this source tree.
+
+package td
+
+import (
+	"bytes"
+	ejson "encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"reflect"
+
+	"github.com/maxatome/go-testdeep/internal/ctxerr"
+	"github.com/maxatome/go-testdeep/internal/types"
+	"github.com/maxatome/go-testdeep/internal/util"
+)
+
+type tdJSONLines struct {
+	tdSmugglerBase
+}
+
+var _ TestDeep = &tdJSONLines{}
+
+// summary(JSONLines): decodes JSON Lines (or concatenated JSON)
+// records and compares them against a slice
+// input(JSONLines): str,slice([]byte),if(io.Reader)
+
+// JSONLines is a smuggler operator. It decodes got, a stream of JSON
+// values, as produced by JSON Lines (newline-delimited JSON) or
+// concatenated JSON outputs, and compares the []interface{} of
+// decoded records to "expectedValue". got can be a:
+//
+//   - string (or convertible) containing the records;
+//   - []byte (or convertible) containing the records;
+//   - io.Reader from which the records are read (it is ioutil.ReadAll
+//     before decoding, so it is consumed).
+//
+// Records can be separated by any JSON white space, or not separated
+// at all, so both:
+//
+//   {"level":"info","msg":"started"}
+//   {"level":"error","msg":"failed"}
+//
+// and
+//
+//   {"level":"info","msg":"started"}{"level":"error","msg":"failed"}
+//
+// are decoded as two records. Empty lines are ignored. If a record
+// cannot be decoded, the error reports the line where the problem
+// occurs.
+//
+// As any TestDeep operator can be used as "expectedValue", the
+// records can be checked one by one, or as a whole:
+//
+//   got := `{"level":"info","msg":"started"}
+//   {"level":"error","msg":"failed","code":42}
+//   `
+//   td.Cmp(t, got, td.JSONLines([]interface{}{
+//     td.JSON(`{"level":"info","msg":"started"}`),
+//     td.SuperJSONOf(`{"level":"error","code":42}`),
+//   })) // succeeds
+//   td.Cmp(t, got, td.JSONLines(td.Len(2)))   // succeeds
+//   td.Cmp(t, got, td.JSONLines(td.ArrayEach( // succeeds
+//     td.ContainsKey("level"))))
+//   td.Cmp(t, got, td.JSONLines(td.Bag(       // succeeds
+//     td.SuperJSONOf(`{"level":"error"}`),
+//     td.SuperJSONOf(`{"level":"info"}`),
+//   )))
+//
+// If the type behind "expectedValue" is a slice of structs, of
+// struct pointers or of a type implementing the
+// encoding/json.Unmarshaler interface, the records are decoded in a
+// slice of this type before the comparison:
+//
+//   type Log struct {
+//     Level string `json:"level"`
+//     Msg   string `json:"msg"`
+//   }
+//   td.Cmp(t, got, td.JSONLines([]Log{ // succeeds
+//     {Level: "info", Msg: "started"},
+//     {Level: "error", Msg: "failed"},
+//   }))
+//
+// Otherwise, the Lax mode is automatically enabled and, as for JSON
+// operator, numbers are compared exactly, so without any loss of
+// precision.
+//
+// TypeBehind method always returns nil as the expected type cannot be
+// guessed from records.
+func JSONLines(expectedValue interface{}) TestDeep {
+	l := tdJSONLines{
+		tdSmugglerBase: newSmugglerBase(expectedValue),
+	}
+	if !l.isTestDeeper {
+		l.expectedValue = reflect.ValueOf(expectedValue)
+	}
+	return &l
+}
+
+func (l *tdJSONLines) Match(ctx ctxerr.Context, got reflect.Value) *ctxerr.Error {
+	b, err := jsonLinesBytes(ctx, got)
+	if err != nil {
+		return ctx.CollectError(err)
+	}
+
+	ctx = ctx.AddCustomLevel(".JSONLines")
+
+	records, line, decErr := jsonLinesDecode(b)
+	if decErr != nil {
+		if ctx.BooleanError {
+			return ctxerr.BooleanError
+		}
+		return ctx.CollectError(&ctxerr.Error{
+			Message: "cannot decode JSON Lines",
+			Summary: ctxerr.NewSummary(fmt.Sprintf("line %d: %s", line, decErr)),
+		})
+	}
+
+	// Check if we have to transform the records into something
+	// compatible with the type of expected
+	if expectedType := l.internalTypeBehind(); expectedType != nil &&
+		expectedType.Kind() == reflect.Slice &&
+		jsonConvertible(expectedType.Elem()) {
+		got, decErr = jsonUnmarshalAs(records, expectedType)
+		if decErr != nil {
+			return jsonUnmarshalError(ctx, expectedType, decErr)
+		}
+	} else {
+		ctx.BeLax = true
+		ctx.JSONNumbers = true
+		got = reflect.ValueOf(records)
+	}
+
+	return deepValueEqual(ctx, got, l.expectedValue)
+}
+
+// jsonLinesBytes returns the bytes contained in or read from "got".
+func jsonLinesBytes(ctx ctxerr.Context, got reflect.Value) ([]byte, *ctxerr.Error) {
+	switch got.Kind() {
+	case reflect.String:
+		return []byte(got.String()), nil
+
+	case reflect.Slice:
+		if got.Type().Elem() == types.Uint8 {
+			return got.Bytes(), nil
+		}
+	}
+
+	if got.CanInterface() {
+		if r, ok := got.Interface().(io.Reader); ok {
+			b, err := ioutil.ReadAll(r)
+			if err != nil {
+				if ctx.BooleanError {
+					return nil, ctxerr.BooleanError
+				}
+				return nil, &ctxerr.Error{
+					Message: "read error",
+					Summary: ctxerr.NewSummary(err.Error()),
+				}
+			}
+			return b, nil
+		}
+	}
+
+	if ctx.BooleanError {
+		return nil, ctxerr.BooleanError
+	}
+	return nil, &ctxerr.Error{
+		Message:  "bad type",
+		Got:      types.RawString(got.Type().String()),
+		Expected: types.RawString("string (convertible) OR []byte (convertible) OR io.Reader"),
+	}
+}
+
+// jsonLinesDecode decodes all the JSON records contained in
+// "b". Numbers are kept as json.Number. If a record cannot be
+// decoded, the line of the error in "b" is returned with the error.
+func jsonLinesDecode(b []byte) ([]interface{}, int, error) {
+	records := []interface{}{}
+
+	pos := 0
+	for {
+		pos += len(b[pos:]) - len(bytes.TrimLeft(b[pos:], " \t\r\n"))
+		if pos == len(b) {
+			return records, 0, nil
+		}
+
+		// A new decoder for each record, so the offset of a syntax
+		// error is relative to the beginning of the record
+		var raw ejson.RawMessage
+		err := ejson.NewDecoder(bytes.NewReader(b[pos:])).Decode(&raw)
+		if err != nil {
+			errPos := pos
+			if serr, ok := err.(*ejson.SyntaxError); ok && serr.Offset > 0 {
+				errPos += int(serr.Offset) - 1
+			}
+			if err == io.ErrUnexpectedEOF {
+				errPos = len(b) - 1
+			}
+			return nil, 1 + bytes.Count(b[:errPos], []byte{'\n'}), err
+		}
+
+		var record interface{}
+		jsonUnmarshalNumbers(raw, &record) //nolint: errcheck
+		records = append(records, record)
+
+		pos += len(raw)
+	}
+}
+
+func (l *tdJSONLines) String() string {
+	var expected string
+	switch {
+	case l.isTestDeeper:
+		expected = l.expectedValue.Interface().(TestDeep).String()
+	case l.expectedValue.IsValid():
+		expected = util.ToString(l.expectedValue.Interface())
+	default:
+		expected = "nil"
+	}
+	return "JSONLines(" + expected + ")"
+}
+
+func (l *tdJSONLines) internalTypeBehind() reflect.Type {
+	if l.isTestDeeper {
+		return l.expectedValue.Interface().(TestDeep).TypeBehind()
+	}
+	if l.expectedValue.IsValid() {
+		return l.expectedValue.Type()
+	}
+	return nil
+}
@@ -0,0 +1,143 @@
+// Copyright (c) 2021, Maxime Soulé
+// All rights reserved.
+//
+// This source code is licensed under the BSD-style license found in the
+// LICENSE file in the root directory of this source tree.
+
+package td_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/maxatome/go-testdeep/internal/test"
+	"github.com/maxatome/go-testdeep/td"
+)
+
+func TestJSONLines(t *testing.T) {
+	type Log struct {
+		Level string `json:"level"`
+		Msg   string `json:"msg"`
+	}
+
+	got := `{"level":"info","msg":"started"}
+
+{"level":"error","msg":"failed","code":42}
+`
+
+	checkOK(t, got, td.JSONLines([]interface{}{
+		td.JSON(`{"level":"info","msg":"started"}`),
+		td.SuperJSONOf(`{"level":"error","code":42}`),
+	}))
+	checkOK(t, got, td.JSONLines(td.Len(2)))
+	checkOK(t, got, td.JSONLines(td.ArrayEach(td.ContainsKey("level"))))
+	checkOK(t, got, td.JSONLines(td.Bag(
+		td.SuperJSONOf(`{"level":"error"}`),
+		td.SuperJSONOf(`{"level":"info"}`),
+	)))
+	checkOK(t, []byte(got), td.JSONLines(td.Len(2)))
+	checkOK(t, json.RawMessage(got), td.JSONLines(td.Len(2)))
+	// A reader is consumed, so cannot be checked twice
+	test.IsTrue(t, td.EqDeeply(strings.NewReader(got), td.JSONLines(td.Len(2))))
+	test.IsTrue(t, td.EqDeeply(bytes.NewBufferString(got), td.JSONLines(td.Len(2))))
+
+	// Converted to the expected type
+	checkOK(t, got, td.JSONLines([]Log{
+		{Level: "info", Msg: "started"},
+		{Level: "error", Msg: "failed"},
+	}))
+	checkOK(t, got, td.JSONLines([]*Log{
+		{Level: "info", Msg: "started"},
+		{Level: "error", Msg: "failed"},
+	}))
+
+	// Concatenated JSON, numbers kept exact
+	checkOK(t, `1 "two"[3]{"four":4}9007199254740993`,
+		td.JSONLines([]interface{}{
+			1, "two", []interface{}{3}, map[string]interface{}{"four": 4},
+			uint64(9007199254740993),
+		}))
+	checkOK(t, "", td.JSONLines([]interface{}{}))
+	checkOK(t, " \n\t", td.JSONLines(td.Empty()))
+
+	//
+	// Errors
+	checkError(t, got, td.JSONLines(td.Len(3)),
+		expectedError{
+			Message:  mustBe("bad length"),
+			Path:     mustBe("DATA.JSONLines"),
+			Got:      mustBe("2"),
+			Expected: mustBe("3"),
+		})
+
+	checkError(t, got, td.JSONLines([]interface{}{
+		td.JSON(`{"level":"info","msg":"started"}`),
+		td.SuperJSONOf(`{"level":"error","code":43}`),
+	}),
+		expectedError{
+			Message:  mustBe("values differ"),
+			Path:     mustBe(`DATA.JSONLines[1]["code"]`),
+			Got:      mustBe("42"),
+			Expected: mustBe("43"),
+		})
+
+	checkError(t, "{}\n{\"a\":1}\n{\"b\":2,}\n{}",
+		td.JSONLines(td.Ignore()),
+		expectedError{
+			Message: mustBe("cannot decode JSON Lines"),
+			Path:    mustBe("DATA.JSONLines"),
+			Summary: mustBe("line 3: invalid character '}' looking for beginning of object key string"),
+		})
+
+	checkError(t, "{}\n\n  oops",
+		td.JSONLines(td.Ignore()),
+		expectedError{
+			Message: mustBe("cannot decode JSON Lines"),
+			Path:    mustBe("DATA.JSONLines"),
+			Summary: mustBe("line 3: invalid character 'o' looking for beginning of value"),
+		})
+
+	checkError(t, "{}\n[1,\n2",
+		td.JSONLines(td.Ignore()),
+		expectedError{
+			Message: mustBe("cannot decode JSON Lines"),
+			Path:    mustBe("DATA.JSONLines"),
+			Summary: mustBe("line 3: unexpected EOF"),
+		})
+
+	checkError(t, `{"level":42}`, td.JSONLines([]Log{}),
+		expectedError{
+			Message: mustBe("an error occurred while unmarshalling JSON into []td_test.Log"),
+			Path:    mustBe("DATA.JSONLines"),
+			Summary: mustContain("cannot unmarshal number"),
+		})
+
+	checkError(t, 42, td.JSONLines(td.Ignore()),
+		expectedError{
+			Message:  mustBe("bad type"),
+			Path:     mustBe("DATA"),
+			Got:      mustBe("int"),
+			Expected: mustBe("string (convertible) OR []byte (convertible) OR io.Reader"),
+		})
+
+	checkError(t, errReader{}, td.JSONLines(td.Ignore()),
+		expectedError{
+			Message: mustBe("read error"),
+			Path:    mustBe("DATA"),
+			Summary: mustBe("an error occurred"),
+		})
+
+	//
+	// String
+	test.EqualStr(t, td.JSONLines(td.Len(2)).String(), "JSONLines(len=2)")
+	test.EqualStr(t, td.JSONLines(nil).String(), "JSONLines(nil)")
+	test.EqualStr(t, td.JSONLines([]int{1}).String(), `JSONLines(([]int) (len=1 cap=1) {
+ (int) 1
+})`)
+}
+
+func TestJSONLinesTypeBehind(t *testing.T) {
+	equalTypes(t, td.JSONLines(td.Len(2)), nil)
+}
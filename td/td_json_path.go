@@ -0,0 +1,264 @@
+// Copyright (c) 2021, Maxime Soulé
+// All rights reserved.
+//
+// This source code is licensed under the BSD-style license found in the
+// LICENSE file in the root directory of this source tree.
+
+package td
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/maxatome/go-testdeep/internal/color"
+	"github.com/maxatome/go-testdeep/internal/ctxerr"
+	"github.com/maxatome/go-testdeep/internal/util"
+)
+
+type tdJSONPath struct {
+	tdSmugglerBase
+	expr   string
+	path   *util.JSONPath
+	strict bool
+}
+
+var _ TestDeep = &tdJSONPath{}
+
+func newJSONPath(fn, expr string, expectedValue interface{}, strict bool) TestDeep {
+	path, err := util.ParseJSONPath(expr)
+	if err != nil {
+		panic(color.Bad("%s(): bad JSONPath %s: %s", fn, expr, err))
+	}
+
+	p := tdJSONPath{
+		tdSmugglerBase: newSmugglerBase(expectedValue, 1),
+		expr:           expr,
+		path:           path,
+		strict:         strict,
+	}
+	if !p.isTestDeeper {
+		p.expectedValue = reflect.ValueOf(expectedValue)
+	}
+	return &p
+}
+
+// summary(JSONPath): compares against JSON representation using a
+// JSONPath expression
+// input(JSONPath): nil,bool,str,int,float,array,slice,map,struct,ptr
+
+// JSONPath is a smuggler operator. It takes the JSON representation
+// of data, gets all the values matched by the JSONPath expression
+// "expr" and compares them, as a slice, to "expectedValue".
+//
+// The supported JSONPath subset is:
+//
+//   $                  root value
+//   .name  ['name']    object member (double quotes allowed)
+//   .*  [*]            all members/items
+//   ..                 recursive descent, as in $..name or $..[0]
+//   [1]  [-1]          array item, negative indexes from the end
+//   [1:3]  [::2]       array slice [start:end:step]
+//   ['a','b']  [0,2]   union of selectors
+//   [?(filter)]        items for which filter is true
+//
+// Filters can use @ (the current item) and $ (the root value) paths,
+// literals (numbers, strings, true, false and null), comparison
+// operators ==, !=, <, <=, > and >=, logical operators &&, || and !,
+// and parentheses. A path alone as in [?(@.isbn)] tests its
+// existence.
+//
+// Values are matched in document order, object members being
+// traversed in the lexical order of their keys.
+//
+//   got := map[string]interface{}{
+//     "users": []map[string]interface{}{
+//       {"name": "Bob", "role": "admin", "age": 42},
+//       {"name": "Alice", "role": "user", "age": 33},
+//     },
+//   }
+//
+//   td.Cmp(t, got, td.JSONPath(`$.users[*].name`, []string{"Bob", "Alice"}))
+//   td.Cmp(t, got, td.JSONPath(`$..age`, td.ArrayEach(td.Gt(18))))
+//   td.Cmp(t, got,
+//     td.JSONPath(`$.users[?(@.role == "admin")].name`, td.Bag("Bob")))
+//
+// If "expectedValue" is a slice or an array, or an operator whose
+// type behind is a slice or an array, the matched values are
+// converted back to this type, using encoding/json. Otherwise, the
+// Lax mode is automatically enabled to simplify numeric tests, and
+// the matched values are compared as a []interface{}:
+//
+//   td.Cmp(t, got, td.JSONPath(`$..age`, td.Bag(33, 42)))
+//
+// In case of failure, the paths of all matched values are displayed,
+// so it is easy to know which value failed.
+//
+// To compare a single value instead of a slice, see SJSONPath.
+//
+// TypeBehind method always returns nil as the expected type cannot be
+// guessed from a JSONPath expression.
+func JSONPath(expr string, expectedValue interface{}) TestDeep {
+	return newJSONPath("JSONPath", expr, expectedValue, false)
+}
+
+// summary(SJSONPath): compares against the only value matched by a
+// JSONPath expression in JSON representation
+// input(SJSONPath): nil,bool,str,int,float,array,slice,map,struct,ptr
+
+// SJSONPath is a smuggler operator, the strict counterpart of
+// JSONPath: exactly one value has to be matched by the JSONPath
+// expression "expr" in the JSON representation of data. This value
+// is then compared to "expectedValue".
+//
+// See JSONPath for the supported JSONPath subset.
+//
+//   got := map[string]interface{}{
+//     "users": []map[string]interface{}{
+//       {"name": "Bob", "role": "admin", "age": 42},
+//       {"name": "Alice", "role": "user", "age": 33},
+//     },
+//   }
+//
+//   td.Cmp(t, got, td.SJSONPath(`$.users[?(@.role == "admin")].name`, "Bob"))
+//   td.Cmp(t, got, td.SJSONPath(`$.users[?(@.name == "Alice")].age`, td.Lt(40)))
+//
+// As JSONPointer does, SJSONPath does its best to convert back the
+// matched value to the type of "expectedValue" or to the type behind
+// the "expectedValue" operator, if it is a struct, a struct pointer
+// or implements the encoding/json.Unmarshaler interface. In the case
+// the conversion does not occur, the Lax mode is automatically
+// enabled to simplify numeric tests.
+//
+// In case of failure, the path of the matched value is displayed.
+//
+// TypeBehind method always returns nil as the expected type cannot be
+// guessed from a JSONPath expression.
+func SJSONPath(expr string, expectedValue interface{}) TestDeep {
+	return newJSONPath("SJSONPath", expr, expectedValue, true)
+}
+
+func (p *tdJSONPath) Match(ctx ctxerr.Context, got reflect.Value) *ctxerr.Error {
+	vgot, eErr := jsonify(ctx, got)
+	if eErr != nil {
+		return ctx.CollectError(eErr)
+	}
+
+	matches := p.path.Find(vgot)
+
+	if p.strict {
+		if len(matches) != 1 {
+			if ctx.BooleanError {
+				return ctxerr.BooleanError
+			}
+			return ctx.AddCustomLevel(".JSONPath<" + p.expr + ">").
+				CollectError(&ctxerr.Error{
+					Message: "JSONPath does not match exactly one value",
+					Summary: jsonPathSummary(matches),
+				})
+		}
+
+		ctx = ctx.AddCustomLevel(".JSONPath<" + matches[0].Path + ">")
+
+		if expectedType := p.internalTypeBehind(); jsonConvertible(expectedType) {
+			var err error
+			got, err = jsonUnmarshalAs(matches[0].Value, expectedType)
+			if err != nil {
+				return jsonUnmarshalError(ctx, expectedType, err)
+			}
+		} else {
+			ctx.BeLax = true
+			ctx.JSONNumbers = true
+			got = reflect.ValueOf(matches[0].Value)
+		}
+		return deepValueEqual(ctx, got, p.expectedValue)
+	}
+
+	ctx = ctx.AddCustomLevel(".JSONPath<" + p.expr + ">")
+
+	values := make([]interface{}, len(matches))
+	for i, m := range matches {
+		values[i] = m.Value
+	}
+
+	if expectedType := p.internalTypeBehind(); expectedType != nil &&
+		(expectedType.Kind() == reflect.Slice || expectedType.Kind() == reflect.Array) {
+		var err error
+		got, err = jsonUnmarshalAs(values, expectedType)
+		if err != nil {
+			return jsonUnmarshalError(ctx, expectedType, err)
+		}
+	} else {
+		ctx.BeLax = true
+		ctx.JSONNumbers = true
+		got = reflect.ValueOf(values)
+	}
+
+	// Use deepValueEqualFinal here instead of deepValueEqual as we
+	// want to know whether an error occurred or not, we do not want
+	// to accumulate it silently
+	origErr := deepValueEqualFinal(ctx.ResetErrors(), got, p.expectedValue)
+	if origErr == nil {
+		return nil
+	}
+	if ctx.BooleanError {
+		return ctxerr.BooleanError
+	}
+	return ctx.CollectError(&ctxerr.Error{
+		Message: "values matched by JSONPath do not match",
+		Summary: jsonPathSummary(matches),
+		Origin:  origErr,
+	})
+}
+
+func (p *tdJSONPath) String() string {
+	var expected string
+	switch {
+	case p.isTestDeeper:
+		expected = p.expectedValue.Interface().(TestDeep).String()
+	case p.expectedValue.IsValid():
+		expected = util.ToString(p.expectedValue.Interface())
+	default:
+		expected = "nil"
+	}
+	if p.strict {
+		return fmt.Sprintf("SJSONPath(%s, %s)", p.expr, expected)
+	}
+	return fmt.Sprintf("JSONPath(%s, %s)", p.expr, expected)
+}
+
+func (p *tdJSONPath) internalTypeBehind() reflect.Type {
+	if p.isTestDeeper {
+		return p.expectedValue.Interface().(TestDeep).TypeBehind()
+	}
+	if p.expectedValue.IsValid() {
+		return p.expectedValue.Type()
+	}
+	return nil
+}
+
+func (p *tdJSONPath) HandleInvalid() bool {
+	return true
+}
+
+// jsonPathSummary returns a summary listing "matches" with their
+// paths, each value being displayed in JSON.
+func jsonPathSummary(matches []util.JSONPathMatch) ctxerr.ErrorSummary {
+	if len(matches) == 0 {
+		return ctxerr.ErrorSummaryItem{
+			Label: "matched",
+			Value: "no values",
+		}
+	}
+
+	lines := make([]string, len(matches))
+	for i, m := range matches {
+		b, _ := json.Marshal(m.Value) // No error can occur here
+		lines[i] = m.Path + ": " + string(b)
+	}
+	return ctxerr.ErrorSummaryItem{
+		Label: "matched",
+		Value: strings.Join(lines, "\n"),
+	}
+}
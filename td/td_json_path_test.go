@@ -0,0 +1,193 @@
+// Copyright (c) 2021, Maxime Soulé
+// All rights reserved.
+//
+// This source code is licensed under the BSD-style license found in the
+// LICENSE file in the root directory of this source tree.
+
+package td_test
+
+import (
+	"testing"
+
+	"github.com/maxatome/go-testdeep/internal/test"
+	"github.com/maxatome/go-testdeep/td"
+)
+
+type jpUser struct {
+	Name string `json:"name"`
+	Role string `json:"role"`
+	Age  int    `json:"age"`
+}
+
+func TestJSONPath(t *testing.T) {
+	got := map[string]interface{}{
+		"users": []jpUser{
+			{Name: "Bob", Role: "admin", Age: 42},
+			{Name: "Alice", Role: "user", Age: 33},
+			{Name: "Brian", Role: "user", Age: 21},
+		},
+		"count": 3,
+	}
+
+	t.Run("JSONPath", func(t *testing.T) {
+		checkOK(t, got, td.JSONPath(`$.users[*].name`, []string{"Bob", "Alice", "Brian"}))
+		checkOK(t, got, td.JSONPath(`$.users[*].name`, [3]string{"Bob", "Alice", "Brian"}))
+		checkOK(t, got, td.JSONPath(`$..age`, []int{42, 33, 21}))
+		checkOK(t, got, td.JSONPath(`$..age`, td.ArrayEach(td.Gt(18))))
+		checkOK(t, got, td.JSONPath(`$..age`, td.Bag(21, 33, 42))) // Lax enabled
+		checkOK(t, got, td.JSONPath(`$.users[1:]`, []jpUser{
+			{Name: "Alice", Role: "user", Age: 33},
+			{Name: "Brian", Role: "user", Age: 21},
+		}))
+		checkOK(t, got,
+			td.JSONPath(`$.users[?(@.role == "admin")].name`, []string{"Bob"}))
+		checkOK(t, got,
+			td.JSONPath(`$.users[?(@.age < $.count)]`, td.Empty()))
+		checkOK(t, got, td.JSONPath(`$.users[?(@.role == "guest")]`, td.Empty()))
+		checkOK(t, got, td.JSONPath(`$.count`, []int{3}))
+		checkOK(t, got, td.JSONPath(`$`, td.Len(1)))
+
+		checkError(t, got, td.JSONPath(`$..age`, []int{42, 33, 20}),
+			expectedError{
+				Message: mustBe("values matched by JSONPath do not match"),
+				Path:    mustBe("DATA.JSONPath<$..age>"),
+				Summary: mustBe(`matched: $.users[0].age: 42
+         $.users[1].age: 33
+         $.users[2].age: 21`),
+				Origin: &expectedError{
+					Message:  mustBe("values differ"),
+					Path:     mustBe("DATA.JSONPath<$..age>[2]"),
+					Got:      mustBe("21"),
+					Expected: mustBe("20"),
+				},
+			})
+
+		checkError(t, got, td.JSONPath(`$.users[?(@.role == "guest")].name`, []string{"Zip"}),
+			expectedError{
+				Message: mustBe("values matched by JSONPath do not match"),
+				Path:    mustBe(`DATA.JSONPath<$.users[?(@.role == "guest")].name>`),
+				Summary: mustBe(`matched: no values`),
+				Origin: &expectedError{
+					Message: mustBe("comparing slices, from index #0"),
+					Path:    mustBe(`DATA.JSONPath<$.users[?(@.role == "guest")].name>`),
+					Summary: mustBe(`Missing item: ("Zip")`),
+				},
+			})
+
+		checkError(t, got, td.JSONPath(`$..name`, []int{1, 2, 3}),
+			expectedError{
+				Message: mustBe("an error occurred while unmarshalling JSON into []int"),
+				Path:    mustBe("DATA.JSONPath<$..name>"),
+				Summary: mustContain("cannot unmarshal string"),
+			})
+	})
+
+	t.Run("SJSONPath", func(t *testing.T) {
+		checkOK(t, got, td.SJSONPath(`$.users[?(@.role == "admin")].name`, "Bob"))
+		checkOK(t, got, td.SJSONPath(`$.users[?(@.name == "Alice")].age`, td.Lt(40)))
+		checkOK(t, got, td.SJSONPath(`$.users[?(@.name == "Alice")].age`, 33)) // Lax enabled
+		checkOK(t, got, td.SJSONPath(`$.users[-1]`,
+			jpUser{Name: "Brian", Role: "user", Age: 21}))
+		checkOK(t, got, td.SJSONPath(`$.users[-1]`,
+			&jpUser{Name: "Brian", Role: "user", Age: 21}))
+		checkOK(t, got, td.SJSONPath(`$.users[-1]`,
+			td.Struct(jpUser{}, td.StructFields{"Age": td.Between(20, 30)})))
+
+		checkError(t, got, td.SJSONPath(`$.users[?(@.role == "user")].age`, td.Gt(20)),
+			expectedError{
+				Message: mustBe("JSONPath does not match exactly one value"),
+				Path:    mustBe(`DATA.JSONPath<$.users[?(@.role == "user")].age>`),
+				Summary: mustBe(`matched: $.users[1].age: 33
+         $.users[2].age: 21`),
+			})
+
+		checkError(t, got, td.SJSONPath(`$.zip`, td.Gt(20)),
+			expectedError{
+				Message: mustBe("JSONPath does not match exactly one value"),
+				Path:    mustBe(`DATA.JSONPath<$.zip>`),
+				Summary: mustBe(`matched: no values`),
+			})
+
+		checkError(t, got, td.SJSONPath(`$.users[?(@.role == "admin")].age`, td.Lt(40)),
+			expectedError{
+				Message:  mustBe("values differ"),
+				Path:     mustBe("DATA.JSONPath<$.users[0].age>"),
+				Got:      mustBe("42"),
+				Expected: mustBe("< 40"),
+			})
+
+		checkError(t, got, td.SJSONPath(`$.users[0]`, jpUser{Name: "Bob"}),
+			expectedError{
+				Message:  mustBe("values differ"),
+				Path:     mustBe("DATA.JSONPath<$.users[0]>.Role"),
+				Got:      mustBe(`"admin"`),
+				Expected: mustBe(`""`),
+			})
+
+		checkError(t,
+			map[string]int{"zzz": 42},
+			td.SJSONPath("$.zzz", jsonPtrTest(56)),
+			expectedError{
+				Message: mustBe("an error occurred while unmarshalling JSON into td_test.jsonPtrTest"),
+				Path:    mustBe("DATA.JSONPath<$.zzz>"),
+				Summary: mustBe("jsonPtrTest unmarshal custom error"),
+			})
+
+		// Numbers are compared exactly
+		checkOK(t, map[string]int64{"id": 1<<53 + 1},
+			td.SJSONPath(`$.id`, int64(1<<53+1)))
+		checkOK(t, []map[string]int64{{"id": 1 << 53}, {"id": 1<<53 + 1}},
+			td.SJSONPath(`$[?(@.id > 9007199254740992)].id`, int64(1<<53+1)))
+		checkError(t, map[string]int64{"id": 1<<53 + 1},
+			td.SJSONPath(`$.id`, int64(1<<53)),
+			expectedError{
+				Message:  mustBe("values differ"),
+				Path:     mustBe("DATA.JSONPath<$.id>"),
+				Got:      mustBe("9007199254740993"),
+				Expected: mustBe("(int64) 9007199254740992"),
+			})
+	})
+
+	t.Run("JSON embedded", func(t *testing.T) {
+		checkOK(t, got, td.JSON(`{
+  "users": JSONPath("$$[?(@.age > 30)].name", ["Bob", "Alice"]),
+  "count": SJSONPath("$$", 3)
+}`))
+
+		test.CheckPanic(t, func() { td.JSON(`JSONPath("$[*]", [])`) },
+			`JSON(): JSON unmarshal error: bad placeholder "$[*]" at line 1:10 (pos 10)
+JSONPath() bad #1 parameter type: string required but nil received at line 1:0 (pos 0)`)
+	})
+
+	//
+	// Errors
+	checkError(t, func() {}, td.JSONPath("$", td.NotNil()),
+		expectedError{
+			Message: mustBe("json.Marshal failed"),
+			Path:    mustBe("DATA"),
+			Summary: mustContain("json: unsupported type"),
+		})
+
+	//
+	// String
+	test.EqualStr(t, td.JSONPath("$.x", td.Gt(2)).String(),
+		"JSONPath($.x, > 2)")
+	test.EqualStr(t, td.JSONPath("$.x", []int{2}).String(),
+		"JSONPath($.x, ([]int) (len=1 cap=1) {\n (int) 2\n})")
+	test.EqualStr(t, td.SJSONPath("$.x", 2).String(),
+		"SJSONPath($.x, 2)")
+	test.EqualStr(t, td.SJSONPath("$.x", nil).String(),
+		"SJSONPath($.x, nil)")
+
+	//
+	// Bad usage
+	test.CheckPanic(t, func() { td.JSONPath("x", 1234) },
+		"JSONPath(): bad JSONPath x: JSONPath must start with $ at offset 0")
+	test.CheckPanic(t, func() { td.SJSONPath("$[", 1234) },
+		"SJSONPath(): bad JSONPath $[: unexpected end of JSONPath at offset 2")
+}
+
+func TestJSONPathTypeBehind(t *testing.T) {
+	equalTypes(t, td.JSONPath("$", 42), nil)
+	equalTypes(t, td.SJSONPath("$", 42), nil)
+}
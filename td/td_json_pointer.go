@@ -56,7 +56,9 @@ var _ TestDeep = &tdJSONPointer{}
 // It does this conversion only if the expected type is a struct, a
 // struct pointer or implements the encoding/json.Unmarshaler
 // interface. In the case the conversion does not occur, the Lax mode
-// is automatically enabled to simplify numeric tests.
+// is automatically enabled to simplify numeric tests. As for JSON
+// operator, numbers are then compared exactly, so without any loss
+// of precision.
 //
 //   got := map[string]int64{"zzz": 42} // 42 is int64 here
 //   td.Cmp(t, got, td.JSONPointer("/zzz", 42))
@@ -131,32 +133,20 @@ func (p *tdJSONPointer) Match(ctx ctxerr.Context, got reflect.Value) *ctxerr.Err
 
 	ctx = jsonPointerContext(ctx, p.pointer)
 
-	// Here, newGot type is either a bool, float64, string,
+	// Here, newGot type is either a bool, json.Number, string,
 	// []interface{} or a map[string]interface{}
 
 	// Check if we have to transform the new got into something
 	// compatible with the type of expected
-	if expectedType := p.internalTypeBehind(); expectedType != nil &&
-		(expectedType.Implements(types.JsonUnmarshaler) ||
-			reflect.PtrTo(expectedType).Implements(types.JsonUnmarshaler) ||
-			types.IsStruct(expectedType)) {
-		b, _ := json.Marshal(newGot) // No error can occur here
-
-		got = reflect.New(expectedType)
-		err := json.Unmarshal(b, got.Interface())
+	if expectedType := p.internalTypeBehind(); jsonConvertible(expectedType) {
+		var err error
+		got, err = jsonUnmarshalAs(newGot, expectedType)
 		if err != nil {
-			if ctx.BooleanError {
-				return ctxerr.BooleanError
-			}
-			return ctx.CollectError(&ctxerr.Error{
-				Message: fmt.Sprintf(
-					"an error occurred while unmarshalling JSON into %s", expectedType),
-				Summary: ctxerr.NewSummary(err.Error()),
-			})
+			return jsonUnmarshalError(ctx, expectedType, err)
 		}
-		got = got.Elem()
 	} else {
 		ctx.BeLax = true
+		ctx.JSONNumbers = true
 		got = reflect.ValueOf(newGot)
 	}
 
@@ -193,3 +183,36 @@ func (p *tdJSONPointer) HandleInvalid() bool {
 func jsonPointerContext(ctx ctxerr.Context, pointer string) ctxerr.Context {
 	return ctx.AddCustomLevel(".JSONPointer<" + pointer + ">")
 }
+
+// jsonConvertible returns true if a value decoded by encoding/json
+// has to be converted to "typ" before being compared: "typ" is a
+// struct, a struct pointer or implements encoding/json.Unmarshaler.
+func jsonConvertible(typ reflect.Type) bool {
+	return typ != nil &&
+		(typ.Implements(types.JsonUnmarshaler) ||
+			reflect.PtrTo(typ).Implements(types.JsonUnmarshaler) ||
+			types.IsStruct(typ))
+}
+
+// jsonUnmarshalAs converts "v", a value decoded by encoding/json, to
+// a new value of type "typ".
+func jsonUnmarshalAs(v interface{}, typ reflect.Type) (reflect.Value, error) {
+	b, _ := json.Marshal(v) // No error can occur here
+
+	got := reflect.New(typ)
+	if err := json.Unmarshal(b, got.Interface()); err != nil {
+		return reflect.Value{}, err
+	}
+	return got.Elem(), nil
+}
+
+func jsonUnmarshalError(ctx ctxerr.Context, typ reflect.Type, err error) *ctxerr.Error {
+	if ctx.BooleanError {
+		return ctxerr.BooleanError
+	}
+	return ctx.CollectError(&ctxerr.Error{
+		Message: fmt.Sprintf(
+			"an error occurred while unmarshalling JSON into %s", typ),
+		Summary: ctxerr.NewSummary(err.Error()),
+	})
+}
@@ -271,6 +271,22 @@ func TestJSONPointer(t *testing.T) {
 			})
 	})
 
+	//
+	// Numbers are compared exactly
+	t.Run("Numbers", func(t *testing.T) {
+		got := map[string]uint64{"id": 9007199254740993}
+
+		checkOK(t, got, td.JSONPointer("/id", uint64(9007199254740993)))
+		checkOK(t, got, td.JSONPointer("/id", td.Gt(uint64(9007199254740992))))
+		checkError(t, got, td.JSONPointer("/id", uint64(9007199254740992)),
+			expectedError{
+				Message:  mustBe("values differ"),
+				Path:     mustBe("DATA.JSONPointer</id>"),
+				Got:      mustBe("9007199254740993"),
+				Expected: mustBe("(uint64) 9007199254740992"),
+			})
+	})
+
 	//
 	// String
 	test.EqualStr(t, td.JSONPointer("/x", td.Gt(2)).String(),
@@ -0,0 +1,161 @@
+// Copyright (c) 2021, Maxime Soulé
+// All rights reserved.
+//
+// This source code is licensed under the BSD-style license found in the
+// LICENSE file in the root directory of this source tree.
+
+package td
+
+import (
+	"bytes"
+	ejson "encoding/json"
+	"reflect"
+
+	"github.com/maxatome/go-testdeep/internal/color"
+	"github.com/maxatome/go-testdeep/internal/ctxerr"
+	"github.com/maxatome/go-testdeep/internal/json"
+	"github.com/maxatome/go-testdeep/internal/jsonschema"
+)
+
+type tdJSONSchema struct {
+	baseOKNil
+	schema *jsonschema.Schema
+	raw    interface{}
+}
+
+var _ TestDeep = &tdJSONSchema{}
+
+// summary(JSONSchema): checks JSON representation conforms to a
+// JSON Schema
+// input(JSONSchema): nil,bool,str,int,float,array,slice,map,struct,ptr
+
+// JSONSchema operator allows to check that the JSON representation
+// of data conforms to the JSON Schema "schema". "schema" can be a:
+//
+//   - string containing a JSON Schema like `{"type":"object"}`
+//   - string containing a JSON Schema filename, ending with ".json"
+//     (its content is ioutil.ReadFile before unmarshaling)
+//   - []byte containing a JSON Schema
+//   - io.Reader stream containing a JSON Schema (is ioutil.ReadAll
+//     before unmarshaling)
+//
+// Data is json.Marshal'ed then json.Unmarshal'ed in an interface{},
+// exactly as JSON operator does, before being validated:
+//
+//   type Person struct {
+//     Name string `json:"name"`
+//     Age  int    `json:"age"`
+//   }
+//   got := Person{Name: "Bob", Age: 42}
+//
+//   td.Cmp(t, got, td.JSONSchema(`
+//   {
+//     "type": "object",
+//     "properties": {
+//       "name": {"type": "string", "minLength": 1},
+//       "age":  {"type": "integer", "minimum": 0}
+//     },
+//     "required": ["name", "age"],
+//     "additionalProperties": false
+//   }`)) // succeeds
+//
+// The core, applicator and validation vocabularies of JSON Schema
+// draft 2020-12 are supported. So the annotation keywords, as
+// "format", are ignored. unevaluatedItems and unevaluatedProperties
+// keywords are not supported. $ref and $dynamicRef can only
+// reference the schema itself, using a JSON pointer (as in
+// "#/$defs/positive") or an anchor (as in "#node"). Note that
+// "pattern" and "patternProperties" regexps use the Go regexp syntax.
+//
+// A bad "schema" (invalid JSON, unknown type, unresolvable
+// reference, etc.) causes a panic when JSONSchema is called.
+//
+// Each violation is reported as an error located at the path of the
+// faulty value, as in DATA["age"], the failing schema keyword being
+// indicated, as in #/properties/age/minimum. As for structs or maps
+// comparisons, several violations can be reported at once,
+// TESTDEEP_MAX_ERRORS environment variable (or ContextConfig
+// MaxErrors field) limiting their number.
+//
+// TypeBehind method returns nil as the schema does not describe a
+// Go type.
+func JSONSchema(schema interface{}) TestDeep {
+	s := tdJSONSchema{
+		baseOKNil: newBaseOKNil(3),
+	}
+
+	b := newJSONUnmarshaler(s.GetLocation()).
+		read(schema, "JSONSchema(STRING_JSON|STRING_FILENAME|[]byte|io.Reader)")
+
+	var raw interface{}
+	if err := ejson.Unmarshal(b, &raw); err != nil {
+		panic(color.Bad("JSONSchema(): JSON unmarshal error: %s", err))
+	}
+	// As Unmarshal succeeded, it cannot fail. Numbers are kept as
+	// json.Number, so they are compared exactly
+	jsonUnmarshalNumbers(b, &s.raw) //nolint: errcheck
+
+	var err error
+	s.schema, err = jsonschema.Compile(s.raw)
+	if err != nil {
+		panic(color.Bad("JSONSchema(): bad schema: %s", err))
+	}
+
+	return &s
+}
+
+func (s *tdJSONSchema) Match(ctx ctxerr.Context, got reflect.Value) *ctxerr.Error {
+	vgot, err := jsonify(ctx, got)
+	if err != nil {
+		return ctx.CollectError(err)
+	}
+
+	s.schema.Validate(vgot, func(vi jsonschema.Violation) bool {
+		if ctx.BooleanError {
+			err = ctxerr.BooleanError
+			return false
+		}
+
+		vctx := ctx
+		for _, p := range vi.Path {
+			switch p := p.(type) {
+			case string:
+				vctx = vctx.AddMapKey(p)
+			case int:
+				vctx = vctx.AddArrayIndex(p)
+			}
+		}
+
+		err = vctx.CollectError(&ctxerr.Error{
+			Message: "JSON Schema violation",
+			Summary: ctxerr.ErrorSummaryItems{
+				{
+					Label: "value",
+					Value: jsonSchemaValue(vi.Value),
+				},
+				{
+					Label: "keyword",
+					Value: vi.Keyword,
+				},
+				{
+					Label: "reason",
+					Value: vi.Message,
+				},
+			},
+		})
+		return err == nil
+	})
+
+	return err
+}
+
+// jsonSchemaValue returns the JSON representation of "v".
+func jsonSchemaValue(v interface{}) string {
+	var b bytes.Buffer
+	json.AppendMarshal(&b, v, 0) //nolint: errcheck
+	return b.String()
+}
+
+func (s *tdJSONSchema) String() string {
+	return jsonStringify("JSONSchema", reflect.ValueOf(s.raw))
+}
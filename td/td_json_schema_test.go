@@ -0,0 +1,169 @@
+// Copyright (c) 2021, Maxime Soulé
+// All rights reserved.
+//
+// This source code is licensed under the BSD-style license found in the
+// LICENSE file in the root directory of this source tree.
+
+package td_test
+
+import (
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/maxatome/go-testdeep/internal/test"
+	"github.com/maxatome/go-testdeep/td"
+)
+
+func TestJSONSchema(t *testing.T) {
+	type Person struct {
+		Name     string   `json:"name"`
+		Age      int      `json:"age"`
+		Children []string `json:"children,omitempty"`
+	}
+
+	schema := `
+{
+  "$schema": "https://json-schema.org/draft/2020-12/schema",
+  "type": "object",
+  "properties": {
+    "name":     {"type": "string", "minLength": 1},
+    "age":      {"$ref": "#/$defs/age"},
+    "children": {"type": "array", "items": {"type": "string"}, "maxItems": 2}
+  },
+  "required": ["name", "age"],
+  "additionalProperties": false,
+  "$defs": {
+    "age": {"type": "integer", "minimum": 0, "maximum": 150}
+  }
+}`
+
+	checkOK(t, Person{Name: "Bob", Age: 42}, td.JSONSchema(schema))
+	checkOK(t, &Person{Name: "Bob", Age: 42, Children: []string{"Alice"}},
+		td.JSONSchema(schema))
+	checkOK(t, map[string]interface{}{"name": "Bob", "age": 42},
+		td.JSONSchema([]byte(schema)))
+	checkOK(t, nil, td.JSONSchema(`{"type": "null"}`))
+	checkOK(t, 42, td.JSONSchema(`true`))
+
+	// Inside JSON
+	checkOK(t, Person{Name: "Bob", Age: 42},
+		td.JSON(`{"name": JSONSchema("{\"minLength\": 2}"), "age": 42}`))
+
+	//
+	// Errors
+	checkError(t, Person{Name: "Bob", Age: 200}, td.JSONSchema(schema),
+		expectedError{
+			Message: mustBe("JSON Schema violation"),
+			Path:    mustBe(`DATA["age"]`),
+			Summary: mustBe(`  value: 200
+keyword: #/$defs/age/maximum
+ reason: must be ≤ 150`),
+		})
+
+	// Numbers are compared exactly
+	checkOK(t, int64(1<<53), td.JSONSchema(`{"maximum": 9007199254740992}`))
+	checkError(t, int64(1<<53+1), td.JSONSchema(`{"maximum": 9007199254740992}`),
+		expectedError{
+			Message: mustBe("JSON Schema violation"),
+			Path:    mustBe("DATA"),
+			Summary: mustBe(`  value: 9007199254740993
+keyword: #/maximum
+ reason: must be ≤ 9007199254740992`),
+		})
+
+	checkError(t, Person{Name: "Bob", Age: 42, Children: []string{"a", "b", "c"}},
+		td.JSONSchema(`{"properties": {"children": {"items": {"maxLength": 0}}}}`),
+		expectedError{
+			Message: mustBe("JSON Schema violation"),
+			Path:    mustBe(`DATA["children"][0]`),
+			Summary: mustContain("reason: must be at most 0 characters long, not 1"),
+		})
+
+	checkError(t, map[string]int{"x": 1}, td.JSONSchema(schema),
+		expectedError{
+			Message: mustBe("JSON Schema violation"),
+			Path:    mustBe("DATA"),
+			Summary: mustBe(`  value: {
+           "x": 1
+         }
+keyword: #/required
+ reason: missing property "name"`),
+		})
+
+	checkError(t, func() {}, td.JSONSchema(schema),
+		expectedError{
+			Message: mustBe("json.Marshal failed"),
+			Path:    mustBe("DATA"),
+		})
+
+	// All violations are reported
+	err := td.EqDeeplyError(
+		map[string]interface{}{"name": "", "age": -1, "zip": 12},
+		td.JSONSchema(schema))
+	if test.IsTrue(t, err != nil) {
+		msg := err.Error()
+		test.IsTrue(t, strings.Contains(msg, `DATA["age"]: JSON Schema violation`), msg)
+		test.IsTrue(t, strings.Contains(msg, `DATA["name"]: JSON Schema violation`), msg)
+		test.IsTrue(t, strings.Contains(msg, `DATA["zip"]: JSON Schema violation`), msg)
+	}
+
+	// ... up to MaxErrors
+	diff := td.Compare(map[string]interface{}{"name": "", "age": -1, "zip": 12},
+		td.JSONSchema(schema), td.ContextConfig{MaxErrors: 2})
+	test.EqualInt(t, len(diff.Mismatches), 2)
+	test.IsTrue(t, diff.TooManyErrors)
+
+	//
+	// Loading a file
+	tmpDir, err2 := ioutil.TempDir("", "")
+	if err2 != nil {
+		t.Fatal(err2)
+	}
+	defer os.RemoveAll(tmpDir) // clean up
+
+	filename := tmpDir + "/schema.json"
+	if err2 = ioutil.WriteFile(filename, []byte(schema), 0644); err2 != nil {
+		t.Fatal(err2)
+	}
+	checkOK(t, Person{Name: "Bob", Age: 42}, td.JSONSchema(filename))
+
+	tmpfile, err2 := os.Open(filename)
+	if err2 != nil {
+		t.Fatal(err2)
+	}
+	checkOK(t, Person{Name: "Bob", Age: 42}, td.JSONSchema(tmpfile))
+	tmpfile.Close()
+
+	//
+	// Panics
+	test.CheckPanic(t, func() { td.JSONSchema("uNkNoWnFiLe.json") },
+		"JSONSchema(): JSON file uNkNoWnFiLe.json cannot be read: ")
+
+	test.CheckPanic(t, func() { td.JSONSchema(42) },
+		"usage: JSONSchema(STRING_JSON|STRING_FILENAME|[]byte|io.Reader), but received int as 1st parameter")
+
+	test.CheckPanic(t, func() { td.JSONSchema(errReader{}) },
+		"JSONSchema(): JSON read error: an error occurred")
+
+	test.CheckPanic(t, func() { td.JSONSchema(`{"type":`) },
+		"JSONSchema(): JSON unmarshal error: unexpected end of JSON input")
+
+	test.CheckPanic(t, func() { td.JSONSchema(`{"type": "int"}`) },
+		`JSONSchema(): bad schema: #/type: unknown type "int"`)
+
+	test.CheckPanic(t, func() { td.JSONSchema(`{"$ref": "other.json"}`) },
+		`JSONSchema(): bad schema: #/$ref: only local references are supported, not "other.json"`)
+
+	//
+	// String
+	test.EqualStr(t, td.JSONSchema(`{"type": "string"}`).String(),
+		`JSONSchema({
+             "type": "string"
+           })`)
+}
+
+func TestJSONSchemaTypeBehind(t *testing.T) {
+	equalTypes(t, td.JSONSchema(`{"type": "string"}`), nil)
+}
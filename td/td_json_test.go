@@ -11,7 +11,9 @@ import (
 	"errors"
 	"io/ioutil"
 	"os"
+	"path/filepath"
 	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/maxatome/go-testdeep/internal/test"
@@ -441,13 +443,118 @@ func TestJSONInside(t *testing.T) {
 			`JSON(): JSON unmarshal error: SuperMapOf() requires only one parameter at line 1:2 (pos 2)`)
 	})
 
+	// Catch
+	t.Run("Catch", func(t *testing.T) {
+		got := map[string]interface{}{"id": 42, "name": "Bob"}
+
+		var id int
+		if checkOK(t, got,
+			td.JSON(`{"id": Catch($id, Gt(0)), "name": "Bob"}`, td.Tag("id", &id))) {
+			test.EqualInt(t, id, 42)
+		}
+
+		var name string
+		if checkOK(t, got,
+			td.SuperJSONOf(`{"name": Catch("$name", HasPrefix("B"))}`, td.Tag("name", &name))) {
+			test.EqualStr(t, name, "Bob")
+		}
+
+		test.CheckPanic(t, func() { td.JSON(`Catch(12, Gt(0))`) },
+			`JSON(): JSON unmarshal error: Catch() bad #1 parameter: $name placeholder referencing a Tag of a non-nil pointer required but json.Number received at line 1:0 (pos 0)`)
+		test.CheckPanic(t, func() { td.JSON(`Catch($1, Gt(0))`, td.Gt(1)) },
+			`JSON(): JSON unmarshal error: Catch() bad #1 parameter: $name placeholder referencing a Tag of a non-nil pointer required but $1 placeholder received at line 1:0 (pos 0)`)
+		test.CheckPanic(t, func() { td.JSON(`Catch($x, Gt(0))`, td.Tag("x", 12)) },
+			`JSON(): JSON unmarshal error: Catch() bad #1 parameter: $name placeholder referencing a Tag of a non-nil pointer required but $x placeholder received at line 1:0 (pos 0)`)
+		test.CheckPanic(t, func() { td.JSON(`Catch($x)`, td.Tag("x", &id)) },
+			`JSON(): JSON unmarshal error: Catch() requires 2 parameters at line 1:0 (pos 0)`)
+	})
+
+	// Tag
+	t.Run("Tag", func(t *testing.T) {
+		got := map[string]int{"min": 3, "max": 8}
+
+		checkOK(t, got, td.JSON(`{"min": Tag("pos", Between(0, 10)), "max": $pos}`))
+		checkOK(t, got, td.JSON(`{"min": Tag("pos", Between(0, 10)), "max": "$pos"}`))
+
+		checkError(t, got, td.JSON(`{"min": Tag("pos", Between(0, 5)), "max": $pos}`),
+			expectedError{
+				Message:  mustBe("values differ"),
+				Path:     mustBe(`DATA["max"]`),
+				Got:      mustBe("8"),
+				Expected: mustBe("0 ≤ got ≤ 5"),
+			})
+
+		test.EqualStr(t,
+			td.JSON(`{"min": Tag("pos", Between(0, 10)), "max": $pos}`).String(),
+			`JSON({
+       "max": "$pos" /* 0 ≤ got ≤ 10 */,
+       "min": 0 ≤ got ≤ 10
+     })`)
+
+		test.CheckPanic(t, func() { td.JSON(`[$pos, Tag("pos", 1)]`) },
+			`JSON(): JSON unmarshal error: unknown placeholder "$pos" at line 1:1 (pos 1)`)
+		test.CheckPanic(t, func() { td.JSON(`[Tag("pos", 1), Tag("pos", 2)]`) },
+			`JSON(): JSON unmarshal error: Tag() "pos" is already used as a placeholder name at line 1:16 (pos 16)`)
+		test.CheckPanic(t, func() { td.JSON(`Tag("pos", 1)`, td.Tag("pos", 2)) },
+			`JSON(): JSON unmarshal error: Tag() "pos" is already used as a placeholder name at line 1:0 (pos 0)`)
+		test.CheckPanic(t, func() { td.JSON(`Tag("1pos", 1)`) },
+			`JSON(): JSON unmarshal error: Tag() Invalid tag, should match (Letter|_)(Letter|_|Number)* at line 1:0 (pos 0)`)
+		test.CheckPanic(t, func() { td.JSON(`Tag(1, 1)`) },
+			`JSON(): JSON unmarshal error: Tag() bad #1 parameter type: string required but json.Number received at line 1:0 (pos 0)`)
+	})
+
+	// Smuggle
+	t.Run("Smuggle", func(t *testing.T) {
+		got := map[string]interface{}{
+			"user": map[string]interface{}{"name": "Bob", "age": 42},
+		}
+
+		checkOK(t, got, td.JSON(`{"user": Smuggle("/name", "Bob")}`))
+		checkOK(t, got, td.JSON(`Smuggle("/user/age", Between(40, 45))`))
+		checkOK(t, got, td.JSON(`Smuggle("[user][name]", HasPrefix("B"))`))
+
+		checkError(t, got, td.JSON(`Smuggle("/user/age", Lt(40))`),
+			expectedError{
+				Message:  mustBe("values differ"),
+				Path:     mustBe("DATA.JSONPointer</user/age>"),
+				Got:      mustBe("42"),
+				Expected: mustBe("< 40"),
+			})
+
+		test.CheckPanic(t, func() { td.JSON(`Smuggle(12, 1)`) },
+			`JSON(): JSON unmarshal error: Smuggle() bad #1 parameter type: fields-path or JSON pointer string required but json.Number received at line 1:0 (pos 0)`)
+		test.CheckPanic(t, func() { td.JSON(`Smuggle($1, 1)`, td.Gt(1)) },
+			`JSON(): JSON unmarshal error: Smuggle() bad #1 parameter type: fields-path or JSON pointer string required but $1 placeholder received at line 1:0 (pos 0)`)
+		test.CheckPanic(t, func() { td.JSON(`Smuggle("/a")`) },
+			`JSON(): JSON unmarshal error: Smuggle() requires 2 parameters at line 1:0 (pos 0)`)
+	})
+
+	// String
+	t.Run("String", func(t *testing.T) {
+		got := map[string]string{"name": "Bob"}
+
+		checkOK(t, got, td.JSON(`{"name": String("Bob")}`))
+		checkOK(t, got, td.JSON(`{"name": All(String("Bob"), HasPrefix("B"))}`))
+
+		checkError(t, got, td.JSON(`{"name": String("Alice")}`),
+			expectedError{
+				Message:  mustBe("does not match"),
+				Path:     mustBe(`DATA["name"]`),
+				Got:      mustBe(`"Bob"`),
+				Expected: mustBe(`"Alice"`),
+			})
+
+		test.CheckPanic(t, func() { td.JSON(`String(12)`) },
+			`JSON(): JSON unmarshal error: String() bad #1 parameter type: string required but float64 received at line 1:0 (pos 0)`)
+	})
+
 	// errors
 	t.Run("Errors", func(t *testing.T) {
 		test.CheckPanic(t, func() { td.JSON(`[ UnknownOp() ]`) },
 			`JSON(): JSON unmarshal error: unknown operator UnknownOp() at line 1:2 (pos 2)`)
 
-		test.CheckPanic(t, func() { td.JSON(`[ Catch() ]`) },
-			`JSON(): JSON unmarshal error: Catch() is not usable in JSON() at line 1:2 (pos 2)`)
+		test.CheckPanic(t, func() { td.JSON(`[ Code() ]`) },
+			`JSON(): JSON unmarshal error: Code() is not usable in JSON() at line 1:2 (pos 2)`)
 
 		test.CheckPanic(t, func() { td.JSON(`[ JSON() ]`) },
 			`JSON(): JSON unmarshal error: JSON() is not usable in JSON(), use literal JSON instead at line 1:2 (pos 2)`)
@@ -472,6 +579,249 @@ func TestJSONInside(t *testing.T) {
 	})
 }
 
+func TestJSONInclude(t *testing.T) {
+	tmpDir, err := ioutil.TempDir("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpDir) // clean up
+
+	for name, content := range map[string]string{
+		"users.json": `{
+  "users":      $^NotEmpty,
+  "pagination": Include("common/pagination.json", {"total": $1})
+}`,
+		"common/pagination.json": `{
+  "page":     Param("page", 1),
+  "per_page": Param("per_page", 20),
+  "total":    Param("total"),
+  "next":     HasPrefix("/users?")
+}`,
+		"missing.json": `Include("common/pagination.json")`,
+		"cycle.json":   `[Include("cycle.json")]`,
+	} {
+		name = filepath.Join(tmpDir, name)
+		if err = os.MkdirAll(filepath.Dir(name), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err = ioutil.WriteFile(name, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	got := map[string]interface{}{
+		"users": []string{"Bob"},
+		"pagination": map[string]interface{}{
+			"page":     1,
+			"per_page": 20,
+			"total":    1,
+			"next":     "/users?page=2",
+		},
+	}
+	checkOK(t, got, td.JSON(filepath.Join(tmpDir, "users.json"), td.Gt(0)))
+
+	// Operators of included files report their position in this file
+	got["pagination"].(map[string]interface{})["next"] = "/groups?page=2"
+	ttb := test.NewTestingTB(t.Name())
+	td.Cmp(ttb, got, td.JSON(filepath.Join(tmpDir, "users.json"), td.Gt(0)))
+	test.IsTrue(t, strings.Contains(ttb.LastMessage(),
+		"[under operator HasPrefix at line 5:14 (pos 115) in "+
+			filepath.Join(tmpDir, "common", "pagination.json")+
+			" inside operator JSON at td_json_test.go:"),
+		ttb.LastMessage())
+
+	test.CheckPanic(t,
+		func() { td.JSON(filepath.Join(tmpDir, "missing.json")) },
+		`JSON(): JSON unmarshal error: Param() missing parameter "total" without default value at line 4:14 (pos 85) in `+
+			filepath.Join(tmpDir, "common", "pagination.json"))
+
+	// Errors in the expected file itself are located in it too
+	cycle := filepath.Join(tmpDir, "cycle.json")
+	test.CheckPanic(t,
+		func() { td.JSON(cycle) },
+		"JSON(): JSON unmarshal error: Include() cycle detected: "+
+			cycle+" → "+cycle+" at line 1:1 (pos 1) in "+cycle)
+}
+
+func TestJSONNumbers(t *testing.T) {
+	type Item struct {
+		ID    int64   `json:"id"`
+		Price float64 `json:"price"`
+	}
+
+	// Integers above 2^53 are compared exactly
+	checkOK(t, Item{ID: 9007199254740993}, td.JSON(`{"id": 9007199254740993, "price": 0}`))
+	checkOK(t, uint64(18446744073709551615), td.JSON(`18446744073709551615`))
+	checkOK(t, json.RawMessage(`1e400`), td.JSON(`1e400`))
+	checkOK(t, json.RawMessage(`1.0`), td.JSON(`1`))
+	checkOK(t, json.RawMessage(`0.1000000000000000000000000001`),
+		td.JSON(`1.000000000000000000000000001e-1`))
+
+	checkError(t, Item{ID: 9007199254740993},
+		td.JSON(`{"id": 9007199254740992, "price": 0}`),
+		expectedError{
+			Message:  mustBe("values differ"),
+			Path:     mustBe(`DATA["id"]`),
+			Got:      mustBe("9007199254740993"),
+			Expected: mustBe("9007199254740992"),
+		})
+
+	checkError(t, json.RawMessage(`0.1000000000000000000000000001`), td.JSON(`0.1`),
+		expectedError{
+			Message:  mustBe("values differ"),
+			Path:     mustBe("DATA"),
+			Got:      mustBe("0.1000000000000000000000000001"),
+			Expected: mustBe("0.1"),
+		})
+
+	checkError(t, 12, td.JSON(`"12"`),
+		expectedError{
+			Message:  mustBe("type mismatch"),
+			Path:     mustBe("DATA"),
+			Got:      mustBe("json.Number"),
+			Expected: mustBe("string"),
+		})
+
+	// Placeholders
+	checkOK(t, Item{ID: 9007199254740993},
+		td.JSON(`{"id": $1, "price": $2}`, int64(9007199254740993), 0))
+	checkError(t, Item{ID: 9007199254740993},
+		td.JSON(`{"id": $1, "price": 0}`, int64(9007199254740992)),
+		expectedError{
+			Message:  mustBe("values differ"),
+			Path:     mustBe(`DATA["id"]`),
+			Got:      mustBe("9007199254740993"),
+			Expected: mustBe("9007199254740992"),
+		})
+
+	// Operators
+	checkOK(t, Item{ID: 9007199254740993, Price: 12.5},
+		td.JSON(`{"id": $1, "price": Between(10, 20)}`,
+			td.Between(int64(9007199254740992), int64(9007199254740994))))
+	checkOK(t, Item{ID: 9007199254740993, Price: 12.5},
+		td.JSON(`{"id": $^NotZero, "price": N(12.4, 0.1)}`))
+	checkOK(t, Item{ID: 42}, td.JSON(`{"id": Gt(41), "price": Zero()}`))
+	checkError(t, Item{ID: 9007199254740993},
+		td.JSON(`{"id": $1, "price": 0}`, td.Gt(int64(9007199254740993))),
+		expectedError{
+			Message:  mustBe("values differ"),
+			Path:     mustBe(`DATA["id"]`),
+			Got:      mustBe("9007199254740993"),
+			Expected: mustBe("> 9007199254740993"),
+		})
+
+	checkError(t, Item{ID: 9007199254740993},
+		td.JSON(`{"id": $1, "price": 0}`, td.Gt(int32(0))),
+		expectedError{
+			Message:  mustBe("JSON number does not fit in expected type"),
+			Path:     mustBe(`DATA["id"]`),
+			Got:      mustBe("9007199254740993"),
+			Expected: mustBe("int32"),
+		})
+
+	checkError(t, Item{Price: 1.5},
+		td.JSON(`{"id": 0, "price": $1}`, td.Lt(2)),
+		expectedError{
+			Message:  mustBe("JSON number does not fit in expected type"),
+			Path:     mustBe(`DATA["price"]`),
+			Got:      mustBe("1.5"),
+			Expected: mustBe("int"),
+		})
+
+	// Operators embedded in JSON keep integers above 2^53 exact
+	checkOK(t, Item{ID: 9007199254740994},
+		td.JSON(`{"id": Between(9007199254740993, 9007199254740995), "price": 0}`))
+	checkError(t, Item{ID: 9007199254740992},
+		td.JSON(`{"id": Between(9007199254740993, 9007199254740995), "price": 0}`),
+		expectedError{
+			Message:  mustBe("values differ"),
+			Path:     mustBe(`DATA["id"]`),
+			Got:      mustBe("9007199254740992"),
+			Expected: mustBe("9007199254740993 ≤ got ≤ 9007199254740995"),
+		})
+	checkOK(t, Item{ID: 9007199254740994},
+		td.JSON(`{"id": Gt(9007199254740993), "price": 0}`))
+	checkError(t, Item{ID: 9007199254740993},
+		td.JSON(`{"id": Gt(9007199254740993), "price": 0}`),
+		expectedError{
+			Message:  mustBe("values differ"),
+			Path:     mustBe(`DATA["id"]`),
+			Got:      mustBe("9007199254740993"),
+			Expected: mustBe("> 9007199254740993"),
+		})
+	checkOK(t, uint64(18446744073709551615),
+		td.JSON(`Gte(18446744073709551615)`))
+	checkError(t, uint64(18446744073709551614),
+		td.JSON(`Gte(18446744073709551615)`),
+		expectedError{
+			Message:  mustBe("values differ"),
+			Path:     mustBe("DATA"),
+			Got:      mustBe("18446744073709551614"),
+			Expected: mustBe("≥ 18446744073709551615"),
+		})
+	checkOK(t, uint64(18446744073709551614),
+		td.JSON(`Between(18446744073709551613, 18446744073709551615)`))
+
+	// Numeric parameters of different types are converted to a same one
+	checkOK(t, int64(9007199254740992), td.JSON(`Between(1, 9007199254740993)`))
+	checkError(t, int64(9007199254740994), td.JSON(`Between(1, 9007199254740993)`),
+		expectedError{
+			Message:  mustBe("values differ"),
+			Path:     mustBe("DATA"),
+			Got:      mustBe("9007199254740994"),
+			Expected: mustBe("1 ≤ got ≤ 9007199254740993"),
+		})
+	checkOK(t, uint64(18446744073709551614),
+		td.JSON(`Between(1, 18446744073709551615)`))
+	checkOK(t, 3.5, td.JSON(`Between(1.5, 9007199254740993)`))
+
+	// Registered operators receive numbers in the type they want
+	td.RegisterJSONOperator("TestJSONNumbersID", func(id int64) td.TestDeep {
+		return td.Between(id-1, id+1)
+	})
+	checkOK(t, Item{ID: 9007199254740993},
+		td.JSON(`{"id": $^TestJSONNumbersID(9007199254740993), "price": 0}`))
+	test.CheckPanic(t,
+		func() { td.JSON(`$^TestJSONNumbersID(1.5)`) },
+		`JSON(): JSON unmarshal error: $^TestJSONNumbersID() bad #1 parameter: 1.5 does not fit in int64 at line 1:0 (pos 0)`)
+
+	// SubJSONOf & SuperJSONOf
+	checkOK(t, Item{ID: 9007199254740993},
+		td.SubJSONOf(`{"id": 9007199254740993, "price": 0, "other": 1}`))
+	checkOK(t, Item{ID: 9007199254740993},
+		td.SuperJSONOf(`{"id": 9007199254740993}`))
+	checkError(t, Item{ID: 9007199254740993},
+		td.SuperJSONOf(`{"id": 9007199254740992}`),
+		expectedError{
+			Message: mustBe("values differ"),
+			Path:    mustBe(`DATA["id"]`),
+		})
+
+	// Operators see usual Go numbers, int64 only when a float64 cannot
+	// represent them exactly
+	checkOK(t, map[string]interface{}{"items": []interface{}{1.5, 2}},
+		td.SuperJSONOf(`{"items": $1}`, td.Code(func(items []interface{}) bool {
+			_, ok := items[0].(float64)
+			return ok
+		})))
+	checkOK(t, []uint64{9007199254740993, 2},
+		td.JSON(`$1`, td.Code(func(items []interface{}) bool {
+			return items[0] == int64(9007199254740993) && items[1] == float64(2)
+		})))
+	checkOK(t, []uint64{9007199254740993, 2},
+		td.JSON(`$1`, td.Bag(2, int64(9007199254740993))))
+	checkOK(t, map[string]float64{"a": 1.5},
+		td.JSON(`SuperMapOf({"a": 1.5})`))
+	checkError(t, map[string]int64{"a": 9007199254740993},
+		td.JSON(`SuperMapOf({"a": 9007199254740992})`),
+		expectedError{
+			Message:  mustBe("values differ"),
+			Path:     mustBe(`DATA["a"]`),
+			Got:      mustBe("(int64) 9007199254740993"),
+			Expected: mustBe("9007199254740992"),
+		})
+}
+
 func TestJSONTypeBehind(t *testing.T) {
 	equalTypes(t, td.JSON(`false`), true)
 	equalTypes(t, td.JSON(`"foo"`), "")
@@ -330,7 +330,7 @@ func (m *tdMap) match(ctx ctxerr.Context, got reflect.Value) (err *ctxerr.Error)
 				Kind:    keysSetResult,
 				Missing: notFoundKeys,
 				Sort:    true,
-			}).Summary(),
+			}).Summary(ctx),
 		})
 	}
 
@@ -354,7 +354,7 @@ func (m *tdMap) match(ctx ctxerr.Context, got reflect.Value) (err *ctxerr.Error)
 				Kind:    keysSetResult,
 				Missing: notFoundKeys,
 				Sort:    true,
-			}).Summary(),
+			}).Summary(ctx),
 		})
 	}
 
@@ -378,7 +378,7 @@ func (m *tdMap) match(ctx ctxerr.Context, got reflect.Value) (err *ctxerr.Error)
 
 	return ctx.CollectError(&ctxerr.Error{
 		Message: errorMessage,
-		Summary: res.Summary(),
+		Summary: res.Summary(ctx),
 	})
 }
 
@@ -175,7 +175,7 @@ func (r *tdRe) matchStringCaptures(ctx ctxerr.Context, got string, result [][]st
 
 func (r *tdRe) matchCaptures(ctx ctxerr.Context, captures interface{}) (err *ctxerr.Error) {
 	return deepValueEqual(
-		ctx.ResetPath("("+ctx.Path.String()+" =~ "+r.String()+")"),
+		ctx.ResetPath("("+ctx.PathString()+" =~ "+r.String()+")"),
 		reflect.ValueOf(captures), r.captures)
 }
 
@@ -80,7 +80,7 @@ func (s *tdSetBase) Match(ctx ctxerr.Context, got reflect.Value) *ctxerr.Error {
 					continue
 				}
 
-				if deepValueEqualFinalOK(ctx, got.Index(idx), expected) {
+				if deepValueEqualFinalOK(setItemContext(ctx, idx), got.Index(idx), expected) {
 					foundItems = append(foundItems, expected)
 
 					foundGotIdxes[idx] = true
@@ -112,7 +112,7 @@ func (s *tdSetBase) Match(ctx ctxerr.Context, got reflect.Value) *ctxerr.Error {
 				nextExpected:
 					for _, expected := range missingItems {
 						for idxGot := range foundGotIdxes {
-							if deepValueEqualFinalOK(ctx, got.Index(idxGot), expected) {
+							if deepValueEqualFinalOK(setItemContext(ctx, idxGot), got.Index(idxGot), expected) {
 								continue nextExpected
 							}
 						}
@@ -156,7 +156,7 @@ func (s *tdSetBase) Match(ctx ctxerr.Context, got reflect.Value) *ctxerr.Error {
 		}
 		return ctx.CollectError(&ctxerr.Error{
 			Message: "comparing %% as a " + s.GetLocation().Func,
-			Summary: res.Summary(),
+			Summary: res.Summary(ctx),
 		})
 	}
 
@@ -182,3 +182,28 @@ func (s *tdSetBase) String() string {
 	return util.SliceToBuffer(
 		bytes.NewBufferString(s.GetLocation().Func), s.expectedItems).String()
 }
+
+// setItemContext returns the context to use to compare the got item
+// at index "idx". As items are compared in a boolean context, the
+// index is only added to the path when needed by path rules or
+// unordered slices patterns.
+func setItemContext(ctx ctxerr.Context, idx int) ctxerr.Context {
+	if ctx.UsesPath() {
+		return ctx.AddArrayIndex(idx)
+	}
+	return ctx
+}
+
+// unorderedEqual compares "got" and "expected" slices or arrays
+// regardless of the order of their items, as Bag operator does.
+func unorderedEqual(ctx ctxerr.Context, got, expected reflect.Value) *ctxerr.Error {
+	s := tdSetBase{
+		kind:          allSet,
+		expectedItems: make([]reflect.Value, expected.Len()),
+	}
+	s.location.Func = "Bag"
+	for i := range s.expectedItems {
+		s.expectedItems[i] = expected.Index(i)
+	}
+	return s.Match(ctx, got)
+}
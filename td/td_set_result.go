@@ -14,7 +14,6 @@ import (
 	"github.com/maxatome/go-testdeep/helpers/tdutil"
 	"github.com/maxatome/go-testdeep/internal/ctxerr"
 	"github.com/maxatome/go-testdeep/internal/types"
-	"github.com/maxatome/go-testdeep/internal/util"
 )
 
 type tdSetResultKind uint8
@@ -48,7 +47,7 @@ func (r tdSetResult) IsEmpty() bool {
 	return len(r.Missing) == 0 && len(r.Extra) == 0
 }
 
-func (r tdSetResult) Summary() ctxerr.ErrorSummary {
+func (r tdSetResult) Summary(ctx ctxerr.Context) ctxerr.ErrorSummary {
 	var summary ctxerr.ErrorSummaryItems
 
 	if len(r.Missing) > 0 {
@@ -65,7 +64,7 @@ func (r tdSetResult) Summary() ctxerr.ErrorSummary {
 
 		summary = append(summary, ctxerr.ErrorSummaryItem{
 			Label: missing,
-			Value: util.ToString(r.Missing),
+			Value: ctx.ToString(r.Missing),
 		})
 	}
 
@@ -83,7 +82,7 @@ func (r tdSetResult) Summary() ctxerr.ErrorSummary {
 
 		summary = append(summary, ctxerr.ErrorSummaryItem{
 			Label: extra,
-			Value: util.ToString(r.Extra),
+			Value: ctx.ToString(r.Extra),
 		})
 	}
 
@@ -282,7 +282,8 @@ func (s *tdStruct) Match(ctx ctxerr.Context, got reflect.Value) (err *ctxerr.Err
 	}
 
 	for _, fieldInfo := range s.expectedFields {
-		err = deepValueEqual(ctx.AddField(fieldInfo.name),
+		err = deepValueEqual(
+			ctx.AddStructField(got.Type().FieldByIndex(fieldInfo.index)),
 			got.FieldByIndex(fieldInfo.index), fieldInfo.expected)
 		if err != nil {
 			return
@@ -0,0 +1,444 @@
+// Copyright (c) 2021, Maxime Soulé
+// All rights reserved.
+//
+// This source code is licensed under the BSD-style license found in the
+// LICENSE file in the root directory of this source tree.
+
+package td
+
+import (
+	"bytes"
+	ejson "encoding/json"
+	exml "encoding/xml"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/maxatome/go-testdeep/internal/ctxerr"
+	"github.com/maxatome/go-testdeep/internal/dark"
+	"github.com/maxatome/go-testdeep/internal/json"
+	"github.com/maxatome/go-testdeep/internal/location"
+	"github.com/maxatome/go-testdeep/internal/types"
+	"github.com/maxatome/go-testdeep/internal/xml"
+)
+
+type tdXML struct {
+	base
+	expected *xml.Node
+}
+
+var _ TestDeep = &tdXML{}
+
+// newXMLUnmarshaler returns a new instance of tdJSONUnmarshaler
+// for XML data.
+func newXMLUnmarshaler(pos location.Location) tdJSONUnmarshaler {
+	return tdJSONUnmarshaler{
+		Location: pos,
+		format:   "XML",
+		exts:     []string{".xml"},
+		parse:    xml.Parse,
+	}
+}
+
+// summary(XML): compares against XML document with placeholders
+// input(XML): str,slice([]byte),struct,ptr
+
+// XML operator allows to compare an XML document against
+// "expectedXML". "expectedXML" can be a:
+//
+//   - string containing XML data like `<person age="42">Bob</person>`
+//   - string containing a XML filename, ending with ".xml" (its
+//     content is ioutil.ReadFile before parsing)
+//   - []byte containing XML data
+//   - io.Reader stream containing XML data (is ioutil.ReadAll before
+//     parsing)
+//
+// The compared data can be a string or a []byte containing an XML
+// document. Any other data is first xml.Marshal'ed.
+//
+// Both documents are parsed into canonical trees before being
+// compared element by element:
+//
+//   - element and attribute names are compared using their namespace
+//     URL, not their prefix;
+//   - attribute order and namespace declarations are ignored;
+//   - the text content of an element is the concatenation of its
+//     character data (including CDATA sections), leading and trailing
+//     whitespaces removed, so indentation does not matter;
+//   - comments, processing instructions and directives are ignored;
+//   - child elements are compared in order.
+//
+//   td.Cmp(t, `<feed><entry href="/a"/><entry href="/b">B</entry></feed>`,
+//     td.XML(`
+//   <feed>
+//     <entry href="/a"/>
+//     <entry href="/b">B</entry>
+//   </feed>`)) // succeeds
+//
+// As for JSON operator, a text content or an attribute value can be
+// a placeholder referencing "params" items, numeric like $2 or named
+// like $name using Tag operator, or an embedded operator using
+// $^OperatorName(PARAMS…) notation, PARAMS being expressed in JSON:
+//
+//   td.Cmp(t, got, td.XML(`
+//   <person id="$^NotZero" age="$1">
+//     <name>$^Re("^Bo")</name>
+//     <role>$name</role>
+//   </person>`,
+//     td.Between(40, 45),
+//     td.Tag("name", td.Any("admin", "user"))))
+//
+// The operators usable in JSON, as well as the ones registered using
+// RegisterJSONOperator, can be embedded this way. To avoid a legit
+// "$" prefix causes a bad placeholder error, just double it to escape
+// it, as in "$$info".
+//
+// Text contents and attribute values are strings. Nevertheless, when
+// the expected value is a number (or an operator working on
+// numbers, like Between) and the got string is a valid JSON number,
+// the got value is converted to a number before the comparison, that
+// is then done exactly as for JSON operator. The same applies to
+// booleans. Note that Lax mode is automatically enabled by
+// XML operator to simplify numeric tests.
+//
+// In case of error, the path indicates the faulty node using an XPath
+// like notation, as in DATA/feed/entry[2]/@href. Namespaced
+// attributes are prefixed by their namespace, as in
+// DATA/feed/entry[2]/@{http://www.w3.org/1999/xlink}href.
+//
+// TypeBehind method returns nil as XML operator accepts several
+// types.
+func XML(expectedXML interface{}, params ...interface{}) TestDeep {
+	x := tdXML{
+		base: newBase(3),
+	}
+
+	v := newXMLUnmarshaler(x.GetLocation()).unmarshal(expectedXML, params)
+	x.expected = v.(*xml.Node)
+
+	return &x
+}
+
+func (x *tdXML) Match(ctx ctxerr.Context, got reflect.Value) *ctxerr.Error {
+	gotIf, ok := dark.GetInterface(got, true)
+	if !ok {
+		return ctx.CollectError(ctx.CannotCompareError())
+	}
+
+	var b []byte
+	switch gotIf := gotIf.(type) {
+	case string:
+		b = []byte(gotIf)
+	case []byte:
+		b = gotIf
+	default:
+		var err error
+		b, err = exml.Marshal(gotIf)
+		if err != nil {
+			if ctx.BooleanError {
+				return ctxerr.BooleanError
+			}
+			return ctx.CollectError(&ctxerr.Error{
+				Message: "xml.Marshal failed",
+				Summary: ctxerr.NewSummary(err.Error()),
+			})
+		}
+	}
+
+	gotNode, err := xml.ParseRaw(b)
+	if err != nil {
+		if ctx.BooleanError {
+			return ctxerr.BooleanError
+		}
+		return ctx.CollectError(&ctxerr.Error{
+			Message: "cannot parse got XML document",
+			Summary: ctxerr.NewSummary(err.Error()),
+		})
+	}
+
+	ctx.BeLax = true
+
+	if gotNode.Name != x.expected.Name {
+		if ctx.BooleanError {
+			return ctxerr.BooleanError
+		}
+		return ctx.CollectError(&ctxerr.Error{
+			Message:  "root element names differ",
+			Got:      types.RawString(xmlName(gotNode.Name)),
+			Expected: types.RawString(xmlName(x.expected.Name)),
+		})
+	}
+
+	return x.matchNode(ctx.AddCustomLevel("/"+gotNode.Name.Local), gotNode, x.expected)
+}
+
+func (x *tdXML) matchNode(ctx ctxerr.Context, got, expected *xml.Node) *ctxerr.Error {
+	// Attributes
+	var err *ctxerr.Error
+	gotIdx, expIdx := 0, 0
+	for gotIdx < len(got.Attrs) || expIdx < len(expected.Attrs) {
+		var gotAttr, expAttr *xml.Attr
+		if gotIdx < len(got.Attrs) {
+			gotAttr = &got.Attrs[gotIdx]
+		}
+		if expIdx < len(expected.Attrs) {
+			expAttr = &expected.Attrs[expIdx]
+		}
+
+		switch {
+		case expAttr == nil || (gotAttr != nil && xmlNameLess(gotAttr.Name, expAttr.Name)):
+			if ctx.BooleanError {
+				return ctxerr.BooleanError
+			}
+			err = ctx.AddCustomLevel("/@" + xmlName(gotAttr.Name)).CollectError(&ctxerr.Error{
+				Message: "unexpected attribute",
+				Got:     gotAttr.Value,
+			})
+			gotIdx++
+
+		case gotAttr == nil || xmlNameLess(expAttr.Name, gotAttr.Name):
+			if ctx.BooleanError {
+				return ctxerr.BooleanError
+			}
+			err = ctx.AddCustomLevel("/@" + xmlName(expAttr.Name)).CollectError(&ctxerr.Error{
+				Message:  "missing attribute",
+				Expected: xmlExpectedValue(expAttr.Value),
+			})
+			expIdx++
+
+		default:
+			err = xmlMatchValue(ctx.AddCustomLevel("/@"+xmlName(expAttr.Name)),
+				gotAttr.Value.(string), expAttr.Value)
+			gotIdx++
+			expIdx++
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	// Text content
+	err = xmlMatchValue(ctx.AddCustomLevel("/text()"),
+		got.Text.(string), expected.Text)
+	if err != nil {
+		return err
+	}
+
+	// Child elements
+	gotCounts, expCounts := xmlNameCounts(got.Children), xmlNameCounts(expected.Children)
+	seen := map[exml.Name]int{}
+	for i, gotChild := range got.Children {
+		if i >= len(expected.Children) {
+			break
+		}
+		expChild := expected.Children[i]
+
+		seen[gotChild.Name]++
+
+		level := "/" + gotChild.Name.Local
+		if gotCounts[gotChild.Name] > 1 || expCounts[gotChild.Name] > 1 {
+			level += "[" + strconv.Itoa(seen[gotChild.Name]) + "]"
+		}
+		cctx := ctx.AddCustomLevel(level)
+
+		if gotChild.Name != expChild.Name {
+			if ctx.BooleanError {
+				return ctxerr.BooleanError
+			}
+			err = cctx.CollectError(&ctxerr.Error{
+				Message:  "element names differ",
+				Got:      types.RawString(xmlName(gotChild.Name)),
+				Expected: types.RawString(xmlName(expChild.Name)),
+			})
+		} else {
+			err = x.matchNode(cctx, gotChild, expChild)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(got.Children) != len(expected.Children) {
+		if ctx.BooleanError {
+			return ctxerr.BooleanError
+		}
+
+		var (
+			label string
+			nodes []*xml.Node
+		)
+		if len(got.Children) > len(expected.Children) {
+			label, nodes = "Extra", got.Children[len(expected.Children):]
+		} else {
+			label, nodes = "Missing", expected.Children[len(got.Children):]
+		}
+		if len(nodes) == 1 {
+			label += " element"
+		} else {
+			label += fmt.Sprintf(" %d elements", len(nodes))
+		}
+
+		names := make([]string, len(nodes))
+		for i, node := range nodes {
+			names[i] = "<" + xmlName(node.Name) + ">"
+		}
+
+		return ctx.CollectError(&ctxerr.Error{
+			Message: "comparing child elements",
+			Summary: ctxerr.ErrorSummaryItem{
+				Label: label,
+				Value: strings.Join(names, ", "),
+			},
+		})
+	}
+	return nil
+}
+
+// xmlMatchValue compares the "got" text content or attribute value
+// against "expected".
+func xmlMatchValue(ctx ctxerr.Context, got string, expected interface{}) *ctxerr.Error {
+	if s, ok := expected.(string); ok {
+		if got == s {
+			return nil
+		}
+		if ctx.BooleanError {
+			return ctxerr.BooleanError
+		}
+		return ctx.CollectError(&ctxerr.Error{
+			Message:  "values differ",
+			Got:      got,
+			Expected: s,
+		})
+	}
+
+	// Convert got to json.Number or bool when expected wants it
+	var typ reflect.Type
+	if op, ok := expected.(TestDeep); ok {
+		typ = op.TypeBehind()
+	} else if expected != nil {
+		typ = reflect.TypeOf(expected)
+	}
+
+	vgot := reflect.ValueOf(got)
+	if typ != nil {
+		switch {
+		case typ == types.JsonNumber || json.IsNumberKind(typ.Kind()):
+			// json.Number is then compared exactly, see jsonNumberEqual
+			if isJSONNumber(got) {
+				vgot = reflect.ValueOf(ejson.Number(got))
+			}
+		case typ.Kind() == reflect.Bool:
+			if b, err := strconv.ParseBool(got); err == nil {
+				vgot = reflect.ValueOf(b)
+			}
+		}
+	}
+
+	return deepValueEqual(ctx, vgot, reflect.ValueOf(expected))
+}
+
+// isJSONNumber returns true if "s" is a valid JSON number.
+func isJSONNumber(s string) bool {
+	return s != "" && (s[0] == '-' || (s[0] >= '0' && s[0] <= '9')) &&
+		ejson.Valid([]byte(s))
+}
+
+// xmlExpectedValue returns the value to display for an expected
+// text content or attribute value.
+func xmlExpectedValue(v interface{}) interface{} {
+	if op, ok := v.(TestDeep); ok {
+		return types.RawString(op.String())
+	}
+	return v
+}
+
+// xmlNameCounts returns the number of occurrences of each element
+// name in "nodes".
+func xmlNameCounts(nodes []*xml.Node) map[exml.Name]int {
+	counts := make(map[exml.Name]int, len(nodes))
+	for _, node := range nodes {
+		counts[node.Name]++
+	}
+	return counts
+}
+
+func xmlNameLess(a, b exml.Name) bool {
+	if a.Space != b.Space {
+		return a.Space < b.Space
+	}
+	return a.Local < b.Local
+}
+
+// xmlName returns the "{space}local" representation of "name", or
+// only "local" if "name" has no namespace.
+func xmlName(name exml.Name) string {
+	if name.Space == "" {
+		return name.Local
+	}
+	return "{" + name.Space + "}" + name.Local
+}
+
+func (x *tdXML) String() string {
+	var b bytes.Buffer
+	b.WriteString(x.GetLocation().Func)
+	b.WriteByte('(')
+	xmlStringify(&b, x.expected, "", "")
+	b.WriteByte(')')
+	return b.String()
+}
+
+// xmlStringify appends the canonical representation of "node" to
+// "b", each line being prefixed by "indent". "space" is the
+// namespace of the parent element.
+func xmlStringify(b *bytes.Buffer, node *xml.Node, indent, space string) {
+	b.WriteByte('<')
+	b.WriteString(node.Name.Local)
+	if node.Name.Space != space {
+		fmt.Fprintf(b, " xmlns=%q", node.Name.Space)
+	}
+	for _, attr := range node.Attrs {
+		b.WriteByte(' ')
+		b.WriteString(xmlName(attr.Name))
+		b.WriteString(`="`)
+		xmlStringifyValue(b, attr.Value)
+		b.WriteByte('"')
+	}
+
+	text, isStr := node.Text.(string)
+	if isStr && text == "" && len(node.Children) == 0 {
+		b.WriteString("/>")
+		return
+	}
+	b.WriteByte('>')
+
+	xmlStringifyValue(b, node.Text)
+
+	if len(node.Children) > 0 {
+		childIndent := indent + "  "
+		for _, child := range node.Children {
+			b.WriteByte('\n')
+			b.WriteString(childIndent)
+			xmlStringify(b, child, childIndent, node.Name.Space)
+		}
+		b.WriteByte('\n')
+		b.WriteString(indent)
+	}
+
+	b.WriteString("</")
+	b.WriteString(node.Name.Local)
+	b.WriteByte('>')
+}
+
+func xmlStringifyValue(b *bytes.Buffer, v interface{}) {
+	switch v := v.(type) {
+	case string:
+		exml.EscapeText(b, []byte(v)) //nolint: errcheck
+	case TestDeep:
+		b.WriteString(v.String())
+	case nil:
+		b.WriteString("null")
+	default:
+		fmt.Fprint(b, v)
+	}
+}
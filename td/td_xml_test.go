@@ -0,0 +1,293 @@
+// Copyright (c) 2021, Maxime Soulé
+// All rights reserved.
+//
+// This source code is licensed under the BSD-style license found in the
+// LICENSE file in the root directory of this source tree.
+
+package td_test
+
+import (
+	"encoding/xml"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/maxatome/go-testdeep/internal/test"
+	"github.com/maxatome/go-testdeep/td"
+)
+
+func TestXML(t *testing.T) {
+	feed := `<?xml version="1.0" encoding="UTF-8"?>
+<!-- A feed -->
+<feed xmlns="http://www.w3.org/2005/Atom" xmlns:media="http://search.yahoo.com/mrss/">
+  <title>Example</title>
+  <entry id="1" href="/a"><title>First</title></entry>
+  <entry href="/b" id="2">
+    <title><![CDATA[Second]]></title>
+    <media:thumbnail url="/b.png"/>
+  </entry>
+</feed>`
+
+	//
+	// Basic
+	checkOK(t, feed, td.XML(`
+<a:feed xmlns:a="http://www.w3.org/2005/Atom" xmlns:m="http://search.yahoo.com/mrss/">
+  <a:title>Example</a:title>
+  <a:entry href="/a" id="1">
+    <a:title>First</a:title>
+  </a:entry>
+  <a:entry id="2" href="/b">
+    <a:title>Second</a:title>
+    <m:thumbnail url="/b.png"/>
+  </a:entry>
+</a:feed>`))
+
+	checkOK(t, []byte(`<a>  text  </a>`), td.XML(`<a>text</a>`))
+
+	// Marshaled value
+	type Person struct {
+		XMLName xml.Name `xml:"person"`
+		ID      int      `xml:"id,attr"`
+		Name    string   `xml:"name"`
+		Age     int      `xml:"age"`
+	}
+	bob := Person{ID: 12, Name: "Bob", Age: 42}
+	checkOK(t, bob, td.XML(`<person id="12"><name>Bob</name><age>42</age></person>`))
+	checkOK(t, &bob, td.XML(`<person id="12"><name>Bob</name><age>42</age></person>`))
+
+	//
+	// Placeholders
+	checkOK(t, bob,
+		td.XML(`<person id="$2"><name>$name</name><age>$3</age></person>`,
+			td.Tag("name", td.Re(`^Bo`)), // matches $1 and $name
+			td.Between(10, 15),
+			42))
+	checkOK(t, `<a b="true">12.5</a>`, td.XML(`<a b="$1">$2</a>`, true, 12.5))
+
+	//
+	// Embedded operators
+	checkOK(t, feed, td.XML(`
+<feed xmlns="http://www.w3.org/2005/Atom" xmlns:media="http://search.yahoo.com/mrss/">
+  <title>$^NotEmpty</title>
+  <entry id="$^Between(1, 2)" href="$^HasPrefix(&quot;/&quot;)">
+    <title>$^Re("^F")</title>
+  </entry>
+  <entry href='$^Contains("b")' id="2">
+    <title>$^Any("Second", "Third")</title>
+    <media:thumbnail url="$^HasSuffix(&quot;.png&quot;)"/>
+  </entry>
+</feed>`))
+
+	// User defined operators
+	checkOK(t, `<p country="FR"><age>42</age></p>`,
+		td.XML(`<p country="$^CountryCode"><age>$^AgeBetween(40, 45)</age></p>`))
+
+	// Escaping $
+	checkOK(t, `<a b="$x">$y</a>`, td.XML(`<a b="$$x">$$y</a>`))
+
+	//
+	// Errors
+	checkError(t, feed, td.XML(`
+<feed xmlns="http://www.w3.org/2005/Atom" xmlns:media="http://search.yahoo.com/mrss/">
+  <title>Example</title>
+  <entry id="1" href="/a"><title>First</title></entry>
+  <entry id="2" href="/c">
+    <title>Second</title>
+    <media:thumbnail url="/b.png"/>
+  </entry>
+</feed>`),
+		expectedError{
+			Message:  mustBe("values differ"),
+			Path:     mustBe("DATA/feed/entry[2]/@href"),
+			Got:      mustBe(`"/b"`),
+			Expected: mustBe(`"/c"`),
+		})
+
+	checkError(t, bob,
+		td.XML(`<person id="12"><name>Bob</name><age>$^Lt(40)</age></person>`),
+		expectedError{
+			Message:  mustBe("values differ"),
+			Path:     mustBe("DATA/person/age/text()"),
+			Got:      mustBe("42"),
+			Expected: mustBe("< 40"),
+		})
+
+	checkError(t, `<a><b/></a>`, td.XML(`<b><b/></b>`),
+		expectedError{
+			Message:  mustBe("root element names differ"),
+			Path:     mustBe("DATA"),
+			Got:      mustBe("a"),
+			Expected: mustBe("b"),
+		})
+
+	checkError(t, `<a xmlns="urn:x"><b/></a>`, td.XML(`<a xmlns="urn:x"><c/></a>`),
+		expectedError{
+			Message:  mustBe("element names differ"),
+			Path:     mustBe("DATA/a/b"),
+			Got:      mustBe("{urn:x}b"),
+			Expected: mustBe("{urn:x}c"),
+		})
+
+	checkError(t, `<a x="1" y="2"/>`, td.XML(`<a x="1"/>`),
+		expectedError{
+			Message: mustBe("unexpected attribute"),
+			Path:    mustBe("DATA/a/@y"),
+			Got:     mustBe(`"2"`),
+		})
+
+	checkError(t, `<a x="1"/>`, td.XML(`<a x="1" y="$^NotEmpty"/>`),
+		expectedError{
+			Message:  mustBe("missing attribute"),
+			Path:     mustBe("DATA/a/@y"),
+			Expected: mustBe("NotEmpty()"),
+		})
+
+	// Namespaced attributes are distinguished in paths
+	checkError(t, `<a xmlns:l="urn:l" l:href="/b"/>`, td.XML(`<a href="/b"/>`),
+		expectedError{
+			Message:  mustBe("missing attribute"),
+			Path:     mustBe("DATA/a/@href"),
+			Expected: mustBe(`"/b"`),
+		})
+
+	checkError(t, `<a href="/b"/>`, td.XML(`<a xmlns:l="urn:l" l:href="/b"/>`),
+		expectedError{
+			Message: mustBe("unexpected attribute"),
+			Path:    mustBe("DATA/a/@href"),
+			Got:     mustBe(`"/b"`),
+		})
+
+	checkError(t, `<a xmlns:l="urn:l" l:href="/b"/>`, td.XML(`<a xmlns:m="urn:l" m:href="/c"/>`),
+		expectedError{
+			Message:  mustBe("values differ"),
+			Path:     mustBe("DATA/a/@{urn:l}href"),
+			Got:      mustBe(`"/b"`),
+			Expected: mustBe(`"/c"`),
+		})
+
+	err := td.EqDeeplyError(`<a xmlns:l="urn:l" l:href="/b"/>`, td.XML(`<a href="/b"/>`))
+	if test.Error(t, err) {
+		test.IsTrue(t, strings.Contains(err.Error(), "DATA/a/@{urn:l}href: unexpected attribute"), err.Error())
+	}
+
+	checkError(t, `<a>foo</a>`, td.XML(`<a/>`),
+		expectedError{
+			Message:  mustBe("values differ"),
+			Path:     mustBe("DATA/a/text()"),
+			Got:      mustBe(`"foo"`),
+			Expected: mustBe(`""`),
+		})
+
+	checkError(t, `<a><b/><c/><c/></a>`, td.XML(`<a><b/></a>`),
+		expectedError{
+			Message: mustBe("comparing child elements"),
+			Path:    mustBe("DATA/a"),
+			Summary: mustBe("Extra 2 elements: <c>, <c>"),
+		})
+
+	checkError(t, `<a><b/></a>`, td.XML(`<a><b/><c/></a>`),
+		expectedError{
+			Message: mustBe("comparing child elements"),
+			Path:    mustBe("DATA/a"),
+			Summary: mustBe("Missing element: <c>"),
+		})
+
+	checkError(t, `<a><b/>`, td.XML(`<a/>`),
+		expectedError{
+			Message: mustBe("cannot parse got XML document"),
+			Path:    mustBe("DATA"),
+			Summary: mustBe("unexpected EOF at line 1:7 (pos 7)"),
+		})
+
+	checkError(t, map[string]int{}, td.XML(`<a/>`),
+		expectedError{
+			Message: mustBe("xml.Marshal failed"),
+			Path:    mustBe("DATA"),
+			Summary: mustBe("xml: unsupported type: map[string]int"),
+		})
+
+	err = td.EqDeeplyError(`<a><b>12</b></a>`, td.XML(`<a>
+  <b>$^Gt(40)</b>
+</a>`))
+	if test.IsTrue(t, err != nil) {
+		test.IsTrue(t, strings.Contains(err.Error(),
+			"[under operator Gt at line 2:5 (pos 9) inside operator XML at td_xml_test.go:"),
+			err.Error())
+	}
+
+	//
+	// Loading a file
+	tmpDir, err := ioutil.TempDir("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpDir) // clean up
+
+	filename := tmpDir + "/test.xml"
+	err = ioutil.WriteFile(filename,
+		[]byte(`<person id="$1"><name>Bob</name><age>$^Between(40, 45)</age></person>`), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkOK(t, bob, td.XML(filename, 12))
+
+	tmpfile, err := os.Open(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkOK(t, bob, td.XML(tmpfile, td.NotZero()))
+	tmpfile.Close()
+
+	//
+	// Panics
+	test.CheckPanic(t, func() { td.XML("uNkNoWnFiLe.xml") },
+		"XML(): XML file uNkNoWnFiLe.xml cannot be read: ")
+
+	test.CheckPanic(t, func() { td.XML(42) },
+		"usage: XML(STRING_XML|STRING_FILENAME|[]byte|io.Reader, ...), but received int as 1st parameter")
+
+	test.CheckPanic(t, func() { td.XML(errReader{}) },
+		"XML(): XML read error: an error occurred")
+
+	// Numbers are compared exactly
+	checkOK(t, `<a id="9007199254740993">1.50</a>`,
+		td.XML(`<a id="$1">$2</a>`, uint64(9007199254740993), 1.5))
+	checkError(t, `<a id="9007199254740993"/>`,
+		td.XML(`<a id="$1"/>`, uint64(9007199254740992)),
+		expectedError{
+			Message:  mustBe("values differ"),
+			Path:     mustBe("DATA/a/@id"),
+			Got:      mustBe("9007199254740993"),
+			Expected: mustBe("9007199254740992"),
+		})
+
+	test.CheckPanic(t, func() { td.XML(``) },
+		"XML(): XML unmarshal error: no root element found at line 1:0 (pos 0)")
+
+	test.CheckPanic(t, func() { td.XML(`<a/><b/>`) },
+		"XML(): XML unmarshal error: unexpected element after root element at line 1:4 (pos 4)")
+
+	test.CheckPanic(t, func() { td.XML("<a>\n  $1</a>") },
+		`XML(): XML unmarshal error: numeric placeholder "$1", but no params given at line 2:2 (pos 6)`)
+
+	test.CheckPanic(t, func() { td.XML(`<a b="$^Unknown"/>`) },
+		`XML(): XML unmarshal error: bad operator shortcut "$^Unknown" at line 1:6 (pos 6)`)
+
+	test.CheckPanic(t, func() { td.XML(`<a>$^Code(1)</a>`) },
+		`XML(): XML unmarshal error: Code() is not usable in XML() at line 1:3 (pos 3)`)
+
+	//
+	// String
+	test.EqualStr(t,
+		td.XML(`<a xmlns="urn:x" x="1"><b y="$1">$^NotZero</b><c/><c>&lt;c&gt;</c></a>`, 12).String(),
+		`XML(<a xmlns="urn:x" x="1">
+  <b y="12">NotZero()</b>
+  <c/>
+  <c>&lt;c&gt;</c>
+</a>)`)
+}
+
+func TestXMLTypeBehind(t *testing.T) {
+	equalTypes(t, td.XML(`<a/>`), nil)
+}
@@ -0,0 +1,174 @@
+// Copyright (c) 2021, Maxime Soulé
+// All rights reserved.
+//
+// This source code is licensed under the BSD-style license found in the
+// LICENSE file in the root directory of this source tree.
+
+package td
+
+import (
+	"reflect"
+
+	"github.com/maxatome/go-testdeep/internal/color"
+)
+
+// summary(YAML): compares against JSON representation using YAML
+// expected data
+// input(YAML): nil,bool,str,int,float,array,slice,map,struct,ptr
+
+// YAML operator allows to compare the JSON representation of data
+// against "expectedYAML". "expectedYAML" can be a:
+//
+//   - string containing YAML data like `{name: Bob, age: 42}`
+//   - string containing a YAML filename, ending with ".yaml" or
+//     ".yml" (its content is ioutil.ReadFile before unmarshaling)
+//   - []byte containing YAML data
+//   - io.Reader stream containing YAML data (is ioutil.ReadAll before
+//     unmarshaling)
+//
+// It works exactly as JSON operator does, but with YAML data:
+//
+//   td.Cmp(t, gotValue, td.YAML(`
+//   name: Bob   # the name
+//   age:  42
+//   children:
+//     - Alice
+//     - Brian
+//   `))
+//
+// YAML data is parsed by an internal parser supporting the subset of
+// YAML needed to describe JSON-like data: block and flow mappings and
+// sequences, plain and quoted scalars, literal (|) and folded (>)
+// block scalars and comments. Anchors, aliases, tags, complex keys and
+// multiple documents are not supported. Mapping keys are always
+// strings and, as for JSON, numbers are kept as json.Number and
+// compared exactly.
+//
+// As for JSON operator, "expectedYAML" can contain placeholders
+// referencing "params" items, numeric like $2 or named like $name
+// using Tag operator:
+//
+//   td.Cmp(t, gotValue,
+//     td.YAML(`{fullname: $name, age: $2, gender: "$3"}`,
+//       td.Tag("name", td.HasPrefix("Foo")), // matches $1 and $name
+//       td.Between(41, 43),                  // matches only $2
+//       "male"))                             // matches only $3
+//
+// Operators can be directly embedded using $^OperatorName(PARAMS…)
+// notation, their parameters being expressed in JSON (so possibly
+// containing other operators, as in JSON operator), or $^OperatorName
+// for operators taking no parameters:
+//
+//   td.Cmp(t, gotValue, td.YAML(`
+//   fullname: $^HasPrefix("Foo")
+//   age:      $^Between(41, 43)
+//   id:       $^NotZero
+//   details:  $^SuperMapOf({"car": Any("Peugeot", "Tesla")})
+//   `))
+//
+// The operators usable in JSON, as well as the ones registered using
+// RegisterJSONOperator, can be embedded this way.
+//
+// To avoid a legit "$" string prefix causes a bad placeholder error,
+// just double it to escape it, as in "$$info".
+//
+// Note that Lax mode is automatically enabled by YAML operator to
+// simplify numeric tests.
+//
+// TypeBehind method returns the reflect.Type of the "expectedYAML"
+// unmarshal'ed. So it can be bool, string, float64,
+// []interface{}, map[string]interface{} or interface{} in case
+// "expectedYAML" is null.
+func YAML(expectedYAML interface{}, params ...interface{}) TestDeep {
+	b := newBaseOKNil(3)
+
+	v := newYAMLUnmarshaler(b.GetLocation()).unmarshal(expectedYAML, params)
+
+	return &tdJSON{
+		baseOKNil: b,
+		expected:  reflect.ValueOf(v),
+	}
+}
+
+// summary(SubYAMLOf): compares struct or map against JSON
+// representation using YAML expected data but with potentially some
+// exclusions
+// input(SubYAMLOf): map,struct,ptr(ptr on map/struct)
+
+// SubYAMLOf operator allows to compare the JSON representation of
+// data against "expectedYAML". Unlike YAML operator, marshaled data
+// must be a JSON object/map (aka {…}). "expectedYAML" must be a
+// mapping. It works exactly as SubJSONOf operator does, but with YAML
+// data:
+//
+//   got := MyStruct{
+//     Name: "Bob",
+//     Age:  42,
+//   }
+//   td.Cmp(t, got, td.SubYAMLOf(`
+//   name:   Bob
+//   age:    $^Between(40, 45)
+//   gender: male
+//   `)) // succeeds, gender is missing in got
+//
+// See YAML operator for the YAML features supported, placeholders
+// and embedded operators.
+//
+// TypeBehind method returns the map[string]interface{} type.
+func SubYAMLOf(expectedYAML interface{}, params ...interface{}) TestDeep {
+	return newYAMLMap(expectedYAML, params, subMap)
+}
+
+// summary(SuperYAMLOf): compares struct or map against JSON
+// representation using YAML expected data but with potentially
+// extra entries
+// input(SuperYAMLOf): map,struct,ptr(ptr on map/struct)
+
+// SuperYAMLOf operator allows to compare the JSON representation of
+// data against "expectedYAML". Unlike YAML operator, marshaled data
+// must be a JSON object/map (aka {…}). "expectedYAML" must be a
+// mapping. It works exactly as SuperJSONOf operator does, but with
+// YAML data:
+//
+//   got := MyStruct{
+//     Name:   "Bob",
+//     Age:    42,
+//     Gender: "male",
+//   }
+//   td.Cmp(t, got, td.SuperYAMLOf(`
+//   name: Bob
+//   age:  $^Between(40, 45)
+//   `)) // succeeds, gender is ignored
+//
+// See YAML operator for the YAML features supported, placeholders
+// and embedded operators.
+//
+// TypeBehind method returns the map[string]interface{} type.
+func SuperYAMLOf(expectedYAML interface{}, params ...interface{}) TestDeep {
+	return newYAMLMap(expectedYAML, params, superMap)
+}
+
+func newYAMLMap(expectedYAML interface{}, params []interface{}, kind mapKind) TestDeep {
+	b := newBase(4)
+
+	v := newYAMLUnmarshaler(b.GetLocation()).unmarshal(expectedYAML, params)
+
+	_, ok := v.(map[string]interface{})
+	if !ok {
+		panic(color.Bad("%s() only accepts YAML mappings", b.GetLocation().Func))
+	}
+
+	m := tdMapJSON{
+		tdMap: tdMap{
+			tdExpectedType: tdExpectedType{
+				base:         b,
+				expectedType: reflect.TypeOf((map[string]interface{})(nil)),
+			},
+			kind: kind,
+		},
+		expected: reflect.ValueOf(v),
+	}
+	m.populateExpectedEntries(nil, m.expected)
+
+	return &m
+}
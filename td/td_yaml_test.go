@@ -0,0 +1,292 @@
+// Copyright (c) 2021, Maxime Soulé
+// All rights reserved.
+//
+// This source code is licensed under the BSD-style license found in the
+// LICENSE file in the root directory of this source tree.
+
+package td_test
+
+import (
+	"io/ioutil"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/maxatome/go-testdeep/internal/test"
+	"github.com/maxatome/go-testdeep/td"
+)
+
+func TestYAML(t *testing.T) {
+	type MyStruct struct {
+		Name     string   `json:"name"`
+		Age      uint     `json:"age"`
+		Gender   string   `json:"gender"`
+		Children []string `json:"children,omitempty"`
+	}
+
+	//
+	// nil
+	checkOK(t, nil, td.YAML(`null`))
+	checkOK(t, nil, td.YAML(``))
+	checkOK(t, (*int)(nil), td.YAML(`~`))
+
+	//
+	// Basic types
+	checkOK(t, 123, td.YAML(`123`))
+	checkOK(t, true, td.YAML(`true`))
+	checkOK(t, "foobar", td.YAML(`foobar`))
+	checkOK(t, "123", td.YAML(`"123"`))
+
+	//
+	// struct
+	got := MyStruct{Name: "Bob", Age: 42, Gender: "male", Children: []string{"Alice", "Brian"}}
+
+	checkOK(t, got, td.YAML(`
+name:   Bob # the name
+age:    42
+gender: male
+children:
+  - Alice
+  - Brian
+`))
+	checkOK(t, got,
+		td.YAML(`{name: Bob, age: 42, gender: male, children: [Alice, Brian]}`))
+
+	// Placeholders
+	checkOK(t, got,
+		td.YAML(`
+name:     $name
+age:      $2
+gender:   "$3"
+children: $4
+`,
+			td.Tag("name", td.Re(`^Bob`)),
+			td.Between(40, 45),
+			"male",
+			td.Len(2)))
+
+	// Embedded operators
+	checkOK(t, got, td.YAML(`
+name:     $^Re("^Bo")
+age:      $^Between(40, 45)
+gender:   $^NotEmpty
+children: $^Bag("Brian", HasPrefix("A"))
+`))
+	checkOK(t, got, td.YAML(`
+name:     '$^Re("^Bo")'
+age:      $^Between(
+  40,
+  45
+)
+gender:   "$^NotEmpty"
+children: [$^HasPrefix("A"), Brian]
+`))
+
+	// User defined operators
+	checkOK(t, map[string]interface{}{"country": "FR", "age": 42},
+		td.YAML(`{country: $^CountryCode, age: $^AgeBetween(40, 45)}`))
+
+	// Tag, Catch
+	var age int
+	if checkOK(t, got, td.YAML(`
+name:     Bob
+children: $^Len(Tag("count", Gt(1)))
+age:      $^Catch($age, All(Gt(40), $count))
+gender:   male
+`, td.Tag("age", &age))) {
+		test.EqualInt(t, age, 42)
+	}
+
+	// Escaping $ in strings
+	checkOK(t, "$test", td.YAML(`$$test`))
+
+	// Block scalars
+	checkOK(t, "line 1\nline 2\n", td.YAML(`|
+  line 1
+  line 2
+`))
+
+	//
+	// Errors
+	checkError(t, got, td.YAML(`{name: Bob, age: $^Lt(40), gender: male, children: $^Len(Gt(1))}`),
+		expectedError{
+			Message:  mustBe("values differ"),
+			Path:     mustBe(`DATA["age"]`),
+			Got:      mustBe("42"),
+			Expected: mustBe("< 40"),
+		})
+
+	err := td.EqDeeplyError(got, td.YAML(`
+name:     Bob
+age:      $^Lt(40)
+gender:   male
+children: $^Len(Gt(1))`))
+	if test.IsTrue(t, err != nil) {
+		test.IsTrue(t, strings.Contains(err.Error(),
+			"[under operator Lt at line 3:10 (pos 25) inside operator YAML at td_yaml_test.go:"),
+			err.Error())
+	}
+
+	//
+	// Loading a file
+	tmpDir, err := ioutil.TempDir("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpDir) // clean up
+
+	for _, name := range []string{"test.yaml", "test.yml"} {
+		filename := tmpDir + "/" + name
+		err = ioutil.WriteFile(filename, []byte(`
+name:     $name
+age:      $2
+gender:   $^NotEmpty
+children: $^Len(Gt(1))
+`), 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+		checkOK(t, got,
+			td.YAML(filename,
+				td.Tag("name", td.Re(`^Bob`)),
+				td.Between(40, 45)))
+
+		// Reading (a file)
+		tmpfile, err := os.Open(filename)
+		if err != nil {
+			t.Fatal(err)
+		}
+		checkOK(t, got,
+			td.YAML(tmpfile,
+				td.Tag("name", td.Re(`^Bob`)),
+				td.Between(40, 45)))
+		tmpfile.Close()
+	}
+
+	//
+	// Panics
+	test.CheckPanic(t, func() { td.YAML("uNkNoWnFiLe.yaml") },
+		"YAML(): YAML file uNkNoWnFiLe.yaml cannot be read: ")
+
+	test.CheckPanic(t, func() { td.YAML(42) },
+		"usage: YAML(STRING_YAML|STRING_FILENAME|[]byte|io.Reader, ...), but received int as 1st parameter")
+
+	test.CheckPanic(t, func() { td.YAML(errReader{}) },
+		"YAML(): YAML read error: an error occurred")
+
+	test.CheckPanic(t, func() { td.YAML("a: 1\n  b: 2") },
+		"YAML(): YAML unmarshal error: bad indentation of a mapping entry at line 2:2 (pos 7)")
+
+	test.CheckPanic(t, func() { td.YAML("a: $1") },
+		`YAML(): YAML unmarshal error: numeric placeholder "$1", but no params given at line 1:3 (pos 3)`)
+
+	test.CheckPanic(t, func() { td.YAML("a: $^Unknown(1)") },
+		`YAML(): YAML unmarshal error: unknown operator $^Unknown() at line 1:3 (pos 3)`)
+
+	test.CheckPanic(t, func() { td.YAML("a: $^Code(1)") },
+		`YAML(): YAML unmarshal error: Code() is not usable in YAML() at line 1:3 (pos 3)`)
+
+	test.CheckPanic(t, func() { td.YAML("a:\n  b: $^Between(1)") },
+		`YAML(): YAML unmarshal error: Between() requires 2 or 3 parameters at line 2:5 (pos 8)`)
+
+	//
+	// String
+	test.EqualStr(t, td.YAML(`{name: Bob, age: $^Between(40, 45)}`).String(),
+		`YAML({
+       "age": 40 ≤ got ≤ 45,
+       "name": "Bob"
+     })`)
+	test.EqualStr(t, td.YAML(`null`).String(), "YAML(null)")
+}
+
+func TestYAMLTypeBehind(t *testing.T) {
+	equalTypes(t, td.YAML(`false`), true)
+	equalTypes(t, td.YAML(`foo`), "")
+	equalTypes(t, td.YAML(`42`), float64(0))
+	equalTypes(t, td.YAML(`[1, 2, 3]`), ([]interface{})(nil))
+	equalTypes(t, td.YAML(`{a: 12}`), (map[string]interface{})(nil))
+
+	nullType := td.YAML(`null`).TypeBehind()
+	if nullType != reflect.TypeOf((*interface{})(nil)).Elem() {
+		t.Errorf("Failed test: got %s intead of interface {}", nullType)
+	}
+}
+
+func TestSubYAMLOf(t *testing.T) {
+	type MyStruct struct {
+		Name   string `json:"name"`
+		Age    uint   `json:"age"`
+		Gender string `json:"gender"`
+	}
+
+	got := MyStruct{Name: "Bob", Age: 42, Gender: "male"}
+
+	checkOK(t, got, td.SubYAMLOf(`
+name:   $^Re("^Bo")
+age:    $1
+gender: male
+city:   Paris
+`, td.Between(40, 45)))
+
+	checkError(t, got, td.SubYAMLOf(`{name: Bob, age: 42}`),
+		expectedError{
+			Message: mustBe("comparing hash keys of %%"),
+			Path:    mustBe("DATA"),
+			Summary: mustBe(`Extra key: ("gender")`),
+		})
+
+	checkError(t, nil, td.SubYAMLOf(`{name: Bob}`),
+		expectedError{
+			Message:  mustBe("values differ"),
+			Path:     mustBe("DATA"),
+			Got:      mustBe("null"),
+			Expected: mustBe("non-null"),
+		})
+
+	test.CheckPanic(t, func() { td.SubYAMLOf(`[1, 2]`) },
+		"SubYAMLOf() only accepts YAML mappings")
+
+	test.EqualStr(t, td.SubYAMLOf(`{name: Bob}`).String(),
+		`SubYAMLOf({
+            "name": "Bob"
+          })`)
+}
+
+func TestSubYAMLOfTypeBehind(t *testing.T) {
+	equalTypes(t, td.SubYAMLOf(`{a: 1}`), map[string]interface{}{})
+}
+
+func TestSuperYAMLOf(t *testing.T) {
+	type MyStruct struct {
+		Name   string `json:"name"`
+		Age    uint   `json:"age"`
+		Gender string `json:"gender"`
+	}
+
+	got := MyStruct{Name: "Bob", Age: 42, Gender: "male"}
+
+	checkOK(t, got, td.SuperYAMLOf(`
+name: $^Re("^Bo")
+age:  $1
+`, td.Between(40, 45)))
+
+	checkError(t, got, td.SuperYAMLOf(`{name: Bob, city: Paris}`),
+		expectedError{
+			Message: mustBe("comparing hash keys of %%"),
+			Path:    mustBe("DATA"),
+			Summary: mustBe(`Missing key: ("city")`),
+		})
+
+	test.CheckPanic(t, func() { td.SuperYAMLOf(`Bob`) },
+		"SuperYAMLOf() only accepts YAML mappings")
+
+	test.EqualStr(t, td.SuperYAMLOf(`{name: Bob}`).String(),
+		`SuperYAMLOf({
+              "name": "Bob"
+            })`)
+}
+
+func TestSuperYAMLOfTypeBehind(t *testing.T) {
+	equalTypes(t, td.SuperYAMLOf(`{a: 1}`), map[string]interface{}{})
+}
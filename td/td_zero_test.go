@@ -53,14 +53,14 @@ func TestZero(t *testing.T) {
 		expectedError{
 			Message:  mustBe("nil map"),
 			Path:     mustBe("DATA"),
-			Got:      mustBe("not nil"),
+			Got:      mustBe("empty, use NilEqualsEmpty to ignore"),
 			Expected: mustBe("nil"),
 		})
 	checkError(t, []int{}, td.Zero(),
 		expectedError{
 			Message:  mustBe("nil slice"),
 			Path:     mustBe("DATA"),
-			Got:      mustBe("not nil"),
+			Got:      mustBe("empty, use NilEqualsEmpty to ignore"),
 			Expected: mustBe("nil"),
 		})
 	checkError(t, [3]int{0, 12}, td.Zero(),
@@ -20,6 +20,9 @@ var (
 	testDeeper         = reflect.TypeOf((*TestDeep)(nil)).Elem()
 	smuggledGotType    = reflect.TypeOf(SmuggledGot{})
 	smuggledGotPtrType = reflect.TypeOf((*SmuggledGot)(nil))
+
+	// tdPkg is the path of this package, possibly vendored
+	tdPkg = reflect.TypeOf(base{}).PkgPath()
 )
 
 // TestingT is the minimal interface used by Cmp to report errors. It
@@ -88,9 +91,12 @@ func (t *base) setLocation(callDepth int) {
 	}
 
 	// Here package is github.com/maxatome/go-testdeep, or its vendored
-	// counterpart
+	// counterpart, except for custom operators based on Base
 	var pkg string
 	pkg, t.location.Func = pkgFunc(t.location.Func)
+	if pkg != tdPkg {
+		return
+	}
 
 	// Try to go one level upper, if we are still in go-testdeep package
 	cmpLoc, ok := location.New(callDepth + 1)